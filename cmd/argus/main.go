@@ -19,17 +19,30 @@ On a version change, send notification(s) and/or webhook(s).
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	cfg "github.com/release-argus/Argus/config"
 	"github.com/release-argus/Argus/db"
+	"github.com/release-argus/Argus/scheduler"
+	"github.com/release-argus/Argus/service"
+	"github.com/release-argus/Argus/ssh"
 	argus_testing "github.com/release-argus/Argus/testing"
 	"github.com/release-argus/Argus/util"
 	"github.com/release-argus/Argus/web"
 	_ "modernc.org/sqlite"
 )
 
+const (
+	shutdownTimeout = 20 * time.Second // Time to wait for the web server, trackers and in-flight actions to stop.
+	flushTimeout    = 5 * time.Second  // Time to wait for the pending config save and database messages.
+)
+
 var (
 	jLog             util.JLog
 	configFile       = flag.String("config.file", "config.yml", "Argus configuration file path.")
@@ -41,7 +54,8 @@ var (
 
 // main loads the config and then calls service.Track to monitor
 // each Service of the config for version changes and acts on
-// them as defined. It also sets up the Web UI and SaveHandler.
+// them as defined. It also sets up the Web UI and SaveHandler,
+// and shuts them all down gracefully on SIGINT/SIGTERM.
 func main() {
 	flag.Parse()
 	flagset := make(map[string]bool)
@@ -77,11 +91,85 @@ func main() {
 		}
 	}
 
-	go db.Run(&config, &jLog)
+	// Shut down gracefully on SIGINT/SIGTERM.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	dbCtx, stopDB := context.WithCancel(context.Background())
+	dbDone := make(chan struct{})
+	go func() {
+		db.Run(dbCtx, &config, &jLog)
+		close(dbDone)
+	}()
 
 	// Track all targets for changes in version and act on any found changes.
-	go (&config).Service.Track(&config.Order, &config.OrderMutex)
+	(&config).Service.Track(&config.Order, &config.OrderMutex)
 
 	// Web server
-	web.Run(&config, &jLog)
+	webCtx, stopWeb := context.WithCancel(context.Background())
+	webDone := make(chan struct{})
+	go func() {
+		web.Run(webCtx, &config, &jLog)
+		close(webDone)
+	}()
+
+	sig := <-signals
+	// A second signal will exit immediately.
+	signal.Stop(signals)
+	jLog.Info(fmt.Sprintf("Received %s, shutting down", sig), &util.LogFrom{}, true)
+
+	os.Exit(shutdown(&config, stopWeb, webDone, stopDB, dbDone))
+}
+
+// shutdown Argus, stopping the web server and trackers and waiting for in-flight actions
+// (up to shutdownTimeout), then saving any pending config changes and draining the
+// database messages (up to flushTimeout).
+//
+// Returns the exit code, 1 if anything didn't stop cleanly.
+func shutdown(
+	config *cfg.Config,
+	stopWeb context.CancelFunc, webDone <-chan struct{},
+	stopDB context.CancelFunc, dbDone <-chan struct{},
+) (exitCode int) {
+	check := func(what string, err error) {
+		if err != nil {
+			exitCode = 1
+			jLog.Error(
+				fmt.Sprintf("Shutdown of %s failed: %s", what, err),
+				&util.LogFrom{}, true)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Stop serving the Web UI/API (and accepting actions from it).
+	stopWeb()
+	check("the web server", wait(ctx, webDone))
+	// Stop tracking, waiting for the queries running.
+	check("the trackers", scheduler.Default().Shutdown(ctx))
+	// Wait for the Commands/Notifies/WebHooks in-flight.
+	check("the in-flight actions", service.WaitForActions(ctx))
+	ssh.CloseAll()
+
+	// Given their own time, even if the above timed out.
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancelFlush()
+	check("the config save", config.StopSaveHandler(flushCtx))
+	stopDB()
+	check("the database", wait(flushCtx, dbDone))
+
+	if exitCode == 0 {
+		jLog.Info("Shutdown complete", &util.LogFrom{}, true)
+	}
+	return
+}
+
+// wait for `done` to be closed, or `ctx` to be done.
+func wait(ctx context.Context, done <-chan struct{}) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -15,11 +15,14 @@
 package command
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"time"
 
 	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 	metric "github.com/release-argus/Argus/web/metrics"
 )
@@ -63,7 +66,11 @@ func (c *Controller) ExecIndex(logFrom *util.LogFrom, index int) (err error) {
 	command := (*c.Command)[index].ApplyTemplate(c.ServiceStatus)
 
 	// Execute
-	err = command.Exec(logFrom)
+	if c.SSH != "" {
+		err = command.ExecSSH(c.SSH, logFrom)
+	} else {
+		err = command.Exec(logFrom)
+	}
 
 	// Set fail/not
 	failed := err != nil
@@ -104,6 +111,26 @@ func (c *Command) Exec(logFrom *util.LogFrom) error {
 	return err
 }
 
+// ExecSSH runs this Command on the host of the SSH profile `profile` and returns any errors encountered.
+//
+// The Command is killed if it runs for longer than execSSHTimeout.
+func (c *Command) ExecSSH(profile string, logFrom *util.LogFrom) error {
+	jLog.Info(fmt.Sprintf("Executing '%s' on %q", c, profile), logFrom, true)
+	ctx, cancel := context.WithTimeout(context.Background(), execSSHTimeout)
+	defer cancel()
+	out, stderr, err := ssh.Run(ctx, profile, *c)
+	if err != nil && len(bytes.TrimSpace(stderr)) != 0 {
+		err = fmt.Errorf("%w - %s", err, bytes.TrimSpace(stderr))
+	}
+
+	jLog.Error(util.ErrorToString(err), logFrom, err != nil)
+	jLog.Info(string(out), logFrom, err == nil && string(out) != "")
+
+	return err
+}
+
+// ApplyTemplate returns a copy of the Command with Jinja templating applied
+// using the info of the Service (serviceStatus.ServiceInfo).
 func (c *Command) ApplyTemplate(serviceStatus *svcstatus.Status) (command Command) {
 	if serviceStatus == nil {
 		return *c
@@ -111,9 +138,9 @@ func (c *Command) ApplyTemplate(serviceStatus *svcstatus.Status) (command Comman
 
 	command = Command(make([]string, len(*c)))
 	copy(command, *c)
-	serviceInfo := util.ServiceInfo{LatestVersion: serviceStatus.LatestVersion()}
+	serviceInfo := serviceStatus.ServiceInfo()
 	for i := range command {
-		command[i] = util.TemplateString(command[i], serviceInfo)
+		command[i] = util.TemplateString(command[i], *serviceInfo)
 	}
 	return
 }
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/test"
 	"github.com/release-argus/Argus/util"
 )
@@ -34,6 +35,7 @@ func TestCommand_ApplyTemplate(t *testing.T) {
 		want          Command
 		serviceStatus *svcstatus.Status
 		latestVersion string
+		serviceInfo   *util.ServiceInfo
 	}{
 		"command with no templating and non-nil service status": {
 			input:         Command{"ls", "-lah"},
@@ -51,6 +53,16 @@ func TestCommand_ApplyTemplate(t *testing.T) {
 			want:          Command{"ls", "-lah", "1.2.3"},
 			serviceStatus: &svcstatus.Status{},
 			latestVersion: "1.2.3"},
+		"command with templating of the service info": {
+			input:         Command{"deploy", "{{ version }}", "{{ image.digest }}", "{{ artifact.sha256 }}", "{{ release.name }}", "{{ release_notes }}"},
+			want:          Command{"deploy", "1.2.3", "sha256:abc", "def", "v1.2.3", "notes"},
+			serviceStatus: &svcstatus.Status{},
+			serviceInfo: &util.ServiceInfo{
+				LatestVersion: "1.2.3",
+				Image:         &util.ImageInfo{Digest: "sha256:abc"},
+				Artifact:      &util.ArtifactInfo{SHA256: "def"},
+				Release:       &util.ReleaseInfo{Name: "v1.2.3"},
+				ReleaseNotes:  "notes"}},
 	}
 
 	for name, tc := range tests {
@@ -60,6 +72,9 @@ func TestCommand_ApplyTemplate(t *testing.T) {
 			if tc.latestVersion != "" {
 				tc.serviceStatus.SetLatestVersion(tc.latestVersion, false)
 			}
+			if tc.serviceInfo != nil {
+				tc.serviceStatus.SetServiceInfo(func() *util.ServiceInfo { return tc.serviceInfo })
+			}
 
 			// WHEN ApplyTemplate is called on the Command
 			got := tc.input.ApplyTemplate(tc.serviceStatus)
@@ -113,6 +128,55 @@ func TestCommand_Exec(t *testing.T) {
 	}
 }
 
+func TestCommand_ExecSSH(t *testing.T) {
+	// GIVEN SSH profiles and a Command to execute on them
+	ssh.SetProfiles(ssh.Profiles{
+		"unreachable": {
+			Host:       "127.0.0.1:1",
+			User:       "argus",
+			Key:        "/does/not/exist",
+			KnownHosts: "/does/not/exist"}})
+	t.Cleanup(func() { ssh.SetProfiles(nil) })
+	tests := map[string]struct {
+		profile     string
+		errRegex    string
+		stdoutRegex string
+	}{
+		"unknown profile": {
+			profile:     "unknown",
+			errRegex:    `^ssh profile "unknown" not found$`,
+			stdoutRegex: `Executing 'date' on "unknown".*ssh profile "unknown" not found\s+$`},
+		"profile that can't connect": {
+			profile:     "unreachable",
+			errRegex:    `^ssh argus@127.0.0.1:1: failed to read known_hosts: `,
+			stdoutRegex: `Executing 'date' on "unreachable".*failed to read known_hosts`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Cannot run in parallel since we're using stdout
+			releaseStdout := test.CaptureStdout()
+			cmd := Command{"date"}
+
+			// WHEN ExecSSH is called on it
+			err := cmd.ExecSSH(tc.profile, &util.LogFrom{})
+
+			// THEN the err is expected
+			stdout := releaseStdout()
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the stdout is expected
+			if !regexp.MustCompile(`(?s)` + tc.stdoutRegex).MatchString(stdout) {
+				t.Errorf("want match for %q\nnot: %q",
+					tc.stdoutRegex, stdout)
+			}
+		})
+	}
+}
+
 func TestController_ExecIndex(t *testing.T) {
 	// GIVEN a Controller with different Command's to execute
 	announce := make(chan []byte, 8)
@@ -26,6 +26,8 @@ import (
 
 var (
 	jLog *util.JLog
+	// execSSHTimeout is the longest a Command can run on an SSH host before it's killed.
+	execSSHTimeout = time.Hour
 )
 
 // Slice mapping of WebHook.
@@ -49,6 +51,7 @@ type Controller struct {
 	Notifiers      Notifiers               `yaml:"-" json:"-"` // The Notify's to notify on failures
 	ServiceStatus  *svcstatus.Status       `yaml:"-" json:"-"` // Status of the Service (used for templating commands)
 	ParentInterval *string                 `yaml:"-" json:"-"` // Interval between the parent Service's queries
+	SSH            string                  `yaml:"-" json:"-"` // Name of the SSH profile to run the Commands with (empty = run locally)
 	mutex          sync.RWMutex            ``                  // Mutex for concurrent access.
 }
 
@@ -181,6 +181,9 @@ func TestDefaults_SetDefaults(t *testing.T) {
 		"Service.Interval": {
 			got:  defaults.Service.Options.Interval,
 			want: "10m"},
+		"Service.BackoffMax": {
+			got:  defaults.Service.Options.BackoffMax,
+			want: "1h"},
 		"Notify.discord.username": {
 			got:  defaults.Notify["discord"].GetParam("username"),
 			want: "Argus"},
@@ -1067,7 +1070,7 @@ func TestDefaults_Print(t *testing.T) {
 	}{
 		"unmodified hard defaults": {
 			input: &defaults,
-			lines: 164 + len(defaults.Notify)},
+			lines: 167 + len(defaults.Notify)},
 		"empty defaults": {
 			input: &Defaults{},
 			lines: 1},
@@ -89,7 +89,7 @@ func (c *Config) AddService(oldServiceID string, newService *service.Service) (e
 	}
 
 	// Start tracking the service
-	go c.Service[newService.ID].Track()
+	c.Service[newService.ID].Track()
 
 	return
 }
@@ -59,6 +59,8 @@ func testConfig() Config {
 					nil, &databaseChannel, &saveChannel)}},
 		DatabaseChannel: &databaseChannel,
 		SaveChannel:     &saveChannel,
+		saveStop:        make(chan struct{}),
+		saveDone:        make(chan struct{}),
 	}
 }
 
@@ -188,11 +190,13 @@ func testServiceURL(id string) *service.Service {
 	svc.LatestVersion.Init(
 		&latestver.LookupDefaults{}, &latestver.LookupDefaults{},
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	svc.DeployedVersionLookup.Init(
 		&deployedver.LookupDefaults{}, &deployedver.LookupDefaults{},
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	svc.Status.WebURL = &svc.Dashboard.WebURL
 
 	svc.Status.SetLastQueried("")
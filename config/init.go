@@ -19,8 +19,10 @@ import (
 	"os"
 
 	dbtype "github.com/release-argus/Argus/db/types"
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/service"
 	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 	"gopkg.in/yaml.v3"
 )
@@ -32,6 +34,7 @@ func LogInit(log *util.JLog) {
 	}
 
 	jLog = log
+	ratelimit.LogInit(jLog)
 	service.LogInit(jLog)
 }
 
@@ -49,6 +52,11 @@ func (c *Config) Init(setLog bool) {
 
 	c.HardDefaults.Service.Status.SaveChannel = c.SaveChannel
 
+	// SSH profiles for the deployed_version lookups and commands
+	ssh.SetProfiles(c.SSH)
+	// Limits on the outbound requests to each host
+	ratelimit.SetSettings(c.Settings.RateLimit)
+
 	if setLog {
 		jLog.SetTimestamps(*c.Settings.LogTimestamps())
 		jLog.SetLevel(c.Settings.LogLevel())
@@ -100,6 +108,8 @@ func (c *Config) Load(file string, flagset *map[string]bool, log *util.JLog) {
 	c.HardDefaults.Service.Status.SaveChannel = c.SaveChannel
 
 	// SaveHandler that listens for calls to save config changes.
+	c.saveStop = make(chan struct{})
+	c.saveDone = make(chan struct{})
 	go c.SaveHandler()
 
 	c.Init(log != nil)
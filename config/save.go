@@ -16,6 +16,7 @@ package config
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -27,19 +28,50 @@ import (
 )
 
 // SaveHandler will listen to the `SaveChannel` and save the config (after a delay)
-// when it receives a message.
+// when it receives a message, until stopped (with StopSaveHandler).
 func (c *Config) SaveHandler() {
+	defer close(c.saveDone)
 	for {
-		<-*c.SaveChannel
-		waitChannelTimeout(c.SaveChannel)
+		select {
+		case <-*c.SaveChannel:
+		case <-c.saveStop:
+			// Save any changes that were queued as we stopped.
+			if len(*c.SaveChannel) != 0 {
+				c.Save()
+			}
+			return
+		}
+
+		stopped := waitChannelTimeout(c.SaveChannel, c.saveStop)
 		c.Save()
+		if stopped {
+			return
+		}
+	}
+}
+
+// StopSaveHandler stops the SaveHandler, saving any pending changes now rather than
+// after the delay, and waits for it to finish (or `ctx` to be done).
+func (c *Config) StopSaveHandler(ctx context.Context) error {
+	// SaveHandler not started.
+	if c.saveStop == nil {
+		return nil
+	}
+
+	close(c.saveStop)
+	select {
+	case <-c.saveDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // waitChannelTimeout will remove from `channel` and wait 30 seconds.
 //
-// Repeat until channel is empty at the end of the 30 seconds.
-func waitChannelTimeout(channel *chan bool) {
+// Repeat until channel is empty at the end of the 30 seconds,
+// or `stop` is closed (returning true).
+func waitChannelTimeout(channel *chan bool, stop <-chan struct{}) (stopped bool) {
 	for {
 		// Clear queue
 		for len(*channel) != 0 {
@@ -47,11 +79,17 @@ func waitChannelTimeout(channel *chan bool) {
 		}
 
 		// Sleep 30s
-		time.Sleep(30 * time.Second)
+		timer := time.NewTimer(30 * time.Second)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return true
+		}
 
 		// End if channel is still empty
 		if len(*channel) == 0 {
-			break
+			return false
 		}
 	}
 }
@@ -17,6 +17,7 @@
 package config
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -49,11 +50,73 @@ func TestConfig_SaveHandler(t *testing.T) {
 		config.File)
 }
 
+func TestConfig_StopSaveHandler(t *testing.T) {
+	// GIVEN a Config with a SaveHandler running
+	tests := map[string]struct {
+		started  bool
+		messages int
+		wantSave bool
+	}{
+		"not started": {},
+		"nothing to save": {
+			started: true},
+		"save pending": {
+			started:  true,
+			messages: 1,
+			wantSave: true},
+		"save queued": {
+			started:  true,
+			messages: 2,
+			wantSave: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := testConfig()
+			config.File = t.TempDir() + "/config.yml"
+			if !tc.started {
+				config.saveStop = nil
+			} else {
+				go config.SaveHandler()
+			}
+			// AND messages sent to the SaveChannel
+			for i := 0; i < tc.messages; i++ {
+				*config.SaveChannel <- true
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			// WHEN StopSaveHandler is called
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			start := time.Now()
+			err := config.StopSaveHandler(ctx)
+
+			// THEN it stops without waiting for the save delay
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("should have stopped without waiting for the save delay, took %s",
+					elapsed)
+			}
+			// AND the pending changes are saved
+			_, err = os.Stat(config.File)
+			if saved := err == nil; saved != tc.wantSave {
+				t.Errorf("want saved=%t, got %t",
+					tc.wantSave, saved)
+			}
+		})
+	}
+}
+
 func TestWaitChannelTimeout(t *testing.T) {
 	// GIVEN a Config.SaveChannel and messages to send/not send
 	tests := map[string]struct {
 		messages  int
 		timeTaken time.Duration
+		stop      bool
 	}{
 		"no messages": {
 			messages:  0,
@@ -67,6 +130,11 @@ func TestWaitChannelTimeout(t *testing.T) {
 			messages:  2,
 			timeTaken: 2 * TIMEOUT,
 		},
+		"stopped": {
+			messages:  1,
+			timeTaken: 5 * time.Second,
+			stop:      true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -83,12 +151,21 @@ func TestWaitChannelTimeout(t *testing.T) {
 					tc.messages--
 				}
 			}()
+			// AND stopped if it should be
+			stop := make(chan struct{})
+			if tc.stop {
+				time.AfterFunc(tc.timeTaken+time.Second, func() { close(stop) })
+			}
 			time.Sleep(time.Second)
 			start := time.Now().UTC()
-			waitChannelTimeout(config.SaveChannel)
+			stopped := waitChannelTimeout(config.SaveChannel, stop)
 
-			// THEN after `TIMEOUT`, it would have tried to Save
+			// THEN after `TIMEOUT` (or the stop), it would have tried to Save
 			elapsed := time.Since(start)
+			if stopped != tc.stop {
+				t.Errorf("want stopped=%t, got %t",
+					tc.stop, stopped)
+			}
 			if elapsed < tc.timeTaken-100*time.Millisecond ||
 				elapsed > tc.timeTaken+100*time.Millisecond {
 				t.Errorf("waitChannelTimeout should have waited atleast %s, but only waited %s",
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/util"
 )
 
@@ -62,6 +63,8 @@ type SettingsBase struct {
 	Log  LogSettings  `yaml:"log,omitempty"`  // Log settings
 	Data DataSettings `yaml:"data,omitempty"` // Data settings
 	Web  WebSettings  `yaml:"web,omitempty"`  // Web settings
+
+	RateLimit *ratelimit.Settings `yaml:"rate_limit,omitempty"` // Limits on the outbound requests to each host
 }
 
 // CheckValues of the SettingsBase.
@@ -20,6 +20,7 @@ import (
 	dbtype "github.com/release-argus/Argus/db/types"
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	"github.com/release-argus/Argus/service"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 	"github.com/release-argus/Argus/webhook"
 )
@@ -36,9 +37,13 @@ type Config struct {
 	Defaults        Defaults               `yaml:"defaults,omitempty"` // Default values for the various parameters.
 	Notify          shoutrrr.SliceDefaults `yaml:"notify,omitempty"`   // Shoutrrr message(s) to send on a new release.
 	WebHook         webhook.SliceDefaults  `yaml:"webhook,omitempty"`  // WebHook(s) to send on a new release.
+	SSH             ssh.Profiles           `yaml:"ssh,omitempty"`      // SSH connection profiles for deployed_version lookups and commands.
 	Service         service.Slice          `yaml:"service,omitempty"`  // The service(s) to monitor.
 	Order           []string               `yaml:"-"`                  // Ordered slice of all Service(s).
 	OrderMutex      sync.RWMutex           `yaml:"-"`                  // Mutex for the Order/Service slice.
 	DatabaseChannel *chan dbtype.Message   `yaml:"-"`                  // Channel for broadcasts to the Database
 	SaveChannel     *chan bool             `yaml:"-"`                  // Channel for triggering a save of the config.
+
+	saveStop chan struct{} // Closed to stop the SaveHandler.
+	saveDone chan struct{} // Closed when the SaveHandler has stopped.
 }
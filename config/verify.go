@@ -27,6 +27,11 @@ func (c *Config) CheckValues() {
 	var errs error
 	c.Settings.CheckValues()
 
+	if err := c.Settings.RateLimit.CheckValues("  "); err != nil {
+		errs = fmt.Errorf("%ssettings:\\%w",
+			util.ErrorToString(errs), err)
+	}
+
 	if err := c.Defaults.CheckValues(""); err != nil {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), err)
@@ -42,6 +47,11 @@ func (c *Config) CheckValues() {
 			util.ErrorToString(errs), err)
 	}
 
+	if err := c.SSH.CheckValues(""); err != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), err)
+	}
+
 	if err := c.Service.CheckValues(""); err != nil {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), err)
@@ -71,6 +81,10 @@ func (c *Config) Print(flag *bool) {
 		c.WebHook.Print("")
 		fmt.Println()
 	}
+	if len(c.SSH) > 0 {
+		c.SSH.Print("")
+		fmt.Println()
+	}
 	c.Defaults.Print("")
 	if !jLog.Testing {
 		os.Exit(0)
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/service"
 	latestver "github.com/release-argus/Argus/service/latest_version"
 	opt "github.com/release-argus/Argus/service/options"
@@ -102,6 +103,18 @@ func TestConfig_CheckValues(t *testing.T) {
 				`^  test:$`,
 				`^    delay: "3x" <invalid>`},
 		},
+		"invalid Settings.RateLimit": {
+			config: &Config{
+				Settings: Settings{
+					SettingsBase: SettingsBase{
+						RateLimit: &ratelimit.Settings{
+							Limit: ratelimit.Limit{
+								Interval: "4x"}}}}},
+			errRegex: []string{
+				`^settings:$`,
+				`^  rate_limit:$`,
+				`^    interval: "4x" <invalid>`},
+		},
 		"invalid Service": {
 			config: &Config{
 				Service: service.Slice{
@@ -185,7 +198,7 @@ func TestConfig_Print(t *testing.T) {
 		flag  bool
 		lines int
 	}{
-		"flag on":  {flag: true, lines: 190 + len(config.Defaults.Notify)},
+		"flag on":  {flag: true, lines: 193 + len(config.Defaults.Notify)},
 		"flag off": {flag: false},
 	}
 
@@ -15,6 +15,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -23,21 +24,35 @@ import (
 )
 
 // handler will listen to the DatabaseChannel and act on
-// incoming messages.
-func (api *api) handler() {
-	for message := range *api.config.DatabaseChannel {
-		// If the message is to delete a row
-		if message.Delete {
-			api.deleteRow(message.ServiceID)
-			continue
+// incoming messages, until `ctx` is done (then acting on those queued).
+func (api *api) handler(ctx context.Context) {
+	for {
+		select {
+		case message := <-*api.config.DatabaseChannel:
+			api.handle(message)
+		case <-ctx.Done():
+			// Drain the messages queued before we stopped.
+			for len(*api.config.DatabaseChannel) != 0 {
+				api.handle(<-*api.config.DatabaseChannel)
+			}
+			return
 		}
+	}
+}
 
-		// Else, the message is to update a row
-		api.updateRow(
-			message.ServiceID,
-			message.Cells,
-		)
+// handle the `message` from the DatabaseChannel.
+func (api *api) handle(message dbtype.Message) {
+	// If the message is to delete a row
+	if message.Delete {
+		api.deleteRow(message.ServiceID)
+		return
 	}
+
+	// Else, the message is to update a row
+	api.updateRow(
+		message.ServiceID,
+		message.Cells,
+	)
 }
 
 // updateRow will update the cells of the serviceID row.
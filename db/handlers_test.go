@@ -17,6 +17,7 @@
 package db
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -158,7 +159,7 @@ func TestAPI_Handler(t *testing.T) {
 	tAPI := testAPI("TestAPI_Handler", "db")
 	defer dbCleanup(tAPI)
 	tAPI.initialise()
-	go tAPI.handler()
+	go tAPI.handler(context.Background())
 
 	// WHEN a message is sent to the DatabaseChannel targeting latest_version
 	target := "keep0"
@@ -216,3 +217,45 @@ func TestAPI_Handler(t *testing.T) {
 			cell2.Column, cell2.Value, got, want)
 	}
 }
+
+func TestAPI_Handler_Stop(t *testing.T) {
+	// GIVEN a DB with messages queued on the DatabaseChannel
+	tAPI := testAPI("TestAPI_Handler_Stop", "db")
+	defer dbCleanup(tAPI)
+	tAPI.initialise()
+	targets := []string{"keep0", "keep1", "keep2"}
+	for _, target := range targets {
+		*tAPI.config.DatabaseChannel <- dbtype.Message{
+			ServiceID: target,
+			Cells:     []dbtype.Cell{{Column: "latest_version", Value: target + "-9.9.9"}},
+		}
+	}
+
+	// WHEN the handler is run with a done context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan bool)
+	go func() {
+		tAPI.handler(ctx)
+		done <- true
+	}()
+
+	// THEN it returns
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler didn't return when its context was done")
+	}
+	// AND the queued messages were all acted on
+	if got := len(*tAPI.config.DatabaseChannel); got != 0 {
+		t.Errorf("want DatabaseChannel to be drained, %d messages remain",
+			got)
+	}
+	for _, target := range targets {
+		got := queryRow(t, tAPI.db, target)
+		if want := target + "-9.9.9"; got.LatestVersion() != want {
+			t.Errorf("%s: want latest_version=%q, got %q",
+				target, want, got.LatestVersion())
+		}
+	}
+}
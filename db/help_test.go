@@ -17,6 +17,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -44,7 +45,7 @@ func TestMain(m *testing.M) {
 
 	cfg = testConfig()
 	*cfg.Settings.Data.DatabaseFile = databaseFile
-	go Run(cfg, nil)
+	go Run(context.Background(), cfg, nil)
 	time.Sleep(250 * time.Millisecond) // Time for db to start
 
 	exitCode := m.Run()
@@ -15,6 +15,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -67,7 +68,9 @@ func checkFile(path string) {
 	}
 }
 
-func Run(cfg *config.Config, log *util.JLog) {
+// Run the database, acting on messages from the DatabaseChannel until `ctx` is done
+// (and the messages queued by then have been acted on).
+func Run(ctx context.Context, cfg *config.Config, log *util.JLog) {
 	api := api{config: cfg}
 	if log != nil {
 		LogInit(log, cfg.Settings.DataDatabaseFile())
@@ -79,7 +82,7 @@ func Run(cfg *config.Config, log *util.JLog) {
 		api.extractServiceStatus()
 	}
 
-	api.handler()
+	api.handler(ctx)
 }
 
 func (api *api) initialise() {
@@ -140,7 +143,9 @@ func (api *api) extractServiceStatus() {
 		latest_version_timestamp,
 		deployed_version,
 		deployed_version_timestamp,
-		approved_version
+		approved_version,
+		pending_version,
+		pending_version_seen
 	FROM status;`)
 	jLog.Fatal(err, logFrom, err != nil)
 	defer rows.Close()
@@ -155,8 +160,10 @@ func (api *api) extractServiceStatus() {
 			dv  string
 			dvt string
 			av  string
+			pv  string
+			pvs string
 		)
-		err = rows.Scan(&id, &lv, &lvt, &dv, &dvt, &av)
+		err = rows.Scan(&id, &lv, &lvt, &dv, &dvt, &av, &pv, &pvs)
 		jLog.Fatal(
 			fmt.Sprintf("extractServiceStatus row: %s", util.ErrorToString(err)),
 			logFrom,
@@ -166,6 +173,7 @@ func (api *api) extractServiceStatus() {
 		api.config.Service[id].Status.SetDeployedVersion(dv, false)
 		api.config.Service[id].Status.SetDeployedVersionTimestamp(dvt)
 		api.config.Service[id].Status.SetApprovedVersion(av, false)
+		api.config.Service[id].Status.SetPendingVersionSeen(pv, pvs)
 	}
 	err = rows.Err()
 	jLog.Fatal(
@@ -174,6 +182,14 @@ func (api *api) extractServiceStatus() {
 		err != nil)
 }
 
+// addedColumns to the status table since it was first created.
+var addedColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "pending_version", definition: "TEXT DEFAULT ''"},
+	{name: "pending_version_seen", definition: "TEXT DEFAULT ''"}}
+
 // updateTable will update the table for the latest version
 func updateTable(db *sql.DB) {
 	// Get the type of the *_version columns
@@ -186,6 +202,24 @@ func updateTable(db *sql.DB) {
 		updateColumnTypes(db)
 		jLog.Verbose("Finished updating column types", logFrom, true)
 	}
+
+	addColumns(db)
+}
+
+// addColumns will add the addedColumns that the table doesn't have
+func addColumns(db *sql.DB) {
+	for _, column := range addedColumns {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('status') WHERE name = ?", column.name).Scan(&count)
+		jLog.Fatal(fmt.Sprintf("addColumns - %s: %s", column.name, util.ErrorToString(err)), logFrom, err != nil)
+		if count != 0 {
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE status ADD COLUMN %s %s;",
+			column.name, column.definition))
+		jLog.Fatal(fmt.Sprintf("addColumns - %s: %s", column.name, util.ErrorToString(err)), logFrom, err != nil)
+	}
 }
 
 // updateColumnTypes will recreate the table with the correct column types
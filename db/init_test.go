@@ -17,6 +17,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math/rand"
@@ -313,7 +314,7 @@ func TestAPI_extractServiceStatus(t *testing.T) {
 	tAPI := testAPI("TestAPI_extractServiceStatus", "db")
 	defer dbCleanup(tAPI)
 	tAPI.initialise()
-	go tAPI.handler()
+	go tAPI.handler(context.Background())
 	wantStatus := make([]svcstatus.Status, len(cfg.Service))
 	// push a random Status for each Service to the DB
 	index := 0
@@ -325,6 +326,9 @@ func TestAPI_extractServiceStatus(t *testing.T) {
 		wantStatus[index].SetDeployedVersion(fmt.Sprintf("%d.%d.%d", rand.Intn(10), rand.Intn(10), rand.Intn(10)), false)
 		wantStatus[index].SetDeployedVersionTimestamp(time.Now().UTC().Format(time.RFC3339))
 		wantStatus[index].SetApprovedVersion(fmt.Sprintf("%d.%d.%d", rand.Intn(10), rand.Intn(10), rand.Intn(10)), false)
+		wantStatus[index].SetPendingVersionSeen(
+			fmt.Sprintf("%d.%d.%d", rand.Intn(10), rand.Intn(10), rand.Intn(10)),
+			time.Now().UTC().Format(time.RFC3339))
 
 		*tAPI.config.DatabaseChannel <- dbtype.Message{
 			ServiceID: id,
@@ -334,7 +338,9 @@ func TestAPI_extractServiceStatus(t *testing.T) {
 				{Column: "latest_version_timestamp", Value: wantStatus[index].LatestVersionTimestamp()},
 				{Column: "deployed_version", Value: wantStatus[index].DeployedVersion()},
 				{Column: "deployed_version_timestamp", Value: wantStatus[index].DeployedVersionTimestamp()},
-				{Column: "approved_version", Value: wantStatus[index].ApprovedVersion()}}}
+				{Column: "approved_version", Value: wantStatus[index].ApprovedVersion()},
+				{Column: "pending_version", Value: wantStatus[index].PendingVersion()},
+				{Column: "pending_version_seen", Value: wantStatus[index].PendingVersionSeen()}}}
 		// Clear the Status in the Config
 		svc.Status = *svcstatus.New(
 			svc.Status.AnnounceChannel, svc.Status.DatabaseChannel, svc.Status.SaveChannel,
@@ -370,6 +376,16 @@ func TestAPI_extractServiceStatus(t *testing.T) {
 			t.Errorf(errMsg,
 				"approved_version", row.ApprovedVersion(), row, wantStatus[i].String())
 		}
+		// AND the pending version (and when it was first seen) are in the Config
+		got := &tAPI.config.Service[*wantStatus[i].ServiceID].Status
+		if got.PendingVersion() != wantStatus[i].PendingVersion() {
+			t.Errorf(errMsg,
+				"pending_version", got.PendingVersion(), got.String(), wantStatus[i].String())
+		}
+		if got.PendingVersionSeen() != wantStatus[i].PendingVersionSeen() {
+			t.Errorf(errMsg,
+				"pending_version_seen", got.PendingVersionSeen(), got.String(), wantStatus[i].String())
+		}
 	}
 }
 
@@ -443,6 +459,15 @@ func Test_UpdateColumnTypes(t *testing.T) {
 						row, "TEXT", columnType)
 				}
 			}
+			// AND the added columns are there
+			for _, column := range addedColumns {
+				var count int
+				db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('status') WHERE name = ?", column.name).Scan(&count)
+				if count != 1 {
+					t.Errorf("Expected column %q to have been added",
+						column.name)
+				}
+			}
 			// AND all rows were carried over
 			got := queryRow(t, db, id)
 			if got.LatestVersion() != latest_version || got.LatestVersionTimestamp() != latest_version_timestamp ||
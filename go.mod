@@ -5,13 +5,16 @@ go 1.22
 toolchain go1.22.0
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/Masterminds/semver/v3 v3.3.0
 	github.com/containrrr/shoutrrr v0.8.0
 	github.com/flosch/pongo2/v5 v5.0.0
 	github.com/gorilla/mux v1.8.1
 	github.com/gorilla/websocket v1.5.3
 	github.com/prometheus/client_golang v1.20.4
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/vearutop/statigz v1.4.3
+	golang.org/x/crypto v0.24.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,244 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+// defaultLimiter is the Limiter shared by all outbound requests.
+var defaultLimiter = New(nil)
+
+// Limiter of the outbound requests to each host.
+type Limiter struct {
+	mutex    sync.Mutex
+	settings *Settings        // Limits of the hosts.
+	hosts    map[string]*host // State of the requests to each host, mapped by hostname.
+}
+
+// host is the state of the requests to a host.
+type host struct {
+	requests uint          // Requests allowed per interval (0 = unlimited).
+	interval time.Duration // Interval that the requests are allowed in.
+	sent     []time.Time   // Times of the requests in the last interval, oldest first.
+	slots    chan struct{} // Slots of the concurrent requests, one is taken for each request (nil = unlimited).
+}
+
+// New returns a Limiter with the limits of `settings`.
+func New(settings *Settings) *Limiter {
+	return &Limiter{
+		settings: settings,
+		hosts:    make(map[string]*host)}
+}
+
+// SetSettings of the Limiter shared by all outbound requests.
+func SetSettings(settings *Settings) {
+	defaultLimiter.SetSettings(settings)
+}
+
+// Wait on the Limiter shared by all outbound requests. (See Limiter.Wait)
+func Wait(ctx context.Context, hostname string, logFrom *util.LogFrom) (done func(), err error) {
+	return defaultLimiter.Wait(ctx, hostname, logFrom)
+}
+
+// SetSettings of the Limiter, resetting the state of every host.
+func (l *Limiter) SetSettings(settings *Settings) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.settings = settings
+	l.hosts = make(map[string]*host)
+}
+
+// host returns the state of the requests to `hostname`.
+//
+// (l.mutex must be held)
+func (l *Limiter) host(hostname string) *host {
+	if h := l.hosts[hostname]; h != nil {
+		return h
+	}
+
+	limit := l.settings.limitOf(hostname)
+	h := &host{
+		requests: limit.GetRequests(),
+		interval: limit.GetIntervalDuration()}
+	if maxConcurrent := limit.GetMaxConcurrent(); maxConcurrent != 0 {
+		h.slots = make(chan struct{}, maxConcurrent)
+	}
+	l.hosts[hostname] = h
+	return h
+}
+
+// reserve a request at `now`, returning the time to wait until one is allowed (0 if reserved).
+//
+// (Limiter.mutex must be held)
+func (h *host) reserve(now time.Time) time.Duration {
+	if h.requests == 0 {
+		return 0
+	}
+
+	// Forget the requests from before this interval.
+	windowStart := now.Add(-h.interval)
+	expired := 0
+	for expired < len(h.sent) && !h.sent[expired].After(windowStart) {
+		expired++
+	}
+	h.sent = h.sent[expired:]
+
+	if uint(len(h.sent)) < h.requests {
+		h.sent = append(h.sent, now)
+		return 0
+	}
+	return h.sent[0].Sub(windowStart)
+}
+
+// Wait until a request to `hostname` is allowed by its limits,
+// returning the func to call once the request has finished.
+func (l *Limiter) Wait(ctx context.Context, hostname string, logFrom *util.LogFrom) (done func(), err error) {
+	hostname = strings.ToLower(hostname)
+	start := time.Now()
+	waited := false
+	l.mutex.Lock()
+	h := l.host(hostname)
+	l.mutex.Unlock()
+
+	// Wait for a free slot.
+	done = func() {}
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		default:
+			waited = true
+			select {
+			case h.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		done = sync.OnceFunc(func() { <-h.slots })
+	}
+
+	// Wait for the rate.
+	for {
+		l.mutex.Lock()
+		wait := h.reserve(time.Now())
+		l.mutex.Unlock()
+		if wait == 0 {
+			break
+		}
+
+		waited = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			done()
+			return nil, ctx.Err()
+		}
+	}
+
+	if waited {
+		waitTime := time.Since(start)
+		metric.RateLimitWaits.WithLabelValues(hostname).Inc()
+		metric.RateLimitWaitSeconds.WithLabelValues(hostname).Add(waitTime.Seconds())
+		if jLog != nil {
+			if logFrom == nil {
+				logFrom = &util.LogFrom{}
+			}
+			jLog.Verbose(
+				fmt.Sprintf("Waited %s for the rate limit of %q",
+					waitTime.Round(time.Millisecond), hostname),
+				logFrom, true)
+		}
+	}
+	return done, nil
+}
+
+// Transport returns a RoundTripper that sends requests with `base` (http.DefaultTransport if nil)
+// once the shared Limiter allows them.
+func Transport(base http.RoundTripper, logFrom *util.LogFrom) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{
+		base:    base,
+		logFrom: logFrom}
+}
+
+// transport waits on the shared Limiter before each request.
+type transport struct {
+	base    http.RoundTripper
+	logFrom *util.LogFrom
+}
+
+// RoundTrip the request once it's allowed, holding its slot until the response body is closed
+// or the request's context is done. Requests without a deadline are given one of util.HTTPTimeout,
+// so that a stalled response can't hold its slot forever.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if _, ok := ctx.Deadline(); !ok {
+		ctx, cancel = context.WithTimeout(ctx, util.HTTPTimeout)
+		req = req.WithContext(ctx)
+	}
+
+	wait, err := Wait(ctx, req.URL.Hostname(), t.logFrom)
+	if err != nil {
+		cancel()
+		//nolint:wrapcheck
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, wait)
+	done := func() {
+		stop()
+		wait()
+		cancel()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		done()
+		//nolint:wrapcheck
+		return nil, err
+	}
+	resp.Body = &body{
+		ReadCloser: resp.Body,
+		done:       done}
+	return resp, nil
+}
+
+// body of a response, calling done when it's closed.
+type body struct {
+	io.ReadCloser
+	done func()
+}
+
+// Close the body, freeing its slot.
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	//nolint:wrapcheck
+	return err
+}
@@ -0,0 +1,289 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build unit
+
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/release-argus/Argus/test"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+func TestLimiter_Wait_Requests(t *testing.T) {
+	// GIVEN a Limiter allowing 2 requests every 300ms to a host
+	limiter := New(&Settings{
+		Hosts: []*HostLimit{
+			{Host: "limited.example.com",
+				Limit: Limit{
+					Requests: test.UIntPtr(2),
+					Interval: "300ms"}}}})
+	hostname := "limited.example.com"
+	waitsBefore := testutil.ToFloat64(metric.RateLimitWaits.WithLabelValues(hostname))
+
+	// WHEN 3 requests are made to that host
+	start := time.Now()
+	var took []time.Duration
+	for i := 0; i < 3; i++ {
+		done, err := limiter.Wait(context.Background(), hostname, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		took = append(took, time.Since(start))
+		done()
+	}
+
+	// THEN the first 2 are sent straight away
+	for i := 0; i < 2; i++ {
+		if took[i] > 100*time.Millisecond {
+			t.Errorf("want request %d sent straight away, took %s",
+				i+1, took[i])
+		}
+	}
+	// AND the 3rd waits for the interval to pass
+	if took[2] < 300*time.Millisecond {
+		t.Errorf("want request 3 to wait for the interval, took %s",
+			took[2])
+	}
+	// AND the wait is counted
+	if got := testutil.ToFloat64(metric.RateLimitWaits.WithLabelValues(hostname)); got != waitsBefore+1 {
+		t.Errorf("want %v waits counted, got %v",
+			waitsBefore+1, got)
+	}
+	// AND other hosts aren't limited
+	done, _ := limiter.Wait(context.Background(), "other.example.com", nil)
+	done()
+	if took := time.Since(start) - took[2]; took > 100*time.Millisecond {
+		t.Errorf("want requests to other hosts sent straight away, took %s", took)
+	}
+}
+
+func TestLimiter_Wait_MaxConcurrent(t *testing.T) {
+	// GIVEN a Limiter allowing 2 concurrent requests to each host
+	limiter := New(&Settings{
+		Limit: Limit{
+			MaxConcurrent: test.UIntPtr(2)}})
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+
+	// WHEN 5 requests are made to the same host at once
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := limiter.Wait(context.Background(), "concurrent.example.com", nil)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			now := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if now <= max || atomic.CompareAndSwapInt32(&maxRunning, max, now) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			done()
+		}()
+	}
+	wg.Wait()
+
+	// THEN only 2 are sent at once
+	if got := atomic.LoadInt32(&maxRunning); got != 2 {
+		t.Errorf("want 2 requests at once, got %d", got)
+	}
+}
+
+func TestLimiter_Wait_Cancelled(t *testing.T) {
+	// GIVEN a Limiter with a host that's at its limits
+	tests := map[string]struct {
+		limit Limit
+	}{
+		"requests": {
+			limit: Limit{
+				Requests: test.UIntPtr(1),
+				Interval: "1h"}},
+		"max_concurrent": {
+			limit: Limit{
+				MaxConcurrent: test.UIntPtr(1)}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := New(&Settings{Limit: tc.limit})
+			hostname := "cancelled.example.com"
+			if _, err := limiter.Wait(context.Background(), hostname, nil); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// WHEN a request waits with a context that's cancelled
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			done, err := limiter.Wait(ctx, hostname, nil)
+
+			// THEN the wait is abandoned with the error of the context
+			if err != context.DeadlineExceeded {
+				t.Errorf("want err %q, got %v",
+					context.DeadlineExceeded, err)
+			}
+			if done != nil {
+				t.Error("want a nil done func")
+			}
+		})
+	}
+}
+
+func TestLimiter_SetSettings(t *testing.T) {
+	// GIVEN a Limiter with a host that's at its limits
+	limiter := New(&Settings{
+		Limit: Limit{
+			Requests: test.UIntPtr(1),
+			Interval: "1h"}})
+	hostname := "reset.example.com"
+	limiter.Wait(context.Background(), hostname, nil)
+
+	// WHEN its Settings are changed
+	limiter.SetSettings(nil)
+
+	// THEN requests to the host are no longer limited
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx, hostname, nil); err != nil {
+		t.Errorf("want the request allowed, got %s", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	// GIVEN a server, and the shared Limiter allowing 1 concurrent request to it
+	release := make(chan bool)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	serverURL, _ := url.Parse(server.URL)
+	SetSettings(&Settings{
+		Hosts: []*HostLimit{
+			{Host: serverURL.Hostname(),
+				Limit: Limit{MaxConcurrent: test.UIntPtr(1)}}}})
+	t.Cleanup(func() { SetSettings(nil) })
+	client := &http.Client{Transport: Transport(nil, nil)}
+
+	// WHEN 2 requests are made to it with the Transport
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	secondDone := make(chan bool)
+	go func() {
+		defer close(secondDone)
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	// THEN the 2nd isn't sent whilst the body of the 1st is open
+	time.Sleep(200 * time.Millisecond)
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("want 1 request sent whilst the 1st body is open, got %d", got)
+	}
+	// AND it's sent once the 1st body is closed
+	release <- true
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	release <- true
+	select {
+	case <-secondDone:
+	case <-time.After(time.Second):
+		t.Fatal("2nd request wasn't sent after the 1st body was closed")
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("want 2 requests sent, got %d", got)
+	}
+}
+
+func TestTransport_Deadline(t *testing.T) {
+	// GIVEN a server that stalls the body of the 1st response,
+	// and the shared Limiter allowing 1 concurrent request to it
+	release := make(chan bool)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-release
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	serverURL, _ := url.Parse(server.URL)
+	SetSettings(&Settings{
+		Hosts: []*HostLimit{
+			{Host: serverURL.Hostname(),
+				Limit: Limit{MaxConcurrent: test.UIntPtr(1)}}}})
+	t.Cleanup(func() { SetSettings(nil) })
+	client := &http.Client{Transport: Transport(nil, nil)}
+
+	// WHEN the 1st request has a deadline, and its body is never closed
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// THEN its slot is freed at the deadline, so the 2nd request is sent
+	secondDone := make(chan bool)
+	go func() {
+		defer close(secondDone)
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+	select {
+	case <-secondDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("2nd request wasn't sent after the deadline of the 1st")
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("want 2 requests sent, got %d", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	jLog *util.JLog
+)
+
+// LogInit for this package.
+func LogInit(log *util.JLog) {
+	jLog = log
+}
+
+// Settings of the limits on the outbound requests to each host.
+type Settings struct {
+	Limit `yaml:",inline" json:",inline"` // Limit of each host that doesn't match any of the Hosts.
+
+	Hosts []*HostLimit `yaml:"hosts,omitempty" json:"hosts,omitempty"` // Limits of the hosts matching a pattern (first match wins).
+}
+
+// Limit on the requests to a host.
+type Limit struct {
+	Requests      *uint  `yaml:"requests,omitempty" json:"requests,omitempty"`             // Requests allowed to the host per Interval (0 = unlimited)
+	Interval      string `yaml:"interval,omitempty" json:"interval,omitempty"`             // AhBmCs = Interval that the Requests are allowed in (default 1m)
+	MaxConcurrent *uint  `yaml:"max_concurrent,omitempty" json:"max_concurrent,omitempty"` // Requests allowed to the host at once (0 = unlimited)
+}
+
+// HostLimit is the Limit of the hosts matching the Host pattern.
+type HostLimit struct {
+	Host  string `yaml:"host,omitempty" json:"host,omitempty"` // Pattern of the hosts, e.g. 'api.github.com' or '*.docker.io'
+	Limit `yaml:",inline" json:",inline"`
+}
+
+// String returns a string representation of the Settings.
+func (s *Settings) String(prefix string) (str string) {
+	if s != nil {
+		str = util.ToYAMLString(s, prefix)
+	}
+	return
+}
+
+// limitOf returns the Limit of `host`,
+// the Limit of the first Hosts pattern it matches, falling back to the Settings for unset values.
+func (s *Settings) limitOf(host string) (limit Limit) {
+	if s == nil {
+		return
+	}
+
+	limit = s.Limit
+	host = strings.ToLower(host)
+	for _, hostLimit := range s.Hosts {
+		if matched, _ := path.Match(strings.ToLower(hostLimit.Host), host); !matched {
+			continue
+		}
+
+		if hostLimit.Requests != nil {
+			limit.Requests = hostLimit.Requests
+		}
+		if hostLimit.Interval != "" {
+			limit.Interval = hostLimit.Interval
+		}
+		if hostLimit.MaxConcurrent != nil {
+			limit.MaxConcurrent = hostLimit.MaxConcurrent
+		}
+		break
+	}
+	return
+}
+
+// GetRequests allowed per Interval (0 = unlimited).
+func (l *Limit) GetRequests() uint {
+	return util.DefaultIfNil(l.Requests)
+}
+
+// GetIntervalDuration returns the Interval that the Requests are allowed in.
+func (l *Limit) GetIntervalDuration() time.Duration {
+	if interval, err := time.ParseDuration(l.Interval); err == nil && interval > 0 {
+		return interval
+	}
+	return time.Minute
+}
+
+// GetMaxConcurrent requests allowed at once (0 = unlimited).
+func (l *Limit) GetMaxConcurrent() uint {
+	return util.DefaultIfNil(l.MaxConcurrent)
+}
@@ -0,0 +1,110 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build unit
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/test"
+)
+
+func TestSettings_LimitOf(t *testing.T) {
+	// GIVEN Settings with host patterns
+	settings := &Settings{
+		Limit: Limit{
+			Requests:      test.UIntPtr(60),
+			MaxConcurrent: test.UIntPtr(4)},
+		Hosts: []*HostLimit{
+			{Host: "api.github.com",
+				Limit: Limit{
+					Requests: test.UIntPtr(30),
+					Interval: "1h"}},
+			{Host: "*.github.com",
+				Limit: Limit{
+					Requests:      test.UIntPtr(10),
+					MaxConcurrent: test.UIntPtr(0)}},
+			{Host: "*.docker.io",
+				Limit: Limit{
+					MaxConcurrent: test.UIntPtr(1)}}}}
+	tests := map[string]struct {
+		settings          *Settings
+		host              string
+		wantRequests      uint
+		wantInterval      time.Duration
+		wantMaxConcurrent uint
+	}{
+		"nil settings are unlimited": {
+			settings:          nil,
+			host:              "example.com",
+			wantRequests:      0,
+			wantInterval:      time.Minute,
+			wantMaxConcurrent: 0},
+		"no match uses the settings": {
+			settings:          settings,
+			host:              "example.com",
+			wantRequests:      60,
+			wantInterval:      time.Minute,
+			wantMaxConcurrent: 4},
+		"first match wins": {
+			settings:          settings,
+			host:              "api.github.com",
+			wantRequests:      30,
+			wantInterval:      time.Hour,
+			wantMaxConcurrent: 4},
+		"wildcard match": {
+			settings:          settings,
+			host:              "codeload.github.com",
+			wantRequests:      10,
+			wantInterval:      time.Minute,
+			wantMaxConcurrent: 0},
+		"match is case-insensitive": {
+			settings:          settings,
+			host:              "Registry-1.Docker.IO",
+			wantRequests:      60,
+			wantInterval:      time.Minute,
+			wantMaxConcurrent: 1},
+		"wildcard doesn't match the bare domain": {
+			settings:          settings,
+			host:              "docker.io",
+			wantRequests:      60,
+			wantInterval:      time.Minute,
+			wantMaxConcurrent: 4},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN limitOf is called for the host
+			got := tc.settings.limitOf(tc.host)
+
+			// THEN the Limit of that host is returned
+			if gotRequests := got.GetRequests(); gotRequests != tc.wantRequests {
+				t.Errorf("want %d requests, got %d",
+					tc.wantRequests, gotRequests)
+			}
+			if gotInterval := got.GetIntervalDuration(); gotInterval != tc.wantInterval {
+				t.Errorf("want an interval of %s, got %s",
+					tc.wantInterval, gotInterval)
+			}
+			if gotMaxConcurrent := got.GetMaxConcurrent(); gotMaxConcurrent != tc.wantMaxConcurrent {
+				t.Errorf("want %d max_concurrent, got %d",
+					tc.wantMaxConcurrent, gotMaxConcurrent)
+			}
+		})
+	}
+}
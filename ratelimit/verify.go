@@ -0,0 +1,92 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// CheckValues of the Settings.
+func (s *Settings) CheckValues(prefix string) (errs error) {
+	if s == nil {
+		return
+	}
+
+	if err := s.Limit.CheckValues(prefix + "  "); err != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), err)
+	}
+
+	// Hosts
+	var hostErrs error
+	for index, hostLimit := range s.Hosts {
+		if err := hostLimit.CheckValues(prefix + "      "); err != nil {
+			hostErrs = fmt.Errorf("%s%s    item_%d:\\%w",
+				util.ErrorToString(hostErrs), prefix, index, err)
+		}
+	}
+	if hostErrs != nil {
+		errs = fmt.Errorf("%s%s  hosts:\\%w",
+			util.ErrorToString(errs), prefix, hostErrs)
+	}
+
+	if errs != nil {
+		errs = fmt.Errorf("%srate_limit:\\%w",
+			prefix, errs)
+	}
+	return
+}
+
+// CheckValues of the HostLimit.
+func (h *HostLimit) CheckValues(prefix string) (errs error) {
+	if h == nil || h.Host == "" {
+		errs = fmt.Errorf("%shost: <required> (pattern of the hosts, e.g. '*.github.com')\\",
+			prefix)
+	} else if _, err := path.Match(h.Host, ""); err != nil {
+		errs = fmt.Errorf("%shost: %q <invalid> (%s)\\",
+			prefix, h.Host, err)
+	}
+	if h == nil {
+		return
+	}
+
+	if err := h.Limit.CheckValues(prefix); err != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), err)
+	}
+	return
+}
+
+// CheckValues of the Limit.
+func (l *Limit) CheckValues(prefix string) (errs error) {
+	// Interval
+	if l.Interval != "" {
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(l.Interval); err == nil {
+			l.Interval += "s"
+		}
+		if interval, err := time.ParseDuration(l.Interval); err != nil || interval <= 0 {
+			errs = fmt.Errorf("%s%sinterval: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, l.Interval)
+		}
+	}
+
+	return
+}
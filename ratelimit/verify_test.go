@@ -0,0 +1,124 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build unit
+
+package ratelimit
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestSettings_CheckValues(t *testing.T) {
+	// GIVEN Settings
+	tests := map[string]struct {
+		settings     *Settings
+		wantInterval string
+		errRegex     []string
+	}{
+		"nil": {
+			settings: nil,
+			errRegex: []string{`^$`}},
+		"valid": {
+			settings: &Settings{
+				Limit: Limit{
+					Requests:      test.UIntPtr(60),
+					Interval:      "1m",
+					MaxConcurrent: test.UIntPtr(4)},
+				Hosts: []*HostLimit{
+					{Host: "*.github.com",
+						Limit: Limit{Requests: test.UIntPtr(30)}}}},
+			errRegex: []string{`^$`}},
+		"integer interval is seconds": {
+			settings: &Settings{
+				Limit: Limit{
+					Interval: "30"}},
+			wantInterval: "30s",
+			errRegex:     []string{`^$`}},
+		"invalid interval": {
+			settings: &Settings{
+				Limit: Limit{
+					Interval: "ten"}},
+			errRegex: []string{
+				`^rate_limit:$`,
+				`^  interval: "ten" <invalid>`}},
+		"zero interval": {
+			settings: &Settings{
+				Limit: Limit{
+					Interval: "0s"}},
+			errRegex: []string{
+				`^  interval: "0s" <invalid>`}},
+		"host without a pattern": {
+			settings: &Settings{
+				Hosts: []*HostLimit{
+					{Limit: Limit{Requests: test.UIntPtr(1)}}}},
+			errRegex: []string{
+				`^rate_limit:$`,
+				`^  hosts:$`,
+				`^    item_0:$`,
+				`^      host: <required>`}},
+		"nil host": {
+			settings: &Settings{
+				Hosts: []*HostLimit{nil}},
+			errRegex: []string{
+				`^    item_0:$`,
+				`^      host: <required>`}},
+		"invalid host pattern and interval": {
+			settings: &Settings{
+				Hosts: []*HostLimit{
+					{Host: "example.com"},
+					{Host: "[.example.com",
+						Limit: Limit{Interval: "ten"}}}},
+			errRegex: []string{
+				`^    item_1:$`,
+				`^      host: "\[\.example\.com" <invalid>`,
+				`^      interval: "ten" <invalid>`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN CheckValues is called on them
+			err := tc.settings.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND the interval is converted to seconds
+			if tc.wantInterval != "" && tc.settings.Interval != tc.wantInterval {
+				t.Errorf("want interval %q, got %q",
+					tc.wantInterval, tc.settings.Interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+// DefaultWorkers is the number of Jobs the default Scheduler runs at once.
+const DefaultWorkers = 32
+
+var (
+	defaultScheduler     *Scheduler // Scheduler used by the package-level functions.
+	defaultSchedulerOnce sync.Once  // Create the defaultScheduler on first use.
+)
+
+// Default returns the Scheduler used by the package-level functions.
+func Default() *Scheduler {
+	defaultSchedulerOnce.Do(func() {
+		defaultScheduler = New(DefaultWorkers)
+	})
+	return defaultScheduler
+}
+
+// Add the Job with `id` to the default Scheduler. (See Scheduler.Add)
+func Add(id string, delay time.Duration, job Job) {
+	Default().Add(id, delay, job)
+}
+
+// Remove the Jobs with `ids` from the default Scheduler. (See Scheduler.Remove)
+func Remove(ids ...string) {
+	Default().Remove(ids...)
+}
+
+// Wake the Job with `id` on the default Scheduler. (See Scheduler.Wake)
+func Wake(id string) {
+	Default().Wake(id)
+}
+
+// New returns a Scheduler that runs up to `workers` Jobs at once.
+func New(workers int) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		tasks:   make(map[string]*task),
+		slots:   make(chan struct{}, workers),
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel}
+
+	go s.dispatch()
+	return s
+}
+
+// Add the Job with `id`, to run after `delay`, replacing (and cancelling) any Job with that `id`.
+func (s *Scheduler) Add(id string, delay time.Duration, job Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remove(id)
+	ctx, cancel := context.WithCancel(s.ctx)
+	t := &task{
+		id:     id,
+		job:    job,
+		next:   time.Now().Add(delay),
+		ctx:    ctx,
+		cancel: cancel}
+	s.tasks[id] = t
+	heap.Push(&s.queue, t)
+	metric.SchedulerJobs.Set(float64(len(s.tasks)))
+	s.signal()
+}
+
+// Remove the Jobs with `ids`, cancelling the context of any that are running.
+func (s *Scheduler) Remove(ids ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, id := range ids {
+		s.remove(id)
+	}
+	metric.SchedulerJobs.Set(float64(len(s.tasks)))
+	s.signal()
+}
+
+// remove the Job with `id`.
+//
+// (s.mutex must be held)
+func (s *Scheduler) remove(id string) {
+	t := s.tasks[id]
+	if t == nil {
+		return
+	}
+
+	t.cancel()
+	delete(s.tasks, id)
+	if t.index != -1 {
+		heap.Remove(&s.queue, t.index)
+	}
+}
+
+// Wake the Job with `id` to run now,
+// or straight after its current run finishes if it's running.
+func (s *Scheduler) Wake(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t := s.tasks[id]
+	if t == nil {
+		return
+	}
+
+	if t.running {
+		t.woken = true
+		return
+	}
+	t.next = time.Now()
+	heap.Fix(&s.queue, t.index)
+	s.signal()
+}
+
+// Scheduled returns whether a Job with `id` is scheduled.
+func (s *Scheduler) Scheduled(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.tasks[id] != nil
+}
+
+// Stop the Scheduler, cancelling the context of all Jobs.
+func (s *Scheduler) Stop() {
+	s.cancel()
+}
+
+// Shutdown the Scheduler, cancelling the context of all Jobs and waiting for
+// those running to return (or `ctx` to be done).
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.Stop()
+	// Wait for the dispatcher to stop, so no more Jobs are started.
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// signal the dispatcher that the queue has changed.
+//
+// (s.mutex must be held)
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch the Jobs to the workers as they become due, until stopped.
+func (s *Scheduler) dispatch() {
+	defer close(s.stopped)
+	for {
+		s.mutex.Lock()
+		now := time.Now()
+		metric.SchedulerQueueDepth.Set(float64(s.queue.due(now)))
+
+		// Run the next Job if it's due.
+		if len(s.queue) != 0 && !s.queue[0].next.After(now) {
+			t := heap.Pop(&s.queue).(*task)
+			t.running = true
+			s.mutex.Unlock()
+
+			// Wait for a free worker.
+			select {
+			case s.slots <- struct{}{}:
+				// Don't start Jobs once stopped.
+				if s.ctx.Err() != nil {
+					<-s.slots
+					return
+				}
+				s.running.Add(1)
+				go s.run(t)
+			case <-s.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		// Wait until the next Job is due, or the queue changes.
+		wait := time.Hour
+		if len(s.queue) != 0 {
+			wait = s.queue[0].next.Sub(now)
+		}
+		s.mutex.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
+
+// run the task, and queue its next run.
+func (s *Scheduler) run(t *task) {
+	defer func() {
+		<-s.slots
+		s.running.Done()
+	}()
+	// Removed whilst waiting for a worker.
+	if t.ctx.Err() != nil {
+		return
+	}
+	metric.SchedulerLag.Set(time.Since(t.next).Seconds())
+
+	next := t.job(t.ctx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t.running = false
+	// Removed (or replaced) whilst running.
+	if s.tasks[t.id] != t {
+		return
+	}
+	// Finished.
+	if next <= 0 || t.ctx.Err() != nil {
+		t.cancel()
+		delete(s.tasks, t.id)
+		metric.SchedulerJobs.Set(float64(len(s.tasks)))
+		return
+	}
+
+	if t.woken {
+		t.woken = false
+		next = 0
+	}
+	t.next = time.Now().Add(next)
+	heap.Push(&s.queue, t)
+	s.signal()
+}
@@ -0,0 +1,327 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Add(t *testing.T) {
+	// GIVEN a Scheduler
+	tests := map[string]struct {
+		delay    time.Duration
+		next     time.Duration
+		wantRuns int32
+	}{
+		"runs once": {
+			wantRuns: 1},
+		"runs after the delay": {
+			delay:    300 * time.Millisecond,
+			wantRuns: 1},
+		"runs again after the duration returned": {
+			next:     200 * time.Millisecond,
+			wantRuns: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := New(2)
+			t.Cleanup(s.Stop)
+			var runs int32
+			start := time.Now()
+			var firstRun int64
+
+			// WHEN a Job is added to it
+			s.Add("job", tc.delay, func(ctx context.Context) time.Duration {
+				if atomic.AddInt32(&runs, 1) == 1 {
+					atomic.StoreInt64(&firstRun, int64(time.Since(start)))
+				}
+				return tc.next
+			})
+			time.Sleep(tc.delay + 500*time.Millisecond)
+
+			// THEN it's run after the delay
+			if got := atomic.LoadInt32(&runs); got != tc.wantRuns {
+				t.Errorf("want %d runs, got %d",
+					tc.wantRuns, got)
+			}
+			if got := time.Duration(atomic.LoadInt64(&firstRun)); got < tc.delay {
+				t.Errorf("want first run after %s, got %s",
+					tc.delay, got)
+			}
+			// AND it's only scheduled if it runs again
+			if got := s.Scheduled("job"); got != (tc.next > 0) {
+				t.Errorf("want Scheduled=%t, got %t",
+					tc.next > 0, got)
+			}
+		})
+	}
+}
+
+func TestScheduler_Add_Order(t *testing.T) {
+	// GIVEN a Scheduler with one worker
+	s := New(1)
+	t.Cleanup(s.Stop)
+	var mutex sync.Mutex
+	var order []string
+	done := make(chan bool, 3)
+	job := func(id string) Job {
+		return func(ctx context.Context) time.Duration {
+			mutex.Lock()
+			order = append(order, id)
+			mutex.Unlock()
+			done <- true
+			return 0
+		}
+	}
+
+	// WHEN Jobs are added out of order
+	s.Add("c", 300*time.Millisecond, job("c"))
+	s.Add("a", 100*time.Millisecond, job("a"))
+	s.Add("b", 200*time.Millisecond, job("b"))
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Jobs didn't run")
+		}
+	}
+
+	// THEN they're run in order of when they're due
+	if got := order[0] + order[1] + order[2]; got != "abc" {
+		t.Errorf("want Jobs run in order %q, got %q",
+			"abc", got)
+	}
+}
+
+func TestScheduler_Workers(t *testing.T) {
+	// GIVEN a Scheduler with 2 workers
+	s := New(2)
+	t.Cleanup(s.Stop)
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+
+	// WHEN more Jobs than workers are due
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		wg.Add(1)
+		s.Add(id, 0, func(ctx context.Context) time.Duration {
+			defer wg.Done()
+			now := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if now <= max || atomic.CompareAndSwapInt32(&maxRunning, max, now) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return 0
+		})
+	}
+	wg.Wait()
+
+	// THEN only 2 are run at once
+	if got := atomic.LoadInt32(&maxRunning); got != 2 {
+		t.Errorf("want 2 Jobs running at once, got %d", got)
+	}
+}
+
+func TestScheduler_Remove(t *testing.T) {
+	// GIVEN a Scheduler with a running Job and a queued Job
+	s := New(2)
+	t.Cleanup(s.Stop)
+	started := make(chan bool, 1)
+	cancelled := make(chan bool, 1)
+	var queuedRuns int32
+	s.Add("running", 0, func(ctx context.Context) time.Duration {
+		started <- true
+		<-ctx.Done()
+		cancelled <- true
+		return time.Millisecond
+	})
+	s.Add("queued", 200*time.Millisecond, func(ctx context.Context) time.Duration {
+		atomic.AddInt32(&queuedRuns, 1)
+		return time.Millisecond
+	})
+	<-started
+
+	// WHEN they're removed
+	s.Remove("running", "queued", "unknown")
+
+	// THEN the context of the running Job is cancelled
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("context of the running Job wasn't cancelled")
+	}
+	// AND neither are run again
+	time.Sleep(400 * time.Millisecond)
+	if got := atomic.LoadInt32(&queuedRuns); got != 0 {
+		t.Errorf("want removed Job not to run, ran %d times", got)
+	}
+	if s.Scheduled("running") || s.Scheduled("queued") {
+		t.Error("want the Jobs to no longer be scheduled")
+	}
+}
+
+func TestScheduler_Add_Replaces(t *testing.T) {
+	// GIVEN a Scheduler with a Job
+	s := New(1)
+	t.Cleanup(s.Stop)
+	var oldRuns, newRuns int32
+	s.Add("job", 200*time.Millisecond, func(ctx context.Context) time.Duration {
+		atomic.AddInt32(&oldRuns, 1)
+		return 0
+	})
+
+	// WHEN a Job with the same ID is added
+	s.Add("job", 0, func(ctx context.Context) time.Duration {
+		atomic.AddInt32(&newRuns, 1)
+		return 0
+	})
+	time.Sleep(400 * time.Millisecond)
+
+	// THEN only the new Job is run
+	if got := atomic.LoadInt32(&oldRuns); got != 0 {
+		t.Errorf("want replaced Job not to run, ran %d times", got)
+	}
+	if got := atomic.LoadInt32(&newRuns); got != 1 {
+		t.Errorf("want new Job to run once, ran %d times", got)
+	}
+}
+
+func TestScheduler_Wake(t *testing.T) {
+	// GIVEN a Scheduler with a Job that runs every hour
+	s := New(1)
+	t.Cleanup(s.Stop)
+	ran := make(chan bool, 4)
+	release := make(chan bool, 1)
+	s.Add("job", time.Hour, func(ctx context.Context) time.Duration {
+		ran <- true
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return time.Hour
+	})
+
+	// WHEN it's woken
+	s.Wake("job")
+
+	// THEN it runs straight away
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("Job wasn't run when woken")
+	}
+
+	// WHEN it's woken whilst running
+	s.Wake("job")
+	release <- true
+
+	// THEN it runs again once it finishes
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("Job wasn't run again after being woken whilst running")
+	}
+	release <- true
+}
+
+func TestScheduler_Shutdown(t *testing.T) {
+	// GIVEN a Scheduler with a running Job that takes a while to return once cancelled
+	tests := map[string]struct {
+		returnAfter time.Duration
+		timeout     time.Duration
+		wantErr     error
+	}{
+		"waits for the running Job": {
+			returnAfter: 200 * time.Millisecond,
+			timeout:     time.Second},
+		"times out waiting for the running Job": {
+			returnAfter: time.Second,
+			timeout:     200 * time.Millisecond,
+			wantErr:     context.DeadlineExceeded},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := New(2)
+			started := make(chan bool, 1)
+			var returned, runs int32
+			s.Add("job", 0, func(ctx context.Context) time.Duration {
+				atomic.AddInt32(&runs, 1)
+				started <- true
+				<-ctx.Done()
+				time.Sleep(tc.returnAfter)
+				atomic.StoreInt32(&returned, 1)
+				return time.Millisecond
+			})
+			<-started
+
+			// WHEN it's shut down
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := s.Shutdown(ctx)
+
+			// THEN it waits for the running Job to return (or the timeout)
+			if err != tc.wantErr {
+				t.Fatalf("want err=%v, got %v", tc.wantErr, err)
+			}
+			if got := atomic.LoadInt32(&returned) == 1; got != (tc.wantErr == nil) {
+				t.Errorf("want Job returned=%t, got %t", tc.wantErr == nil, got)
+			}
+			// AND the dispatcher has stopped
+			select {
+			case <-s.stopped:
+			default:
+				t.Errorf("want the dispatcher to have stopped")
+			}
+			// AND the Job isn't run again
+			time.Sleep(tc.returnAfter + 100*time.Millisecond)
+			if got := atomic.LoadInt32(&runs); got != 1 {
+				t.Errorf("want Job to have run once, ran %d times", got)
+			}
+		})
+	}
+}
+
+func TestQueue_Due(t *testing.T) {
+	// GIVEN a queue of tasks
+	var q queue
+	now := time.Now()
+	for _, delay := range []time.Duration{5, -1, 3, -4, 0, 2, -2, 1} {
+		heap.Push(&q, &task{next: now.Add(delay * time.Minute)})
+	}
+
+	// WHEN due is called on it
+	got := q.due(now)
+
+	// THEN the tasks due at that time are counted
+	if got != 4 {
+		t.Errorf("want 4 tasks due, got %d", got)
+	}
+}
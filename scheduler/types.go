@@ -0,0 +1,99 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Job run by the Scheduler, returning the time to wait before running it again
+// (or <= 0 to stop running it).
+//
+// `ctx` is cancelled when the Job is removed from the Scheduler.
+type Job func(ctx context.Context) time.Duration
+
+// Scheduler runs Jobs at their next run time on a bounded pool of workers.
+type Scheduler struct {
+	mutex sync.Mutex
+	queue queue            // Jobs waiting for their next run, soonest first.
+	tasks map[string]*task // Jobs scheduled, mapped by ID.
+
+	slots   chan struct{}  // Worker slots, one is taken for each running Job.
+	wake    chan struct{}  // Wake the dispatcher as the queue has changed.
+	stopped chan struct{}  // Closed when the dispatcher has stopped (so won't start any more Jobs).
+	running sync.WaitGroup // Jobs running.
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// task is a scheduled Job.
+type task struct {
+	id      string
+	job     Job
+	next    time.Time // Time the Job is due.
+	index   int       // Index in the queue (-1 when it's not queued).
+	running bool      // Whether the Job is running.
+	woken   bool      // Whether the Job was woken whilst running.
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// queue of tasks, implementing heap.Interface ordered by the next run time.
+type queue []*task
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x any) {
+	t := x.(*task)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
+
+// due returns the number of tasks in the queue that are due at `now`.
+func (q queue) due(now time.Time) (count int) {
+	var walk func(i int)
+	walk = func(i int) {
+		// The children of a task are never due before it.
+		if i >= len(q) || q[i].next.After(now) {
+			return
+		}
+		count++
+		walk(2*i + 1)
+		walk(2*i + 2)
+	}
+	walk(0)
+	return
+}
@@ -0,0 +1,251 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	net_url "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	// Types of Auth.
+	Types = []string{"bearer", "oauth2", "api_key"}
+	// defaultAPIKeyHeader is the header the key of a type:api_key Auth is sent in by default.
+	defaultAPIKeyHeader = "X-API-Key"
+	// tokenExpiryMargin is how long before its expiry an OAuth2 token is renewed.
+	tokenExpiryMargin = 30 * time.Second
+)
+
+// Auth to use on an HTTP request.
+type Auth struct {
+	Type         string   `yaml:"type,omitempty" json:"type,omitempty"`                   // bearer/oauth2/api_key
+	Token        string   `yaml:"token,omitempty" json:"token,omitempty"`                 // type:bearer - Token to send in the Authorization header
+	TokenURL     string   `yaml:"token_url,omitempty" json:"token_url,omitempty"`         // type:oauth2 - URL to get the access token from
+	ClientID     string   `yaml:"client_id,omitempty" json:"client_id,omitempty"`         // type:oauth2 - Client ID
+	ClientSecret string   `yaml:"client_secret,omitempty" json:"client_secret,omitempty"` // type:oauth2 - Client Secret
+	Scopes       []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`               // type:oauth2 - Scopes to request
+	Header       string   `yaml:"header,omitempty" json:"header,omitempty"`               // type:api_key - Header to send the Key in (default X-API-Key)
+	Key          string   `yaml:"key,omitempty" json:"key,omitempty"`                     // type:api_key - API Key
+
+	accessToken string     // type:oauth2 - Access token for the requests
+	validUntil  time.Time  // type:oauth2 - Time until the access token needs to be renewed
+	mutex       sync.Mutex // Mutex for the access token
+}
+
+// String returns a string representation of the Auth.
+func (a *Auth) String(prefix string) (str string) {
+	if a != nil {
+		str = util.ToYAMLString(a, prefix)
+	}
+	return
+}
+
+// GetHeader returns the header to send the Key of a type:api_key Auth in.
+func (a *Auth) GetHeader() string {
+	if a.Header == "" {
+		return defaultAPIKeyHeader
+	}
+	return a.Header
+}
+
+// Apply the Auth to the request `req`.
+func (a *Auth) Apply(req *http.Request, allowInvalidCerts bool) error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Type {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+util.EvalEnvVars(a.Token))
+	case "oauth2":
+		accessToken, err := a.getAccessToken(req.Context(), allowInvalidCerts)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	case "api_key":
+		req.Header.Set(a.GetHeader(), util.EvalEnvVars(a.Key))
+	}
+	return nil
+}
+
+// Rejected is called when a request using this Auth was rejected (e.g. 401),
+// so that a new OAuth2 access token is fetched for the next request.
+func (a *Auth) Rejected() {
+	if a == nil || a.Type != "oauth2" {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.accessToken = ""
+	a.validUntil = time.Time{}
+}
+
+// tokenResponse is the response of an OAuth2 token request.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// getAccessToken returns the cached OAuth2 access token, or requests a new one with
+// the client credentials if it has expired.
+func (a *Auth) getAccessToken(ctx context.Context, allowInvalidCerts bool) (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// Cached token that's still valid.
+	if a.accessToken != "" &&
+		(a.validUntil.IsZero() || time.Now().Before(a.validUntil)) {
+		return a.accessToken, nil
+	}
+
+	token, err := a.requestAccessToken(ctx, allowInvalidCerts)
+	if err != nil {
+		return "", err
+	}
+
+	a.accessToken = token.AccessToken
+	a.validUntil = time.Time{}
+	// Renew the token a little before it expires.
+	if token.ExpiresIn > 0 {
+		a.validUntil = time.Now().
+			Add(time.Duration(token.ExpiresIn) * time.Second).
+			Add(-tokenExpiryMargin)
+	}
+	return a.accessToken, nil
+}
+
+// requestAccessToken does the OAuth2 client-credentials request to the TokenURL.
+func (a *Auth) requestAccessToken(ctx context.Context, allowInvalidCerts bool) (token tokenResponse, err error) {
+	// HTTPS insecure skip verify.
+	customTransport := &http.Transport{}
+	if allowInvalidCerts {
+		customTransport = http.DefaultTransport.(*http.Transport).Clone()
+		//#nosec G402 -- explicitly wanted InsecureSkipVerify
+		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	form := net_url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(a.Scopes) != 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+	tokenURL := util.EvalEnvVars(a.TokenURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		err = fmt.Errorf("oauth2 token request failed: %w", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Connection", "close")
+	req.SetBasicAuth(
+		net_url.QueryEscape(util.EvalEnvVars(a.ClientID)),
+		net_url.QueryEscape(util.EvalEnvVars(a.ClientSecret)))
+
+	client := &http.Client{
+		Transport: customTransport,
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Don't log the whole certificate error.
+		if strings.Contains(err.Error(), "x509") {
+			err = fmt.Errorf("x509 (certificate invalid)")
+		}
+		err = fmt.Errorf("oauth2 token request to %q failed: %w", tokenURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = fmt.Errorf("oauth2 token request to %q failed: %w", tokenURL, err)
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("oauth2 token request to %q gave status %d: %s",
+			tokenURL, resp.StatusCode, strings.TrimSpace(string(body)))
+		return
+	}
+
+	if err = json.Unmarshal(body, &token); err != nil {
+		err = fmt.Errorf("oauth2 token request to %q gave an invalid response: %w",
+			tokenURL, err)
+		return
+	}
+	if token.AccessToken == "" {
+		err = fmt.Errorf("oauth2 token request to %q didn't return an access_token",
+			tokenURL)
+	}
+	return
+}
+
+// CheckValues of the Auth.
+func (a *Auth) CheckValues(prefix string) (errs error) {
+	if a == nil {
+		return
+	}
+
+	a.Type = strings.ToLower(a.Type)
+	switch a.Type {
+	case "bearer":
+		if a.Token == "" {
+			errs = fmt.Errorf("%s%stoken: <required> (token to send as 'Authorization: Bearer <token>')\\",
+				util.ErrorToString(errs), prefix)
+		}
+	case "oauth2":
+		if a.TokenURL == "" {
+			errs = fmt.Errorf("%s%stoken_url: <required> (URL to get the access token from)\\",
+				util.ErrorToString(errs), prefix)
+		} else if !strings.Contains(a.TokenURL, "://") {
+			errs = fmt.Errorf("%s%stoken_url: %q <invalid> (must include the scheme, e.g. 'https://')\\",
+				util.ErrorToString(errs), prefix, a.TokenURL)
+		}
+		if a.ClientID == "" {
+			errs = fmt.Errorf("%s%sclient_id: <required>\\",
+				util.ErrorToString(errs), prefix)
+		}
+		if a.ClientSecret == "" {
+			errs = fmt.Errorf("%s%sclient_secret: <required>\\",
+				util.ErrorToString(errs), prefix)
+		}
+	case "api_key":
+		if a.Key == "" {
+			errs = fmt.Errorf("%s%skey: <required> (API key to send in the %q header)\\",
+				util.ErrorToString(errs), prefix, a.GetHeader())
+		}
+	case "":
+		errs = fmt.Errorf("%s%stype: <required> (supported types = %s)\\",
+			util.ErrorToString(errs), prefix, strings.Join(Types, ","))
+	default:
+		errs = fmt.Errorf("%s%stype: %q <invalid> (supported types = %s)\\",
+			util.ErrorToString(errs), prefix, a.Type, strings.Join(Types, ","))
+	}
+
+	return
+}
@@ -0,0 +1,328 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// testTokenServer returns an OAuth2 token server that gives out "token-<n>" access tokens
+// valid for `expiresIn` seconds, counting the requests in `requests`.
+func testTokenServer(t *testing.T, expiresIn int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "id" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed parsing the token request: %s", err)
+		}
+		if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+			t.Errorf("want grant_type %q, got %q",
+				"client_credentials", grantType)
+		}
+
+		n := atomic.AddInt32(requests, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d,"scope":%q}`,
+			n, expiresIn, r.PostForm.Get("scope"))
+	}))
+}
+
+func TestAuth_Apply(t *testing.T) {
+	// GIVEN an Auth
+	os.Setenv("TEST_AUTH_APPLY", "from-env")
+	tests := map[string]struct {
+		auth      *Auth
+		header    string
+		want      string
+		tokenURL  bool
+		errRegex  string
+		expiresIn int
+	}{
+		"nil Auth": {
+			auth:     nil,
+			header:   "Authorization",
+			want:     "",
+			errRegex: `^$`},
+		"bearer": {
+			auth: &Auth{
+				Type:  "bearer",
+				Token: "abc"},
+			header:   "Authorization",
+			want:     "Bearer abc",
+			errRegex: `^$`},
+		"bearer from env": {
+			auth: &Auth{
+				Type:  "bearer",
+				Token: "${TEST_AUTH_APPLY}"},
+			header:   "Authorization",
+			want:     "Bearer from-env",
+			errRegex: `^$`},
+		"api_key with the default header": {
+			auth: &Auth{
+				Type: "api_key",
+				Key:  "abc"},
+			header:   "X-API-Key",
+			want:     "abc",
+			errRegex: `^$`},
+		"api_key with a header": {
+			auth: &Auth{
+				Type:   "api_key",
+				Header: "X-Token",
+				Key:    "abc"},
+			header:   "X-Token",
+			want:     "abc",
+			errRegex: `^$`},
+		"oauth2": {
+			auth: &Auth{
+				Type:         "oauth2",
+				ClientID:     "id",
+				ClientSecret: "secret",
+				Scopes:       []string{"read", "write"}},
+			tokenURL:  true,
+			expiresIn: 3600,
+			header:    "Authorization",
+			want:      "Bearer token-1",
+			errRegex:  `^$`},
+		"oauth2 with invalid credentials": {
+			auth: &Auth{
+				Type:         "oauth2",
+				ClientID:     "id",
+				ClientSecret: "wrong"},
+			tokenURL: true,
+			header:   "Authorization",
+			want:     "",
+			errRegex: `gave status 401: {"error":"invalid_client"}$`},
+		"oauth2 with an unreachable token_url": {
+			auth: &Auth{
+				Type:         "oauth2",
+				TokenURL:     "http://127.0.0.1:0/token",
+				ClientID:     "id",
+				ClientSecret: "secret"},
+			header:   "Authorization",
+			want:     "",
+			errRegex: `^oauth2 token request to "[^"]+" failed: `},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.tokenURL {
+				var requests int32
+				server := testTokenServer(t, tc.expiresIn, &requests)
+				defer server.Close()
+				tc.auth.TokenURL = server.URL
+			}
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+			// WHEN Apply is called on it
+			err := tc.auth.Apply(req, false)
+
+			// THEN the header is set as expected
+			if got := req.Header.Get(tc.header); got != tc.want {
+				t.Errorf("want %s: %q, got %q",
+					tc.header, tc.want, got)
+			}
+			// AND the err is what we expect
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Errorf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestAuth_OAuth2TokenCaching(t *testing.T) {
+	// GIVEN an oauth2 Auth and a token server
+	tests := map[string]struct {
+		expiresIn        int
+		expire           bool
+		rejected         bool
+		wantToken        string
+		wantRequestCount int32
+	}{
+		"token is cached": {
+			expiresIn:        3600,
+			wantToken:        "token-1",
+			wantRequestCount: 1},
+		"token without an expiry is cached": {
+			expiresIn:        0,
+			wantToken:        "token-1",
+			wantRequestCount: 1},
+		"expired token is renewed": {
+			expiresIn:        3600,
+			expire:           true,
+			wantToken:        "token-2",
+			wantRequestCount: 2},
+		"token that expires within the margin is renewed": {
+			expiresIn:        int(tokenExpiryMargin.Seconds()),
+			wantToken:        "token-2",
+			wantRequestCount: 2},
+		"rejected token is renewed": {
+			expiresIn:        3600,
+			rejected:         true,
+			wantToken:        "token-2",
+			wantRequestCount: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests int32
+			server := testTokenServer(t, tc.expiresIn, &requests)
+			defer server.Close()
+			auth := &Auth{
+				Type:         "oauth2",
+				TokenURL:     server.URL,
+				ClientID:     "id",
+				ClientSecret: "secret"}
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+			if err := auth.Apply(req, false); err != nil {
+				t.Fatalf("unexpected error on the first Apply: %s", err)
+			}
+			if tc.expire {
+				auth.validUntil = time.Now().Add(-time.Second)
+			}
+			if tc.rejected {
+				auth.Rejected()
+			}
+
+			// WHEN Apply is called again
+			err := auth.Apply(req, false)
+
+			// THEN the expected token is used
+			if err != nil {
+				t.Fatalf("unexpected error on the second Apply: %s", err)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer "+tc.wantToken {
+				t.Errorf("want Authorization %q, got %q",
+					"Bearer "+tc.wantToken, got)
+			}
+			// AND the token server was queried the expected number of times
+			if got := atomic.LoadInt32(&requests); got != tc.wantRequestCount {
+				t.Errorf("want %d token requests, got %d",
+					tc.wantRequestCount, got)
+			}
+		})
+	}
+}
+
+func TestAuth_CheckValues(t *testing.T) {
+	// GIVEN an Auth
+	tests := map[string]struct {
+		auth     *Auth
+		errRegex []string
+	}{
+		"nil Auth": {
+			auth:     nil,
+			errRegex: []string{`^$`}},
+		"no type": {
+			auth: &Auth{},
+			errRegex: []string{
+				`^type: <required> \(supported types = bearer,oauth2,api_key\)$`}},
+		"invalid type": {
+			auth: &Auth{
+				Type: "digest"},
+			errRegex: []string{
+				`^type: "digest" <invalid>`}},
+		"valid bearer": {
+			auth: &Auth{
+				Type:  "Bearer",
+				Token: "abc"},
+			errRegex: []string{`^$`}},
+		"bearer without a token": {
+			auth: &Auth{
+				Type: "bearer"},
+			errRegex: []string{
+				`^token: <required>`}},
+		"valid oauth2": {
+			auth: &Auth{
+				Type:         "oauth2",
+				TokenURL:     "https://auth.example.com/token",
+				ClientID:     "id",
+				ClientSecret: "secret"},
+			errRegex: []string{`^$`}},
+		"oauth2 without credentials": {
+			auth: &Auth{
+				Type: "oauth2"},
+			errRegex: []string{
+				`^token_url: <required>`,
+				`^client_id: <required>$`,
+				`^client_secret: <required>$`}},
+		"oauth2 with a token_url without a scheme": {
+			auth: &Auth{
+				Type:         "oauth2",
+				TokenURL:     "auth.example.com/token",
+				ClientID:     "id",
+				ClientSecret: "secret"},
+			errRegex: []string{
+				`^token_url: "auth.example.com/token" <invalid>`}},
+		"valid api_key": {
+			auth: &Auth{
+				Type: "api_key",
+				Key:  "abc"},
+			errRegex: []string{`^$`}},
+		"api_key without a key": {
+			auth: &Auth{
+				Type:   "api_key",
+				Header: "X-Token"},
+			errRegex: []string{
+				`^key: <required> \(API key to send in the "X-Token" header\)$`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN CheckValues is called on it
+			err := tc.auth.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
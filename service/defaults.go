@@ -19,6 +19,7 @@ func (s *Defaults) SetDefaults() {
 	// Service.Options
 	serviceSemanticVersioning := true
 	s.Options.Interval = "10m"
+	s.Options.BackoffMax = "1h"
 	s.Options.SemanticVersioning = &serviceSemanticVersioning
 
 	// Service.LatestVersion
@@ -31,6 +32,8 @@ func (s *Defaults) SetDefaults() {
 	// Service.DeployedVersionLookup
 	serviceDeployedVersionLookupAllowInvalidCerts := false
 	s.DeployedVersionLookup.AllowInvalidCerts = &serviceDeployedVersionLookupAllowInvalidCerts
+	s.DeployedVersionLookup.WatchInterval = "15s"
+	s.DeployedVersionLookup.WatchTimeout = "15m"
 
 	// Service.Dashboard
 	serviceAutoApprove := false
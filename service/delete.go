@@ -18,9 +18,11 @@ import (
 	dbtype "github.com/release-argus/Argus/db/types"
 )
 
-// PrepDelete prepares a service for deletion by removing all channels and setting the `deleting“ flag.
+// PrepDelete prepares a service for deletion by removing all channels, setting the `deleting“ flag and removing it from the scheduler.
 func (s *Service) PrepDelete(removeFromDB bool) {
 	s.Status.SetDeleting()
+	// Stop tracking, cancelling any queries in progress.
+	s.Untrack()
 
 	// nil the channels so the service doesn't trigger any more events
 	s.Status.AnnounceChannel = nil
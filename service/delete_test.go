@@ -0,0 +1,49 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/scheduler"
+	deployedver "github.com/release-argus/Argus/service/deployed_version"
+)
+
+func TestService_PrepDelete_Untracks(t *testing.T) {
+	// GIVEN a tracked Service that was queried recently
+	svc := testService("TestService_PrepDelete_Untracks", "url")
+	svc.Status.SetLastQueried(time.Now().UTC().Format(time.RFC3339))
+	svc.DeployedVersionLookup.Interval = "1h"
+	svc.Track()
+	// (its first latest_version query is after the interval, and the deployed_version is tracked now)
+	for _, id := range []string{TrackID(svc.ID), deployedver.TrackID(svc.ID)} {
+		if !scheduler.Default().Scheduled(id) {
+			t.Fatalf("want %q scheduled after Track", id)
+		}
+	}
+
+	// WHEN PrepDelete is called on it
+	svc.PrepDelete(false)
+
+	// THEN it's no longer tracked
+	for _, id := range []string{TrackID(svc.ID), deployedver.TrackID(svc.ID)} {
+		if scheduler.Default().Scheduled(id) {
+			t.Errorf("want %q removed from the scheduler after PrepDelete", id)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	command "github.com/release-argus/Argus/commands"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+func TestLookup_ExecCommand(t *testing.T) {
+	// GIVEN a Lookup with a Command
+	tests := map[string]struct {
+		command    command.Command
+		timeout    string
+		wantOutput string
+		errRegex   string
+	}{
+		"stdout": {
+			command:    command.Command{"echo", "1.2.3"},
+			wantOutput: "1.2.3",
+			errRegex:   `^$`},
+		"stderr if no stdout": {
+			command:    command.Command{"sh", "-c", "echo 'nginx version: nginx/1.25.3' >&2"},
+			wantOutput: "nginx version: nginx/1.25.3",
+			errRegex:   `^$`},
+		"stdout over stderr": {
+			command:    command.Command{"sh", "-c", "echo 'warning' >&2; echo '1.2.3'"},
+			wantOutput: "1.2.3",
+			errRegex:   `^$`},
+		"templated": {
+			command:    command.Command{"echo", "{{ version }}"},
+			wantOutput: "2.0.0",
+			errRegex:   `^$`},
+		"fails": {
+			command:  command.Command{"sh", "-c", "echo 'not found' >&2; exit 3"},
+			errRegex: `^command "sh -c .*" failed: exit status 3 - not found$`},
+		"unknown command": {
+			command:  command.Command{"argus-unknown-command"},
+			errRegex: `^command "argus-unknown-command" failed: .*not found`},
+		"times out": {
+			command:  command.Command{"sleep", "5"},
+			timeout:  "100ms",
+			errRegex: `^command "sleep 5" timed out after 100ms$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "command"
+			lookup.Command = tc.command
+			lookup.Timeout = tc.timeout
+			lookup.Status.SetLatestVersion("2.0.0", false)
+
+			// WHEN execCommand is called on it
+			output, err := lookup.execCommand(context.Background(), &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the output is what we expect
+			if string(output) != tc.wantOutput {
+				t.Errorf("want output %q, got %q",
+					tc.wantOutput, output)
+			}
+		})
+	}
+}
+
+func TestLookup_Query_Command(t *testing.T) {
+	// GIVEN a type:command Lookup
+	tests := map[string]struct {
+		command       command.Command
+		json          string
+		regex         string
+		regexTemplate *string
+		wantVersion   string
+		errRegex      string
+	}{
+		"regex on stdout": {
+			command:     command.Command{"sh", "-c", "echo 'psql (PostgreSQL) 16.1.0'"},
+			regex:       `PostgreSQL\) ([0-9.]+)`,
+			wantVersion: "16.1.0",
+			errRegex:    `^$`},
+		"regex_template on stdout": {
+			command:       command.Command{"sh", "-c", "echo 'v16_1_0'"},
+			regex:         `v(\d+)_(\d+)_(\d+)`,
+			regexTemplate: test.StringPtr("$1.$2.$3"),
+			wantVersion:   "16.1.0",
+			errRegex:      `^$`},
+		"json on stdout": {
+			command:     command.Command{"echo", `{"clientVersion": {"gitVersion": "1.29.0"}}`},
+			json:        "clientVersion.gitVersion",
+			wantVersion: "1.29.0",
+			errRegex:    `^$`},
+		"regex doesn't match": {
+			command:  command.Command{"echo", "unknown"},
+			regex:    `([0-9.]+)`,
+			errRegex: `^regex "\(\[0-9.\]\+\)" didn't find a match on "unknown"$`},
+		"command fails": {
+			command:  command.Command{"false"},
+			errRegex: `^command "false" failed: exit status 1$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Cannot run in parallel since we're using the same metrics
+
+			lookup := testLookup()
+			lookup.Type = "command"
+			lookup.Command = tc.command
+			lookup.JSON = tc.json
+			lookup.Regex = tc.regex
+			lookup.RegexTemplate = tc.regexTemplate
+			lookup.Status.ServiceID = &name
+			lookup.InitMetrics()
+			t.Cleanup(func() { lookup.DeleteMetrics() })
+
+			// WHEN Query is called on it
+			version, err := lookup.Query(context.Background(), true, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+			// AND the query metrics are updated
+			wantResult, wantLiveness := "SUCCESS", float64(1)
+			if err != nil {
+				wantResult, wantLiveness = "FAIL", 0
+			}
+			gotCount := testutil.ToFloat64(metric.DeployedVersionQueryMetric.WithLabelValues(name, wantResult))
+			if gotCount != 1 {
+				t.Errorf("want %s metric to be 1, got %f",
+					wantResult, gotCount)
+			}
+			gotLiveness := testutil.ToFloat64(metric.DeployedVersionQueryLiveness.WithLabelValues(name))
+			if gotLiveness != wantLiveness {
+				t.Errorf("want liveness %f, got %f",
+					wantLiveness, gotLiveness)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValues_Command(t *testing.T) {
+	// GIVEN a type:command Lookup
+	tests := map[string]struct {
+		lookupType  string
+		command     command.Command
+		timeout     string
+		wantTimeout string
+		errRegex    []string
+	}{
+		"valid": {
+			lookupType: "command",
+			command:    command.Command{"nginx", "-v"},
+			errRegex:   []string{`^$`}},
+		"valid with timeout": {
+			lookupType:  "command",
+			command:     command.Command{"nginx", "-v"},
+			timeout:     "1m",
+			wantTimeout: "1m",
+			errRegex:    []string{`^$`}},
+		"timeout in seconds": {
+			lookupType:  "command",
+			command:     command.Command{"nginx", "-v"},
+			timeout:     "5",
+			wantTimeout: "5s",
+			errRegex:    []string{`^$`}},
+		"invalid timeout": {
+			lookupType:  "command",
+			command:     command.Command{"nginx", "-v"},
+			timeout:     "1x",
+			wantTimeout: "1x",
+			errRegex: []string{
+				`^  timeout: "1x" <invalid>`}},
+		"no command": {
+			lookupType: "command",
+			errRegex: []string{
+				`^  command: <required>`}},
+		"command fails templating": {
+			lookupType: "command",
+			command:    command.Command{"echo", "{{ version }"},
+			errRegex: []string{
+				`^  command: .* \("\{\{ version }"\) <invalid> \(didn't pass templating\)`}},
+		"invalid type": {
+			lookupType: "ftp",
+			errRegex: []string{
+				`^  type: "ftp" <invalid> \(only \[url, command, file, docker, kubernetes, ssh\] are allowed\)`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = tc.lookupType
+			lookup.Method = ""
+			lookup.URL = ""
+			lookup.Command = tc.command
+			lookup.Timeout = tc.timeout
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND the timeout is converted to a duration
+			if lookup.Timeout != tc.wantTimeout {
+				t.Errorf("want timeout %q, got %q",
+					tc.wantTimeout, lookup.Timeout)
+			}
+		})
+	}
+}
+
+func TestLookup_Refresh_Command(t *testing.T) {
+	// GIVEN a type:command Lookup
+	lookup := testLookup()
+	lookup.Type = "command"
+	lookup.Command = command.Command{"echo", "1.2.3"}
+	lookup.JSON = ""
+	lookup.URL = ""
+
+	// WHEN Refresh is called on it with a url override
+	version, _, err := lookup.Refresh(
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		test.StringPtr("https://example.com"))
+
+	// THEN the command is still used
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if version != "1.2.3" {
+		t.Errorf("want version %q, got %q",
+			"1.2.3", version)
+	}
+}
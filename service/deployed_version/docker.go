@@ -0,0 +1,364 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	net_url "net/url"
+	"os"
+	"strings"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	dockerSources             = []string{"tag", "label", "digest"}
+	dockerDefaultHost         = "unix:///var/run/docker.sock"
+	dockerDefaultVersionLabel = "org.opencontainers.image.version"
+)
+
+// DockerLookup finds the deployed version from a container with the Docker Engine API.
+type DockerLookup struct {
+	Host      string            `yaml:"host,omitempty" json:"host,omitempty"`           // OPTIONAL: Docker Engine API, unix:///var/run/docker.sock (default, or $DOCKER_HOST)/tcp://host:2376.
+	CACert    string            `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`     // OPTIONAL: Path of the CA certificate to verify the TCP host with.
+	Cert      string            `yaml:"cert,omitempty" json:"cert,omitempty"`           // OPTIONAL: Path of the client certificate for TCP hosts.
+	Key       string            `yaml:"key,omitempty" json:"key,omitempty"`             // OPTIONAL: Path of the client key for TCP hosts.
+	Container string            `yaml:"container,omitempty" json:"container,omitempty"` // Name/ID of the container.
+	Labels    map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`       // Labels to find the container by (if no Container).
+	Source    string            `yaml:"source,omitempty" json:"source,omitempty"`       // OPTIONAL: tag (default)/label/digest - What to use as the version.
+	Label     string            `yaml:"label,omitempty" json:"label,omitempty"`         // OPTIONAL (source:label): Label to use (default org.opencontainers.image.version).
+}
+
+// dockerContainer is the part of a container inspect that we use.
+type dockerContainer struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Image  string `json:"Image"` // Image ID
+	Config struct {
+		Image  string            `json:"Image"` // Image reference, e.g. nginx:1.25.3
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// GetHost returns the Host of the Docker Engine API (defaults to $DOCKER_HOST, or the unix socket).
+func (d *DockerLookup) GetHost() string {
+	if d.Host != "" {
+		return util.EvalEnvVars(d.Host)
+	}
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host
+	}
+	return dockerDefaultHost
+}
+
+// GetSource returns what to use as the version (defaults to tag).
+func (d *DockerLookup) GetSource() string {
+	if d.Source == "" {
+		return "tag"
+	}
+	return d.Source
+}
+
+// GetLabel returns the Label to use as the version (defaults to org.opencontainers.image.version).
+func (d *DockerLookup) GetLabel() string {
+	if d.Label == "" {
+		return dockerDefaultVersionLabel
+	}
+	return d.Label
+}
+
+// dockerVersion returns the version of the container from the Docker Engine API.
+func (l *Lookup) dockerVersion(ctx context.Context, logFrom *util.LogFrom) ([]byte, error) {
+	version, err := l.Docker.version(ctx, l.GetAllowInvalidCerts())
+	if err != nil {
+		err = fmt.Errorf("docker %s: %w",
+			l.Docker.GetHost(), err)
+		jLog.Warn(err, logFrom, true)
+		return nil, err
+	}
+
+	return []byte(version), nil
+}
+
+// version finds the container and returns the tag/label/digest of it.
+func (d *DockerLookup) version(ctx context.Context, allowInvalidCerts bool) (string, error) {
+	client, baseURL, err := d.client(allowInvalidCerts)
+	if err != nil {
+		return "", err
+	}
+
+	container, err := d.findContainer(ctx, client, baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch d.GetSource() {
+	case "label":
+		label := d.GetLabel()
+		version := container.Config.Labels[label]
+		if version == "" {
+			return "", fmt.Errorf("container %q has no %q label",
+				container.Name, label)
+		}
+		return version, nil
+	case "digest":
+		return d.imageDigest(ctx, client, baseURL, container)
+	default:
+		tag := imageTag(container.Config.Image)
+		if tag == "" {
+			return "", fmt.Errorf("container %q image %q has no tag",
+				container.Name, container.Config.Image)
+		}
+		return tag, nil
+	}
+}
+
+// client returns the HTTP client (and base URL) for the Host.
+func (d *DockerLookup) client(allowInvalidCerts bool) (*http.Client, string, error) {
+	host := d.GetHost()
+	scheme, address, _ := strings.Cut(host, "://")
+	transport := &http.Transport{}
+	var baseURL string
+
+	switch scheme {
+	case "unix":
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", address)
+		}
+		baseURL = "http://docker"
+	case "tcp":
+		// Plain HTTP without any TLS configuration.
+		if d.CACert == "" && d.Cert == "" && !allowInvalidCerts {
+			baseURL = "http://" + address
+			break
+		}
+		tlsConfig, err := d.tlsConfig(allowInvalidCerts)
+		if err != nil {
+			return nil, "", err
+		}
+		transport.TLSClientConfig = tlsConfig
+		baseURL = "https://" + address
+	default:
+		return nil, "", fmt.Errorf("unsupported host %q (use unix:// or tcp://)", host)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   util.HTTPTimeout}
+	return client, baseURL, nil
+}
+
+// tlsConfig returns the TLS config for a TCP Host from the CACert/Cert/Key.
+func (d *DockerLookup) tlsConfig(allowInvalidCerts bool) (*tls.Config, error) {
+	//#nosec G402 -- explicitly wanted InsecureSkipVerify
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: allowInvalidCerts}
+
+	if d.CACert != "" {
+		caCert, err := os.ReadFile(util.EvalEnvVars(d.CACert))
+		if err != nil {
+			return nil, fmt.Errorf("ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("ca_cert: no certificates found in %q", d.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if d.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(util.EvalEnvVars(d.Cert), util.EvalEnvVars(d.Key))
+		if err != nil {
+			return nil, fmt.Errorf("cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// get does a GET on the Docker Engine API `path` and unmarshals the JSON response into `target`.
+func (d *DockerLookup) get(ctx context.Context, client *http.Client, baseURL string, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("request creation failed: %w", err)
+	}
+	req.Header.Set("Connection", "close")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Don't log the whole certificate error.
+		if strings.Contains(err.Error(), "x509") {
+			err = fmt.Errorf("x509 (certificate invalid)")
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Errors are {"message": "..."}.
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			return fmt.Errorf("%s (%d)", apiError.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("non-200 response code: %d", resp.StatusCode)
+	}
+
+	if err = json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return nil
+}
+
+// findContainer by the Container name/ID, or the running container with all the Labels.
+func (d *DockerLookup) findContainer(ctx context.Context, client *http.Client, baseURL string) (*dockerContainer, error) {
+	name := util.EvalEnvVars(d.Container)
+	if name == "" {
+		filters, _ := json.Marshal(map[string][]string{
+			"label":  d.labelFilters(),
+			"status": {"running"}})
+		var containers []struct {
+			ID string `json:"Id"`
+		}
+		if err := d.get(ctx, client, baseURL, "/containers/json?filters="+net_url.QueryEscape(string(filters)), &containers); err != nil {
+			return nil, err
+		}
+		if len(containers) == 0 {
+			return nil, fmt.Errorf("no running container with labels %v", d.labelFilters())
+		}
+		// Containers are listed newest first.
+		name = containers[0].ID
+	}
+
+	var container dockerContainer
+	if err := d.get(ctx, client, baseURL, "/containers/"+net_url.PathEscape(name)+"/json", &container); err != nil {
+		return nil, err
+	}
+	container.Name = strings.TrimPrefix(container.Name, "/")
+	return &container, nil
+}
+
+// labelFilters returns the Labels as filters, e.g. ["com.example.app=web"].
+func (d *DockerLookup) labelFilters() []string {
+	filters := make([]string, 0, len(d.Labels))
+	for _, key := range util.SortedKeys(d.Labels) {
+		filter := key
+		if value := d.Labels[key]; value != "" {
+			filter += "=" + value
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+// imageDigest returns the repo digest of the image of the `container` (or the image ID if not pulled from a registry).
+func (d *DockerLookup) imageDigest(ctx context.Context, client *http.Client, baseURL string, container *dockerContainer) (string, error) {
+	var image struct {
+		ID          string   `json:"Id"`
+		RepoDigests []string `json:"RepoDigests"`
+	}
+	if err := d.get(ctx, client, baseURL, "/images/"+net_url.PathEscape(container.Image)+"/json", &image); err != nil {
+		return "", err
+	}
+
+	// Prefer the digest of the repository the container was created from.
+	repository := imageRepository(container.Config.Image)
+	for _, repoDigest := range image.RepoDigests {
+		if name, digest, found := strings.Cut(repoDigest, "@"); found && name == repository {
+			return digest, nil
+		}
+	}
+	if len(image.RepoDigests) != 0 {
+		_, digest, _ := strings.Cut(image.RepoDigests[0], "@")
+		return digest, nil
+	}
+	return image.ID, nil
+}
+
+// imageRepository returns the repository of the image `reference`, e.g. nginx:1.25.3 => nginx.
+func imageRepository(reference string) string {
+	reference, _, _ = strings.Cut(reference, "@")
+	// A ':' after the last '/' is the tag (not a registry port).
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference
+}
+
+// imageTag returns the tag of the image `reference`, e.g. nginx:1.25.3 => 1.25.3.
+//
+// References without a tag are "latest", unless pinned to a digest.
+func imageTag(reference string) string {
+	reference, digest, pinned := strings.Cut(reference, "@")
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[i+1:]
+	}
+	if pinned && digest != "" {
+		return ""
+	}
+	return "latest"
+}
+
+// CheckValues of the DockerLookup.
+func (d *DockerLookup) CheckValues(prefix string) (errs error) {
+	if d == nil {
+		return
+	}
+
+	if d.Host != "" {
+		scheme, address, _ := strings.Cut(d.Host, "://")
+		if (scheme != "unix" && scheme != "tcp") || address == "" {
+			errs = fmt.Errorf("%s%shost: %q <invalid> (use 'unix:///path/to/docker.sock' or 'tcp://host:port')\\",
+				util.ErrorToString(errs), prefix, d.Host)
+		}
+	}
+
+	if (d.Cert == "") != (d.Key == "") {
+		errs = fmt.Errorf("%s%scert/key: <invalid> (both are required for client certificates)\\",
+			util.ErrorToString(errs), prefix)
+	}
+
+	if d.Container == "" && len(d.Labels) == 0 {
+		errs = fmt.Errorf("%s%scontainer: <required> (name of the container, or the labels to find it by)\\",
+			util.ErrorToString(errs), prefix)
+	}
+
+	if d.Source != "" && !util.Contains(dockerSources, d.Source) {
+		errs = fmt.Errorf("%s%ssource: %q <invalid> (only [%s] are allowed)\\",
+			util.ErrorToString(errs), prefix, d.Source, strings.Join(dockerSources, ", "))
+	}
+
+	if d.Label != "" && d.GetSource() != "label" {
+		errs = fmt.Errorf("%s%slabel: %q <invalid> (only used with source: label)\\",
+			util.ErrorToString(errs), prefix, d.Label)
+	}
+
+	return
+}
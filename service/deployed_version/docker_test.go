@@ -0,0 +1,401 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+// testDockerEngine returns the unix socket host of a Docker Engine API with these containers.
+func testDockerEngine(t *testing.T) string {
+	containers := map[string]string{
+		"web": `{
+			"Id": "aaa111", "Name": "/web", "Image": "sha256:img1",
+			"Config": {"Image": "ghcr.io/release-argus/argus:0.17.0",
+				"Labels": {"app": "argus", "org.opencontainers.image.version": "0.17.0"}}}`,
+		"db": `{
+			"Id": "bbb222", "Name": "/db", "Image": "sha256:img2",
+			"Config": {"Image": "postgres@sha256:pinned", "Labels": {}}}`,
+		"local": `{
+			"Id": "ccc333", "Name": "/local", "Image": "sha256:img3",
+			"Config": {"Image": "localhost:5000/app", "Labels": {}}}`}
+	containers["aaa111"] = containers["web"]
+	images := map[string]string{
+		"sha256:img1": `{"Id": "sha256:img1", "RepoDigests": ["release-argus/argus@sha256:hub", "ghcr.io/release-argus/argus@sha256:ghcr"]}`,
+		"sha256:img2": `{"Id": "sha256:img2", "RepoDigests": ["postgres@sha256:pinned"]}`,
+		"sha256:img3": `{"Id": "sha256:img3", "RepoDigests": []}`}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		// GET /containers/json?filters=
+		case r.URL.Path == "/containers/json":
+			var filters map[string][]string
+			json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+			if fmt.Sprint(filters["label"]) == "[app=argus]" && fmt.Sprint(filters["status"]) == "[running]" {
+				fmt.Fprint(w, `[{"Id": "aaa111"}]`)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+		// GET /containers/{name}/json
+		case len(parts) == 3 && parts[0] == "containers" && containers[parts[1]] != "":
+			fmt.Fprint(w, containers[parts[1]])
+		// GET /images/{id}/json
+		case len(parts) == 3 && parts[0] == "images" && images[parts[1]] != "":
+			fmt.Fprint(w, images[parts[1]])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message": "No such container: %s"}`, parts[len(parts)-2])
+		}
+	}))
+	// Unix sockets have a short path limit, so don't use the (long) t.TempDir.
+	dir, _ := os.MkdirTemp("", "argus")
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %q: %s", socket, err)
+	}
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+	return "unix://" + socket
+}
+
+func TestLookup_Query_Docker(t *testing.T) {
+	// GIVEN a type:docker Lookup
+	tests := map[string]struct {
+		docker      DockerLookup
+		regex       string
+		wantVersion string
+		errRegex    string
+	}{
+		"tag by container name": {
+			docker: DockerLookup{
+				Container: "web"},
+			wantVersion: "0.17.0",
+			errRegex:    `^$`},
+		"tag by labels": {
+			docker: DockerLookup{
+				Labels: map[string]string{"app": "argus"}},
+			wantVersion: "0.17.0",
+			errRegex:    `^$`},
+		"label": {
+			docker: DockerLookup{
+				Container: "web",
+				Source:    "label"},
+			wantVersion: "0.17.0",
+			errRegex:    `^$`},
+		"missing label": {
+			docker: DockerLookup{
+				Container: "web",
+				Source:    "label",
+				Label:     "version"},
+			errRegex: `^docker unix://.*: container "web" has no "version" label$`},
+		"digest of the repository used": {
+			docker: DockerLookup{
+				Container: "web",
+				Source:    "digest"},
+			regex:       `sha256:([a-z]+)`,
+			wantVersion: "ghcr",
+			errRegex:    `^$`},
+		"digest of a local image is the image ID": {
+			docker: DockerLookup{
+				Container: "local",
+				Source:    "digest"},
+			regex:       `sha256:([a-z0-9]+)`,
+			wantVersion: "img3",
+			errRegex:    `^$`},
+		"tag of image with a registry port": {
+			docker: DockerLookup{
+				Container: "local"},
+			wantVersion: "latest",
+			errRegex:    `^$`},
+		"tag of image pinned to a digest": {
+			docker: DockerLookup{
+				Container: "db"},
+			errRegex: `^docker unix://.*: container "db" image "postgres@sha256:pinned" has no tag$`},
+		"unknown container": {
+			docker: DockerLookup{
+				Container: "unknown"},
+			errRegex: `^docker unix://.*: No such container: unknown \(404\)$`},
+		"no container with labels": {
+			docker: DockerLookup{
+				Labels: map[string]string{"app": "other"}},
+			errRegex: `^docker unix://.*: no running container with labels \[app=other\]$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "docker"
+			lookup.URL = ""
+			lookup.JSON = ""
+			lookup.Regex = tc.regex
+			lookup.Docker = &tc.docker
+			lookup.Docker.Host = testDockerEngine(t)
+			// Digests aren't semantic versions.
+			lookup.Options.SemanticVersioning = test.BoolPtr(false)
+
+			// WHEN Query is called on it
+			version, err := lookup.Query(context.Background(), false, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+		})
+	}
+}
+
+func TestDockerLookup_Client(t *testing.T) {
+	// GIVEN a DockerLookup with a Host
+	tests := map[string]struct {
+		host              string
+		caCert            string
+		allowInvalidCerts bool
+		wantBaseURL       string
+		errRegex          string
+	}{
+		"unix socket": {
+			host:        "unix:///var/run/docker.sock",
+			wantBaseURL: "http://docker",
+			errRegex:    `^$`},
+		"tcp": {
+			host:        "tcp://127.0.0.1:2375",
+			wantBaseURL: "http://127.0.0.1:2375",
+			errRegex:    `^$`},
+		"tcp with TLS": {
+			host:              "tcp://docker.example.com:2376",
+			allowInvalidCerts: true,
+			wantBaseURL:       "https://docker.example.com:2376",
+			errRegex:          `^$`},
+		"tcp with missing CA": {
+			host:     "tcp://docker.example.com:2376",
+			caCert:   "/does/not/exist.pem",
+			errRegex: `^ca_cert: open /does/not/exist.pem: no such file or directory$`},
+		"unsupported scheme": {
+			host:     "ssh://docker.example.com",
+			errRegex: `^unsupported host "ssh://docker.example.com" \(use unix:// or tcp://\)$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			docker := DockerLookup{
+				Host:   tc.host,
+				CACert: tc.caCert}
+
+			// WHEN client is called on it
+			client, baseURL, err := docker.client(tc.allowInvalidCerts)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the base URL is what we expect
+			if baseURL != tc.wantBaseURL {
+				t.Errorf("want base URL %q, got %q",
+					tc.wantBaseURL, baseURL)
+			}
+			// AND the client times out
+			if client != nil && client.Timeout != util.HTTPTimeout {
+				t.Errorf("want client timeout %s, got %s",
+					util.HTTPTimeout, client.Timeout)
+			}
+		})
+	}
+}
+
+func TestDockerLookup_Version_Cancelled(t *testing.T) {
+	// GIVEN a DockerLookup and a cancelled context
+	docker := DockerLookup{
+		Host:      testDockerEngine(t),
+		Container: "web"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// WHEN version is called with it
+	_, err := docker.version(ctx, false)
+
+	// THEN the request isn't sent
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want %q, got %v",
+			context.Canceled, err)
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	// GIVEN an image reference
+	tests := map[string]struct {
+		reference      string
+		wantTag        string
+		wantRepository string
+	}{
+		"tag": {
+			reference:      "nginx:1.25.3",
+			wantTag:        "1.25.3",
+			wantRepository: "nginx"},
+		"no tag": {
+			reference:      "nginx",
+			wantTag:        "latest",
+			wantRepository: "nginx"},
+		"registry port": {
+			reference:      "localhost:5000/app",
+			wantTag:        "latest",
+			wantRepository: "localhost:5000/app"},
+		"registry port and tag": {
+			reference:      "localhost:5000/app:v1",
+			wantTag:        "v1",
+			wantRepository: "localhost:5000/app"},
+		"digest": {
+			reference:      "postgres@sha256:abc",
+			wantTag:        "",
+			wantRepository: "postgres"},
+		"tag and digest": {
+			reference:      "postgres:16@sha256:abc",
+			wantTag:        "16",
+			wantRepository: "postgres"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN imageTag and imageRepository are called on it
+			gotTag := imageTag(tc.reference)
+			gotRepository := imageRepository(tc.reference)
+
+			// THEN the tag and repository are what we expect
+			if gotTag != tc.wantTag {
+				t.Errorf("want tag %q, got %q",
+					tc.wantTag, gotTag)
+			}
+			if gotRepository != tc.wantRepository {
+				t.Errorf("want repository %q, got %q",
+					tc.wantRepository, gotRepository)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValues_Docker(t *testing.T) {
+	// GIVEN a type:docker Lookup
+	tests := map[string]struct {
+		docker   *DockerLookup
+		errRegex []string
+	}{
+		"valid": {
+			docker: &DockerLookup{
+				Container: "web"},
+			errRegex: []string{`^$`}},
+		"no docker": {
+			errRegex: []string{
+				`^  docker: <required>`}},
+		"no container or labels": {
+			docker: &DockerLookup{},
+			errRegex: []string{
+				`^    container: <required>`}},
+		"invalid host": {
+			docker: &DockerLookup{
+				Host:      "/var/run/docker.sock",
+				Container: "web"},
+			errRegex: []string{
+				`^    host: "/var/run/docker.sock" <invalid>`}},
+		"cert without key": {
+			docker: &DockerLookup{
+				Host:      "tcp://docker:2376",
+				Cert:      "/certs/cert.pem",
+				Container: "web"},
+			errRegex: []string{
+				`^    cert/key: <invalid>`}},
+		"invalid source": {
+			docker: &DockerLookup{
+				Container: "web",
+				Source:    "env"},
+			errRegex: []string{
+				`^    source: "env" <invalid> \(only \[tag, label, digest\] are allowed\)`}},
+		"label without source label": {
+			docker: &DockerLookup{
+				Container: "web",
+				Label:     "version"},
+			errRegex: []string{
+				`^    label: "version" <invalid> \(only used with source: label\)`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "docker"
+			lookup.URL = ""
+			lookup.Docker = tc.docker
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
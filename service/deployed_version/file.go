@@ -0,0 +1,33 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// readFile returns the contents of the file at Path.
+func (l *Lookup) readFile(logFrom *util.LogFrom) ([]byte, error) {
+	body, err := os.ReadFile(l.GetPath())
+	if err != nil {
+		jLog.Warn(err, logFrom, true)
+		return nil, err //nolint:wrapcheck
+	}
+
+	return bytes.TrimSpace(body), nil
+}
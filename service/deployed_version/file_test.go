@@ -0,0 +1,392 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestLookup_Query_File(t *testing.T) {
+	// GIVEN a type:file Lookup
+	tests := map[string]struct {
+		filename    string
+		content     string
+		format      string
+		key         string
+		json        string
+		regex       string
+		wantVersion string
+		errRegex    string
+	}{
+		"VERSION file": {
+			filename:    "VERSION",
+			content:     "1.2.3\n",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"package.json with json key": {
+			filename:    "package.json",
+			content:     `{"name": "app", "version": "1.2.3"}`,
+			json:        "version",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"yaml key (format from extension)": {
+			filename:    "Chart.yaml",
+			content:     "apiVersion: v2\nappVersion: 1.2.3\n",
+			key:         "appVersion",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"toml key (format from extension)": {
+			filename:    "pyproject.toml",
+			content:     "[tool.poetry]\nname = \"app\"\nversion = \"1.2.3\"\n",
+			key:         "tool.poetry.version",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"env key": {
+			filename:    ".env",
+			content:     "# App\nexport APP_VERSION=\"1.2.3\"\n",
+			key:         "APP_VERSION",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"ini key with format": {
+			filename:    "app.settings",
+			content:     "[app]\nversion = 1.2.3\n",
+			format:      "ini",
+			key:         "app.version",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"regex": {
+			filename:    "CHANGELOG.md",
+			content:     "# Changelog\n\n## [1.2.3] - 2024-01-01\n",
+			regex:       `## \[([0-9.]+)\]`,
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"key not found": {
+			filename: "Chart.yaml",
+			content:  "apiVersion: v2\n",
+			key:      "appVersion",
+			errRegex: `^failed to find value for "appVersion"`},
+		"invalid toml": {
+			filename: "Cargo.toml",
+			content:  "[package\nversion = \"1.2.3\"\n",
+			key:      "package.version",
+			errRegex: `^failed to unmarshal .* into toml: .* - toml: line 2: expected '\.' or '\]' to end table name, but got '\\n' instead$`},
+		"file doesn't exist": {
+			errRegex: `no such file or directory$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "missing")
+			if tc.filename != "" {
+				path = filepath.Join(dir, tc.filename)
+				os.WriteFile(path, []byte(tc.content), 0600)
+			}
+			lookup := testLookup()
+			lookup.Type = "file"
+			lookup.URL = ""
+			lookup.Path = path
+			lookup.Format = tc.format
+			lookup.Key = tc.key
+			lookup.JSON = tc.json
+			lookup.Regex = tc.regex
+
+			// WHEN Query is called on it
+			version, err := lookup.Query(context.Background(), false, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+		})
+	}
+}
+
+func TestLookup_Refresh_FileFormat(t *testing.T) {
+	// GIVEN a type:file Lookup of a YAML file
+	path := filepath.Join(t.TempDir(), "status")
+	if err := os.WriteFile(path, []byte("app:\n  name: argus\n  version: 1.2.3\n"), 0600); err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+	tests := map[string]struct {
+		format      *string
+		key         *string
+		wantVersion string
+		errRegex    string
+	}{
+		"format and key": {
+			format:      test.StringPtr("yaml"),
+			key:         test.StringPtr("app.version"),
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"key not found": {
+			format:   test.StringPtr("yaml"),
+			key:      test.StringPtr("app.versoin"),
+			errRegex: `^failed to find value for "app.versoin" in "app" - no "versoin" key \(has \[name, version\]\)$`},
+		"format doesn't parse": {
+			format:   test.StringPtr("xml"),
+			key:      test.StringPtr("app.version"),
+			errRegex: `^failed to unmarshal the following from "[^"]+" into xml: `},
+		"invalid format": {
+			format:   test.StringPtr("csv"),
+			key:      test.StringPtr("app.version"),
+			errRegex: `format: "csv" <invalid>`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "file"
+			lookup.Path = path
+			lookup.JSON = ""
+			lookup.URL = ""
+
+			// WHEN Refresh is called on it with a format and key
+			version, _, err := lookup.Refresh(
+				nil, nil, nil,
+				tc.format,
+				nil, nil,
+				tc.key,
+				nil, nil, nil, nil, nil)
+
+			// THEN the err is what we expect (with the offending path)
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+		})
+	}
+}
+
+func TestLookup_GetFormat(t *testing.T) {
+	// GIVEN a Lookup
+	tests := map[string]struct {
+		lookupType string
+		path       string
+		format     string
+		want       string
+	}{
+		"url defaults to json": {
+			want: "json"},
+		"format overrides the extension": {
+			lookupType: "file",
+			path:       "/app/config.yml",
+			format:     "env",
+			want:       "env"},
+		"file yaml": {
+			lookupType: "file",
+			path:       "/app/config.yml",
+			want:       "yaml"},
+		"file .env": {
+			lookupType: "file",
+			path:       "/app/.env.production",
+			want:       "env"},
+		"file unknown extension": {
+			lookupType: "file",
+			path:       "/app/VERSION",
+			want:       "json"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := Lookup{
+				Type:   tc.lookupType,
+				Path:   tc.path,
+				Format: tc.format}
+
+			// WHEN GetFormat is called on it
+			got := lookup.GetFormat()
+
+			// THEN the format is what we expect
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValues_File(t *testing.T) {
+	// GIVEN a type:file Lookup
+	tests := map[string]struct {
+		path     string
+		format   string
+		key      string
+		errRegex []string
+	}{
+		"valid": {
+			path:     "/app/package.json",
+			key:      "version",
+			errRegex: []string{`^$`}},
+		"no path": {
+			errRegex: []string{
+				`^  path: <required>`}},
+		"invalid format": {
+			path:   "/app/VERSION",
+			format: "csv",
+			errRegex: []string{
+				`^  format: "csv" <invalid> \(only \[json, yaml, toml, xml, ini, env\] are allowed\)`}},
+		"invalid key": {
+			path: "/app/package.json",
+			key:  "versions[a]",
+			errRegex: []string{
+				`^  key: "versions\[a\]" <invalid> - failed to parse index "a"`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "file"
+			lookup.URL = ""
+			lookup.Path = tc.path
+			lookup.Format = tc.format
+			lookup.Key = tc.key
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForFileChange(t *testing.T) {
+	// GIVEN a file that may be changed
+	tests := map[string]struct {
+		change   func(path string)
+		timeout  time.Duration
+		wantWait time.Duration
+		errRegex string
+	}{
+		"written": {
+			change: func(path string) {
+				os.WriteFile(path, []byte("1.2.4"), 0600)
+			},
+			timeout:  5 * time.Second,
+			wantWait: 4 * time.Second,
+			errRegex: `^$`},
+		"replaced": {
+			change: func(path string) {
+				os.WriteFile(path+".tmp", []byte("1.2.4"), 0600)
+				os.Rename(path+".tmp", path)
+			},
+			timeout:  5 * time.Second,
+			wantWait: 4 * time.Second,
+			errRegex: `^$`},
+		"removed": {
+			change: func(path string) {
+				os.Remove(path)
+			},
+			timeout:  5 * time.Second,
+			wantWait: 4 * time.Second,
+			errRegex: `^$`},
+		"unchanged": {
+			change:   func(path string) {},
+			timeout:  500 * time.Millisecond,
+			wantWait: time.Second,
+			errRegex: `^context deadline exceeded$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "VERSION")
+			os.WriteFile(path, []byte("1.2.3"), 0600)
+			// Another file in the directory changing is ignored.
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				os.WriteFile(filepath.Join(dir, "other"), []byte("foo"), 0600)
+				time.Sleep(1100 * time.Millisecond)
+				tc.change(path)
+			}()
+
+			// WHEN waitForFileChange is called on it
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := waitForFileChange(ctx, path)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND it returns once the file changes (or the timeout passes)
+			waited := time.Since(start)
+			if waited > tc.wantWait {
+				t.Errorf("want return within %s, took %s",
+					tc.wantWait, waited)
+			}
+			if waited < time.Second && tc.timeout > time.Second {
+				t.Errorf("returned after %s, before the file changed",
+					waited)
+			}
+		})
+	}
+}
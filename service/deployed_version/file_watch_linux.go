@@ -0,0 +1,90 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package deployedver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileWatchMask is the inotify events that may change the version in a file,
+// including it being replaced (e.g. by an editor/atomic rename) or removed.
+const fileWatchMask = syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE |
+	syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO
+
+// waitForFileChange blocks until the file at `path` changes, or `ctx` is done (returning its error).
+//
+// The directory of the file is watched with inotify, so that the file being replaced
+// or created is also seen.
+func waitForFileChange(ctx context.Context, path string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("inotify init failed: %w", err)
+	}
+	// Non-blocking, so reads can have a deadline.
+	watcher := os.NewFile(uintptr(fd), "inotify")
+	defer watcher.Close()
+
+	dir, name := filepath.Split(filepath.Clean(path))
+	if dir == "" {
+		dir = "."
+	}
+	if _, err = syscall.InotifyAddWatch(fd, dir, fileWatchMask); err != nil {
+		return fmt.Errorf("inotify watch of %q failed: %w", dir, err)
+	}
+	// Interrupt the read when ctx is done.
+	stop := context.AfterFunc(ctx, func() {
+		//nolint:errcheck
+		watcher.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := watcher.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("inotify read failed: %w", err)
+		}
+
+		// Check whether any of the events are for the file.
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			// struct inotify_event { int wd; uint32_t mask; uint32_t cookie; uint32_t len; char name[]; }
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			offset = nameStart + nameLen
+			if offset > n {
+				break
+			}
+			if strings.TrimRight(string(buf[nameStart:offset]), "\x00") == name {
+				return nil
+			}
+		}
+	}
+}
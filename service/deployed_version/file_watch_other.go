@@ -0,0 +1,53 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package deployedver
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// fileWatchPollInterval is how often the modification time of the file is checked.
+const fileWatchPollInterval = time.Second
+
+// waitForFileChange blocks until the file at `path` changes, or `ctx` is done (returning its error).
+//
+// inotify is only available on Linux, so the modification time of the file is polled.
+func waitForFileChange(ctx context.Context, path string) error {
+	modTime := func() time.Time {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+
+	initial := modTime()
+	ticker := time.NewTicker(fileWatchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !modTime().Equal(initial) {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
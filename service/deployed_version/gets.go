@@ -17,6 +17,7 @@ package deployedver
 import (
 	"io"
 	"strings"
+	"time"
 
 	"github.com/release-argus/Argus/util"
 )
@@ -29,6 +30,49 @@ func (l *Lookup) GetAllowInvalidCerts() bool {
 		l.HardDefaults.AllowInvalidCerts)
 }
 
+// GetInterval between queries of the deployed version (defaults to the Service's interval).
+func (l *Lookup) GetInterval() string {
+	return util.FirstNonDefault(
+		l.Interval,
+		l.Defaults.Interval,
+		l.HardDefaults.Interval,
+		l.Options.GetInterval())
+}
+
+// GetIntervalDuration returns the interval between queries of the deployed version.
+func (l *Lookup) GetIntervalDuration() time.Duration {
+	d, _ := time.ParseDuration(l.GetInterval())
+	return d
+}
+
+// GetWatchInterval between queries after actions have run.
+func (l *Lookup) GetWatchInterval() string {
+	return util.FirstNonDefault(
+		l.WatchInterval,
+		l.Defaults.WatchInterval,
+		l.HardDefaults.WatchInterval)
+}
+
+// GetWatchIntervalDuration returns the interval between queries after actions have run.
+func (l *Lookup) GetWatchIntervalDuration() time.Duration {
+	d, _ := time.ParseDuration(l.GetWatchInterval())
+	return d
+}
+
+// GetWatchTimeout returns how long to watch for the approved version to be deployed after actions have run.
+func (l *Lookup) GetWatchTimeout() string {
+	return util.FirstNonDefault(
+		l.WatchTimeout,
+		l.Defaults.WatchTimeout,
+		l.HardDefaults.WatchTimeout)
+}
+
+// GetWatchTimeoutDuration returns how long to watch for the approved version to be deployed after actions have run.
+func (l *Lookup) GetWatchTimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(l.GetWatchTimeout())
+	return d
+}
+
 // GetURL will return the URL of the Lookup.
 func (l *Lookup) GetURL() string {
 	return util.EvalEnvVars(l.URL)
@@ -41,3 +85,72 @@ func (l *Lookup) GetBody() io.Reader {
 	}
 	return strings.NewReader(*l.Body)
 }
+
+// GetType returns the type of the Lookup (defaults to url).
+func (l *Lookup) GetType() string {
+	if l.Type == "" {
+		return "url"
+	}
+	return l.Type
+}
+
+// GetTarget returns what the Lookup queries,
+// the URL (type:url), the Command (type:command), the Path (type:file), the container (type:docker),
+// the workload (type:kubernetes), or the SSH profile and Command (type:ssh).
+func (l *Lookup) GetTarget() string {
+	switch l.GetType() {
+	case "command":
+		return l.Command.String()
+	case "ssh":
+		return l.SSH + ": " + l.Command.String()
+	case "file":
+		return l.GetPath()
+	case "docker":
+		container := l.Docker.Container
+		if container == "" {
+			container = strings.Join(l.Docker.labelFilters(), ",")
+		}
+		return l.Docker.GetHost() + " " + container
+	case "kubernetes":
+		return l.Kubernetes.GetKind() + "/" + l.Kubernetes.Name
+	default:
+		return l.GetURL()
+	}
+}
+
+// GetPath returns the Path of the file (type:file).
+func (l *Lookup) GetPath() string {
+	return util.EvalEnvVars(l.Path)
+}
+
+// GetFormat returns the Format to get the Key from,
+// (type:file) inferred from the extension of the Path when not set, otherwise json.
+func (l *Lookup) GetFormat() string {
+	if l.Format != "" {
+		return l.Format
+	}
+	if l.GetType() == "file" {
+		if format := util.FormatFromPath(l.Path); format != "" {
+			return format
+		}
+	}
+	return "json"
+}
+
+// GetKey returns the Key to get the version from (defaults to the JSON key).
+func (l *Lookup) GetKey() string {
+	if l.Key != "" {
+		return l.Key
+	}
+	return l.JSON
+}
+
+// GetTimeoutDuration returns the time the Command can run for (defaults to 10s).
+func (l *Lookup) GetTimeoutDuration() time.Duration {
+	if l.Timeout != "" {
+		if timeout, err := time.ParseDuration(l.Timeout); err == nil {
+			return timeout
+		}
+	}
+	return 10 * time.Second
+}
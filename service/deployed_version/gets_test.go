@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/release-argus/Argus/test"
 )
@@ -69,6 +70,112 @@ func TestLookup_GetAllowInvalidCerts(t *testing.T) {
 	}
 }
 
+func TestLookup_GetInterval(t *testing.T) {
+	// GIVEN a Lookup
+	tests := map[string]struct {
+		root            string
+		dfault          string
+		hardDefault     string
+		serviceInterval string
+		want            string
+	}{
+		"root overrides all": {
+			want:            "1m",
+			root:            "1m",
+			dfault:          "2m",
+			hardDefault:     "3m",
+			serviceInterval: "1h"},
+		"default overrides hardDefault": {
+			want:            "2m",
+			dfault:          "2m",
+			hardDefault:     "3m",
+			serviceInterval: "1h"},
+		"hardDefault overrides the Service interval": {
+			want:            "3m",
+			hardDefault:     "3m",
+			serviceInterval: "1h"},
+		"Service interval is last resort": {
+			want:            "1h",
+			serviceInterval: "1h"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Interval = tc.root
+			lookup.Defaults.Interval = tc.dfault
+			lookup.HardDefaults.Interval = tc.hardDefault
+			lookup.Options.Interval = tc.serviceInterval
+
+			// WHEN GetInterval is called
+			got := lookup.GetInterval()
+
+			// THEN the function returns the correct result
+			if got != tc.want {
+				t.Errorf("want: %q\ngot:  %q",
+					tc.want, got)
+			}
+			// AND GetIntervalDuration is that duration
+			wantDuration, _ := time.ParseDuration(tc.want)
+			if gotDuration := lookup.GetIntervalDuration(); gotDuration != wantDuration {
+				t.Errorf("want duration: %s\ngot:  %s",
+					wantDuration, gotDuration)
+			}
+		})
+	}
+}
+
+func TestLookup_GetWatchIntervalAndTimeout(t *testing.T) {
+	// GIVEN a Lookup
+	tests := map[string]struct {
+		root, dfault, hardDefault string
+		want                      time.Duration
+	}{
+		"root overrides all": {
+			want:        time.Second,
+			root:        "1s",
+			dfault:      "2s",
+			hardDefault: "3s"},
+		"default overrides hardDefault": {
+			want:        2 * time.Second,
+			dfault:      "2s",
+			hardDefault: "3s"},
+		"hardDefault is last resort": {
+			want:        3 * time.Second,
+			hardDefault: "3s"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.WatchInterval = tc.root
+			lookup.Defaults.WatchInterval = tc.dfault
+			lookup.HardDefaults.WatchInterval = tc.hardDefault
+			lookup.WatchTimeout = tc.root
+			lookup.Defaults.WatchTimeout = tc.dfault
+			lookup.HardDefaults.WatchTimeout = tc.hardDefault
+
+			// WHEN GetWatchIntervalDuration and GetWatchTimeoutDuration are called
+			gotInterval := lookup.GetWatchIntervalDuration()
+			gotTimeout := lookup.GetWatchTimeoutDuration()
+
+			// THEN the functions return the correct result
+			if gotInterval != tc.want {
+				t.Errorf("want interval: %s\ngot:  %s",
+					tc.want, gotInterval)
+			}
+			if gotTimeout != tc.want {
+				t.Errorf("want timeout: %s\ngot:  %s",
+					tc.want, gotTimeout)
+			}
+		})
+	}
+}
+
 func TestLookup_GetURL(t *testing.T) {
 	// GIVEN a Lookup
 	tests := map[string]struct {
@@ -15,6 +15,7 @@
 package deployedver
 
 import (
+	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/util"
@@ -32,6 +33,7 @@ func (l *Lookup) Init(
 	hardDefaults *LookupDefaults,
 	status *svcstatus.Status,
 	options *opt.Options,
+	notify *shoutrrr.Slice,
 ) {
 	if l == nil {
 		return
@@ -41,6 +43,8 @@ func (l *Lookup) Init(
 	l.HardDefaults = hardDefaults
 	l.Status = status
 	l.Options = options
+	l.Notify = notify
+	l.watch = newWatch()
 }
 
 // InitMetrics for this Lookup.
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/test"
@@ -83,12 +84,14 @@ func TestLookup_Init(t *testing.T) {
 	hardDefaults := &LookupDefaults{}
 	status := svcstatus.Status{ServiceID: test.StringPtr("TestInit")}
 	var options opt.Options
+	var notify shoutrrr.Slice
 
 	// WHEN Init is called on it
 	lookup.Init(
 		defaults, hardDefaults,
 		&status,
-		&options)
+		&options,
+		&notify)
 
 	// THEN pointers to those vars are handed out to the Lookup
 	// defaults
@@ -111,12 +114,18 @@ func TestLookup_Init(t *testing.T) {
 		t.Errorf("Options were not handed to the Lookup correctly\n want: %v\ngot:  %v",
 			&options, lookup.Options)
 	}
+	// notify
+	if lookup.Notify != &notify {
+		t.Errorf("Notify was not handed to the Lookup correctly\n want: %v\ngot:  %v",
+			&notify, lookup.Notify)
+	}
 
 	var nilLookup *Lookup
 	nilLookup.Init(
 		defaults, hardDefaults,
 		&status,
-		&options)
+		&options,
+		&notify)
 	if nilLookup != nil {
 		t.Error("Init on nil shouldn't have initialised the Lookup")
 	}
@@ -0,0 +1,286 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	net_url "net/url"
+	"strings"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	kubernetesKinds   = []string{"deployment", "statefulset", "daemonset"}
+	kubernetesSources = []string{"tag", "label", "annotation"}
+)
+
+// KubernetesLookup finds the deployed version from a workload with the Kubernetes API.
+type KubernetesLookup struct {
+	Kubeconfig   string `yaml:"kubeconfig,omitempty" json:"kubeconfig,omitempty"`       // OPTIONAL: Path of the kubeconfig (default in-cluster service account, $KUBECONFIG or ~/.kube/config).
+	Context      string `yaml:"context,omitempty" json:"context,omitempty"`             // OPTIONAL: Context of the kubeconfig to use (default current-context).
+	Namespace    string `yaml:"namespace,omitempty" json:"namespace,omitempty"`         // OPTIONAL: Namespace of the workload (default that of the context/service account, or 'default').
+	Kind         string `yaml:"kind,omitempty" json:"kind,omitempty"`                   // OPTIONAL: deployment (default)/statefulset/daemonset.
+	Name         string `yaml:"name,omitempty" json:"name,omitempty"`                   // REQUIRED: Name of the workload.
+	Container    string `yaml:"container,omitempty" json:"container,omitempty"`         // OPTIONAL: Container to get the image tag of (default the first).
+	Source       string `yaml:"source,omitempty" json:"source,omitempty"`               // OPTIONAL: tag (default)/label/annotation - What to use as the version.
+	Key          string `yaml:"key,omitempty" json:"key,omitempty"`                     // REQUIRED (source:label/annotation): Key of the label/annotation, e.g. app.kubernetes.io/version.
+	RequireReady bool   `yaml:"require_ready,omitempty" json:"require_ready,omitempty"` // OPTIONAL: Only count the version once all replicas are updated and ready.
+}
+
+// kubernetesMetadata is the part of the metadata of an object that we use.
+type kubernetesMetadata struct {
+	Generation  int64             `json:"generation"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// kubernetesWorkload is the part of a Deployment/StatefulSet/DaemonSet that we use.
+type kubernetesWorkload struct {
+	Metadata kubernetesMetadata `json:"metadata"`
+	Spec     struct {
+		Replicas *int `json:"replicas"`
+		Template struct {
+			Metadata kubernetesMetadata `json:"metadata"`
+			Spec     struct {
+				Containers []struct {
+					Name  string `json:"name"`
+					Image string `json:"image"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+		// Deployment/StatefulSet
+		Replicas        int    `json:"replicas"`
+		UpdatedReplicas int    `json:"updatedReplicas"`
+		ReadyReplicas   int    `json:"readyReplicas"`
+		CurrentRevision string `json:"currentRevision"`
+		UpdateRevision  string `json:"updateRevision"`
+		// DaemonSet
+		DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+		CurrentNumberScheduled int `json:"currentNumberScheduled"`
+		UpdatedNumberScheduled int `json:"updatedNumberScheduled"`
+		NumberReady            int `json:"numberReady"`
+	} `json:"status"`
+}
+
+// GetKind returns the Kind of the workload (defaults to deployment).
+func (k *KubernetesLookup) GetKind() string {
+	if k.Kind == "" {
+		return "deployment"
+	}
+	return strings.ToLower(k.Kind)
+}
+
+// GetSource returns what to use as the version (defaults to tag).
+func (k *KubernetesLookup) GetSource() string {
+	if k.Source == "" {
+		return "tag"
+	}
+	return k.Source
+}
+
+// kubernetesVersion returns the version of the workload from the Kubernetes API.
+func (l *Lookup) kubernetesVersion(ctx context.Context, logFrom *util.LogFrom) ([]byte, error) {
+	version, err := l.Kubernetes.version(ctx, l.GetAllowInvalidCerts())
+	if err != nil {
+		err = fmt.Errorf("kubernetes %s/%s: %w",
+			l.Kubernetes.GetKind(), l.Kubernetes.Name, err)
+		jLog.Warn(err, logFrom, true)
+		return nil, err
+	}
+
+	return []byte(version), nil
+}
+
+// version gets the workload and returns the tag/label/annotation of it.
+func (k *KubernetesLookup) version(ctx context.Context, allowInvalidCerts bool) (string, error) {
+	config, err := k.config(allowInvalidCerts)
+	if err != nil {
+		return "", err
+	}
+
+	workload, err := k.getWorkload(ctx, config)
+	if err != nil {
+		return "", err
+	}
+
+	if k.RequireReady {
+		if err := workload.ready(k.GetKind()); err != nil {
+			return "", err
+		}
+	}
+
+	switch source := k.GetSource(); source {
+	case "label", "annotation":
+		// Prefer the workload metadata, then the Pod template.
+		values := []map[string]string{workload.Metadata.Labels, workload.Spec.Template.Metadata.Labels}
+		if source == "annotation" {
+			values = []map[string]string{workload.Metadata.Annotations, workload.Spec.Template.Metadata.Annotations}
+		}
+		for _, value := range values {
+			if version := value[k.Key]; version != "" {
+				return version, nil
+			}
+		}
+		return "", fmt.Errorf("no %q %s", k.Key, source)
+	default:
+		containers := workload.Spec.Template.Spec.Containers
+		for i := range containers {
+			if k.Container == "" || containers[i].Name == k.Container {
+				tag := imageTag(containers[i].Image)
+				if tag == "" {
+					return "", fmt.Errorf("container %q image %q has no tag",
+						containers[i].Name, containers[i].Image)
+				}
+				return tag, nil
+			}
+		}
+		return "", fmt.Errorf("container %q not found", k.Container)
+	}
+}
+
+// getWorkload gets the Deployment/StatefulSet/DaemonSet from the API server.
+func (k *KubernetesLookup) getWorkload(ctx context.Context, config *kubernetesConfig) (*kubernetesWorkload, error) {
+	namespace := util.FirstNonDefault(k.Namespace, config.namespace, "default")
+	url := fmt.Sprintf("%s/apis/apps/v1/namespaces/%s/%ss/%s",
+		strings.TrimSuffix(config.server, "/"),
+		net_url.PathEscape(namespace), k.GetKind(), net_url.PathEscape(k.Name))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request creation failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	config.authorize(req)
+
+	resp, err := config.client().Do(req)
+	if err != nil {
+		// Don't log the whole certificate error.
+		if strings.Contains(err.Error(), "x509") {
+			err = fmt.Errorf("x509 (certificate invalid)")
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Errors are a Status, {"message": "..."}.
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &status) == nil && status.Message != "" {
+			return nil, fmt.Errorf("%s (%d)", status.Message, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("non-200 response code: %d", resp.StatusCode)
+	}
+
+	var workload kubernetesWorkload
+	if err = json.Unmarshal(body, &workload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the %s: %w", k.GetKind(), err)
+	}
+	return &workload, nil
+}
+
+// ready returns an error if the rollout of the workload isn't complete,
+// i.e. not all replicas are updated and ready.
+func (w *kubernetesWorkload) ready(kind string) error {
+	if w.Status.ObservedGeneration < w.Metadata.Generation {
+		return fmt.Errorf("rollout in progress (generation %d not yet observed)",
+			w.Metadata.Generation)
+	}
+
+	var desired, current, updated, ready int
+	switch kind {
+	case "daemonset":
+		desired = w.Status.DesiredNumberScheduled
+		current = w.Status.CurrentNumberScheduled
+		updated = w.Status.UpdatedNumberScheduled
+		ready = w.Status.NumberReady
+	default:
+		desired = 1
+		if w.Spec.Replicas != nil {
+			desired = *w.Spec.Replicas
+		}
+		current = w.Status.Replicas
+		updated = w.Status.UpdatedReplicas
+		ready = w.Status.ReadyReplicas
+		if kind == "statefulset" && w.Status.UpdateRevision != "" &&
+			w.Status.CurrentRevision != w.Status.UpdateRevision {
+			return fmt.Errorf("rollout in progress (revision %q, want %q)",
+				w.Status.CurrentRevision, w.Status.UpdateRevision)
+		}
+	}
+
+	if updated < desired || ready < desired || current > desired {
+		return fmt.Errorf("rollout in progress (%d/%d updated, %d/%d ready, %d total)",
+			updated, desired, ready, desired, current)
+	}
+	return nil
+}
+
+// CheckValues of the KubernetesLookup.
+func (k *KubernetesLookup) CheckValues(prefix string) (errs error) {
+	if k == nil {
+		return
+	}
+
+	if k.Kind != "" && !util.Contains(kubernetesKinds, k.GetKind()) {
+		errs = fmt.Errorf("%s%skind: %q <invalid> (only [%s] are allowed)\\",
+			util.ErrorToString(errs), prefix, k.Kind, strings.Join(kubernetesKinds, ", "))
+	} else if k.Kind != "" {
+		k.Kind = k.GetKind()
+	}
+
+	if k.Name == "" {
+		errs = fmt.Errorf("%s%sname: <required> (name of the %s)\\",
+			util.ErrorToString(errs), prefix, k.GetKind())
+	}
+
+	if k.Source != "" && !util.Contains(kubernetesSources, k.Source) {
+		errs = fmt.Errorf("%s%ssource: %q <invalid> (only [%s] are allowed)\\",
+			util.ErrorToString(errs), prefix, k.Source, strings.Join(kubernetesSources, ", "))
+	}
+
+	source := k.GetSource()
+	if source == "label" || source == "annotation" {
+		if k.Key == "" {
+			errs = fmt.Errorf("%s%skey: <required> (%s to use, e.g. 'app.kubernetes.io/version')\\",
+				util.ErrorToString(errs), prefix, source)
+		}
+	} else if k.Key != "" {
+		errs = fmt.Errorf("%s%skey: %q <invalid> (only used with source: label/annotation)\\",
+			util.ErrorToString(errs), prefix, k.Key)
+	}
+
+	if path := k.kubeconfigPath(); path != "" {
+		if user, auth := kubeconfigUnsupportedAuth(path, util.EvalEnvVars(k.Context)); auth != "" {
+			errs = fmt.Errorf("%s%skubeconfig: %q <unsupported> (user %q uses %s, only token, tokenFile, username/password and client certificates are supported)\\",
+				util.ErrorToString(errs), prefix, path, user, auth)
+		}
+	}
+
+	return
+}
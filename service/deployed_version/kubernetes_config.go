@@ -0,0 +1,333 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/release-argus/Argus/util"
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesServiceAccountDir is where the service account is mounted in a Pod.
+var kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// kubernetesConfig is the API server, credentials and namespace to use.
+type kubernetesConfig struct {
+	server    string
+	namespace string
+	token     string
+	username  string
+	password  string
+	tlsConfig *tls.Config
+}
+
+// kubeconfig is the part of a kubeconfig file that we use.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string         `yaml:"name"`
+		User kubeconfigUser `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeconfigUser is the credentials of a kubeconfig user.
+type kubeconfigUser struct {
+	Token                 string                 `yaml:"token"`
+	TokenFile             string                 `yaml:"tokenFile"`
+	Username              string                 `yaml:"username"`
+	Password              string                 `yaml:"password"`
+	ClientCertificate     string                 `yaml:"client-certificate"`
+	ClientCertificateData string                 `yaml:"client-certificate-data"`
+	ClientKey             string                 `yaml:"client-key"`
+	ClientKeyData         string                 `yaml:"client-key-data"`
+	Exec                  map[string]interface{} `yaml:"exec"`          // Unsupported.
+	AuthProvider          map[string]interface{} `yaml:"auth-provider"` // Unsupported.
+}
+
+// unsupportedAuth returns the credential plugin the user authenticates with, "exec"/"auth-provider",
+// or "" if it only uses the token/basic auth/client certificate that we support.
+func (u *kubeconfigUser) unsupportedAuth() string {
+	switch {
+	case u.Exec != nil:
+		return "exec"
+	case u.AuthProvider != nil:
+		return "auth-provider"
+	}
+	return ""
+}
+
+// config returns the kubernetesConfig from the Kubeconfig, or the service account when in-cluster,
+// falling back to $KUBECONFIG/~/.kube/config.
+func (k *KubernetesLookup) config(allowInvalidCerts bool) (*kubernetesConfig, error) {
+	path := k.kubeconfigPath()
+	if path == "" {
+		return inClusterConfig(allowInvalidCerts)
+	}
+
+	return kubeconfigConfig(path, util.EvalEnvVars(k.Context), allowInvalidCerts)
+}
+
+// kubeconfigPath returns the path of the kubeconfig to use, or "" for the in-cluster service account.
+func (k *KubernetesLookup) kubeconfigPath() string {
+	path := util.EvalEnvVars(k.Kubeconfig)
+	if path == "" {
+		if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			return ""
+		}
+		path = os.Getenv("KUBECONFIG")
+		if path == "" {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, ".kube", "config")
+		}
+	}
+	return path
+}
+
+// inClusterConfig returns the kubernetesConfig for the service account of this Pod.
+func inClusterConfig(allowInvalidCerts bool) (*kubernetesConfig, error) {
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	port := os.Getenv("KUBERNETES_SERVICE_PORT")
+	if port == "" {
+		port = "443"
+	}
+
+	token, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("service account token: %w", err)
+	}
+	config := &kubernetesConfig{
+		server: "https://" + net.JoinHostPort(host, port),
+		token:  strings.TrimSpace(string(token))}
+	if namespace, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "namespace")); err == nil {
+		config.namespace = strings.TrimSpace(string(namespace))
+	}
+
+	caCert, _ := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "ca.crt"))
+	if config.tlsConfig, err = kubernetesTLSConfig(caCert, nil, nil, allowInvalidCerts); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// readKubeconfig parses the kubeconfig file at `path`.
+func readKubeconfig(path string) (*kubeconfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %w", err)
+	}
+	var kc kubeconfig
+	if err = yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("kubeconfig %q: %w", path, err)
+	}
+	return &kc, nil
+}
+
+// contextIndex returns the index of the `contextName` (or current-context) context, or -1 if not found.
+func (kc *kubeconfig) contextIndex(contextName string) int {
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	for i := range kc.Contexts {
+		if kc.Contexts[i].Name == contextName {
+			return i
+		}
+	}
+	return -1
+}
+
+// user returns the `name` user, or nil if not found.
+func (kc *kubeconfig) user(name string) *kubeconfigUser {
+	for i := range kc.Users {
+		if kc.Users[i].Name == name {
+			return &kc.Users[i].User
+		}
+	}
+	return nil
+}
+
+// kubeconfigUnsupportedAuth returns the user of the `contextName` (or current-context)
+// of the kubeconfig file at `path`, and the credential plugin it uses that we don't support (if any).
+func kubeconfigUnsupportedAuth(path string, contextName string) (userName string, auth string) {
+	kc, err := readKubeconfig(path)
+	if err != nil {
+		return "", ""
+	}
+	contextIndex := kc.contextIndex(contextName)
+	if contextIndex == -1 {
+		return "", ""
+	}
+	userName = kc.Contexts[contextIndex].Context.User
+	if user := kc.user(userName); user != nil {
+		auth = user.unsupportedAuth()
+	}
+	return userName, auth
+}
+
+// kubeconfigConfig returns the kubernetesConfig for the `contextName` (or current-context)
+// of the kubeconfig file at `path`.
+func kubeconfigConfig(path string, contextName string, allowInvalidCerts bool) (*kubernetesConfig, error) {
+	kc, err := readKubeconfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Context
+	contextIndex := kc.contextIndex(contextName)
+	if contextIndex == -1 {
+		if contextName == "" {
+			contextName = kc.CurrentContext
+		}
+		return nil, fmt.Errorf("kubeconfig %q: context %q not found", path, contextName)
+	}
+	context := kc.Contexts[contextIndex].Context
+	config := &kubernetesConfig{
+		namespace: context.Namespace}
+
+	// Cluster
+	clusterIndex := -1
+	for i := range kc.Clusters {
+		if kc.Clusters[i].Name == context.Cluster {
+			clusterIndex = i
+			break
+		}
+	}
+	if clusterIndex == -1 {
+		return nil, fmt.Errorf("kubeconfig %q: cluster %q not found", path, context.Cluster)
+	}
+	cluster := kc.Clusters[clusterIndex].Cluster
+	config.server = cluster.Server
+	caCert, err := kubeconfigData(cluster.CertificateAuthorityData, cluster.CertificateAuthority, path)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig %q: certificate-authority: %w", path, err)
+	}
+
+	// User
+	var clientCert, clientKey []byte
+	if user := kc.user(context.User); user != nil {
+		// Don't send the requests unauthenticated.
+		if auth := user.unsupportedAuth(); auth != "" {
+			return nil, fmt.Errorf("kubeconfig %q: user %q: %s is unsupported", path, context.User, auth)
+		}
+		config.token = user.Token
+		if config.token == "" && user.TokenFile != "" {
+			token, err := os.ReadFile(kubeconfigPath(user.TokenFile, path))
+			if err != nil {
+				return nil, fmt.Errorf("kubeconfig %q: tokenFile: %w", path, err)
+			}
+			config.token = strings.TrimSpace(string(token))
+		}
+		config.username = user.Username
+		config.password = user.Password
+		if clientCert, err = kubeconfigData(user.ClientCertificateData, user.ClientCertificate, path); err != nil {
+			return nil, fmt.Errorf("kubeconfig %q: client-certificate: %w", path, err)
+		}
+		if clientKey, err = kubeconfigData(user.ClientKeyData, user.ClientKey, path); err != nil {
+			return nil, fmt.Errorf("kubeconfig %q: client-key: %w", path, err)
+		}
+	}
+
+	if config.tlsConfig, err = kubernetesTLSConfig(caCert, clientCert, clientKey,
+		allowInvalidCerts || cluster.InsecureSkipTLSVerify); err != nil {
+		return nil, fmt.Errorf("kubeconfig %q: %w", path, err)
+	}
+	return config, nil
+}
+
+// kubeconfigData returns the base64 decoded `data`, or the contents of the `file` if there's no data.
+func kubeconfigData(data string, file string, kubeconfigFile string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data) //nolint:wrapcheck
+	}
+	if file != "" {
+		return os.ReadFile(kubeconfigPath(file, kubeconfigFile)) //nolint:wrapcheck
+	}
+	return nil, nil
+}
+
+// kubeconfigPath returns the `path`, relative to the kubeconfig file if it isn't absolute.
+func kubeconfigPath(path string, kubeconfigFile string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(kubeconfigFile), path)
+}
+
+// kubernetesTLSConfig returns the TLS config trusting the `caCert`, with the client certificate (if any).
+func kubernetesTLSConfig(caCert, clientCert, clientKey []byte, allowInvalidCerts bool) (*tls.Config, error) {
+	//#nosec G402 -- explicitly wanted InsecureSkipVerify
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: allowInvalidCerts}
+
+	if len(caCert) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in the certificate-authority")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(clientCert) != 0 || len(clientKey) != 0 {
+		cert, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// client returns the HTTP client for the API server.
+func (c *kubernetesConfig) client() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: c.tlsConfig},
+		Timeout:   util.HTTPTimeout}
+}
+
+// authorize the `req` with the token or basic auth credentials.
+func (c *kubernetesConfig) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var testKubernetesWorkloads = map[string]string{
+	"/apis/apps/v1/namespaces/argus/deployments/argus": `{
+		"metadata": {"generation": 2, "labels": {"app.kubernetes.io/version": "1.2.3"}},
+		"spec": {"replicas": 3, "template": {
+			"metadata": {"annotations": {"argus/version": "1.2.4"}},
+			"spec": {"containers": [
+				{"name": "sidecar", "image": "envoy:v1.28.0"},
+				{"name": "argus", "image": "ghcr.io/release-argus/argus:1.2.3"}]}}},
+		"status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 3, "readyReplicas": 3}}`,
+	"/apis/apps/v1/namespaces/argus/deployments/rolling": `{
+		"metadata": {"generation": 3},
+		"spec": {"replicas": 3, "template": {"spec": {"containers": [{"name": "app", "image": "app:2.0.0"}]}}},
+		"status": {"observedGeneration": 3, "replicas": 4, "updatedReplicas": 2, "readyReplicas": 3}}`,
+	"/apis/apps/v1/namespaces/argus/deployments/pinned": `{
+		"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app@sha256:abc"}]}}}}`,
+	"/apis/apps/v1/namespaces/argus/statefulsets/db": `{
+		"metadata": {"generation": 1},
+		"spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "db", "image": "postgres:16.1.0"}]}}},
+		"status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "readyReplicas": 1,
+			"currentRevision": "db-a", "updateRevision": "db-b"}}`,
+	"/apis/apps/v1/namespaces/default/daemonsets/agent": `{
+		"metadata": {"generation": 1},
+		"spec": {"template": {"spec": {"containers": [{"name": "agent", "image": "agent:2.0.0"}]}}},
+		"status": {"observedGeneration": 1, "desiredNumberScheduled": 2, "currentNumberScheduled": 2,
+			"updatedNumberScheduled": 2, "numberReady": 2}}`,
+}
+
+// testKubernetesHandler is a fake API server that requires the `token`.
+func testKubernetesHandler(token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind": "Status", "message": "Unauthorized"}`)
+			return
+		}
+		workload, ok := testKubernetesWorkloads[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"kind": "Status", "message": "%s not found"}`, filepath.Base(r.URL.Path))
+			return
+		}
+		fmt.Fprint(w, workload)
+	}
+}
+
+// testKubeconfig writes a kubeconfig for the `server` to a temp dir, returning its path.
+func testKubeconfig(t *testing.T, server string, caData string, token string) string {
+	kubeconfig := fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+  - name: test
+    cluster:
+      server: %s
+      certificate-authority-data: %s
+users:
+  - name: argus
+    user:
+      tokenFile: token
+  - name: eks
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1beta1
+        command: aws
+  - name: gke
+    user:
+      auth-provider:
+        name: gcp
+contexts:
+  - name: eks
+    context:
+      cluster: test
+      user: eks
+  - name: gke
+    context:
+      cluster: test
+      user: gke
+  - name: other
+    context:
+      cluster: other
+      user: argus
+  - name: test
+    context:
+      cluster: test
+      user: argus
+      namespace: argus
+`, server, caData)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "token"), []byte(token+"\n"), 0600)
+	path := filepath.Join(dir, "config")
+	os.WriteFile(path, []byte(kubeconfig), 0600)
+	return path
+}
+
+func TestLookup_Query_Kubernetes(t *testing.T) {
+	// GIVEN a type:kubernetes Lookup
+	tests := map[string]struct {
+		kubernetes  KubernetesLookup
+		token       string
+		wantVersion string
+		errRegex    string
+	}{
+		"tag of the first container": {
+			kubernetes: KubernetesLookup{
+				Name: "argus"},
+			wantVersion: "v1.28.0",
+			errRegex:    `^$`},
+		"tag of the container": {
+			kubernetes: KubernetesLookup{
+				Name:      "argus",
+				Container: "argus"},
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"unknown container": {
+			kubernetes: KubernetesLookup{
+				Name:      "argus",
+				Container: "unknown"},
+			errRegex: `^kubernetes deployment/argus: container "unknown" not found$`},
+		"image pinned to a digest": {
+			kubernetes: KubernetesLookup{
+				Name: "pinned"},
+			errRegex: `^kubernetes deployment/pinned: container "app" image "app@sha256:abc" has no tag$`},
+		"label": {
+			kubernetes: KubernetesLookup{
+				Name:   "argus",
+				Source: "label",
+				Key:    "app.kubernetes.io/version"},
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"annotation of the pod template": {
+			kubernetes: KubernetesLookup{
+				Name:   "argus",
+				Source: "annotation",
+				Key:    "argus/version"},
+			wantVersion: "1.2.4",
+			errRegex:    `^$`},
+		"missing annotation": {
+			kubernetes: KubernetesLookup{
+				Name:   "argus",
+				Source: "annotation",
+				Key:    "app.kubernetes.io/version"},
+			errRegex: `^kubernetes deployment/argus: no "app.kubernetes.io/version" annotation$`},
+		"require_ready, ready": {
+			kubernetes: KubernetesLookup{
+				Name:         "argus",
+				Container:    "argus",
+				RequireReady: true},
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"require_ready, rolling out": {
+			kubernetes: KubernetesLookup{
+				Name:         "rolling",
+				RequireReady: true},
+			errRegex: `^kubernetes deployment/rolling: rollout in progress \(2/3 updated, 3/3 ready, 4 total\)$`},
+		"rolling out without require_ready": {
+			kubernetes: KubernetesLookup{
+				Name: "rolling"},
+			wantVersion: "2.0.0",
+			errRegex:    `^$`},
+		"require_ready, statefulset revision": {
+			kubernetes: KubernetesLookup{
+				Kind:         "statefulset",
+				Name:         "db",
+				RequireReady: true},
+			errRegex: `^kubernetes statefulset/db: rollout in progress \(revision "db-a", want "db-b"\)$`},
+		"require_ready, daemonset in another namespace": {
+			kubernetes: KubernetesLookup{
+				Kind:         "daemonset",
+				Namespace:    "default",
+				Name:         "agent",
+				RequireReady: true},
+			wantVersion: "2.0.0",
+			errRegex:    `^$`},
+		"not found": {
+			kubernetes: KubernetesLookup{
+				Name: "unknown"},
+			errRegex: `^kubernetes deployment/unknown: unknown not found \(404\)$`},
+		"unauthorized": {
+			kubernetes: KubernetesLookup{
+				Name: "argus"},
+			token:    "wrong",
+			errRegex: `^kubernetes deployment/argus: Unauthorized \(401\)$`},
+		"unknown context": {
+			kubernetes: KubernetesLookup{
+				Name:    "argus",
+				Context: "unknown"},
+			errRegex: `^kubernetes deployment/argus: kubeconfig ".*": context "unknown" not found$`},
+		"context without a cluster": {
+			kubernetes: KubernetesLookup{
+				Name:    "argus",
+				Context: "other"},
+			errRegex: `^kubernetes deployment/argus: kubeconfig ".*": cluster "other" not found$`},
+		"exec user": {
+			kubernetes: KubernetesLookup{
+				Name:    "argus",
+				Context: "eks"},
+			errRegex: `^kubernetes deployment/argus: kubeconfig ".*": user "eks": exec is unsupported$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(testKubernetesHandler("token"))
+			t.Cleanup(server.Close)
+			lookup := testLookup()
+			lookup.Type = "kubernetes"
+			lookup.URL = ""
+			lookup.JSON = ""
+			lookup.Kubernetes = &tc.kubernetes
+			lookup.Kubernetes.Kubeconfig = testKubeconfig(t, server.URL, "", util.FirstNonDefault(tc.token, "token"))
+
+			// WHEN Query is called on it
+			version, err := lookup.Query(context.Background(), false, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+		})
+	}
+}
+
+func TestKubernetesLookup_Config_TLS(t *testing.T) {
+	// GIVEN an API server with a self-signed certificate
+	server := httptest.NewTLSServer(testKubernetesHandler("token"))
+	t.Cleanup(server.Close)
+	caData := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw}))
+	tests := map[string]struct {
+		caData            string
+		allowInvalidCerts bool
+		errRegex          string
+	}{
+		"trusted with certificate-authority-data": {
+			caData:   caData,
+			errRegex: `^$`},
+		"untrusted": {
+			errRegex: `x509 \(certificate invalid\)$`},
+		"untrusted, but allow_invalid_certs": {
+			allowInvalidCerts: true,
+			errRegex:          `^$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			kubernetes := KubernetesLookup{
+				Kubeconfig: testKubeconfig(t, server.URL, tc.caData, "token"),
+				Name:       "argus"}
+
+			// WHEN version is called on it
+			_, err := kubernetes.version(context.Background(), tc.allowInvalidCerts)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestKubernetesLookup_Config_InCluster(t *testing.T) {
+	// GIVEN Argus is running in a Pod with a service account
+	server := httptest.NewTLSServer(testKubernetesHandler("sa-token"))
+	t.Cleanup(server.Close)
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+	t.Setenv("KUBERNETES_SERVICE_HOST", host)
+	t.Setenv("KUBERNETES_SERVICE_PORT", port)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "token"), []byte("sa-token"), 0600)
+	os.WriteFile(filepath.Join(dir, "namespace"), []byte("argus\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "ca.crt"), pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw}), 0600)
+	previousDir := kubernetesServiceAccountDir
+	kubernetesServiceAccountDir = dir
+	t.Cleanup(func() { kubernetesServiceAccountDir = previousDir })
+	kubernetes := KubernetesLookup{
+		Name:      "argus",
+		Container: "argus"}
+
+	// WHEN version is called without a kubeconfig
+	version, err := kubernetes.version(context.Background(), false)
+
+	// THEN the service account is used to get the workload in its namespace
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if version != "1.2.3" {
+		t.Errorf("want version %q, got %q",
+			"1.2.3", version)
+	}
+}
+
+func TestLookup_CheckValues_Kubernetes(t *testing.T) {
+	// GIVEN a type:kubernetes Lookup
+	tests := map[string]struct {
+		kubernetes     *KubernetesLookup
+		withKubeconfig bool
+		wantKind       string
+		errRegex       []string
+	}{
+		"valid": {
+			kubernetes: &KubernetesLookup{
+				Name: "argus"},
+			errRegex: []string{`^$`}},
+		"kind is lowercased": {
+			kubernetes: &KubernetesLookup{
+				Kind: "StatefulSet",
+				Name: "argus"},
+			wantKind: "statefulset",
+			errRegex: []string{`^$`}},
+		"no kubernetes": {
+			errRegex: []string{
+				`^  kubernetes: <required>`}},
+		"no name": {
+			kubernetes: &KubernetesLookup{},
+			errRegex: []string{
+				`^    name: <required> \(name of the deployment\)`}},
+		"invalid kind": {
+			kubernetes: &KubernetesLookup{
+				Kind: "Pod",
+				Name: "argus"},
+			wantKind: "Pod",
+			errRegex: []string{
+				`^    kind: "Pod" <invalid> \(only \[deployment, statefulset, daemonset\] are allowed\)`}},
+		"invalid source": {
+			kubernetes: &KubernetesLookup{
+				Name:   "argus",
+				Source: "env"},
+			errRegex: []string{
+				`^    source: "env" <invalid>`}},
+		"label without key": {
+			kubernetes: &KubernetesLookup{
+				Name:   "argus",
+				Source: "label"},
+			errRegex: []string{
+				`^    key: <required> \(label to use`}},
+		"key without label/annotation source": {
+			kubernetes: &KubernetesLookup{
+				Name: "argus",
+				Key:  "app.kubernetes.io/version"},
+			errRegex: []string{
+				`^    key: "app.kubernetes.io/version" <invalid> \(only used with source: label/annotation\)`}},
+		"kubeconfig with token user": {
+			kubernetes: &KubernetesLookup{
+				Name: "argus"},
+			withKubeconfig: true,
+			errRegex:       []string{`^$`}},
+		"kubeconfig with exec user": {
+			kubernetes: &KubernetesLookup{
+				Name:    "argus",
+				Context: "eks"},
+			withKubeconfig: true,
+			errRegex: []string{
+				`^    kubeconfig: ".*" <unsupported> \(user "eks" uses exec, `}},
+		"kubeconfig with auth-provider user": {
+			kubernetes: &KubernetesLookup{
+				Name:    "argus",
+				Context: "gke"},
+			withKubeconfig: true,
+			errRegex: []string{
+				`^    kubeconfig: ".*" <unsupported> \(user "gke" uses auth-provider, `}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = "kubernetes"
+			lookup.URL = ""
+			lookup.Kubernetes = tc.kubernetes
+			if tc.withKubeconfig {
+				lookup.Kubernetes.Kubeconfig = testKubeconfig(t, "https://127.0.0.1:6443", "", "token")
+			}
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND the kind is lowercased when valid
+			if tc.kubernetes != nil && tc.kubernetes.Kind != tc.wantKind {
+				t.Errorf("want kind %q, got %q",
+					tc.wantKind, tc.kubernetes.Kind)
+			}
+		})
+	}
+}
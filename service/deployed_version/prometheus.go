@@ -0,0 +1,212 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	prometheusMetricRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	prometheusLabelRegex  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// PrometheusMetric to get the version from in a Prometheus exposition format body,
+// e.g. the version label of `app_build_info{version="1.2.3"} 1`.
+type PrometheusMetric struct {
+	Metric string            `yaml:"metric,omitempty" json:"metric,omitempty"` // REQUIRED: Name of the metric, e.g. app_build_info.
+	Label  string            `yaml:"label,omitempty" json:"label,omitempty"`   // OPTIONAL: Label to use the value of, e.g. version (default - the sample value).
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"` // OPTIONAL: Labels the sample must have, e.g. {job: app}.
+}
+
+// prometheusSample is a sample line of the Prometheus exposition format.
+type prometheusSample struct {
+	name   string
+	labels map[string]string
+	value  string
+}
+
+// value returns the Label (or value) of the first sample of the Metric (with Labels) in `body`.
+func (p *PrometheusMetric) value(body []byte) (string, error) {
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		// Empty/comment (# HELP/# TYPE)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		sample, err := parsePrometheusSample(line)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse the metrics on line %d: %w",
+				i+1, err)
+		}
+		if sample.name != p.Metric || !p.matches(sample.labels) {
+			continue
+		}
+
+		// Value of the sample.
+		if p.Label == "" {
+			return sample.value, nil
+		}
+		// Value of the label.
+		if value, ok := sample.labels[p.Label]; ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("metric %s has no %q label",
+			p.describe(), p.Label)
+	}
+
+	return "", fmt.Errorf("metric %s not found",
+		p.describe())
+}
+
+// matches returns whether `labels` contains all of the Labels.
+func (p *PrometheusMetric) matches(labels map[string]string) bool {
+	for key, value := range p.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns the Metric with its Labels, e.g. `app_build_info{job="app"}`.
+func (p *PrometheusMetric) describe() string {
+	if len(p.Labels) == 0 {
+		return p.Metric
+	}
+
+	labels := make([]string, 0, len(p.Labels))
+	for _, key := range util.SortedKeys(p.Labels) {
+		labels = append(labels, fmt.Sprintf("%s=%q", key, p.Labels[key]))
+	}
+	return fmt.Sprintf("%s{%s}", p.Metric, strings.Join(labels, ","))
+}
+
+// parsePrometheusSample parses a sample `line`, e.g. `name{label="value",...} 1 1700000000000`.
+func parsePrometheusSample(line string) (sample prometheusSample, err error) {
+	// Name
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd == -1 {
+		return sample, fmt.Errorf("no value for %q", line)
+	}
+	sample.name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	// Labels
+	sample.labels = make(map[string]string)
+	if rest[0] == '{' {
+		rest, err = parsePrometheusLabels(rest[1:], sample.labels)
+		if err != nil {
+			return sample, fmt.Errorf("%s - %w", sample.name, err)
+		}
+	}
+
+	// Value (ignoring any timestamp)
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("no value for %q", sample.name)
+	}
+	sample.value = fields[0]
+	return
+}
+
+// parsePrometheusLabels parses the `label="value",...}` at the start of `str` into `labels`,
+// returning what follows the closing brace.
+func parsePrometheusLabels(str string, labels map[string]string) (string, error) {
+	for {
+		str = strings.TrimLeft(str, " \t")
+		if str == "" {
+			return "", fmt.Errorf("missing '}'")
+		}
+		if str[0] == '}' {
+			return str[1:], nil
+		}
+
+		// Label name
+		equals := strings.IndexByte(str, '=')
+		if equals == -1 {
+			return "", fmt.Errorf("missing '=' in %q", str)
+		}
+		name := strings.TrimSpace(str[:equals])
+		str = strings.TrimLeft(str[equals+1:], " \t")
+		if str == "" || str[0] != '"' {
+			return "", fmt.Errorf("value of label %q isn't quoted", name)
+		}
+
+		// Label value
+		var value strings.Builder
+		i := 1
+		for ; i < len(str) && str[i] != '"'; i++ {
+			if str[i] == '\\' && i+1 < len(str) {
+				i++
+				switch str[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(str[i])
+				}
+				continue
+			}
+			value.WriteByte(str[i])
+		}
+		if i == len(str) {
+			return "", fmt.Errorf("value of label %q isn't terminated", name)
+		}
+		labels[name] = value.String()
+
+		// Separator
+		str = strings.TrimLeft(str[i+1:], " \t")
+		if str != "" && str[0] == ',' {
+			str = str[1:]
+		}
+	}
+}
+
+// CheckValues of the PrometheusMetric.
+func (p *PrometheusMetric) CheckValues(prefix string) (errs error) {
+	if p == nil {
+		return
+	}
+
+	// Metric
+	if p.Metric == "" {
+		errs = fmt.Errorf("%s%smetric: <required> (name of the metric, e.g. 'app_build_info')\\",
+			util.ErrorToString(errs), prefix)
+	} else if !prometheusMetricRegex.MatchString(p.Metric) {
+		errs = fmt.Errorf("%s%smetric: %q <invalid> (not a valid metric name)\\",
+			util.ErrorToString(errs), prefix, p.Metric)
+	}
+
+	// Label
+	if p.Label != "" && !prometheusLabelRegex.MatchString(p.Label) {
+		errs = fmt.Errorf("%s%slabel: %q <invalid> (not a valid label name)\\",
+			util.ErrorToString(errs), prefix, p.Label)
+	}
+
+	// Labels
+	for _, key := range util.SortedKeys(p.Labels) {
+		if !prometheusLabelRegex.MatchString(key) {
+			errs = fmt.Errorf("%s%slabels: %q <invalid> (not a valid label name)\\",
+				util.ErrorToString(errs), prefix, key)
+		}
+	}
+
+	return
+}
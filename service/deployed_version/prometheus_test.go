@@ -0,0 +1,310 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+var testMetrics = `# HELP app_build_info Build information.
+# TYPE app_build_info gauge
+app_build_info{branch="main",job="app",version="1.2.3"} 1
+app_build_info{branch="dev",job="worker",version="1.3.0-rc.1"} 1 1700000000000
+# HELP process_start_time_seconds Start time of the process.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 1.7e+09
+app_escaped_info{description="say \"hi\"\nand\\bye", version="2.0.0"} 1
+`
+
+func TestPrometheusMetric_Value(t *testing.T) {
+	// GIVEN a PrometheusMetric and some metrics
+	tests := map[string]struct {
+		body     string
+		metric   PrometheusMetric
+		want     string
+		errRegex string
+	}{
+		"label of the first sample": {
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			want:     "1.2.3",
+			errRegex: `^$`},
+		"label of the sample with labels": {
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version",
+				Labels: map[string]string{"job": "worker"}},
+			want:     "1.3.0-rc.1",
+			errRegex: `^$`},
+		"value of the sample": {
+			metric: PrometheusMetric{
+				Metric: "process_start_time_seconds"},
+			want:     "1.7e+09",
+			errRegex: `^$`},
+		"escaped label values": {
+			metric: PrometheusMetric{
+				Metric: "app_escaped_info",
+				Label:  "description"},
+			want:     "say \"hi\"\nand\\bye",
+			errRegex: `^$`},
+		"label after escaped label values": {
+			metric: PrometheusMetric{
+				Metric: "app_escaped_info",
+				Label:  "version"},
+			want:     "2.0.0",
+			errRegex: `^$`},
+		"metric not found": {
+			metric: PrometheusMetric{
+				Metric: "app_info",
+				Label:  "version"},
+			errRegex: `^metric app_info not found$`},
+		"no sample with labels": {
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version",
+				Labels: map[string]string{"job": "web", "branch": "main"}},
+			errRegex: `^metric app_build_info\{branch="main",job="web"\} not found$`},
+		"label not found": {
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "commit"},
+			errRegex: `^metric app_build_info has no "commit" label$`},
+		"unterminated label value": {
+			body: `app_build_info{version="1.2.3} 1`,
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			errRegex: `^failed to parse the metrics on line 1: app_build_info - value of label "version" isn't terminated$`},
+		"unquoted label value": {
+			body: "# TYPE app_build_info gauge\napp_build_info{version=1.2.3} 1",
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			errRegex: `^failed to parse the metrics on line 2: app_build_info - value of label "version" isn't quoted$`},
+		"no value": {
+			body: `app_build_info{version="1.2.3"}`,
+			metric: PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			errRegex: `^failed to parse the metrics on line 1: no value for "app_build_info"$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body := testMetrics
+			if tc.body != "" {
+				body = tc.body
+			}
+
+			// WHEN value is called on the metrics
+			got, err := tc.metric.value([]byte(body))
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the value is what we expect
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup_Query_HeaderPrometheus(t *testing.T) {
+	// GIVEN a Lookup of a server that gives the version in a header and its metrics
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+		w.Header().Set("X-Version", "1.2.3")
+		if r.URL.Path == "/metrics" {
+			fmt.Fprint(w, testMetrics)
+			return
+		}
+		fmt.Fprint(w, `{"version": "0.0.1"}`)
+	}))
+	t.Cleanup(server.Close)
+	tests := map[string]struct {
+		path        string
+		header      string
+		prometheus  *PrometheusMetric
+		regex       string
+		wantVersion string
+		errRegex    string
+	}{
+		"header": {
+			header:      "X-Version",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"header is case-insensitive": {
+			header:      "x-version",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"header with regex": {
+			header:      "Server",
+			regex:       `nginx/([0-9.]+)`,
+			wantVersion: "1.25.3",
+			errRegex:    `^$`},
+		"header not found": {
+			header:   "X-App-Version",
+			errRegex: `^header "X-App-Version" not found in the response$`},
+		"prometheus": {
+			path: "/metrics",
+			prometheus: &PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"prometheus metric not found": {
+			path: "/metrics",
+			prometheus: &PrometheusMetric{
+				Metric: "app_info",
+				Label:  "version"},
+			errRegex: `^http://[^ ]+/metrics - metric app_info not found$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.URL = server.URL + tc.path
+			lookup.AllowInvalidCerts = test.BoolPtr(false)
+			lookup.JSON = ""
+			lookup.Header = tc.header
+			lookup.Prometheus = tc.prometheus
+			lookup.Regex = tc.regex
+
+			// WHEN query is called on it
+			version, err := lookup.query(context.Background(), &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValues_HeaderPrometheus(t *testing.T) {
+	// GIVEN a Lookup with a Header/Prometheus metric
+	tests := map[string]struct {
+		lookupType string
+		header     string
+		prometheus *PrometheusMetric
+		json       string
+		errRegex   []string
+	}{
+		"valid header": {
+			header:   "X-Version",
+			errRegex: []string{`^$`}},
+		"header with type command": {
+			lookupType: "command",
+			header:     "X-Version",
+			errRegex: []string{
+				`^  header: "X-Version" <invalid> \(only supported with type: url\)`}},
+		"valid prometheus": {
+			prometheus: &PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version",
+				Labels: map[string]string{"job": "app"}},
+			errRegex: []string{`^$`}},
+		"prometheus with type command": {
+			lookupType: "command",
+			prometheus: &PrometheusMetric{
+				Metric: "app_build_info",
+				Label:  "version"},
+			errRegex: []string{`^  command: <required>`}},
+		"prometheus with json": {
+			prometheus: &PrometheusMetric{
+				Metric: "app_build_info"},
+			json: "version",
+			errRegex: []string{
+				`^  prometheus: <invalid> \(can't be used with json/key\)`}},
+		"prometheus without a metric": {
+			prometheus: &PrometheusMetric{
+				Label: "version"},
+			errRegex: []string{
+				`^  prometheus:$`,
+				`^    metric: <required>`}},
+		"prometheus with invalid names": {
+			prometheus: &PrometheusMetric{
+				Metric: "app-build-info",
+				Label:  "app.version",
+				Labels: map[string]string{"0job": "app"}},
+			errRegex: []string{
+				`^    metric: "app-build-info" <invalid>`,
+				`^    label: "app.version" <invalid>`,
+				`^    labels: "0job" <invalid>`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = tc.lookupType
+			lookup.Header = tc.header
+			lookup.Prometheus = tc.prometheus
+			lookup.JSON = tc.json
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
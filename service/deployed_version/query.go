@@ -15,6 +15,7 @@
 package deployedver
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -24,51 +25,77 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/release-argus/Argus/ratelimit"
+	"github.com/release-argus/Argus/scheduler"
 	"github.com/release-argus/Argus/util"
 	metric "github.com/release-argus/Argus/web/metrics"
 )
 
-// Track the deployed version (DeployedVersion) of the `parent`.
+// Track the deployed version (DeployedVersion) of the `parent` on the scheduler.
 func (l *Lookup) Track() {
-	if l == nil {
+	if l == nil || l.Status.Deleting() {
 		return
 	}
-	logFrom := util.LogFrom{Primary: *l.Status.ServiceID}
 
-	// Track forever.
-	for {
-		// If we're deleting this Service, stop tracking it.
-		if l.Status.Deleting() {
-			return
-		}
+	// Wait for any QuietHours to pass.
+	var delay time.Duration
+	if l.Options != nil {
+		delay = time.Until(l.Options.DeferQuietHours(time.Now()))
+	}
+	scheduler.Add(TrackID(*l.Status.ServiceID), max(delay, 0), l.track)
+}
 
-		// Query the deployed version.
-		deployedVersion, _ := l.Query(true, &logFrom)
-		// If new release found by ^ query.
-		l.HandleNewVersion(deployedVersion, true)
-		// Sleep interval between queries.
-		time.Sleep(l.Options.GetIntervalDuration())
+// TrackID returns the ID of the scheduled job tracking the deployed version of the Service with `serviceID`.
+func TrackID(serviceID string) string {
+	return serviceID + "/deployed_version"
+}
+
+// track queries the deployed version, returning the time to wait until the next query.
+func (l *Lookup) track(ctx context.Context) time.Duration {
+	// If we're deleting this Service, stop tracking it.
+	if l.Status.Deleting() {
+		return 0
 	}
+	logFrom := util.LogFrom{Primary: *l.Status.ServiceID}
+
+	// Query the deployed version.
+	deployedVersion, _ := l.Query(ctx, true, &logFrom)
+	// Stop if the Service was deleted/edited during the query.
+	if ctx.Err() != nil {
+		return 0
+	}
+	// If new release found by ^ query.
+	l.HandleNewVersion(deployedVersion, true)
+
+	return l.nextQuery(ctx, &logFrom)
 }
 
 // query the deployed version (DeployedVersion) of the Service.
-func (l *Lookup) query(logFrom *util.LogFrom) (string, error) {
-	rawBody, err := l.httpRequest(logFrom)
+func (l *Lookup) query(ctx context.Context, logFrom *util.LogFrom) (string, error) {
+	rawBody, err := l.getBody(ctx, logFrom)
 	if err != nil {
 		return "", err
 	}
 
 	var version string
-	// If JSON is provided, use it to extract the version.
-	if l.JSON != "" {
-		version, err = util.GetValueByKey(rawBody, l.JSON, l.GetURL())
+	// If a Prometheus metric is provided, use it to extract the version.
+	if l.Prometheus != nil {
+		version, err = l.Prometheus.value(rawBody)
+		if err != nil {
+			err = fmt.Errorf("%s - %w", l.GetTarget(), err)
+			jLog.Warn(err, logFrom, true)
+			return "", err
+		}
+		// If a key is provided, use it to extract the version.
+	} else if key := l.GetKey(); key != "" {
+		version, err = util.GetValueByFormatKey(rawBody, l.GetFormat(), key, l.GetTarget())
 		if err != nil {
 			jLog.Error(err, logFrom, true)
 			//nolint:wrapcheck
 			return "", err
 		}
 	} else {
-		// Use the whole body if not parsing as JSON.
+		// Use the whole body if not parsing a key.
 		version = string(rawBody)
 	}
 
@@ -106,8 +133,18 @@ func (l *Lookup) query(logFrom *util.LogFrom) (string, error) {
 }
 
 // Query the deployed version (DeployedVersion) of the Service.
-func (l *Lookup) Query(metrics bool, logFrom *util.LogFrom) (version string, err error) {
-	version, err = l.query(logFrom)
+//
+// ctx - cancels the query (e.g. when the Service is deleted/edited)
+func (l *Lookup) Query(ctx context.Context, metrics bool, logFrom *util.LogFrom) (version string, err error) {
+	if len(l.Targets) == 0 {
+		version, err = l.query(ctx, logFrom)
+	} else {
+		var versions map[string]string
+		versions, version, err = l.queryTargets(ctx, logFrom)
+		if metrics {
+			l.handleTargetVersions(versions, logFrom)
+		}
+	}
 
 	if metrics {
 		l.queryMetrics(err == nil)
@@ -180,7 +217,28 @@ func (l *Lookup) HandleNewVersion(version string, writeToDB bool) {
 	l.Status.AnnounceUpdate()
 }
 
-func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBody []byte, err error) {
+// getBody returns the body to extract the version from,
+// the HTTP response (type:url), the Command output (type:command), the file contents (type:file),
+// the container tag/label/digest (type:docker), the workload tag/label/annotation (type:kubernetes),
+// or the remote Command output (type:ssh).
+func (l *Lookup) getBody(ctx context.Context, logFrom *util.LogFrom) ([]byte, error) {
+	switch l.GetType() {
+	case "command":
+		return l.execCommand(ctx, logFrom)
+	case "file":
+		return l.readFile(logFrom)
+	case "docker":
+		return l.dockerVersion(ctx, logFrom)
+	case "kubernetes":
+		return l.kubernetesVersion(ctx, logFrom)
+	case "ssh":
+		return l.sshCommand(ctx, logFrom)
+	default:
+		return l.httpRequest(ctx, logFrom)
+	}
+}
+
+func (l *Lookup) httpRequest(ctx context.Context, logFrom *util.LogFrom) (rawBody []byte, err error) {
 	// HTTPS insecure skip verify.
 	customTransport := &http.Transport{}
 	if l.GetAllowInvalidCerts() {
@@ -190,7 +248,7 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBody []byte, err error)
 	}
 
 	// Create the request.
-	req, err := http.NewRequest(l.Method, l.GetURL(), l.GetBody())
+	req, err := http.NewRequestWithContext(ctx, l.Method, l.GetURL(), l.GetBody())
 	if err != nil {
 		jLog.Error(err, logFrom, true)
 		return
@@ -204,9 +262,16 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBody []byte, err error)
 	if l.BasicAuth != nil {
 		req.SetBasicAuth(util.EvalEnvVars(l.BasicAuth.Username), util.EvalEnvVars(l.BasicAuth.Password))
 	}
+	// Bearer/OAuth2/API key auth
+	if err = l.Auth.Apply(req, l.GetAllowInvalidCerts()); err != nil {
+		jLog.Error(err, logFrom, true)
+		return
+	}
 
 	// Send the request.
-	client := &http.Client{Transport: customTransport}
+	client := &http.Client{
+		Transport: ratelimit.Transport(customTransport, logFrom),
+		Timeout:   util.HTTPTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		// Don't crash on invalid certs.
@@ -218,16 +283,32 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBody []byte, err error)
 		jLog.Error(err, logFrom, true)
 		return
 	}
+	defer resp.Body.Close()
 
 	// Ignore non-2XX responses.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Get a new OAuth2 token for the next query.
+		if resp.StatusCode == http.StatusUnauthorized {
+			l.Auth.Rejected()
+		}
 		err = fmt.Errorf("non-2XX response code: %d", resp.StatusCode)
 		jLog.Warn(err, logFrom, true)
 		return
 	}
 
+	// Use the response header if wanted.
+	if l.Header != "" {
+		values := resp.Header.Values(l.Header)
+		if len(values) == 0 {
+			err = fmt.Errorf("header %q not found in the response", l.Header)
+			jLog.Warn(err, logFrom, true)
+			return
+		}
+		rawBody = []byte(strings.Join(values, ", "))
+		return
+	}
+
 	// Read the response body.
-	defer resp.Body.Close()
 	rawBody, err = io.ReadAll(resp.Body)
 	jLog.Error(err, logFrom, err != nil)
 	return
@@ -17,13 +17,19 @@
 package deployedver
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	dbtype "github.com/release-argus/Argus/db/types"
+	"github.com/release-argus/Argus/service/auth"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/test"
@@ -72,7 +78,7 @@ func TestLookup_HTTPRequest(t *testing.T) {
 			lookup.URL = tc.url
 
 			// WHEN httpRequest is called on it
-			_, err := lookup.httpRequest(&util.LogFrom{})
+			_, err := lookup.httpRequest(context.Background(), &util.LogFrom{})
 
 			// THEN any err is expected
 			e := util.ErrorToString(err)
@@ -226,7 +232,7 @@ func TestLookup_Query(t *testing.T) {
 			*dvl.Options.SemanticVersioning = !tc.noSemanticVersioning
 
 			// WHEN Query is called on it
-			version, err := dvl.Query(true, &util.LogFrom{})
+			version, err := dvl.Query(context.Background(), true, &util.LogFrom{})
 
 			// THEN any err is expected
 			if tc.wantVersion != "" {
@@ -541,3 +547,92 @@ func TestLookup_Track(t *testing.T) {
 		})
 	}
 }
+
+func TestLookup_Query_Auth(t *testing.T) {
+	// GIVEN a Lookup of a server that requires auth
+	var tokenRequests int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&tokenRequests, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, n)
+	}))
+	t.Cleanup(tokenServer.Close)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first OAuth2 token is revoked.
+		if r.Header.Get("Authorization") != "Bearer abc" &&
+			r.Header.Get("Authorization") != "Bearer token-2" &&
+			r.Header.Get("X-API-Key") != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"version": "1.2.3"}`)
+	}))
+	t.Cleanup(server.Close)
+	tests := map[string]struct {
+		auth        *auth.Auth
+		wantVersion []string
+		errRegex    []string
+	}{
+		"no auth": {
+			wantVersion: []string{""},
+			errRegex:    []string{`^non-2XX response code: 401$`}},
+		"bearer": {
+			auth: &auth.Auth{
+				Type:  "bearer",
+				Token: "abc"},
+			wantVersion: []string{"1.2.3"},
+			errRegex:    []string{`^$`}},
+		"bearer with the wrong token": {
+			auth: &auth.Auth{
+				Type:  "bearer",
+				Token: "xyz"},
+			wantVersion: []string{""},
+			errRegex:    []string{`^non-2XX response code: 401$`}},
+		"api_key": {
+			auth: &auth.Auth{
+				Type: "api_key",
+				Key:  "abc"},
+			wantVersion: []string{"1.2.3"},
+			errRegex:    []string{`^$`}},
+		"oauth2 gets a new token after it's rejected": {
+			auth: &auth.Auth{
+				Type:         "oauth2",
+				TokenURL:     tokenServer.URL,
+				ClientID:     "id",
+				ClientSecret: "secret"},
+			wantVersion: []string{"", "1.2.3", "1.2.3"},
+			errRegex: []string{
+				`^non-2XX response code: 401$`,
+				`^$`,
+				`^$`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Shares the token server
+
+			lookup := testLookup()
+			lookup.URL = server.URL
+			lookup.AllowInvalidCerts = test.BoolPtr(false)
+			lookup.JSON = "version"
+			lookup.Auth = tc.auth
+
+			for i := range tc.wantVersion {
+				// WHEN query is called on it
+				version, err := lookup.query(context.Background(), &util.LogFrom{})
+
+				// THEN the err is what we expect
+				e := util.ErrorToString(err)
+				re := regexp.MustCompile(tc.errRegex[i])
+				if !re.MatchString(e) {
+					t.Fatalf("query %d: want match for %q\nnot: %q",
+						i, tc.errRegex[i], e)
+				}
+				// AND the version is what we expect
+				if version != tc.wantVersion[i] {
+					t.Errorf("query %d: want version %q, got %q",
+						i, tc.wantVersion[i], version)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package deployedver
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -28,8 +29,10 @@ func (l *Lookup) applyOverrides(
 	allowInvalidCerts *string,
 	basicAuth *string,
 	body *string,
+	format *string,
 	headers *string,
 	json *string,
+	key *string,
 	method *string,
 	regex *string,
 	regexTemplate *string,
@@ -51,6 +54,8 @@ func (l *Lookup) applyOverrides(
 		logFrom)
 	// body
 	useBody := util.FirstNonNilPtr(body, l.Body)
+	// format
+	useFormat := util.PtrValueOrValue(format, l.Format)
 	// headers
 	useHeaders := headersFromString(
 		headers,
@@ -58,6 +63,8 @@ func (l *Lookup) applyOverrides(
 		logFrom)
 	// json
 	useJSON := util.PtrValueOrValue(json, l.JSON)
+	// key
+	useKey := util.PtrValueOrValue(key, l.Key)
 	// method
 	useMethod := util.PtrValueOrValue(method, l.Method)
 	// regex
@@ -93,6 +100,21 @@ func (l *Lookup) applyOverrides(
 		useURL,
 		l.Defaults,
 		l.HardDefaults)
+	// type:command/file/docker/kubernetes/ssh, auth, header and prometheus aren't overridable (so commands can't be run, or files read, from query params).
+	lookup.Type = l.Type
+	lookup.Command = l.Command
+	lookup.Timeout = l.Timeout
+	lookup.Path = l.Path
+	lookup.Format = useFormat
+	lookup.Key = useKey
+	lookup.Docker = l.Docker
+	lookup.Kubernetes = l.Kubernetes
+	lookup.SSH = l.SSH
+	lookup.Auth = l.Auth
+	lookup.Header = l.Header
+	lookup.Prometheus = l.Prometheus
+	lookup.Targets = l.Targets
+	lookup.Policy = l.Policy
 	if err := lookup.CheckValues(""); err != nil {
 		jLog.Error(err, logFrom, true)
 		return nil, fmt.Errorf("values failed validity check:\n%w", err)
@@ -110,8 +132,10 @@ func (l *Lookup) Refresh(
 	allowInvalidCerts *string,
 	basicAuth *string,
 	body *string,
+	format *string,
 	headers *string,
 	json *string,
+	key *string,
 	method *string,
 	regex *string,
 	regexTemplate *string,
@@ -126,8 +150,10 @@ func (l *Lookup) Refresh(
 		allowInvalidCerts,
 		basicAuth,
 		body,
+		format,
 		headers,
 		json,
+		key,
 		method,
 		regex,
 		regexTemplate,
@@ -150,12 +176,18 @@ func (l *Lookup) Refresh(
 	overrides := headers != nil ||
 		l.Options.GetSemanticVersioning() != lookup.Options.GetSemanticVersioning() ||
 		url != nil ||
+		format != nil ||
 		json != nil ||
+		key != nil ||
 		regex != nil ||
 		regexTemplate != nil
 
 	// Query the lookup.
-	version, err = lookup.Query(!overrides, logFrom)
+	version, err = lookup.Query(context.Background(), !overrides, logFrom)
+	// Store the version of each target.
+	if len(lookup.Targets) != 0 && !overrides {
+		l.handleTargetVersions(lookup.Status.DeployedVersions(), logFrom)
+	}
 	if err != nil {
 		return
 	}
@@ -17,6 +17,7 @@
 package deployedver
 
 import (
+	"context"
 	"reflect"
 	"regexp"
 	"testing"
@@ -153,8 +154,10 @@ func TestLookup_ApplyOverrides(t *testing.T) {
 		allowInvalidCerts  *string
 		basicAuth          *string
 		body               *string
+		format             *string
 		headers            *string
 		json               *string
+		key                *string
 		method             *string
 		regex              *string
 		regexTemplate      *string
@@ -268,6 +271,34 @@ func TestLookup_ApplyOverrides(t *testing.T) {
 				testL.URL,
 				nil, nil),
 		},
+		"format and key": {
+			format: test.StringPtr("xml"),
+			key:    test.StringPtr("status.version"),
+
+			previous: testLookup(),
+			want: func() *Lookup {
+				lookup := New(
+					testL.AllowInvalidCerts,
+					nil, nil, nil,
+					testL.JSON,
+					testL.Method,
+					testL.Options,
+					"", nil,
+					&svcstatus.Status{},
+					testL.URL,
+					nil, nil)
+				lookup.Format = "xml"         // Format
+				lookup.Key = "status.version" // Key
+				return lookup
+			}(),
+		},
+		"override with invalid format": {
+			format: test.StringPtr("csv"),
+
+			previous: testLookup(),
+			want:     nil,
+			errRegex: `format: "csv" <invalid>`,
+		},
 		"method": {
 			method: test.StringPtr("POST"),
 
@@ -377,8 +408,10 @@ func TestLookup_ApplyOverrides(t *testing.T) {
 				tc.allowInvalidCerts,
 				tc.basicAuth,
 				tc.body,
+				tc.format,
 				tc.headers,
 				tc.json,
+				tc.key,
 				tc.method,
 				tc.regex,
 				tc.regexTemplate,
@@ -413,7 +446,7 @@ func TestLookup_ApplyOverrides(t *testing.T) {
 
 func TestLookup_Refresh(t *testing.T) {
 	testL := testLookup()
-	testVersion, _ := testL.Query(true, &util.LogFrom{Primary: "TestRefresh"})
+	testVersion, _ := testL.Query(context.Background(), true, &util.LogFrom{Primary: "TestRefresh"})
 	if testVersion == "" {
 		t.Fatalf("test version is empty")
 	}
@@ -423,8 +456,10 @@ func TestLookup_Refresh(t *testing.T) {
 		allowInvalidCerts        *string
 		basicAuth                *string
 		body                     *string
+		format                   *string
 		headers                  *string
 		json                     *string
+		key                      *string
 		method                   *string
 		regex                    *string
 		regexTemplate            *string
@@ -503,8 +538,10 @@ func TestLookup_Refresh(t *testing.T) {
 				tc.allowInvalidCerts,
 				tc.basicAuth,
 				tc.body,
+				tc.format,
 				tc.headers,
 				tc.json,
+				tc.key,
 				tc.method,
 				tc.regex,
 				tc.regexTemplate,
@@ -0,0 +1,139 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/release-argus/Argus/scheduler"
+	"github.com/release-argus/Argus/util"
+)
+
+// watch of the Lookup, querying at the WatchInterval after actions have run.
+type watch struct {
+	mutex     sync.Mutex
+	version   string             // Version being deployed.
+	until     time.Time          // Time to stop watching.
+	stopFiles context.CancelFunc // Stop the current watch of the file (type:file).
+}
+
+// newWatch returns a new watch.
+func newWatch() *watch {
+	return &watch{}
+}
+
+// Watch the Lookup, querying at the WatchInterval until `version` is deployed,
+// or the WatchTimeout passes.
+func (l *Lookup) Watch(version string) {
+	if l == nil || l.watch == nil ||
+		version == "" || version == l.Status.DeployedVersion() {
+		return
+	}
+
+	l.watch.mutex.Lock()
+	l.watch.version = version
+	l.watch.until = time.Now().Add(l.GetWatchTimeoutDuration())
+	l.watch.mutex.Unlock()
+	jLog.Verbose(
+		fmt.Sprintf("Watching for %q to be deployed (every %s for up to %s)",
+			version, l.GetWatchInterval(), l.GetWatchTimeout()),
+		&util.LogFrom{Primary: *l.Status.ServiceID}, true)
+
+	// Query now.
+	scheduler.Wake(TrackID(*l.Status.ServiceID))
+}
+
+// watching returns whether the Lookup is being watched,
+// stopping the watch once the version is deployed, or the WatchTimeout has passed.
+func (l *Lookup) watching(logFrom *util.LogFrom) bool {
+	if l.watch == nil {
+		return false
+	}
+	l.watch.mutex.Lock()
+	defer l.watch.mutex.Unlock()
+	if l.watch.version == "" {
+		return false
+	}
+
+	switch {
+	case l.Status.DeployedVersion() == l.watch.version:
+		jLog.Verbose(
+			fmt.Sprintf("Stopped watching, %q was deployed", l.watch.version),
+			logFrom, true)
+	case time.Now().After(l.watch.until):
+		jLog.Warn(
+			fmt.Sprintf("Stopped watching, %q wasn't deployed within %s", l.watch.version, l.GetWatchTimeout()),
+			logFrom, true)
+	default:
+		return true
+	}
+	l.watch.version = ""
+	return false
+}
+
+// nextQuery returns the time to wait until the next query is due,
+// the Interval (WatchInterval when watching) deferred until the end of any QuietHours,
+// or (type:file) as soon as the file changes.
+func (l *Lookup) nextQuery(ctx context.Context, logFrom *util.LogFrom) time.Duration {
+	interval := l.GetIntervalDuration()
+	if l.watching(logFrom) {
+		interval = l.GetWatchIntervalDuration()
+	}
+	if l.Options != nil {
+		now := time.Now()
+		next := now.Add(interval)
+		if deferred := l.Options.DeferQuietHours(next); !deferred.Equal(next) {
+			interval = deferred.Sub(now)
+		}
+	}
+	if l.GetType() == "file" {
+		// The interval is kept as a limit on the wait in case a change is missed.
+		l.watchFile(ctx, interval, logFrom)
+	}
+
+	return interval
+}
+
+// watchFile wakes the job tracking the Lookup if the file changes within `timeout`.
+func (l *Lookup) watchFile(ctx context.Context, timeout time.Duration, logFrom *util.LogFrom) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	// Only watch for the current wait.
+	if l.watch != nil {
+		l.watch.mutex.Lock()
+		if l.watch.stopFiles != nil {
+			l.watch.stopFiles()
+		}
+		l.watch.stopFiles = cancel
+		l.watch.mutex.Unlock()
+	}
+
+	path := l.GetPath()
+	go func() {
+		defer cancel()
+		err := waitForFileChange(ctx, path)
+		switch {
+		case err == nil:
+			scheduler.Wake(TrackID(*l.Status.ServiceID))
+		case ctx.Err() == nil:
+			jLog.Verbose(
+				fmt.Sprintf("Watching %q failed, falling back to polling every %s - %s",
+					path, timeout, err),
+				logFrom, true)
+		}
+	}()
+}
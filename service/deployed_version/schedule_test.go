@@ -0,0 +1,287 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	command "github.com/release-argus/Argus/commands"
+	"github.com/release-argus/Argus/scheduler"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestLookup_Watch(t *testing.T) {
+	// GIVEN a Lookup that may be watched
+	tests := map[string]struct {
+		deployedVersion string
+		watchVersion    string
+		watchTimeout    string
+		deploy          string
+		wantWatching    bool
+	}{
+		"watches until the version is deployed": {
+			deployedVersion: "1.2.3",
+			watchVersion:    "1.2.4",
+			wantWatching:    true},
+		"stops watching once the version is deployed": {
+			deployedVersion: "1.2.3",
+			watchVersion:    "1.2.4",
+			deploy:          "1.2.4",
+			wantWatching:    false},
+		"keeps watching if a different version is deployed": {
+			deployedVersion: "1.2.3",
+			watchVersion:    "1.2.5",
+			deploy:          "1.2.4",
+			wantWatching:    true},
+		"stops watching after the timeout": {
+			deployedVersion: "1.2.3",
+			watchVersion:    "1.2.4",
+			watchTimeout:    "1ns",
+			wantWatching:    false},
+		"doesn't watch for the deployed version": {
+			deployedVersion: "1.2.3",
+			watchVersion:    "1.2.3",
+			wantWatching:    false},
+		"doesn't watch without a version": {
+			deployedVersion: "1.2.3",
+			wantWatching:    false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.watch = newWatch()
+			lookup.HardDefaults.WatchTimeout = "1h"
+			lookup.WatchTimeout = tc.watchTimeout
+			lookup.Status.SetDeployedVersion(tc.deployedVersion, false)
+
+			// WHEN Watch is called with a version
+			lookup.Watch(tc.watchVersion)
+			if tc.deploy != "" {
+				lookup.Status.SetDeployedVersion(tc.deploy, false)
+			}
+			time.Sleep(time.Millisecond)
+
+			// THEN the Lookup is watched until that version is deployed, or the timeout passes
+			if got := lookup.watching(&util.LogFrom{}); got != tc.wantWatching {
+				t.Errorf("want watching=%t, got %t",
+					tc.wantWatching, got)
+			}
+		})
+	}
+}
+
+func TestLookup_NextQuery(t *testing.T) {
+	// GIVEN a Lookup with a long Interval and a short WatchInterval
+	lookup := testLookup()
+	lookup.watch = newWatch()
+	lookup.Interval = "1h"
+	lookup.HardDefaults.WatchInterval = "10ms"
+	lookup.HardDefaults.WatchTimeout = "1h"
+	lookup.Status.SetDeployedVersion("1.2.3", false)
+
+	// WHEN nextQuery is called on it
+	got := lookup.nextQuery(context.Background(), &util.LogFrom{})
+
+	// THEN the next query is after the Interval
+	if got != time.Hour {
+		t.Errorf("want next query in %s, got %s",
+			time.Hour, got)
+	}
+
+	// WHEN it's being watched
+	lookup.Watch("1.2.4")
+	got = lookup.nextQuery(context.Background(), &util.LogFrom{})
+
+	// THEN the next query is after the WatchInterval
+	if got != 10*time.Millisecond {
+		t.Errorf("want next query in %s, got %s",
+			10*time.Millisecond, got)
+	}
+}
+
+func TestLookup_Track_Wake(t *testing.T) {
+	// GIVEN a tracked Lookup with a long Interval
+	tests := map[string]struct {
+		lookupType string
+		wake       func(lookup *Lookup)
+	}{
+		"woken by Watch": {
+			lookupType: "command",
+			wake: func(lookup *Lookup) {
+				lookup.Watch("1.2.4")
+			}},
+		"woken by the file changing (type:file)": {
+			lookupType: "file"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "VERSION")
+			os.WriteFile(path, []byte("1.2.3"), 0600)
+			lookup := testLookup()
+			lookup.Status.ServiceID = test.StringPtr("TestLookup_Track_Wake - " + name)
+			lookup.watch = newWatch()
+			lookup.Type = tc.lookupType
+			if tc.lookupType == "file" {
+				lookup.Path = path
+			} else {
+				lookup.Command = command.Command{"cat", path}
+			}
+			lookup.JSON = ""
+			lookup.Interval = "1h"
+			lookup.HardDefaults.WatchInterval = "1h"
+			lookup.HardDefaults.WatchTimeout = "1h"
+			lookup.Track()
+			t.Cleanup(func() { scheduler.Remove(TrackID(*lookup.Status.ServiceID)) })
+			waitForDeployedVersion(t, lookup, "1.2.3")
+
+			// WHEN the version changes and it's woken
+			os.WriteFile(path, []byte("1.2.4"), 0600)
+			if tc.wake != nil {
+				// Not queried until woken.
+				time.Sleep(200 * time.Millisecond)
+				if got := lookup.Status.DeployedVersion(); got != "1.2.3" {
+					t.Fatalf("want no query before being woken, got DeployedVersion %q", got)
+				}
+				tc.wake(lookup)
+			}
+
+			// THEN it's queried straight away
+			waitForDeployedVersion(t, lookup, "1.2.4")
+		})
+	}
+}
+
+// waitForDeployedVersion waits for the Lookup to find the `version`.
+func waitForDeployedVersion(t *testing.T, lookup *Lookup, version string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if lookup.Status.DeployedVersion() == version {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("want DeployedVersion %q, got %q",
+		version, lookup.Status.DeployedVersion())
+}
+
+func TestLookup_CheckValues_Intervals(t *testing.T) {
+	// GIVEN a Lookup with intervals
+	tests := map[string]struct {
+		interval, watchInterval, watchTimeout             string
+		wantInterval, wantWatchInterval, wantWatchTimeout string
+		errRegex                                          []string
+	}{
+		"valid": {
+			interval:          "1m",
+			watchInterval:     "10s",
+			watchTimeout:      "5m",
+			wantInterval:      "1m",
+			wantWatchInterval: "10s",
+			wantWatchTimeout:  "5m",
+			errRegex:          []string{`^$`}},
+		"integers are seconds": {
+			interval:          "60",
+			watchInterval:     "10",
+			watchTimeout:      "300",
+			wantInterval:      "60s",
+			wantWatchInterval: "10s",
+			wantWatchTimeout:  "300s",
+			errRegex:          []string{`^$`}},
+		"invalid": {
+			interval:          "1x",
+			watchInterval:     "0s",
+			watchTimeout:      "-5m",
+			wantInterval:      "1x",
+			wantWatchInterval: "0s",
+			wantWatchTimeout:  "-5m",
+			errRegex: []string{
+				`^  interval: "1x" <invalid>`,
+				`^  watch_interval: "0s" <invalid>`,
+				`^  watch_timeout: "-5m" <invalid>`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Interval = tc.interval
+			lookup.WatchInterval = tc.watchInterval
+			lookup.WatchTimeout = tc.watchTimeout
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND integers are converted to seconds
+			if lookup.Interval != tc.wantInterval ||
+				lookup.WatchInterval != tc.wantWatchInterval ||
+				lookup.WatchTimeout != tc.wantWatchTimeout {
+				t.Errorf("want intervals %q/%q/%q, got %q/%q/%q",
+					tc.wantInterval, tc.wantWatchInterval, tc.wantWatchTimeout,
+					lookup.Interval, lookup.WatchInterval, lookup.WatchTimeout)
+			}
+		})
+	}
+}
+
+func TestLookupDefaults_CheckValues(t *testing.T) {
+	// GIVEN LookupDefaults with an invalid watch_timeout
+	defaults := LookupDefaults{
+		LookupBase: LookupBase{
+			WatchTimeout: "ten minutes"}}
+
+	// WHEN CheckValues is called on it
+	err := defaults.CheckValues("")
+
+	// THEN the error is under deployed_version
+	want := `^deployed_version:\\  watch_timeout: "ten minutes" <invalid> \(Use 'AhBmCs' duration format\)\\$`
+	e := util.ErrorToString(err)
+	if !regexp.MustCompile(want).MatchString(e) {
+		t.Errorf("want match for %q\nnot: %q",
+			want, e)
+	}
+}
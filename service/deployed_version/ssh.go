@@ -0,0 +1,60 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/release-argus/Argus/ssh"
+	"github.com/release-argus/Argus/util"
+)
+
+// sshCommand runs the Command on the host of the SSH profile, returning its stdout
+// (or stderr if nothing was written to stdout, e.g. `nginx -v`).
+func (l *Lookup) sshCommand(ctx context.Context, logFrom *util.LogFrom) ([]byte, error) {
+	cmd := l.Command.ApplyTemplate(l.Status)
+	timeout := l.GetTimeoutDuration()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout, stderr, err := ssh.Run(ctx, l.SSH, cmd)
+
+	// Timed out
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("command %q on %q timed out after %s",
+			cmd.String(), l.SSH, timeout)
+		jLog.Warn(err, logFrom, true)
+		return nil, err
+	}
+	// Failed
+	if err != nil {
+		if output := bytes.TrimSpace(stderr); len(output) != 0 {
+			err = fmt.Errorf("%w - %s", err, output)
+		}
+		err = fmt.Errorf("command %q on %q failed: %w",
+			cmd.String(), l.SSH, err)
+		jLog.Warn(err, logFrom, true)
+		return nil, err
+	}
+
+	output := bytes.TrimSpace(stdout)
+	if len(output) == 0 {
+		output = bytes.TrimSpace(stderr)
+	}
+	return output, nil
+}
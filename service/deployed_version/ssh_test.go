@@ -0,0 +1,155 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	command "github.com/release-argus/Argus/commands"
+	"github.com/release-argus/Argus/ssh"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestLookup_Query_SSH(t *testing.T) {
+	// GIVEN a type:ssh Lookup whose host can't be reached
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	os.WriteFile(knownHosts, []byte{}, 0600)
+	ssh.SetProfiles(ssh.Profiles{
+		"unreachable": {
+			Host:       "127.0.0.1:1",
+			User:       "argus",
+			Agent:      true,
+			KnownHosts: knownHosts}})
+	t.Cleanup(func() { ssh.SetProfiles(nil) })
+	tests := map[string]struct {
+		ssh      string
+		errRegex string
+	}{
+		"unknown profile": {
+			ssh:      "unknown",
+			errRegex: `^command "cat /opt/app/VERSION" on "unknown" failed: ssh profile "unknown" not found$`},
+		"unreachable host": {
+			ssh:      "unreachable",
+			errRegex: `^command "cat /opt/app/VERSION" on "unreachable" failed: ssh argus@127.0.0.1:1: `},
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/argus-test-agent.sock")
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lookup := testLookup()
+			lookup.Type = "ssh"
+			lookup.SSH = tc.ssh
+			lookup.Command = command.Command{"cat", "/opt/app/VERSION"}
+
+			// WHEN query is called on it
+			version, err := lookup.query(context.Background(), &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND no version is found
+			if version != "" {
+				t.Errorf("want no version, got %q",
+					version)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValues_SSH(t *testing.T) {
+	// GIVEN a Lookup using an SSH profile
+	ssh.SetProfiles(ssh.Profiles{
+		"legacy": {
+			Host:  "legacy.example.com",
+			User:  "argus",
+			Agent: true}})
+	t.Cleanup(func() { ssh.SetProfiles(nil) })
+	tests := map[string]struct {
+		lookupType string
+		ssh        string
+		command    command.Command
+		errRegex   []string
+	}{
+		"valid": {
+			lookupType: "ssh",
+			ssh:        "legacy",
+			command:    command.Command{"cat", "/opt/app/VERSION"},
+			errRegex:   []string{`^$`}},
+		"no ssh profile": {
+			lookupType: "ssh",
+			command:    command.Command{"cat", "/opt/app/VERSION"},
+			errRegex: []string{
+				`^  ssh: <required>`}},
+		"unknown ssh profile": {
+			lookupType: "ssh",
+			ssh:        "unknown",
+			command:    command.Command{"cat", "/opt/app/VERSION"},
+			errRegex: []string{
+				`^  ssh: "unknown" <invalid> \(only \[legacy\] are defined\)$`}},
+		"no command": {
+			lookupType: "ssh",
+			ssh:        "legacy",
+			errRegex: []string{
+				`^  command: <required>`}},
+		"ssh with type url": {
+			lookupType: "url",
+			ssh:        "legacy",
+			errRegex: []string{
+				`^  ssh: "legacy" <invalid> \(only used with type: ssh\)$`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Uses the global SSH profiles
+
+			lookup := testLookup()
+			lookup.Type = tc.lookupType
+			lookup.SSH = tc.ssh
+			lookup.Command = tc.command
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployedver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/release-argus/Argus/util"
+)
+
+// targetRegex matches the `{{ target }}` placeholder in the URL.
+var targetRegex = regexp.MustCompile(`\{\{\s*target\s*\}\}`)
+
+// GetPolicy returns how the version of the Targets is decided (defaults to min).
+func (l *Lookup) GetPolicy() string {
+	if l.Policy == "" {
+		return "min"
+	}
+	return l.Policy
+}
+
+// targetURL returns the URL to query for `target`,
+// the URL with `{{ target }}` replaced, or the target itself if it's a URL.
+func (l *Lookup) targetURL(target string) string {
+	if targetRegex.MatchString(l.URL) {
+		return targetRegex.ReplaceAllLiteralString(l.URL, target)
+	}
+	return target
+}
+
+// queryTargets queries each of the Targets concurrently, returning the version of each
+// (leaving out those that failed), and the version decided by the Policy.
+func (l *Lookup) queryTargets(ctx context.Context, logFrom *util.LogFrom) (versions map[string]string, version string, err error) {
+	versions = make(map[string]string, len(l.Targets))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, target := range l.Targets {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+
+			targetLookup := *l
+			targetLookup.URL = l.targetURL(target)
+			targetLookup.Targets = nil
+			targetVersion, err := targetLookup.query(ctx,
+				&util.LogFrom{Primary: logFrom.Primary, Secondary: target})
+			if err != nil {
+				return
+			}
+
+			mutex.Lock()
+			versions[target] = targetVersion
+			mutex.Unlock()
+		}(target)
+	}
+	wg.Wait()
+
+	version, err = l.policyVersion(versions)
+	if err != nil {
+		jLog.Warn(err, logFrom, true)
+	}
+	return
+}
+
+// policyVersion returns the version of the targets decided by the Policy.
+//
+// min - lowest version (default).
+// max - highest version.
+// majority - version on more than half of the Targets.
+// all_equal - version of all the Targets, only when they all have the same version.
+func (l *Lookup) policyVersion(versions map[string]string) (string, error) {
+	if len(versions) == 0 {
+		return "", fmt.Errorf("none of the %d targets returned a version",
+			len(l.Targets))
+	}
+
+	// Count the targets on each version.
+	counts := make(map[string]int, len(versions))
+	for _, version := range versions {
+		counts[version]++
+	}
+	unique := util.SortedKeys(counts)
+	sort.SliceStable(unique, func(i, j int) bool {
+		return l.versionLess(unique[i], unique[j])
+	})
+
+	switch l.GetPolicy() {
+	case "max":
+		return unique[len(unique)-1], nil
+	case "majority":
+		for version, count := range counts {
+			if count*2 > len(l.Targets) {
+				return version, nil
+			}
+		}
+		return "", fmt.Errorf("no version is on a majority of the %d targets: %s",
+			len(l.Targets), describeTargetVersions(versions))
+	case "all_equal":
+		if len(versions) != len(l.Targets) {
+			return "", fmt.Errorf("only %d of the %d targets returned a version",
+				len(versions), len(l.Targets))
+		}
+		if len(unique) != 1 {
+			return "", fmt.Errorf("targets have different versions: %s",
+				describeTargetVersions(versions))
+		}
+		return unique[0], nil
+	default:
+		return unique[0], nil
+	}
+}
+
+// versionLess returns whether version `a` is before `b`,
+// comparing them semantically if they are semantic versions.
+func (l *Lookup) versionLess(a, b string) bool {
+	if l.Options.GetSemanticVersioning() {
+		aSV, errA := semver.NewVersion(a)
+		bSV, errB := semver.NewVersion(b)
+		if errA == nil && errB == nil {
+			return aSV.LessThan(bSV)
+		}
+	}
+	return a < b
+}
+
+// describeTargetVersions returns the targets on each version,
+// e.g. `1.2.3 [app-1, app-2], 1.2.4 [app-3]`.
+func describeTargetVersions(versions map[string]string) string {
+	targets := make(map[string][]string, len(versions))
+	for _, target := range util.SortedKeys(versions) {
+		targets[versions[target]] = append(targets[versions[target]], target)
+	}
+
+	descriptions := make([]string, 0, len(targets))
+	for _, version := range util.SortedKeys(targets) {
+		descriptions = append(descriptions,
+			fmt.Sprintf("%s [%s]", version, strings.Join(targets[version], ", ")))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// handleTargetVersions stores the version of each target in the Status (announcing any change),
+// and warns/notifies (NotifyDrift) when the targets start to have different versions.
+func (l *Lookup) handleTargetVersions(versions map[string]string, logFrom *util.LogFrom) {
+	hadDrift := l.Status.DeployedVersionDrift()
+	if !l.Status.SetDeployedVersions(versions) {
+		return
+	}
+	l.Status.AnnounceDeployedVersions()
+
+	if hadDrift || !l.Status.DeployedVersionDrift() {
+		return
+	}
+	msg := fmt.Sprintf("Deployed versions differ across the targets: %s",
+		describeTargetVersions(versions))
+	jLog.Warn(msg, logFrom, true)
+
+	// Notify
+	if l.NotifyDrift {
+		serviceInfo := &util.ServiceInfo{
+			ID:            *l.Status.ServiceID,
+			WebURL:        l.Status.GetWebURL(),
+			LatestVersion: l.Status.LatestVersion()}
+		go l.Notify.Send("Deployed version drift", msg, serviceInfo, false)
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package deployedver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+// testTargets returns a server for each of `versions`, that give that version as {"version": "X"}
+// (or a 500 if the version is "").
+func testTargets(t *testing.T, versions ...string) (targets []string) {
+	for _, version := range versions {
+		version := version
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if version == "" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, `{"version": %q}`, version)
+		}))
+		t.Cleanup(server.Close)
+		targets = append(targets, server.URL)
+	}
+	return
+}
+
+func TestLookup_Query_Targets(t *testing.T) {
+	// GIVEN a Lookup with Targets
+	tests := map[string]struct {
+		versions    []string
+		policy      string
+		wantVersion string
+		wantDrift   bool
+		errRegex    string
+	}{
+		"min (default)": {
+			versions:    []string{"1.2.10", "1.2.9", "1.2.10"},
+			wantVersion: "1.2.9",
+			wantDrift:   true,
+			errRegex:    `^$`},
+		"max": {
+			versions:    []string{"1.2.10", "1.2.9", "1.2.10"},
+			policy:      "max",
+			wantVersion: "1.2.10",
+			wantDrift:   true,
+			errRegex:    `^$`},
+		"majority": {
+			versions:    []string{"1.2.10", "1.2.9", "1.2.10"},
+			policy:      "majority",
+			wantVersion: "1.2.10",
+			wantDrift:   true,
+			errRegex:    `^$`},
+		"no majority": {
+			versions:  []string{"1.2.10", "1.2.9", ""},
+			policy:    "majority",
+			wantDrift: true,
+			errRegex:  `^no version is on a majority of the 3 targets: 1.2.10 \[.*\], 1.2.9 \[.*\]$`},
+		"all_equal": {
+			versions:    []string{"1.2.3", "1.2.3"},
+			policy:      "all_equal",
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"all_equal with drift": {
+			versions:  []string{"1.2.3", "1.2.4"},
+			policy:    "all_equal",
+			wantDrift: true,
+			errRegex:  `^targets have different versions: 1.2.3 \[.*\], 1.2.4 \[.*\]$`},
+		"all_equal with a failed target": {
+			versions: []string{"1.2.3", ""},
+			policy:   "all_equal",
+			errRegex: `^only 1 of the 2 targets returned a version$`},
+		"min ignores failed targets": {
+			versions:    []string{"", "1.2.3"},
+			wantVersion: "1.2.3",
+			errRegex:    `^$`},
+		"all targets fail": {
+			versions: []string{"", ""},
+			errRegex: `^none of the 2 targets returned a version$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Cannot run in parallel since we're using the same metrics
+
+			lookup := testLookup()
+			lookup.URL = ""
+			lookup.AllowInvalidCerts = test.BoolPtr(false)
+			lookup.Targets = testTargets(t, tc.versions...)
+			lookup.Policy = tc.policy
+			lookup.Status.ServiceID = &name
+			announceChannel := make(chan []byte, 4)
+			lookup.Status.AnnounceChannel = &announceChannel
+			lookup.InitMetrics()
+			t.Cleanup(func() { lookup.DeleteMetrics() })
+
+			// WHEN Query is called on it
+			version, err := lookup.Query(context.Background(), true, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the version is what we expect
+			if version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, version)
+			}
+			// AND the version of each target is stored
+			gotVersions := lookup.Status.DeployedVersions()
+			for i, target := range lookup.Targets {
+				if gotVersions[target] != tc.versions[i] {
+					t.Errorf("want %q to be on %q, got %q",
+						target, tc.versions[i], gotVersions[target])
+				}
+			}
+			// AND the versions were announced (if any were found)
+			wantAnnounces := 0
+			if len(gotVersions) != 0 {
+				wantAnnounces = 1
+			}
+			if len(announceChannel) != wantAnnounces {
+				t.Errorf("want %d announces, got %d",
+					wantAnnounces, len(announceChannel))
+			}
+			// AND the drift is what we expect
+			if got := lookup.Status.DeployedVersionDrift(); got != tc.wantDrift {
+				t.Errorf("want drift %t, got %t",
+					tc.wantDrift, got)
+			}
+			wantDriftMetric := float64(0)
+			if tc.wantDrift {
+				wantDriftMetric = 1
+			}
+			gotDriftMetric := testutil.ToFloat64(metric.DeployedVersionDrift.WithLabelValues(name))
+			if gotDriftMetric != wantDriftMetric {
+				t.Errorf("want drift metric %f, got %f",
+					wantDriftMetric, gotDriftMetric)
+			}
+		})
+	}
+}
+
+func TestLookup_PolicyVersion(t *testing.T) {
+	// GIVEN the versions of some targets
+	versions := map[string]string{
+		"a": "v1.10.0",
+		"b": "v1.9.0",
+		"c": "v1.10.0"}
+	tests := map[string]struct {
+		semanticVersioning bool
+		policy             string
+		want               string
+	}{
+		"min, semantic": {
+			semanticVersioning: true,
+			policy:             "min",
+			want:               "v1.9.0"},
+		"max, semantic": {
+			semanticVersioning: true,
+			policy:             "max",
+			want:               "v1.10.0"},
+		"min, not semantic": {
+			policy: "min",
+			want:   "v1.10.0"},
+		"max, not semantic": {
+			policy: "max",
+			want:   "v1.9.0"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Targets = []string{"a", "b", "c"}
+			lookup.Policy = tc.policy
+			lookup.Options.SemanticVersioning = &tc.semanticVersioning
+
+			// WHEN policyVersion is called on it
+			got, err := lookup.policyVersion(versions)
+
+			// THEN the version is what we expect
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup_TargetURL(t *testing.T) {
+	// GIVEN a Lookup URL and a target
+	tests := map[string]struct {
+		url    string
+		target string
+		want   string
+	}{
+		"templated hostname": {
+			url:    "https://{{ target }}/api/version",
+			target: "app-1.example.com",
+			want:   "https://app-1.example.com/api/version"},
+		"templated without spaces": {
+			url:    "http://{{target}}:8080/version",
+			target: "10.0.0.1",
+			want:   "http://10.0.0.1:8080/version"},
+		"target URL": {
+			url:    "",
+			target: "https://app-1.example.com/version",
+			want:   "https://app-1.example.com/version"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.URL = tc.url
+
+			// WHEN targetURL is called on it
+			got := lookup.targetURL(tc.target)
+
+			// THEN the URL is what we expect
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDescribeTargetVersions(t *testing.T) {
+	// GIVEN the versions of some targets
+	versions := map[string]string{
+		"c": "1.2.4",
+		"b": "1.2.3",
+		"a": "1.2.3"}
+
+	// WHEN describeTargetVersions is called on them
+	got := describeTargetVersions(versions)
+
+	// THEN the targets are grouped by version
+	want := "1.2.3 [a, b], 1.2.4 [c]"
+	if got != want {
+		t.Errorf("want %q, got %q",
+			want, got)
+	}
+}
+
+func TestLookup_HandleTargetVersions(t *testing.T) {
+	// GIVEN a Lookup with Targets
+	lookup := testLookup()
+	lookup.Targets = []string{"a", "b"}
+	announceChannel := make(chan []byte, 4)
+	lookup.Status.AnnounceChannel = &announceChannel
+	steps := []struct {
+		versions      map[string]string
+		wantAnnounces int
+		wantDrift     bool
+	}{
+		{ // First versions
+			versions:      map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			wantAnnounces: 1},
+		{ // Unchanged
+			versions:      map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			wantAnnounces: 1},
+		{ // Drift
+			versions:      map[string]string{"a": "1.2.3", "b": "1.2.4"},
+			wantAnnounces: 2,
+			wantDrift:     true},
+		{ // Back in sync
+			versions:      map[string]string{"a": "1.2.4", "b": "1.2.4"},
+			wantAnnounces: 3},
+	}
+
+	for i, step := range steps {
+		// WHEN handleTargetVersions is called with the versions
+		lookup.handleTargetVersions(step.versions, &util.LogFrom{})
+
+		// THEN the changes are announced
+		if len(announceChannel) != step.wantAnnounces {
+			t.Errorf("step %d: want %d announces, got %d",
+				i, step.wantAnnounces, len(announceChannel))
+		}
+		// AND the drift is what we expect
+		if got := lookup.Status.DeployedVersionDrift(); got != step.wantDrift {
+			t.Errorf("step %d: want drift %t, got %t",
+				i, step.wantDrift, got)
+		}
+	}
+}
+
+func TestLookup_CheckValues_Targets(t *testing.T) {
+	// GIVEN a Lookup with Targets
+	tests := map[string]struct {
+		lookupType  string
+		url         string
+		targets     []string
+		policy      string
+		notifyDrift bool
+		errRegex    []string
+	}{
+		"templated URL": {
+			url:      "https://{{ target }}/version",
+			targets:  []string{"app-1", "app-2"},
+			policy:   "majority",
+			errRegex: []string{`^$`}},
+		"target URLs without a url": {
+			targets:     []string{"https://app-1/version", "https://app-2/version"},
+			notifyDrift: true,
+			errRegex:    []string{`^$`}},
+		"hostnames without a templated URL": {
+			url:     "https://example.com/version",
+			targets: []string{"app-1", ""},
+			errRegex: []string{
+				`^  targets: item_0: "app-1" <invalid>`,
+				`^  targets: item_1: "" <invalid>`}},
+		"invalid policy": {
+			url:     "https://{{ target }}/version",
+			targets: []string{"app-1"},
+			policy:  "newest",
+			errRegex: []string{
+				`^  policy: "newest" <invalid> \(only \[min, max, majority, all_equal\] are allowed\)`}},
+		"policy without targets": {
+			url:    "https://example.com/version",
+			policy: "max",
+			errRegex: []string{
+				`^  policy: "max" <invalid> \(only used with targets\)`}},
+		"notify_drift without targets": {
+			url:         "https://example.com/version",
+			notifyDrift: true,
+			errRegex: []string{
+				`^  notify_drift: <invalid> \(only used with targets\)`}},
+		"targets with type command": {
+			lookupType: "command",
+			targets:    []string{"https://app-1/version"},
+			errRegex: []string{
+				`^  targets: <invalid> \(only supported with type: url\)`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = tc.lookupType
+			lookup.URL = tc.url
+			lookup.Targets = tc.targets
+			lookup.Policy = tc.policy
+			lookup.NotifyDrift = tc.notifyDrift
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
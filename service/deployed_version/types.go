@@ -15,6 +15,9 @@
 package deployedver
 
 import (
+	command "github.com/release-argus/Argus/commands"
+	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/service/auth"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/util"
@@ -23,11 +26,16 @@ import (
 var (
 	jLog           *util.JLog
 	supportedTypes = []string{"GET", "POST"}
+	lookupTypes    = []string{"url", "command", "file", "docker", "kubernetes", "ssh"}
+	targetPolicies = []string{"min", "max", "majority", "all_equal"}
 )
 
 // LookupBase is the base struct for the Lookup struct.
 type LookupBase struct {
-	AllowInvalidCerts *bool `yaml:"allow_invalid_certs,omitempty" json:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	AllowInvalidCerts *bool  `yaml:"allow_invalid_certs,omitempty" json:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	Interval          string `yaml:"interval,omitempty" json:"interval,omitempty"`                       // AhBmCs = Sleep A hours, B minutes and C seconds between queries (default - the Service's interval).
+	WatchInterval     string `yaml:"watch_interval,omitempty" json:"watch_interval,omitempty"`           // AhBmCs = Interval between queries after actions have run, until the approved version is deployed.
+	WatchTimeout      string `yaml:"watch_timeout,omitempty" json:"watch_timeout,omitempty"`             // AhBmCs = Time to watch for the approved version to be deployed after actions have run.
 }
 
 // LookupDefaults are the default values for the Lookup struct.
@@ -46,21 +54,39 @@ func NewDefaults(
 
 // Lookup the deployed version of the service.
 type Lookup struct {
-	Method        string `yaml:"method,omitempty" json:"method,omitempty"` // REQUIRED: HTTP method.
-	URL           string `yaml:"url,omitempty" json:"url,omitempty"`       // REQUIRED: URL to query.
+	Type          string `yaml:"type,omitempty" json:"type,omitempty"`     // OPTIONAL: url (default)/command/file/docker/kubernetes/ssh.
+	Method        string `yaml:"method,omitempty" json:"method,omitempty"` // REQUIRED (type:url): HTTP method.
+	URL           string `yaml:"url,omitempty" json:"url,omitempty"`       // REQUIRED (type:url): URL to query.
 	LookupBase    `yaml:",inline" json:",inline"`
-	BasicAuth     *BasicAuth `yaml:"basic_auth,omitempty" json:"basic_auth,omitempty"`         // OPTIONAL: Basic Auth credentials.
-	Headers       []Header   `yaml:"headers,omitempty" json:"headers,omitempty"`               // OPTIONAL: Request Headers.
-	Body          *string    `yaml:"body,omitempty" json:"body,omitempty"`                     // OPTIONAL: Request Body.
-	JSON          string     `yaml:"json,omitempty" json:"json,omitempty"`                     // OPTIONAL: JSON key to use e.g. version_current.
-	Regex         string     `yaml:"regex,omitempty" json:"regex,omitempty"`                   // OPTIONAL: RegEx for the version.
-	RegexTemplate *string    `yaml:"regex_template,omitempty" json:"regex_template,omitempty"` // OPTIONAL: Template to apply to the RegEx match.
+	BasicAuth     *BasicAuth        `yaml:"basic_auth,omitempty" json:"basic_auth,omitempty"`         // OPTIONAL: Basic Auth credentials.
+	Headers       []Header          `yaml:"headers,omitempty" json:"headers,omitempty"`               // OPTIONAL: Request Headers.
+	Auth          *auth.Auth        `yaml:"auth,omitempty" json:"auth,omitempty"`                     // OPTIONAL (type:url): Bearer token/OAuth2 client-credentials/API key auth.
+	Body          *string           `yaml:"body,omitempty" json:"body,omitempty"`                     // OPTIONAL: Request Body.
+	Command       command.Command   `yaml:"command,omitempty" json:"command,omitempty"`               // REQUIRED (type:command/ssh): Command to run, e.g. ["nginx", "-v"].
+	Timeout       string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`               // OPTIONAL (type:command/ssh): Time the command can run for (default 10s).
+	SSH           string            `yaml:"ssh,omitempty" json:"ssh,omitempty"`                       // REQUIRED (type:ssh): Name of the SSH profile of the host to run the Command on.
+	Path          string            `yaml:"path,omitempty" json:"path,omitempty"`                     // REQUIRED (type:file): Path of the file to read, e.g. /app/package.json.
+	Format        string            `yaml:"format,omitempty" json:"format,omitempty"`                 // OPTIONAL: Format to get the Key from, json/yaml/toml/xml/ini/env (type:file defaults to the extension of the Path).
+	Key           string            `yaml:"key,omitempty" json:"key,omitempty"`                       // OPTIONAL: Key in the Format to use, e.g. tool.poetry.version.
+	Docker        *DockerLookup     `yaml:"docker,omitempty" json:"docker,omitempty"`                 // REQUIRED (type:docker): Container to get the version of.
+	Kubernetes    *KubernetesLookup `yaml:"kubernetes,omitempty" json:"kubernetes,omitempty"`         // REQUIRED (type:kubernetes): Workload to get the version of.
+	Header        string            `yaml:"header,omitempty" json:"header,omitempty"`                 // OPTIONAL (type:url): Response header to get the version from, e.g. X-Version.
+	Prometheus    *PrometheusMetric `yaml:"prometheus,omitempty" json:"prometheus,omitempty"`         // OPTIONAL: Prometheus metric to get the version from, e.g. the version label of app_build_info.
+	JSON          string            `yaml:"json,omitempty" json:"json,omitempty"`                     // OPTIONAL: JSON key to use e.g. version_current.
+	Regex         string            `yaml:"regex,omitempty" json:"regex,omitempty"`                   // OPTIONAL: RegEx for the version.
+	RegexTemplate *string           `yaml:"regex_template,omitempty" json:"regex_template,omitempty"` // OPTIONAL: Template to apply to the RegEx match.
+	Targets       []string          `yaml:"targets,omitempty" json:"targets,omitempty"`               // OPTIONAL (type:url): Instances to query, URLs or hostnames for '{{ target }}' in the URL.
+	Policy        string            `yaml:"policy,omitempty" json:"policy,omitempty"`                 // OPTIONAL (targets): min (default)/max/majority/all_equal - How the version of the targets is decided.
+	NotifyDrift   bool              `yaml:"notify_drift,omitempty" json:"notify_drift,omitempty"`     // OPTIONAL (targets): Notify when the targets have different versions.
 
 	Options *opt.Options      `yaml:"-" json:"-"` // Options for the lookups
 	Status  *svcstatus.Status `yaml:"-" json:"-"` // Service Status
+	Notify  *shoutrrr.Slice   `yaml:"-" json:"-"` // Service Notify's to notify of drift between the targets
 
 	Defaults     *LookupDefaults `yaml:"-" json:"-"` // Default values.
 	HardDefaults *LookupDefaults `yaml:"-" json:"-"` // Hardcoded default values.
+
+	watch *watch // Faster queries after actions have run.
 }
 
 // New returns a new Lookup struct.
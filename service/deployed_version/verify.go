@@ -17,36 +17,224 @@ package deployedver
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 )
 
+// CheckValues of the LookupDefaults.
+func (l *LookupDefaults) CheckValues(prefix string) (errs error) {
+	errs = l.LookupBase.CheckValues(prefix)
+
+	if errs != nil {
+		errs = fmt.Errorf("%sdeployed_version:\\%w",
+			prefix, errs)
+	}
+	return
+}
+
+// CheckValues of the LookupBase.
+func (l *LookupBase) CheckValues(prefix string) (errs error) {
+	for _, interval := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "interval", value: &l.Interval},
+		{key: "watch_interval", value: &l.WatchInterval},
+		{key: "watch_timeout", value: &l.WatchTimeout},
+	} {
+		if *interval.value == "" {
+			continue
+		}
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(*interval.value); err == nil {
+			*interval.value += "s"
+		}
+		if d, err := time.ParseDuration(*interval.value); err != nil || d <= 0 {
+			errs = fmt.Errorf("%s%s  %s: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, interval.key, *interval.value)
+		}
+	}
+
+	return
+}
+
 // CheckValues of the Lookup.
 func (l *Lookup) CheckValues(prefix string) (errs error) {
 	if l == nil {
 		return
 	}
 
-	// Method
-	l.Method = strings.ToUpper(l.Method)
-	if l.Method == "" {
-		l.Method = "GET"
-	} else if !util.Contains(supportedTypes, l.Method) {
-		errs = fmt.Errorf("%s%s  method: %q <invalid> (only [%s] are allowed)\\",
-			util.ErrorToString(errs), prefix, l.Method, strings.Join(supportedTypes, ", "))
+	// Interval/WatchInterval/WatchTimeout
+	errs = l.LookupBase.CheckValues(prefix)
+
+	// Type
+	if l.Type != "" && !util.Contains(lookupTypes, l.Type) {
+		errs = fmt.Errorf("%s%s  type: %q <invalid> (only [%s] are allowed)\\",
+			util.ErrorToString(errs), prefix, l.Type, strings.Join(lookupTypes, ", "))
+	}
+
+	// SSH
+	if l.SSH != "" && l.GetType() != "ssh" {
+		errs = fmt.Errorf("%s%s  ssh: %q <invalid> (only used with type: ssh)\\",
+			util.ErrorToString(errs), prefix, l.SSH)
 	}
-	// Body unused in GET, so ensure it's nil.
-	if l.Method == "GET" {
-		l.Body = nil
+
+	switch l.GetType() {
+	case "command", "ssh":
+		// SSH
+		if l.GetType() == "ssh" {
+			if l.SSH == "" {
+				errs = fmt.Errorf("%s%s  ssh: <required> (name of the ssh profile to run the command with)\\",
+					util.ErrorToString(errs), prefix)
+			} else if err := ssh.CheckProfile(l.SSH); err != nil {
+				errs = fmt.Errorf("%s%s  ssh: %s\\",
+					util.ErrorToString(errs), prefix, err)
+			}
+		}
+
+		// Command
+		if len(l.Command) == 0 {
+			errs = fmt.Errorf("%s%s  command: <required> (command to get the deployed_version, e.g. ['nginx', '-v'])\\",
+				util.ErrorToString(errs), prefix)
+		}
+		for i := range l.Command {
+			if !util.CheckTemplate(l.Command[i]) {
+				errs = fmt.Errorf("%s%s  command: %v (%q) <invalid> (didn't pass templating)\\",
+					util.ErrorToString(errs), prefix, l.Command, l.Command[i])
+				break
+			}
+		}
+
+		// Timeout
+		if l.Timeout != "" {
+			// Default to seconds when an integer is provided
+			if _, err := strconv.Atoi(l.Timeout); err == nil {
+				l.Timeout += "s"
+			}
+			if timeout, err := time.ParseDuration(l.Timeout); err != nil || timeout <= 0 {
+				errs = fmt.Errorf("%s%s  timeout: %q <invalid> (Use 'AhBmCs' duration format)\\",
+					util.ErrorToString(errs), prefix, l.Timeout)
+			}
+		}
+	case "file":
+		// Path
+		if l.Path == "" {
+			errs = fmt.Errorf("%s%s  path: <required> (path of the file to get the deployed_version from, e.g. '/app/package.json')\\",
+				util.ErrorToString(errs), prefix)
+		}
+	case "docker":
+		// Docker
+		if l.Docker == nil {
+			errs = fmt.Errorf("%s%s  docker: <required> (container to get the deployed_version of)\\",
+				util.ErrorToString(errs), prefix)
+		} else if err := l.Docker.CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  docker:\\%w",
+				util.ErrorToString(errs), prefix, err)
+		}
+	case "kubernetes":
+		// Kubernetes
+		if l.Kubernetes == nil {
+			errs = fmt.Errorf("%s%s  kubernetes: <required> (workload to get the deployed_version of)\\",
+				util.ErrorToString(errs), prefix)
+		} else if err := l.Kubernetes.CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  kubernetes:\\%w",
+				util.ErrorToString(errs), prefix, err)
+		}
+	default:
+		// Method
+		l.Method = strings.ToUpper(l.Method)
+		if l.Method == "" {
+			l.Method = "GET"
+		} else if !util.Contains(supportedTypes, l.Method) {
+			errs = fmt.Errorf("%s%s  method: %q <invalid> (only [%s] are allowed)\\",
+				util.ErrorToString(errs), prefix, l.Method, strings.Join(supportedTypes, ", "))
+		}
+		// Body unused in GET, so ensure it's nil.
+		if l.Method == "GET" {
+			l.Body = nil
+		}
+
+		// URL
+		if l.URL == "" && len(l.Targets) == 0 && l.Defaults != nil {
+			errs = fmt.Errorf("%s%s  url: <required> (URL to get the deployed_version is required)\\",
+				util.ErrorToString(errs), prefix)
+		}
 	}
 
-	// URL
-	if l.URL == "" && l.Defaults != nil {
-		errs = fmt.Errorf("%s%s  url: <required> (URL to get the deployed_version is required)\\",
+	// Targets
+	if len(l.Targets) != 0 {
+		if l.GetType() != "url" {
+			errs = fmt.Errorf("%s%s  targets: <invalid> (only supported with type: url)\\",
+				util.ErrorToString(errs), prefix)
+		}
+		templated := targetRegex.MatchString(l.URL)
+		for i, target := range l.Targets {
+			if target == "" || (!templated && !strings.Contains(target, "://")) {
+				errs = fmt.Errorf("%s%s  targets: item_%d: %q <invalid> (must be a URL, or the URL must contain '{{ target }}')\\",
+					util.ErrorToString(errs), prefix, i, target)
+			}
+		}
+	}
+	if l.Policy != "" {
+		if len(l.Targets) == 0 {
+			errs = fmt.Errorf("%s%s  policy: %q <invalid> (only used with targets)\\",
+				util.ErrorToString(errs), prefix, l.Policy)
+		} else if !util.Contains(targetPolicies, l.Policy) {
+			errs = fmt.Errorf("%s%s  policy: %q <invalid> (only [%s] are allowed)\\",
+				util.ErrorToString(errs), prefix, l.Policy, strings.Join(targetPolicies, ", "))
+		}
+	}
+	if l.NotifyDrift && len(l.Targets) == 0 {
+		errs = fmt.Errorf("%s%s  notify_drift: <invalid> (only used with targets)\\",
 			util.ErrorToString(errs), prefix)
 	}
 
+	// Auth
+	if l.Auth != nil {
+		if l.GetType() != "url" {
+			errs = fmt.Errorf("%s%s  auth: <invalid> (only supported with type: url)\\",
+				util.ErrorToString(errs), prefix)
+		}
+		if err := l.Auth.CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  auth:\\%w",
+				util.ErrorToString(errs), prefix, err)
+		}
+	}
+
+	// Header
+	if l.Header != "" && l.GetType() != "url" {
+		errs = fmt.Errorf("%s%s  header: %q <invalid> (only supported with type: url)\\",
+			util.ErrorToString(errs), prefix, l.Header)
+	}
+
+	// Prometheus
+	if l.Prometheus != nil {
+		if l.GetKey() != "" {
+			errs = fmt.Errorf("%s%s  prometheus: <invalid> (can't be used with json/key)\\",
+				util.ErrorToString(errs), prefix)
+		}
+		if err := l.Prometheus.CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  prometheus:\\%w",
+				util.ErrorToString(errs), prefix, err)
+		}
+	}
+
+	// Format
+	if l.Format != "" && !util.Contains(util.Formats, l.Format) {
+		errs = fmt.Errorf("%s%s  format: %q <invalid> (only [%s] are allowed)\\",
+			util.ErrorToString(errs), prefix, l.Format, strings.Join(util.Formats, ", "))
+	}
+
+	// Key
+	if _, err := util.ParseKeys(l.Key); err != nil {
+		errs = fmt.Errorf("%s%s  key: %q <invalid> - %s\\",
+			util.ErrorToString(errs), prefix, l.Key, err.Error())
+	}
+
 	// JSON
 	_, err := util.ParseKeys(l.JSON)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/release-argus/Argus/service/auth"
 	"github.com/release-argus/Argus/test"
 	"github.com/release-argus/Argus/util"
 )
@@ -184,3 +185,64 @@ func TestLookup_CheckValues(t *testing.T) {
 		})
 	}
 }
+
+func TestLookup_CheckValues_Auth(t *testing.T) {
+	// GIVEN a Lookup with an Auth
+	tests := map[string]struct {
+		lookupType string
+		auth       *auth.Auth
+		errRegex   []string
+	}{
+		"valid auth": {
+			auth: &auth.Auth{
+				Type:  "bearer",
+				Token: "abc"},
+			errRegex: []string{`^$`}},
+		"auth with type command": {
+			lookupType: "command",
+			auth: &auth.Auth{
+				Type:  "bearer",
+				Token: "abc"},
+			errRegex: []string{
+				`^  auth: <invalid> \(only supported with type: url\)$`}},
+		"invalid auth": {
+			auth: &auth.Auth{
+				Type: "oauth2"},
+			errRegex: []string{
+				`^  auth:$`,
+				`^    token_url: <required>`,
+				`^    client_id: <required>$`,
+				`^    client_secret: <required>$`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup()
+			lookup.Type = tc.lookupType
+			lookup.Auth = tc.auth
+
+			// WHEN CheckValues is called on it
+			err := lookup.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -43,6 +44,8 @@ func (s *Service) UpdatedVersion(writeToDB bool) {
 			// (only having `deployed_version`,`command` or `webhook` would only use ApprovedVersion to track skips)
 			// They should have all ran/sent successfully at this point
 			s.UpdateLatestApproved()
+			// Query the deployed version more often until the approved version is deployed.
+			s.DeployedVersionLookup.Watch(s.Status.ApprovedVersion())
 		}
 		return
 	}
@@ -69,8 +72,10 @@ func (s *Service) HandleUpdateActions(writeToDB bool) {
 	serviceInfo := s.ServiceInfo()
 
 	// Send the Notify Message(s).
-	//nolint:errcheck
-	go s.Notify.Send("", "", serviceInfo, true)
+	GoAction(func() {
+		//nolint:errcheck
+		s.Notify.Send("", "", serviceInfo, true)
+	})
 
 	//nolint:typecheck
 	if s.WebHook != nil || s.Command != nil {
@@ -80,20 +85,20 @@ func (s *Service) HandleUpdateActions(writeToDB bool) {
 			jLog.Info(msg, &util.LogFrom{Primary: s.ID}, true)
 
 			// Run the Command(s)
-			go func() {
+			GoAction(func() {
 				err := s.CommandController.Exec(&util.LogFrom{Primary: "Command", Secondary: s.ID})
 				if err == nil && len(s.Command) != 0 {
 					s.UpdatedVersion(writeToDB)
 				}
-			}()
+			})
 
 			// Send the WebHook(s)
-			go func() {
+			GoAction(func() {
 				err := s.WebHook.Send(serviceInfo, true)
 				if err == nil && len(s.WebHook) != 0 {
 					s.UpdatedVersion(writeToDB)
 				}
-			}()
+			})
 		} else {
 			jLog.Info("Waiting for approval on the Web UI", &util.LogFrom{Primary: s.ID}, true)
 
@@ -227,6 +232,31 @@ func (s *Service) HandleSkip() {
 	}
 }
 
+// GoAction runs `fn` in a goroutine, tracking it as an in-flight action (see WaitForActions).
+func GoAction(fn func()) {
+	actions.Add(1)
+	go func() {
+		defer actions.Done()
+		fn()
+	}()
+}
+
+// WaitForActions waits for the in-flight actions (Commands running, Notifies/WebHooks sending)
+// to finish, or `ctx` to be done.
+func WaitForActions(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		actions.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *Service) shouldRetryAll() (retry bool) {
 	retry = true
 	// retry all only if every WebHook has been sent successfully
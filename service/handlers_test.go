@@ -17,6 +17,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -1185,3 +1186,41 @@ func TestService_ShouldRetryAll(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitForActions(t *testing.T) {
+	// GIVEN an action in-flight
+	release := make(chan bool)
+	ran := make(chan bool, 1)
+	GoAction(func() {
+		ran <- true
+		<-release
+	})
+	<-ran
+
+	// WHEN WaitForActions is called with a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := WaitForActions(ctx)
+
+	// THEN it waits for the timeout as the action is still in-flight
+	if err != context.DeadlineExceeded {
+		t.Errorf("want err=%v, got %v",
+			context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("want to have waited for the timeout, only waited %s",
+			elapsed)
+	}
+
+	// WHEN the action finishes
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// THEN WaitForActions returns
+	if err := WaitForActions(ctx); err != nil {
+		t.Errorf("want no error once the actions finished, got %v",
+			err)
+	}
+}
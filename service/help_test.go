@@ -84,11 +84,13 @@ func testService(id string, sType string) *Service {
 	svc.LatestVersion.Init(
 		svc.LatestVersion.Defaults, svc.LatestVersion.HardDefaults,
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	svc.DeployedVersionLookup.Init(
 		&deployedver.LookupDefaults{}, &deployedver.LookupDefaults{},
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	return svc
 }
 
@@ -50,6 +50,7 @@ func (s *Service) Init(
 		len(s.Notify), len(s.Command), len(s.WebHook),
 		&s.ID,
 		&s.Dashboard.WebURL)
+	s.Status.SetServiceInfo(s.ServiceInfo)
 
 	// Service
 	s.Defaults = defaults
@@ -82,7 +83,8 @@ func (s *Service) Init(
 		s.commandFromDefaults = true
 	}
 	if len(s.Command) != 0 {
-		s.CommandController = &command.Controller{}
+		s.CommandController = &command.Controller{
+			SSH: s.CommandSSH}
 		s.CommandController.Init(
 			&s.Status,
 			&s.Command,
@@ -109,13 +111,15 @@ func (s *Service) Init(
 	s.LatestVersion.Init(
 		&s.Defaults.LatestVersion, &s.HardDefaults.LatestVersion,
 		&s.Status,
-		&s.Options)
+		&s.Options,
+		&s.Notify)
 
 	// DeployedVersionLookup
 	s.DeployedVersionLookup.Init(
 		&s.Defaults.DeployedVersionLookup, &s.HardDefaults.DeployedVersionLookup,
 		&s.Status,
-		&s.Options)
+		&s.Options,
+		&s.Notify)
 
 }
 
@@ -126,6 +130,10 @@ func (s *Service) ServiceInfo() *util.ServiceInfo {
 		URL:           s.LatestVersion.ServiceURL(true),
 		WebURL:        s.Status.GetWebURL(),
 		LatestVersion: s.Status.LatestVersion(),
+		Release:       s.LatestVersion.LatestRelease().Info(),
+		ReleaseNotes:  s.Status.ReleaseNotes(),
+		Image:         s.LatestVersion.LatestImage(),
+		Artifact:      s.LatestVersion.LatestArtifact(),
 	}
 }
 
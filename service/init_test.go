@@ -298,6 +298,11 @@ func TestService_Init(t *testing.T) {
 				t.Errorf("Options hardDefaults were not handed to the Lookup correctly\n want: %v\ngot:  %v",
 					&hardDefaults.Options, tc.svc.Options.HardDefaults)
 			}
+			// status.serviceInfo (for templating Commands)
+			if got := tc.svc.Status.ServiceInfo(); got.ID != tc.svc.ID {
+				t.Errorf("ServiceInfo was not handed to the Status correctly\nwant ID: %q\ngot:  %#v",
+					tc.svc.ID, got)
+			}
 			// Notify
 			if len(tc.svc.Notify) != 0 {
 				for i := range tc.svc.Notify {
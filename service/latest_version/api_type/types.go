@@ -27,6 +27,12 @@ type Release struct {
 	Name            string          `json:"name,omitempty"` // This is the tag name on /tags queries
 	TagName         string          `json:"tag_name,omitempty"`
 	PreRelease      bool            `json:"prerelease,omitempty"`
+	Draft           bool            `json:"draft,omitempty"`
+	PublishedAt     string          `json:"published_at,omitempty"`     // RFC3339 time the release was published
+	Body            string          `json:"body,omitempty"`             // Release notes
+	HTMLURL         string          `json:"html_url,omitempty"`         // URL of the release page
+	TargetCommitish string          `json:"target_commitish,omitempty"` // Branch/commit the release was created from
+	Author          *Author         `json:"author,omitempty"`
 	Assets          []Asset         `json:"assets,omitempty"`
 }
 
@@ -38,6 +44,41 @@ func (r *Release) String() (str string) {
 	return
 }
 
+// Info returns the metadata of the Release for use in templates.
+func (r *Release) Info() *util.ReleaseInfo {
+	if r == nil {
+		return nil
+	}
+
+	info := &util.ReleaseInfo{
+		Name:            r.Name,
+		TagName:         r.TagName,
+		PreRelease:      r.PreRelease,
+		Draft:           r.Draft,
+		PublishedAt:     r.PublishedAt,
+		Body:            r.Body,
+		URL:             r.HTMLURL,
+		TargetCommitish: r.TargetCommitish}
+	if r.Author != nil {
+		info.Author = r.Author.Login
+	}
+	return info
+}
+
+// Author is the format of the Author of a Release on api.github.com/repos/OWNER/REPO/releases.
+type Author struct {
+	Login   string `json:"login,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// String returns a string representation of the Author.
+func (a *Author) String() (str string) {
+	if a != nil {
+		str = util.ToJSONString(a)
+	}
+	return
+}
+
 // Asset is the format of an Asset on api.github.com/repos/OWNER/REPO/releases.
 type Asset struct {
 	ID                 uint   `json:"id"`
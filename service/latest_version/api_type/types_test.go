@@ -21,6 +21,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
 )
 
 func TestRelease_String(t *testing.T) {
@@ -48,10 +49,16 @@ func TestRelease_String(t *testing.T) {
 				}`},
 		"all fields defined": {
 			release: &Release{
-				URL:        "https://test.com",
-				AssetsURL:  "https://test.com/assets",
-				TagName:    "v1.2.3",
-				PreRelease: true,
+				URL:             "https://test.com",
+				AssetsURL:       "https://test.com/assets",
+				TagName:         "v1.2.3",
+				PreRelease:      true,
+				Draft:           true,
+				PublishedAt:     "2023-01-01T00:00:00Z",
+				Body:            "notes",
+				HTMLURL:         "https://test.com/release",
+				TargetCommitish: "main",
+				Author:          &Author{Login: "octocat", HTMLURL: "https://test.com/octocat"},
 				Assets: []Asset{
 					{ID: 1, Name: "test", URL: "https://test.com", BrowserDownloadURL: "https://test.com/download"}}},
 			release_semantic_version: "1.2.3",
@@ -61,6 +68,12 @@ func TestRelease_String(t *testing.T) {
 					"assets_url": "https://test.com/assets",
 					"tag_name": "v1.2.3",
 					"prerelease": true,
+					"draft": true,
+					"published_at": "2023-01-01T00:00:00Z",
+					"body": "notes",
+					"html_url": "https://test.com/release",
+					"target_commitish": "main",
+					"author": {"login": "octocat", "html_url": "https://test.com/octocat"},
 					"assets": [
 						{"id": 1, "name": "test", "url": "https://test.com", "browser_download_url": "https://test.com/download"}
 					]
@@ -88,6 +101,57 @@ func TestRelease_String(t *testing.T) {
 	}
 }
 
+func TestRelease_Info(t *testing.T) {
+	tests := map[string]struct {
+		release *Release
+		want    *util.ReleaseInfo
+	}{
+		"nil": {
+			release: nil,
+			want:    nil},
+		"empty": {
+			release: &Release{},
+			want:    &util.ReleaseInfo{}},
+		"all fields defined": {
+			release: &Release{
+				Name:            "Release v1.2.3",
+				TagName:         "v1.2.3",
+				PreRelease:      true,
+				Draft:           true,
+				PublishedAt:     "2023-01-01T00:00:00Z",
+				Body:            "notes",
+				HTMLURL:         "https://test.com/release",
+				TargetCommitish: "main",
+				Author:          &Author{Login: "octocat"}},
+			want: &util.ReleaseInfo{
+				Name:            "Release v1.2.3",
+				TagName:         "v1.2.3",
+				PreRelease:      true,
+				Draft:           true,
+				PublishedAt:     "2023-01-01T00:00:00Z",
+				Body:            "notes",
+				URL:             "https://test.com/release",
+				TargetCommitish: "main",
+				Author:          "octocat"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN Info is called on the Release
+			got := tc.release.Info()
+
+			// THEN the result is as expected
+			if (got == nil) != (tc.want == nil) ||
+				(got != nil && *got != *tc.want) {
+				t.Errorf("got:\n%+v\nwant:\n%+v",
+					got, tc.want)
+			}
+		})
+	}
+}
+
 func TestAsset_String(t *testing.T) {
 	tests := map[string]struct {
 		asset *Asset
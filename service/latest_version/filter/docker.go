@@ -15,6 +15,7 @@
 package filter
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -26,11 +27,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/util"
 )
 
 var dockerCheckTypes = []string{
-	"hub", "quay", "ghcr"}
+	"hub", "quay", "ghcr", "registry"}
 
 // DockerCheckRegistryBase is the base for checking a Docker registry for an image:tag.
 type DockerCheckRegistryBase struct {
@@ -160,10 +162,15 @@ type DockerCheck struct {
 	Username                string `yaml:"username,omitempty" json:"username,omitempty"` // Username to get a new token
 	DockerCheckRegistryBase `yaml:",inline" json:",inline"`
 
-	Image string `yaml:"image,omitempty" json:"image,omitempty"` // Image to check
-	Tag   string `yaml:"tag,omitempty" json:"tag,omitempty"`     // Tag to check for
+	Registry  string   `yaml:"registry,omitempty" json:"registry,omitempty"`   // URL of the registry (type:registry), e.g. "https://registry.example.com"
+	Image     string   `yaml:"image,omitempty" json:"image,omitempty"`         // Image to check
+	Tag       string   `yaml:"tag,omitempty" json:"tag,omitempty"`             // Tag to check for
+	Platforms []string `yaml:"platforms,omitempty" json:"platforms,omitempty"` // Platforms the tag has to be available for, e.g. "linux/amd64"
 
 	Defaults *DockerCheckDefaults `yaml:"-" json:"-"` // Default values for DockerCheck
+
+	image        *util.ImageInfo // Image of the last tag found
+	imageVersion string          // Version the image was found for
 }
 
 // New DockerCheck.
@@ -199,7 +206,9 @@ func (d *DockerCheck) String(prefix string) (str string) {
 
 // DockerTagCheck will verify that Tag exists for Image and return an error if not.
 func (r *Require) DockerTagCheck(
+	ctx context.Context,
 	version string,
+	logFrom *util.LogFrom,
 ) error {
 	if r == nil || r.Docker == nil {
 		return nil
@@ -207,7 +216,7 @@ func (r *Require) DockerTagCheck(
 	var url string
 	tag := r.Docker.GetTag(version)
 	var req *http.Request
-	queryToken, err := r.Docker.getQueryToken()
+	queryToken, err := r.Docker.getQueryToken(ctx, logFrom)
 	if err != nil {
 		return fmt.Errorf("%s:%s - %w",
 			r.Docker.Image, tag, err)
@@ -222,15 +231,27 @@ func (r *Require) DockerTagCheck(
 	case "quay":
 		url = fmt.Sprintf("https://quay.io/api/v1/repository/%s/tag/?onlyActiveTags=true&specificTag=%s",
 			r.Docker.Image, tag)
+	case "registry":
+		contentDigest, body, err := r.Docker.registryManifest(ctx, tag, queryToken, logFrom)
+		if err != nil {
+			return fmt.Errorf("%s:%s - %w",
+				r.Docker.Image, tag, err)
+		}
+		return r.Docker.tagFound(version, tag, contentDigest, body)
 	}
-	req, _ = http.NewRequest(http.MethodGet, url, nil)
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if queryToken != "" {
 		req.Header.Set("Authorization", "Bearer "+queryToken)
 	}
+	if r.Docker.GetType() == "ghcr" {
+		req.Header.Set("Accept", dockerManifestAccept)
+	}
 	req.Header.Set("Connection", "close")
 
 	// Do the request
-	client := &http.Client{}
+	client := &http.Client{
+		Transport: ratelimit.Transport(nil, logFrom),
+		Timeout:   util.HTTPTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("%s:%s - %w",
@@ -250,7 +271,7 @@ func (r *Require) DockerTagCheck(
 			r.Docker.Image, tag)
 	}
 
-	return nil
+	return r.Docker.tagFound(version, tag, resp.Header.Get("Docker-Content-Digest"), body)
 }
 
 // CheckValues of the DockerCheck.
@@ -284,6 +305,30 @@ func (d *DockerCheck) CheckValues(prefix string) (errs error) {
 		}
 	}
 
+	if d.GetType() == "registry" {
+		if d.Registry == "" {
+			errs = fmt.Errorf("%s%sregistry: <required> (URL of the registry, e.g. https://registry.example.com)\\",
+				util.ErrorToString(errs), prefix)
+		} else if registryURL, err := net_url.ParseRequestURI(d.Registry); err != nil || registryURL.Host == "" {
+			errs = fmt.Errorf("%s%sregistry: %q <invalid> (URL of the registry, e.g. https://registry.example.com)\\",
+				util.ErrorToString(errs), prefix, d.Registry)
+		}
+	}
+
+	if len(d.Platforms) != 0 {
+		if d.GetType() == "quay" {
+			errs = fmt.Errorf("%s%splatforms: <invalid> (not supported for type quay)\\",
+				util.ErrorToString(errs), prefix)
+		}
+		for _, platform := range d.Platforms {
+			if parts := strings.Split(platform, "/"); len(parts) < 2 || len(parts) > 3 ||
+				util.Contains(parts, "") {
+				errs = fmt.Errorf("%s%splatforms: %q <invalid> (expected 'os/arch' or 'os/arch/variant')\\",
+					util.ErrorToString(errs), prefix, platform)
+			}
+		}
+	}
+
 	if d.Tag == "" {
 		errs = fmt.Errorf("%s%stag: <required> (tag of image to check for existence)",
 			util.ErrorToString(errs), prefix)
@@ -317,6 +362,12 @@ func (d *DockerCheck) checkToken() (err error) {
 		} else if username == "" && token != "" {
 			err = fmt.Errorf("username: <required> (token is for who?)")
 		}
+	case "registry":
+		// require token if username is defined
+		if username := util.EvalEnvVars(d.Username); username != "" && d.getToken() == "" {
+			err = fmt.Errorf("token: <required> (token/password for %s)",
+				username)
+		}
 	case "quay":
 	case "ghcr":
 	}
@@ -466,7 +517,7 @@ func (d *DockerCheckDefaults) getQueryToken(dType string) (queryToken string, va
 }
 
 // getQueryToken for API queries.
-func (d *DockerCheck) getQueryToken() (queryToken string, err error) {
+func (d *DockerCheck) getQueryToken(ctx context.Context, logFrom *util.LogFrom) (queryToken string, err error) {
 	dType := d.GetType()
 	queryToken = d.getValidToken()
 	if queryToken != "" {
@@ -483,7 +534,7 @@ func (d *DockerCheck) getQueryToken() (queryToken string, err error) {
 			d.validUntil = time.Now().AddDate(1, 0, 0)
 			d.mutex.Unlock()
 			// Refresh token
-		} else if err = d.refreshDockerHubToken(); err != nil {
+		} else if err = d.refreshDockerHubToken(ctx, logFrom); err != nil {
 			return
 		}
 	case "ghcr":
@@ -496,7 +547,7 @@ func (d *DockerCheck) getQueryToken() (queryToken string, err error) {
 			validUntil := time.Now().AddDate(1, 0, 0)
 			d.SetQueryToken(&token, &queryToken, &validUntil)
 			// Get a NOOP token for public images
-		} else if err = d.refreshGHCRToken(); err != nil {
+		} else if err = d.refreshGHCRToken(ctx, logFrom); err != nil {
 			return
 		}
 	case "quay":
@@ -626,7 +677,7 @@ func (d *DockerCheck) SetQueryToken(token, queryToken *string, validUntil *time.
 }
 
 // refreshDockerHubToken for the Image
-func (d *DockerCheck) refreshDockerHubToken() error {
+func (d *DockerCheck) refreshDockerHubToken(ctx context.Context, logFrom *util.LogFrom) error {
 	token := d.getToken()
 	// No Token found
 	if token == "" {
@@ -638,14 +689,16 @@ func (d *DockerCheck) refreshDockerHubToken() error {
 	reqBody := net_url.Values{}
 	reqBody.Set("username", d.getUsername())
 	reqBody.Set("password", token)
-	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(reqBody.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(reqBody.Encode()))
 	if err != nil {
 		return fmt.Errorf("DockerHub login request, creation failed: %w", err)
 	}
 	req.Header.Set("Connection", "close")
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	// Do the request
-	client := &http.Client{}
+	client := &http.Client{
+		Transport: ratelimit.Transport(nil, logFrom),
+		Timeout:   util.HTTPTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("DockerHub login fail: %w", err)
@@ -673,9 +726,16 @@ func (d *DockerCheck) refreshDockerHubToken() error {
 }
 
 // refreshGHCRToken for the image
-func (d *DockerCheck) refreshGHCRToken() error {
+func (d *DockerCheck) refreshGHCRToken(ctx context.Context, logFrom *util.LogFrom) error {
 	url := fmt.Sprintf("https://ghcr.io/token?scope=repository:%s:pull", d.Image)
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("GHCR token request, creation failed: %w", err)
+	}
+	client := &http.Client{
+		Transport: ratelimit.Transport(nil, logFrom),
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("GHCR token refresh fail: %w", err)
 	}
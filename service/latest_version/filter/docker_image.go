@@ -0,0 +1,175 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// tagFound will verify the Platforms of the `tag` that was found for `version`,
+// and record its image (digest/platforms) if it passes.
+func (d *DockerCheck) tagFound(version string, tag string, contentDigest string, body []byte) error {
+	image := d.parseImage(tag, contentDigest, body)
+	if err := d.platformsCheck(tag, image); err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.image = image
+	d.imageVersion = version
+	return nil
+}
+
+// ImageInfo returns the image found for `version`
+// (nil if the last tag found was for a different version).
+func (d *DockerCheck) ImageInfo(version string) *util.ImageInfo {
+	if d == nil {
+		return nil
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.imageVersion != version {
+		return nil
+	}
+	return d.image
+}
+
+// parseImage returns the image described by the `body` of the tag query.
+//
+// hub - the digest and images of the tag.
+// quay - the manifest_digest and size of the tag.
+// ghcr/registry - the Docker-Content-Digest (`contentDigest`) and the manifests of the manifest list/index.
+func (d *DockerCheck) parseImage(tag string, contentDigest string, body []byte) (image *util.ImageInfo) {
+	type platformJSON struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	}
+	var parsed struct {
+		// hub
+		Digest string `json:"digest"`
+		Images []struct {
+			platformJSON
+			Digest string `json:"digest"`
+			Size   int64  `json:"size"`
+		} `json:"images"`
+		// quay
+		Tags []struct {
+			ManifestDigest string `json:"manifest_digest"`
+			Size           int64  `json:"size"`
+		} `json:"tags"`
+		// ghcr/registry
+		Manifests []struct {
+			Digest   string        `json:"digest"`
+			Size     int64         `json:"size"`
+			Platform *platformJSON `json:"platform"`
+		} `json:"manifests"`
+	}
+	// A body that can't be parsed has no digest/platforms.
+	//nolint:errcheck
+	json.Unmarshal(body, &parsed)
+
+	image = &util.ImageInfo{
+		Image: d.Image,
+		Tag:   tag}
+	addPlatform := func(platform platformJSON, digest string, size int64) {
+		// Skip attestations, e.g. unknown/unknown
+		if platform.OS == "" || platform.OS == "unknown" {
+			return
+		}
+		name := platform.OS + "/" + platform.Architecture
+		if platform.Variant != "" {
+			name += "/" + platform.Variant
+		}
+		image.Platforms = append(image.Platforms, util.ImagePlatformInfo{
+			Platform: name,
+			Digest:   digest,
+			Size:     size})
+	}
+
+	switch d.GetType() {
+	case "hub":
+		image.Digest = parsed.Digest
+		for _, img := range parsed.Images {
+			addPlatform(img.platformJSON, img.Digest, img.Size)
+		}
+	case "quay":
+		if len(parsed.Tags) != 0 {
+			image.Digest = parsed.Tags[0].ManifestDigest
+		}
+	default:
+		image.Digest = contentDigest
+		// The digest of a manifest is the SHA256 of its body.
+		if image.Digest == "" && len(body) != 0 {
+			hash := sha256.Sum256(body)
+			image.Digest = "sha256:" + hex.EncodeToString(hash[:])
+		}
+		for _, manifest := range parsed.Manifests {
+			if manifest.Platform != nil {
+				addPlatform(*manifest.Platform, manifest.Digest, manifest.Size)
+			}
+		}
+	}
+
+	return
+}
+
+// platformsCheck will verify that the `image` is available for all of the Platforms.
+func (d *DockerCheck) platformsCheck(tag string, image *util.ImageInfo) error {
+	if len(d.Platforms) == 0 {
+		return nil
+	}
+
+	if len(image.Platforms) == 0 {
+		return fmt.Errorf("%s:%s - no platforms found (not a multi-arch manifest list)",
+			d.Image, tag)
+	}
+
+	platforms := make([]string, len(image.Platforms))
+	for i := range image.Platforms {
+		platforms[i] = image.Platforms[i].Platform
+	}
+	var missing []string
+	for _, want := range d.Platforms {
+		if !platformsContain(platforms, want) {
+			missing = append(missing, want)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%s:%s - missing platforms [%s] (has [%s])",
+			d.Image, tag, strings.Join(missing, ", "), strings.Join(platforms, ", "))
+	}
+
+	return nil
+}
+
+// platformsContain returns whether `want` is in `platforms`,
+// where a `want` without a variant matches any variant.
+func platformsContain(platforms []string, want string) bool {
+	for _, platform := range platforms {
+		if platform == want || strings.HasPrefix(platform, want+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	net_url "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/release-argus/Argus/ratelimit"
+	"github.com/release-argus/Argus/util"
+)
+
+// dockerManifestAccept is the Accept header for manifest requests,
+// preferring the multi-arch manifest lists/indexes.
+var dockerManifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json"},
+	", ")
+
+// authChallengeParamRegex matches the `key="value"` params of a WWW-Authenticate challenge.
+var authChallengeParamRegex = regexp.MustCompile(`([A-Za-z]+)="([^"]*)"`)
+
+// registryManifest returns the manifest (and its digest) of the `tag` from the Registry,
+// following the WWW-Authenticate challenge if the registry requires authentication.
+func (d *DockerCheck) registryManifest(ctx context.Context, tag string, queryToken string, logFrom *util.LogFrom) (contentDigest string, body []byte, err error) {
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s",
+		strings.TrimSuffix(d.Registry, "/"), d.Image, tag)
+
+	var authorization string
+	if queryToken != "" {
+		authorization = "Bearer " + queryToken
+	}
+	statusCode, header, body, err := dockerManifestRequest(ctx, url, authorization, logFrom)
+	if err != nil {
+		return
+	}
+
+	// Authenticate and try again.
+	if statusCode == http.StatusUnauthorized {
+		authorization, err = d.registryAuthorization(ctx, header.Get("WWW-Authenticate"), logFrom)
+		if err != nil {
+			return
+		}
+		statusCode, header, body, err = dockerManifestRequest(ctx, url, authorization, logFrom)
+		if err != nil {
+			return
+		}
+	}
+
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%s", body)
+		return
+	}
+	contentDigest = header.Get("Docker-Content-Digest")
+	return
+}
+
+// dockerManifestRequest does a GET on the manifest `url` with the `authorization` header.
+func dockerManifestRequest(ctx context.Context, url string, authorization string, logFrom *util.LogFrom) (statusCode int, header http.Header, body []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		err = fmt.Errorf("registry request, creation failed: %w", err)
+		return
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	req.Header.Set("Accept", dockerManifestAccept)
+	req.Header.Set("Connection", "close")
+
+	// Do the request
+	client := &http.Client{
+		Transport: ratelimit.Transport(nil, logFrom),
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ = io.ReadAll(resp.Body)
+	statusCode = resp.StatusCode
+	header = resp.Header
+	return
+}
+
+// registryAuthorization returns the Authorization header to answer the WWW-Authenticate `challenge`.
+//
+// Basic - the Username and Token are used as the credentials.
+// Bearer - a token is requested from the realm (with the Username and Token as credentials, if set).
+func (d *DockerCheck) registryAuthorization(ctx context.Context, challenge string, logFrom *util.LogFrom) (authorization string, err error) {
+	scheme, params := parseAuthChallenge(challenge)
+	username := util.EvalEnvVars(d.Username)
+	token := d.getToken()
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" && token == "" {
+			err = fmt.Errorf("registry requires basic auth, but no username/token were given")
+			return
+		}
+		authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+token))
+	case "bearer":
+		var queryToken string
+		queryToken, err = d.refreshRegistryToken(ctx, params, logFrom)
+		if err != nil {
+			return
+		}
+		authorization = "Bearer " + queryToken
+	default:
+		err = fmt.Errorf("unsupported WWW-Authenticate challenge %q",
+			challenge)
+	}
+	return
+}
+
+// parseAuthChallenge returns the scheme and params of a WWW-Authenticate `challenge`,
+// e.g. `Bearer realm="https://auth.example.com/token",service="registry.example.com"`.
+func parseAuthChallenge(challenge string) (scheme string, params map[string]string) {
+	challenge = strings.TrimSpace(challenge)
+	scheme, rest, _ := strings.Cut(challenge, " ")
+
+	params = make(map[string]string)
+	for _, match := range authChallengeParamRegex.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	return
+}
+
+// refreshRegistryToken gets a query token from the realm of a Bearer challenge with `params`.
+func (d *DockerCheck) refreshRegistryToken(ctx context.Context, params map[string]string, logFrom *util.LogFrom) (queryToken string, err error) {
+	realm := params["realm"]
+	if realm == "" {
+		err = fmt.Errorf("registry bearer challenge has no realm")
+		return
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", d.Image)
+	}
+
+	query := net_url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	separator := "?"
+	if strings.Contains(realm, "?") {
+		separator = "&"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+separator+query.Encode(), nil)
+	if err != nil {
+		err = fmt.Errorf("registry token request, creation failed: %w", err)
+		return
+	}
+	token := d.getToken()
+	if username := util.EvalEnvVars(d.Username); username != "" || token != "" {
+		req.SetBasicAuth(username, token)
+	}
+	req.Header.Set("Connection", "close")
+
+	// Do the request
+	client := &http.Client{
+		Transport: ratelimit.Transport(nil, logFrom),
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("registry token refresh fail: %w", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	// Read the token
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("registry token request failed: %s", body)
+		return
+	}
+	type registryJSON struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	var tokenJSON registryJSON
+	if err = json.Unmarshal(body, &tokenJSON); err != nil {
+		err = fmt.Errorf("registry token request failed: %w", err)
+		return
+	}
+
+	queryToken = tokenJSON.Token
+	if queryToken == "" {
+		queryToken = tokenJSON.AccessToken
+	}
+	// Tokens without an expiry are valid for 60s (https://distribution.github.io/distribution/spec/auth/token/)
+	expiresIn := tokenJSON.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = 60
+	}
+	validUntil := time.Now().UTC().Add(time.Duration(expiresIn) * time.Second)
+	// Give the Token/ValidUntil to this struct
+	d.SetQueryToken(&d.Token, &queryToken, &validUntil)
+	return
+}
@@ -0,0 +1,291 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var testManifestList = `{
+	"schemaVersion": 2,
+	"mediaType": "application/vnd.oci.image.index.v1+json",
+	"manifests": [
+		{"digest": "sha256:aaa", "size": 1234, "platform": {"architecture": "amd64", "os": "linux"}},
+		{"digest": "sha256:bbb", "size": 5678, "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}},
+		{"digest": "sha256:ccc", "platform": {"architecture": "unknown", "os": "unknown"}}
+	]
+}`
+
+// testRegistry returns a registry that requires `scheme` auth as user:pass.
+func testRegistry(t *testing.T, scheme string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("scope") != "repository:release-argus/argus:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"token": "query-token", "expires_in": 300}`)
+		case "/v2/release-argus/argus/manifests/1.2.3":
+			authorized := false
+			switch scheme {
+			case "Bearer":
+				authorized = r.Header.Get("Authorization") == "Bearer query-token"
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="registry.example.com",scope="repository:release-argus/argus:pull"`,
+					server.URL))
+			case "Basic":
+				user, pass, ok := r.BasicAuth()
+				authorized = ok && user == "user" && pass == "pass"
+				w.Header().Set("WWW-Authenticate", `Basic realm="Registry"`)
+			default:
+				authorized = true
+			}
+			if !authorized {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:list")
+			fmt.Fprint(w, testManifestList)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRequire_DockerTagCheck_Registry(t *testing.T) {
+	// GIVEN a Require with a DockerCheck on a generic registry
+	tests := map[string]struct {
+		scheme         string
+		username       string
+		token          string
+		tag            string
+		platforms      []string
+		wantQueryToken string
+		wantDigest     string
+		errRegex       string
+	}{
+		"anonymous": {
+			tag:        "1.2.3",
+			wantDigest: "sha256:list",
+			errRegex:   "^$"},
+		"unknown tag": {
+			tag:      "1.2.4",
+			errRegex: `^release-argus/argus:1.2.4 - .*MANIFEST_UNKNOWN`},
+		"bearer": {
+			scheme:         "Bearer",
+			username:       "user",
+			token:          "pass",
+			tag:            "1.2.3",
+			wantQueryToken: "query-token",
+			wantDigest:     "sha256:list",
+			errRegex:       "^$"},
+		"bearer with invalid credentials": {
+			scheme:   "Bearer",
+			username: "user",
+			token:    "wrong",
+			tag:      "1.2.3",
+			errRegex: `^release-argus/argus:1.2.3 - registry token request failed`},
+		"basic": {
+			scheme:     "Basic",
+			username:   "user",
+			token:      "pass",
+			tag:        "1.2.3",
+			wantDigest: "sha256:list",
+			errRegex:   "^$"},
+		"basic without credentials": {
+			scheme:   "Basic",
+			tag:      "1.2.3",
+			errRegex: `registry requires basic auth, but no username/token were given$`},
+		"platforms found": {
+			tag:        "1.2.3",
+			platforms:  []string{"linux/amd64", "linux/arm64"},
+			wantDigest: "sha256:list",
+			errRegex:   "^$"},
+		"platforms missing": {
+			tag:       "1.2.3",
+			platforms: []string{"linux/amd64", "linux/arm/v7"},
+			errRegex:  `^release-argus/argus:1.2.3 - missing platforms \[linux/arm/v7\] \(has \[linux/amd64, linux/arm64/v8\]\)$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := testRegistry(t, tc.scheme)
+			require := Require{
+				Docker: &DockerCheck{
+					Type:      "registry",
+					Registry:  server.URL + "/",
+					Username:  tc.username,
+					Image:     "release-argus/argus",
+					Tag:       tc.tag,
+					Platforms: tc.platforms,
+					DockerCheckRegistryBase: DockerCheckRegistryBase{
+						Token: tc.token}}}
+
+			// WHEN DockerTagCheck is called on it
+			err := require.DockerTagCheck(context.Background(), "1.2.3", &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the query token is cached
+			gotQueryToken, _ := require.Docker.CopyQueryToken()
+			if gotQueryToken != tc.wantQueryToken {
+				t.Errorf("want queryToken %q, got %q",
+					tc.wantQueryToken, gotQueryToken)
+			}
+			// AND the digest of the image found is recorded
+			var gotDigest string
+			if image := require.Docker.ImageInfo("1.2.3"); image != nil {
+				gotDigest = image.Digest
+			}
+			if gotDigest != tc.wantDigest {
+				t.Errorf("want digest %q, got %q",
+					tc.wantDigest, gotDigest)
+			}
+		})
+	}
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	// GIVEN a WWW-Authenticate challenge
+	tests := map[string]struct {
+		challenge  string
+		wantScheme string
+		wantParams map[string]string
+	}{
+		"empty": {
+			challenge:  "",
+			wantScheme: "",
+			wantParams: map[string]string{}},
+		"basic": {
+			challenge:  `Basic realm="Registry Realm"`,
+			wantScheme: "Basic",
+			wantParams: map[string]string{"realm": "Registry Realm"}},
+		"bearer": {
+			challenge:  `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo/bar:pull,push"`,
+			wantScheme: "Bearer",
+			wantParams: map[string]string{
+				"realm":   "https://auth.example.com/token",
+				"service": "registry.example.com",
+				"scope":   "repository:foo/bar:pull,push"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN parseAuthChallenge is called on it
+			gotScheme, gotParams := parseAuthChallenge(tc.challenge)
+
+			// THEN the scheme and params are parsed
+			if gotScheme != tc.wantScheme {
+				t.Errorf("want scheme %q, got %q",
+					tc.wantScheme, gotScheme)
+			}
+			if fmt.Sprint(gotParams) != fmt.Sprint(tc.wantParams) {
+				t.Errorf("want params %v, got %v",
+					tc.wantParams, gotParams)
+			}
+		})
+	}
+}
+
+func TestDockerCheck_ParseImage(t *testing.T) {
+	// GIVEN the body of a tag query
+	tests := map[string]struct {
+		dType         string
+		contentDigest string
+		body          string
+		want          *util.ImageInfo
+	}{
+		"manifest list": {
+			dType:         "registry",
+			contentDigest: "sha256:list",
+			body:          testManifestList,
+			want: &util.ImageInfo{
+				Digest: "sha256:list",
+				Platforms: []util.ImagePlatformInfo{
+					{Platform: "linux/amd64", Digest: "sha256:aaa", Size: 1234},
+					{Platform: "linux/arm64/v8", Digest: "sha256:bbb", Size: 5678}}}},
+		"single manifest without a Docker-Content-Digest": {
+			dType: "ghcr",
+			body:  `{}`,
+			want: &util.ImageInfo{
+				Digest: "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"}},
+		"docker hub tag": {
+			dType: "hub",
+			body:  `{"name": "1.2.3", "digest": "sha256:hub", "images": [{"architecture": "amd64", "os": "linux", "digest": "sha256:aaa", "size": 100}, {"architecture": "arm", "os": "linux", "variant": "v7", "digest": "sha256:bbb", "size": 200}]}`,
+			want: &util.ImageInfo{
+				Digest: "sha256:hub",
+				Platforms: []util.ImagePlatformInfo{
+					{Platform: "linux/amd64", Digest: "sha256:aaa", Size: 100},
+					{Platform: "linux/arm/v7", Digest: "sha256:bbb", Size: 200}}}},
+		"quay tag": {
+			dType: "quay",
+			body:  `{"tags": [{"name": "1.2.3", "manifest_digest": "sha256:quay", "size": 300}]}`,
+			want: &util.ImageInfo{
+				Digest: "sha256:quay"}},
+		"invalid JSON": {
+			dType: "hub",
+			body:  `{`,
+			want:  &util.ImageInfo{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dockerCheck := DockerCheck{
+				Type:  tc.dType,
+				Image: "release-argus/argus"}
+			tc.want.Image = dockerCheck.Image
+			tc.want.Tag = "1.2.3"
+
+			// WHEN parseImage is called on it
+			got := dockerCheck.parseImage("1.2.3", tc.contentDigest, []byte(tc.body))
+
+			// THEN the image is what we expect
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("want %+v, got %+v",
+					tc.want, got)
+			}
+		})
+	}
+}
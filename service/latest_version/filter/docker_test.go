@@ -17,6 +17,7 @@
 package filter
 
 import (
+	"context"
 	"encoding/base64"
 	"os"
 	"regexp"
@@ -184,7 +185,7 @@ func TestDockerCheck_getQueryToken(t *testing.T) {
 			tc.dockerCheck.queryToken = tc.hadQueryToken
 
 			// WHEN getQueryToken is called on it
-			queryToken, err := tc.dockerCheck.getQueryToken()
+			queryToken, err := tc.dockerCheck.getQueryToken(context.Background(), &util.LogFrom{})
 
 			// THEN the err is what we expect and a queryToken is retrieved when expected
 			if tc.errRegex == "" {
@@ -1576,7 +1577,7 @@ func TestRequire_DockerTagCheck(t *testing.T) {
 			require := Require{Docker: tc.dockerCheck}
 
 			// WHEN DockerTagCheck is called on it
-			err := require.DockerTagCheck("0.9.0")
+			err := require.DockerTagCheck(context.Background(), "0.9.0", &util.LogFrom{})
 
 			// THEN the err is what we expect
 			if tc.errRegex == "" {
@@ -1639,7 +1640,7 @@ func TestDockerCheck_RefreshDockerHubToken(t *testing.T) {
 			}
 
 			// WHEN refreshDockerHubToken is called on it
-			err := tc.dockerCheck.refreshDockerHubToken()
+			err := tc.dockerCheck.refreshDockerHubToken(context.Background(), &util.LogFrom{})
 
 			// THEN the err is what we expect
 			if tc.errRegex == "" {
@@ -1820,6 +1821,55 @@ func TestDockerCheck_CheckValues(t *testing.T) {
 				"1.2.3",
 				"", "", "", time.Now(), nil),
 		},
+		"registry without a registry": {
+			errRegex: "^-registry: <required>",
+			dockerCheck: &DockerCheck{
+				Type:  "registry",
+				Image: "release-argus/argus",
+				Tag:   "1.2.3"},
+		},
+		"registry with an invalid registry": {
+			errRegex: `^-registry: "registry.example.com" <invalid>`,
+			dockerCheck: &DockerCheck{
+				Type:     "registry",
+				Registry: "registry.example.com",
+				Image:    "release-argus/argus",
+				Tag:      "1.2.3"},
+		},
+		"registry with a username but no token": {
+			errRegex: "^-token: <required>",
+			dockerCheck: &DockerCheck{
+				Type:     "registry",
+				Registry: "https://registry.example.com",
+				Username: "test",
+				Image:    "release-argus/argus",
+				Tag:      "1.2.3"},
+		},
+		"valid registry with platforms": {
+			errRegex: "^$",
+			dockerCheck: &DockerCheck{
+				Type:      "registry",
+				Registry:  "https://registry.example.com",
+				Image:     "release-argus/argus",
+				Tag:       "1.2.3",
+				Platforms: []string{"linux/amd64", "linux/arm/v7"}},
+		},
+		"invalid platforms": {
+			errRegex: `^-platforms: "linux" <invalid>.*-platforms: "linux//v7" <invalid>`,
+			dockerCheck: &DockerCheck{
+				Type:      "ghcr",
+				Image:     "release-argus/argus",
+				Tag:       "1.2.3",
+				Platforms: []string{"linux", "linux//v7"}},
+		},
+		"platforms on quay": {
+			errRegex: `^-platforms: <invalid> \(not supported for type quay\)`,
+			dockerCheck: &DockerCheck{
+				Type:      "quay",
+				Image:     "release-argus/argus",
+				Tag:       "1.2.3",
+				Platforms: []string{"linux/amd64"}},
+		},
 	}
 
 	for name, tc := range tests {
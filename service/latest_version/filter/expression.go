@@ -0,0 +1,205 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/util"
+)
+
+// ExpressionCheck returns whether `version` (and its `release`) passes the Expression.
+//
+// The Expression is a boolean (Jinja-style) expression, e.g.
+//
+//	(bump == "minor" and not (prerelease and weekday == "Monday")) or has_asset("arm64")
+//
+// with the variables:
+//
+//	version - the version being checked
+//	semantic - whether the version is a semantic version
+//	major, minor, patch - the parts of the (semantic) version
+//	version_prerelease - the pre-release part of the (semantic) version, e.g. "rc.1"
+//	prerelease, draft - the GitHub release flags (prerelease is also true for semantic pre-releases)
+//	branch, author - the target_commitish and author of the GitHub release
+//	assets - the names of the assets of the GitHub release
+//	published_at - the RFC3339 time the release was published
+//	weekday - the day of the week the release was published, e.g. "Monday"
+//	deployed_version, latest_version - the current deployed/latest versions of the service
+//	bump - "major"/"minor"/"patch"/"prerelease" change from the deployed (or latest) version
+//
+// and functions:
+//
+//	has_asset(regex) - whether any asset name matches the regex
+//	version_matches(constraint) - whether the version satisfies a semver constraint, e.g. ">= 1.2, < 2"
+func (r *Require) ExpressionCheck(
+	version string,
+	release *github_types.Release,
+	logFrom *util.LogFrom,
+) error {
+	if r == nil || r.Expression == "" {
+		return nil
+	}
+
+	pass, err := util.EvalExpression(r.Expression, r.expressionContext(version, release))
+	if err != nil {
+		err = fmt.Errorf("expression %q failed for version %q: %w",
+			r.Expression, version, err)
+		jLog.Error(err, logFrom, true)
+		return err
+	}
+	if !pass {
+		err = fmt.Errorf("expression %q not matched for version %q",
+			r.Expression, version)
+		jLog.Verbose(err, logFrom, true)
+		return err
+	}
+
+	return nil
+}
+
+// expressionContext returns the variables (and functions) an Expression is evaluated with
+// for `version` and its `release`.
+func (r *Require) expressionContext(version string, release *github_types.Release) map[string]interface{} {
+	context := map[string]interface{}{
+		"version":            version,
+		"semantic":           false,
+		"major":              0,
+		"minor":              0,
+		"patch":              0,
+		"version_prerelease": "",
+		"prerelease":         false,
+		"draft":              false,
+		"branch":             "",
+		"author":             "",
+		"assets":             []string{},
+		"published_at":       "",
+		"weekday":            ""}
+
+	// Release
+	if release != nil {
+		context["prerelease"] = release.PreRelease
+		context["draft"] = release.Draft
+		context["branch"] = release.TargetCommitish
+		if release.Author != nil {
+			context["author"] = release.Author.Login
+		}
+		assets := make([]string, len(release.Assets))
+		for i := range release.Assets {
+			assets[i] = release.Assets[i].Name
+		}
+		context["assets"] = assets
+		context["published_at"] = release.PublishedAt
+		if publishedAt, err := time.Parse(time.RFC3339, release.PublishedAt); err == nil {
+			context["weekday"] = publishedAt.UTC().Weekday().String()
+		}
+	}
+
+	// Semantic version
+	var semanticVersion *semver.Version
+	if release != nil && release.SemanticVersion != nil {
+		semanticVersion = release.SemanticVersion
+	} else if parsed, err := semver.NewVersion(version); err == nil {
+		semanticVersion = parsed
+	}
+	if semanticVersion != nil {
+		context["semantic"] = true
+		context["major"] = int(semanticVersion.Major())
+		context["minor"] = int(semanticVersion.Minor())
+		context["patch"] = int(semanticVersion.Patch())
+		context["version_prerelease"] = semanticVersion.Prerelease()
+		if semanticVersion.Prerelease() != "" {
+			context["prerelease"] = true
+		}
+	}
+
+	// Current versions
+	var deployedVersion, latestVersion string
+	if r.Status != nil {
+		deployedVersion = r.Status.DeployedVersion()
+		latestVersion = r.Status.LatestVersion()
+	}
+	context["deployed_version"] = deployedVersion
+	context["latest_version"] = latestVersion
+	fromVersion := deployedVersion
+	if fromVersion == "" {
+		fromVersion = latestVersion
+	}
+	context["bump"] = versionBump(fromVersion, semanticVersion)
+
+	// Functions
+	assets, _ := context["assets"].([]string)
+	context["has_asset"] = func(regex string) bool {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return false
+		}
+		for _, asset := range assets {
+			if re.MatchString(asset) {
+				return true
+			}
+		}
+		return false
+	}
+	context["version_matches"] = func(constraint string) bool {
+		if semanticVersion == nil {
+			return false
+		}
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return false
+		}
+		return c.Check(semanticVersion)
+	}
+
+	return context
+}
+
+// expressionVariables returns the names of the variables (and functions) an Expression can use.
+func expressionVariables() []string {
+	context := (&Require{}).expressionContext("", nil)
+	variables := make([]string, 0, len(context))
+	for variable := range context {
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// versionBump returns the part of the version that changed going from `from` to `to`,
+// "major"/"minor"/"patch"/"prerelease", or "" if `to` isn't newer (or either isn't semantic).
+func versionBump(from string, to *semver.Version) string {
+	if to == nil {
+		return ""
+	}
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil || !to.GreaterThan(fromVersion) {
+		return ""
+	}
+
+	switch {
+	case to.Major() != fromVersion.Major():
+		return "major"
+	case to.Minor() != fromVersion.Minor():
+		return "minor"
+	case to.Patch() != fromVersion.Patch():
+		return "patch"
+	default:
+		return "prerelease"
+	}
+}
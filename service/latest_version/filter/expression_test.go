@@ -0,0 +1,184 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestRequire_ExpressionCheck(t *testing.T) {
+	// GIVEN a Require with an Expression and a release
+	release := &github_types.Release{
+		TagName:         "1.3.0-rc.1",
+		SemanticVersion: semver.MustParse("1.3.0-rc.1"),
+		PublishedAt:     "2024-01-01T12:00:00Z", // Monday
+		TargetCommitish: "main",
+		Author:          &github_types.Author{Login: "octocat"},
+		Assets: []github_types.Asset{
+			{Name: "app_linux_amd64.tar.gz"},
+			{Name: "app_linux_arm64.tar.gz"}}}
+	tests := map[string]struct {
+		expression      string
+		version         string
+		release         *github_types.Release
+		deployedVersion string
+		errRegex        string
+	}{
+		"no expression": {
+			expression: "",
+			errRegex:   `^$`},
+		"version parts": {
+			expression: "major == 1 and minor == 3 and patch == 0",
+			errRegex:   `^$`},
+		"version pre-release": {
+			expression: `prerelease and version_prerelease == "rc.1"`,
+			errRegex:   `^$`},
+		"minor bump from the deployed version": {
+			expression:      `bump == "minor"`,
+			deployedVersion: "1.2.3",
+			errRegex:        `^$`},
+		"major bump from the deployed version": {
+			expression:      `bump == "minor"`,
+			deployedVersion: "0.9.0",
+			errRegex:        `^expression "bump == \\"minor\\"" not matched for version "1.3.0-rc.1"$`},
+		"not a Monday RC": {
+			expression: `not (prerelease and weekday == "Monday")`,
+			errRegex:   `^expression ".*" not matched for version "1.3.0-rc.1"$`},
+		"not a Monday RC, or has an arm64 asset": {
+			expression: `not (prerelease and weekday == "Monday") or has_asset("arm64")`,
+			errRegex:   `^$`},
+		"asset in assets": {
+			expression: `"app_linux_amd64.tar.gz" in assets`,
+			errRegex:   `^$`},
+		"no asset matching": {
+			expression: `has_asset("windows")`,
+			errRegex:   `not matched`},
+		"version constraint": {
+			expression: `version_matches(">= 1.3.0-0") and not version_matches("< 1.3.0-0")`,
+			errRegex:   `^$`},
+		"branch and author": {
+			expression: `branch == "main" and author == "octocat"`,
+			errRegex:   `^$`},
+		"no release": {
+			expression: `semantic and major == 2 and assets|length == 0 and weekday == ""`,
+			version:    "2.0.0",
+			release:    nil,
+			errRegex:   `^$`},
+		"not semantic": {
+			expression: `not semantic and bump == "" and not version_matches(">0")`,
+			version:    "abc",
+			release:    &github_types.Release{TagName: "abc"},
+			errRegex:   `^$`},
+		"evaluation error": {
+			expression: `has_asset(1, 2)`,
+			errRegex:   `^expression "has_asset\(1, 2\)" failed for version "1.3.0-rc.1": `},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status := svcstatus.Status{}
+			status.Init(
+				0, 0, 0,
+				test.StringPtr("test"),
+				test.StringPtr("http://example.com"))
+			status.SetDeployedVersion(tc.deployedVersion, false)
+			require := Require{
+				Status:     &status,
+				Expression: tc.expression}
+			if tc.version == "" {
+				tc.version = release.TagName
+				tc.release = release
+			}
+
+			// WHEN ExpressionCheck is called on it
+			err := require.ExpressionCheck(tc.version, tc.release, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	// GIVEN two versions
+	tests := map[string]struct {
+		from, to string
+		want     string
+	}{
+		"major": {
+			from: "1.2.3", to: "2.0.0",
+			want: "major"},
+		"minor": {
+			from: "1.2.3", to: "1.3.0",
+			want: "minor"},
+		"patch": {
+			from: "1.2.3", to: "1.2.4",
+			want: "patch"},
+		"prerelease": {
+			from: "1.3.0-rc.1", to: "1.3.0-rc.2",
+			want: "prerelease"},
+		"release of a prerelease": {
+			from: "1.3.0-rc.2", to: "1.3.0",
+			want: "prerelease"},
+		"same": {
+			from: "1.2.3", to: "1.2.3",
+			want: ""},
+		"older": {
+			from: "1.2.3", to: "1.2.2",
+			want: ""},
+		"from not semantic": {
+			from: "abc", to: "1.2.3",
+			want: ""},
+		"to not semantic": {
+			from: "1.2.3", to: "",
+			want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var to *semver.Version
+			if tc.to != "" {
+				to = semver.MustParse(tc.to)
+			}
+
+			// WHEN versionBump is called on them
+			got := versionBump(tc.from, to)
+
+			// THEN the bump is what we expect
+			if got != tc.want {
+				t.Errorf("want: %q\ngot:  %q",
+					tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/release-argus/Argus/ratelimit"
+	"github.com/release-argus/Argus/util"
+)
+
+// HTTPCheck will verify that a URL (e.g. a download link) is available for the version.
+type HTTPCheck struct {
+	Method            string         `yaml:"method,omitempty" json:"method,omitempty"`                           // HEAD (default)/GET
+	URL               string         `yaml:"url,omitempty" json:"url,omitempty"`                                 // URL to query, e.g. "https://example.com/app-{{ version }}.tar.gz"
+	AllowInvalidCerts bool           `yaml:"allow_invalid_certs,omitempty" json:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	BasicAuth         *HTTPBasicAuth `yaml:"basic_auth,omitempty" json:"basic_auth,omitempty"`                   // Basic Auth credentials
+	Headers           []HTTPHeader   `yaml:"headers,omitempty" json:"headers,omitempty"`                         // Request Headers
+	StatusCode        int            `yaml:"status_code,omitempty" json:"status_code,omitempty"`                 // Status code expected (default 200)
+	RegexContent      string         `yaml:"regex_content,omitempty" json:"regex_content,omitempty"`             // "{{ version }}" This regex must exist in the body (method:GET only)
+}
+
+// HTTPBasicAuth to use on the HTTPCheck request.
+type HTTPBasicAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// HTTPHeader to use in the HTTPCheck request.
+type HTTPHeader struct {
+	Key   string `yaml:"key" json:"key"`     // Header key, e.g. Authorization
+	Value string `yaml:"value" json:"value"` // Value to give the key
+}
+
+// String returns a string representation of the HTTPCheck.
+func (h *HTTPCheck) String(prefix string) (str string) {
+	if h != nil {
+		str = util.ToYAMLString(h, prefix)
+	}
+	return
+}
+
+// GetMethod of the HTTPCheck (defaults to HEAD).
+func (h *HTTPCheck) GetMethod() string {
+	if h.Method == "" {
+		return http.MethodHead
+	}
+	return strings.ToUpper(h.Method)
+}
+
+// GetStatusCode expected from the HTTPCheck (defaults to 200).
+func (h *HTTPCheck) GetStatusCode() int {
+	if h.StatusCode == 0 {
+		return http.StatusOK
+	}
+	return h.StatusCode
+}
+
+// GetURL of the HTTPCheck for `version`.
+func (h *HTTPCheck) GetURL(version string) string {
+	return util.TemplateString(h.URL, util.ServiceInfo{LatestVersion: version})
+}
+
+// HTTPRequestCheck will verify that the HTTP URL gives the expected status code
+// (and body RegEx) for `version`, returning an error if not.
+func (r *Require) HTTPRequestCheck(ctx context.Context, version string, logFrom *util.LogFrom) error {
+	if r == nil || r.HTTP == nil {
+		return nil
+	}
+
+	url := r.HTTP.GetURL(version)
+	statusCode, body, err := r.HTTP.request(ctx, url, logFrom)
+	if err != nil {
+		err = fmt.Errorf("http %s %q failed for version %q: %w",
+			r.HTTP.GetMethod(), url, version, err)
+		jLog.Warn(err, logFrom, true)
+		return err
+	}
+
+	// Status code
+	if wantStatusCode := r.HTTP.GetStatusCode(); statusCode != wantStatusCode {
+		err = fmt.Errorf("http %s %q gave status %d for version %q, not %d",
+			r.HTTP.GetMethod(), url, statusCode, version, wantStatusCode)
+		jLog.Verbose(err, logFrom, true)
+		return err
+	}
+
+	// Content RegEx
+	if r.HTTP.RegexContent != "" {
+		regexContent := util.TemplateString(r.HTTP.RegexContent, util.ServiceInfo{LatestVersion: version})
+		if !util.RegexCheck(regexContent, string(body)) {
+			err = fmt.Errorf("regex %q not matched on the body of %q for version %q",
+				regexContent, url, version)
+			jLog.Verbose(err, logFrom, true)
+			return err
+		}
+	}
+
+	jLog.Verbose(
+		fmt.Sprintf("http %s %q passed for version %q", r.HTTP.GetMethod(), url, version),
+		logFrom, true)
+	return nil
+}
+
+// request does the HTTP request to `url`, returning the status code and body.
+func (h *HTTPCheck) request(ctx context.Context, url string, logFrom *util.LogFrom) (statusCode int, body []byte, err error) {
+	// HTTPS insecure skip verify.
+	customTransport := &http.Transport{}
+	if h.AllowInvalidCerts {
+		customTransport = http.DefaultTransport.(*http.Transport).Clone()
+		//#nosec G402 -- explicitly wanted InsecureSkipVerify
+		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, h.GetMethod(), url, nil)
+	if err != nil {
+		return
+	}
+	// Set headers
+	req.Header.Set("Connection", "close")
+	for _, header := range h.Headers {
+		req.Header.Set(util.EvalEnvVars(header.Key), util.EvalEnvVars(header.Value))
+	}
+	// Basic auth
+	if h.BasicAuth != nil {
+		req.SetBasicAuth(util.EvalEnvVars(h.BasicAuth.Username), util.EvalEnvVars(h.BasicAuth.Password))
+	}
+
+	// Send the request.
+	client := &http.Client{
+		Transport: ratelimit.Transport(customTransport, logFrom),
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Don't log the whole certificate error.
+		if strings.Contains(err.Error(), "x509") {
+			err = fmt.Errorf("x509 (certificate invalid)")
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	statusCode = resp.StatusCode
+	if h.RegexContent != "" {
+		body, err = io.ReadAll(resp.Body)
+	}
+	return
+}
+
+// CheckValues of the HTTPCheck.
+func (h *HTTPCheck) CheckValues(prefix string) (errs error) {
+	if h == nil {
+		return
+	}
+
+	method := h.GetMethod()
+	if method != http.MethodHead && method != http.MethodGet {
+		errs = fmt.Errorf("%s%smethod: %q <invalid> (only HEAD and GET are supported)\\",
+			util.ErrorToString(errs), prefix, h.Method)
+	} else if h.Method != "" {
+		h.Method = method
+	}
+
+	if h.URL == "" {
+		errs = fmt.Errorf("%s%surl: <required> (URL to check, e.g. 'https://example.com/app-{{ version }}.tar.gz')\\",
+			util.ErrorToString(errs), prefix)
+	} else if !util.CheckTemplate(h.URL) {
+		errs = fmt.Errorf("%s%surl: %q <invalid> (didn't pass templating)\\",
+			util.ErrorToString(errs), prefix, h.URL)
+	}
+
+	if h.StatusCode != 0 && (h.StatusCode < 100 || h.StatusCode > 599) {
+		errs = fmt.Errorf("%s%sstatus_code: %d <invalid> (must be between 100 and 599)\\",
+			util.ErrorToString(errs), prefix, h.StatusCode)
+	}
+
+	if h.RegexContent != "" {
+		if method == http.MethodHead {
+			errs = fmt.Errorf("%s%sregex_content: %q <invalid> (requires method GET)\\",
+				util.ErrorToString(errs), prefix, h.RegexContent)
+		} else if !util.CheckTemplate(h.RegexContent) {
+			errs = fmt.Errorf("%s%sregex_content: %q <invalid> (didn't pass templating)\\",
+				util.ErrorToString(errs), prefix, h.RegexContent)
+		} else if _, err := regexp.Compile(h.RegexContent); err != nil {
+			errs = fmt.Errorf("%s%sregex_content: %q <invalid> (Invalid RegEx)\\",
+				util.ErrorToString(errs), prefix, h.RegexContent)
+		}
+	}
+
+	for i := range h.Headers {
+		if h.Headers[i].Key == "" {
+			errs = fmt.Errorf("%s%sheaders: item_%d.key: <required>\\",
+				util.ErrorToString(errs), prefix, i)
+		}
+	}
+
+	return
+}
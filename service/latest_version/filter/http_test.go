@@ -0,0 +1,243 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// testHTTPServer returns a server that has the app-1.2.3.tar.gz artifact,
+// and requires user:pass basic auth on /private/.
+func testHTTPServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/private/") {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if r.Header.Get("X-Required") == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/private") {
+		case "/app-1.2.3.tar.gz":
+			fmt.Fprint(w, "app v1.2.3 contents")
+		case "/moved":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRequire_HTTPRequestCheck(t *testing.T) {
+	// GIVEN a Require with an HTTPCheck
+	tests := map[string]struct {
+		http     *HTTPCheck
+		path     string
+		errRegex string
+	}{
+		"nil HTTPCheck": {
+			http:     nil,
+			errRegex: "^$"},
+		"HEAD, artifact available": {
+			http:     &HTTPCheck{},
+			path:     "/app-{{ version }}.tar.gz",
+			errRegex: "^$"},
+		"HEAD, artifact not available": {
+			http:     &HTTPCheck{},
+			path:     "/app-{{ version }}.zip",
+			errRegex: `^http HEAD ".*/app-1.2.3.zip" gave status 404 for version "1.2.3", not 200$`},
+		"non-default status code": {
+			http:     &HTTPCheck{StatusCode: http.StatusNoContent},
+			path:     "/moved",
+			errRegex: "^$"},
+		"GET, regex_content matched": {
+			http: &HTTPCheck{
+				Method:       "GET",
+				RegexContent: `v{{ version }}\b`},
+			path:     "/app-{{ version }}.tar.gz",
+			errRegex: "^$"},
+		"GET, regex_content not matched": {
+			http: &HTTPCheck{
+				Method:       "GET",
+				RegexContent: `v{{ version }}-beta`},
+			path:     "/app-{{ version }}.tar.gz",
+			errRegex: `^regex "v1.2.3-beta" not matched on the body of ".*/app-1.2.3.tar.gz" for version "1.2.3"$`},
+		"basic auth": {
+			http: &HTTPCheck{
+				BasicAuth: &HTTPBasicAuth{Username: "user", Password: "pass"}},
+			path:     "/private/app-{{ version }}.tar.gz",
+			errRegex: "^$"},
+		"basic auth, invalid credentials": {
+			http: &HTTPCheck{
+				BasicAuth: &HTTPBasicAuth{Username: "user", Password: "wrong"}},
+			path:     "/private/app-{{ version }}.tar.gz",
+			errRegex: `gave status 401 for version "1.2.3", not 200$`},
+		"headers are sent": {
+			http: &HTTPCheck{
+				Headers: []HTTPHeader{{Key: "X-Required", Value: "missing"}}},
+			path:     "/app-{{ version }}.tar.gz",
+			errRegex: `gave status 400 for version "1.2.3", not 200$`},
+		"request fails": {
+			http:     &HTTPCheck{URL: "http://localhost:0/app.tar.gz"},
+			errRegex: `^http HEAD "http://localhost:0/app.tar.gz" failed for version "1.2.3": `},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := testHTTPServer(t)
+			if tc.http != nil && tc.http.URL == "" {
+				tc.http.URL = server.URL + tc.path
+			}
+			require := Require{HTTP: tc.http}
+
+			// WHEN HTTPRequestCheck is called on it
+			err := require.HTTPRequestCheck(context.Background(), "1.2.3", &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestHTTPCheck_CheckValues(t *testing.T) {
+	// GIVEN an HTTPCheck
+	tests := map[string]struct {
+		http       *HTTPCheck
+		wantMethod string
+		errRegex   []string
+	}{
+		"nil": {
+			http:     nil,
+			errRegex: []string{`^$`}},
+		"valid": {
+			http: &HTTPCheck{
+				Method:       "get",
+				URL:          "https://example.com/app-{{ version }}.tar.gz",
+				StatusCode:   http.StatusNoContent,
+				RegexContent: `v{{ version }}`,
+				Headers:      []HTTPHeader{{Key: "X-Foo", Value: "bar"}}},
+			wantMethod: "GET",
+			errRegex:   []string{`^$`}},
+		"invalid method": {
+			http: &HTTPCheck{
+				Method: "POST",
+				URL:    "https://example.com"},
+			wantMethod: "POST",
+			errRegex: []string{
+				`^method: "POST" <invalid>`}},
+		"no url": {
+			http: &HTTPCheck{},
+			errRegex: []string{
+				`^url: <required>`}},
+		"url fails templating": {
+			http: &HTTPCheck{
+				URL: "https://example.com/{{ version }"},
+			errRegex: []string{
+				`^url: "[^"]+" <invalid> \(didn't pass templating\)`}},
+		"invalid status_code": {
+			http: &HTTPCheck{
+				URL:        "https://example.com",
+				StatusCode: 999},
+			errRegex: []string{
+				`^status_code: 999 <invalid>`}},
+		"regex_content with HEAD": {
+			http: &HTTPCheck{
+				URL:          "https://example.com",
+				RegexContent: "foo"},
+			errRegex: []string{
+				`^regex_content: "foo" <invalid> \(requires method GET\)`}},
+		"invalid regex_content": {
+			http: &HTTPCheck{
+				Method:       "GET",
+				URL:          "https://example.com",
+				RegexContent: "[0-"},
+			wantMethod: "GET",
+			errRegex: []string{
+				`^regex_content: "\[0-" <invalid> \(Invalid RegEx\)`}},
+		"header without key": {
+			http: &HTTPCheck{
+				URL:     "https://example.com",
+				Headers: []HTTPHeader{{Key: "X-Foo"}, {Value: "bar"}}},
+			errRegex: []string{
+				`^headers: item_1.key: <required>`}},
+		"all errs": {
+			http: &HTTPCheck{
+				Method:       "PUT",
+				StatusCode:   1,
+				RegexContent: "[0-",
+				Headers:      []HTTPHeader{{}}},
+			wantMethod: "PUT",
+			errRegex: []string{
+				`^method: "PUT" <invalid>`,
+				`^url: <required>`,
+				`^status_code: 1 <invalid>`,
+				`^regex_content: "\[0-" <invalid> \(Invalid RegEx\)`,
+				`^headers: item_0.key: <required>`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN CheckValues is called on it
+			err := tc.http.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND the method is uppercased
+			if tc.http != nil && tc.http.Method != tc.wantMethod {
+				t.Errorf("want method %q, got %q",
+					tc.wantMethod, tc.http.Method)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// GetMinAge returns the MinAge as a time.Duration.
+func (r *Require) GetMinAge() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	d, _ := time.ParseDuration(r.MinAge)
+	return d
+}
+
+// EligibleAt returns the time that `version` will pass the MinAge requirement.
+//
+// `publishedAt` is the RFC3339 time the release was published, and if that's empty/invalid,
+// the time that Argus first saw this version is used instead.
+func (r *Require) EligibleAt(version string, publishedAt string) time.Time {
+	published, err := time.Parse(time.RFC3339, publishedAt)
+	if err != nil {
+		published = r.FirstSeenAt(version)
+	}
+
+	return published.Add(r.GetMinAge()).UTC()
+}
+
+// FirstSeenAt returns the time that `version` was first seen, recording now if it hasn't been seen before.
+//
+// The first seen time of the Status' PendingVersion is kept in the database, so is carried over restarts.
+func (r *Require) FirstSeenAt(version string) time.Time {
+	r.firstSeenMutex.Lock()
+	defer r.firstSeenMutex.Unlock()
+
+	if r.firstSeen == nil {
+		r.firstSeen = make(map[string]time.Time, 1)
+	}
+	seen, exists := r.firstSeen[version]
+	if !exists {
+		seen = time.Now().UTC()
+		// Seen before a restart.
+		if r.Status != nil && r.Status.PendingVersion() == version {
+			if pendingSeen, err := time.Parse(time.RFC3339, r.Status.PendingVersionSeen()); err == nil {
+				seen = pendingSeen.UTC()
+			}
+		}
+		r.firstSeen[version] = seen
+	}
+	return seen
+}
+
+// MinAgeCheck returns an error if `version` has not been published (or seen) for at least MinAge.
+//
+// Versions that are already the latest/deployed version are always considered old enough.
+func (r *Require) MinAgeCheck(
+	version string,
+	publishedAt string,
+	logFrom *util.LogFrom,
+) error {
+	if r == nil || r.MinAge == "" {
+		return nil
+	}
+
+	// Already acting on this version.
+	if r.Status != nil &&
+		(version == r.Status.LatestVersion() || version == r.Status.DeployedVersion()) {
+		return nil
+	}
+
+	eligibleAt := r.EligibleAt(version, publishedAt)
+	if time.Now().UTC().Before(eligibleAt) {
+		err := fmt.Errorf("min_age %s not reached for version %q (eligible at %s)",
+			r.MinAge, version, eligibleAt.Format(time.RFC3339))
+		jLog.Verbose(err, logFrom, true)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestRequire_EligibleAt(t *testing.T) {
+	// GIVEN a Require with a MinAge
+	tests := map[string]struct {
+		minAge      string
+		publishedAt string
+		firstSeen   *time.Time
+		want        *time.Time
+		wantNearNow bool
+	}{
+		"uses publishedAt": {
+			minAge:      "1h",
+			publishedAt: "2020-01-01T00:00:00Z",
+			want:        timePtr(time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC))},
+		"falls back to firstSeen when no publishedAt": {
+			minAge:    "2h",
+			firstSeen: timePtr(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+			want:      timePtr(time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC))},
+		"falls back to firstSeen when publishedAt is invalid": {
+			minAge:      "2h",
+			publishedAt: "yesterday",
+			firstSeen:   timePtr(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+			want:        timePtr(time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC))},
+		"first time seen is now": {
+			minAge:      "0s",
+			wantNearNow: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require := &Require{MinAge: tc.minAge}
+			if tc.firstSeen != nil {
+				require.firstSeen = map[string]time.Time{
+					"1.2.3": *tc.firstSeen}
+			}
+
+			// WHEN EligibleAt is called on it
+			got := require.EligibleAt("1.2.3", tc.publishedAt)
+
+			// THEN the time is as expected
+			if tc.wantNearNow {
+				if time.Since(got) > time.Second || time.Since(got) < 0 {
+					t.Errorf("want ~%s, got %s",
+						time.Now().UTC(), got)
+				}
+				// AND the version is recorded as first seen
+				if _, exists := require.firstSeen["1.2.3"]; !exists {
+					t.Errorf("version wasn't recorded as seen")
+				}
+				return
+			}
+			if !got.Equal(*tc.want) {
+				t.Errorf("want %s, got %s",
+					*tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRequire_FirstSeenAt(t *testing.T) {
+	// GIVEN a Require whose Status may have a PendingVersion from before a restart
+	tests := map[string]struct {
+		pendingVersion, pendingSeen string
+		want                        *time.Time
+	}{
+		"no pending version - now": {},
+		"pending version - seen from the Status": {
+			pendingVersion: "1.2.3",
+			pendingSeen:    "2021-01-01T00:00:00Z",
+			want:           timePtr(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))},
+		"other pending version - now": {
+			pendingVersion: "1.2.4",
+			pendingSeen:    "2021-01-01T00:00:00Z"},
+		"pending version with invalid seen - now": {
+			pendingVersion: "1.2.3",
+			pendingSeen:    "yesterday"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require := &Require{
+				MinAge: "1h",
+				Status: &svcstatus.Status{}}
+			require.Status.SetPendingVersionSeen(tc.pendingVersion, tc.pendingSeen)
+
+			// WHEN FirstSeenAt is called on it
+			got := require.FirstSeenAt("1.2.3")
+
+			// THEN the time is as expected
+			if tc.want == nil {
+				if time.Since(got) > time.Second || time.Since(got) < 0 {
+					t.Errorf("want ~%s, got %s",
+						time.Now().UTC(), got)
+				}
+			} else if !got.Equal(*tc.want) {
+				t.Errorf("want %s, got %s",
+					*tc.want, got)
+			}
+			// AND it's the same time when called again
+			if again := require.FirstSeenAt("1.2.3"); !again.Equal(got) {
+				t.Errorf("want %s again, got %s",
+					got, again)
+			}
+		})
+	}
+}
+
+func TestRequire_MinAgeCheck(t *testing.T) {
+	// GIVEN a Require with a MinAge
+	tests := map[string]struct {
+		require       *Require
+		publishedAt   string
+		latestVersion string
+		errRegex      string
+	}{
+		"nil require": {
+			require:  nil,
+			errRegex: "^$"},
+		"no min_age": {
+			require:     &Require{},
+			publishedAt: time.Now().UTC().Format(time.RFC3339),
+			errRegex:    "^$"},
+		"published long enough ago": {
+			require:     &Require{MinAge: "1h"},
+			publishedAt: time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339),
+			errRegex:    "^$"},
+		"published too recently": {
+			require:     &Require{MinAge: "1h"},
+			publishedAt: time.Now().UTC().Add(-30 * time.Minute).Format(time.RFC3339),
+			errRegex:    `^min_age 1h not reached for version "1.2.3" \(eligible at [^)]+\)$`},
+		"never seen before": {
+			require:  &Require{MinAge: "1h"},
+			errRegex: `^min_age 1h not reached for version "1.2.3"`},
+		"already the latest version": {
+			require:       &Require{MinAge: "1h"},
+			publishedAt:   time.Now().UTC().Format(time.RFC3339),
+			latestVersion: "1.2.3",
+			errRegex:      "^$"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.require != nil {
+				tc.require.Status = &svcstatus.Status{}
+				tc.require.Status.SetLatestVersion(tc.latestVersion, false)
+			}
+
+			// WHEN MinAgeCheck is called on it
+			err := tc.require.MinAgeCheck("1.2.3", tc.publishedAt, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			match := re.MatchString(e)
+			if !match {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
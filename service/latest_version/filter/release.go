@@ -0,0 +1,66 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/util"
+)
+
+// ReleaseCheck returns whether the GitHub `release` of `version` passes the
+// IgnoreDrafts, RegexBranch and RegexAuthor requirements.
+func (r *Require) ReleaseCheck(
+	version string,
+	release *github_types.Release,
+	logFrom *util.LogFrom,
+) error {
+	if r == nil || release == nil {
+		return nil
+	}
+
+	// Draft
+	if r.IgnoreDrafts && release.Draft {
+		err := fmt.Errorf("release %q is a draft",
+			version)
+		jLog.Verbose(err, logFrom, true)
+		return err
+	}
+
+	// Branch RegEx
+	if r.RegexBranch != "" && !util.RegexCheck(r.RegexBranch, release.TargetCommitish) {
+		err := fmt.Errorf("regex %q not matched on branch %q for version %q",
+			r.RegexBranch, release.TargetCommitish, version)
+		jLog.Verbose(err, logFrom, true)
+		return err
+	}
+
+	// Author RegEx
+	if r.RegexAuthor != "" {
+		var author string
+		if release.Author != nil {
+			author = release.Author.Login
+		}
+		if !util.RegexCheck(r.RegexAuthor, author) {
+			err := fmt.Errorf("regex %q not matched on author %q for version %q",
+				r.RegexAuthor, author, version)
+			jLog.Verbose(err, logFrom, true)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"regexp"
+	"testing"
+
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestRequire_ReleaseCheck(t *testing.T) {
+	// GIVEN a Require and a GitHub Release
+	release := &github_types.Release{
+		TagName:         "1.2.3",
+		Draft:           true,
+		TargetCommitish: "main",
+		Author:          &github_types.Author{Login: "octocat"}}
+	tests := map[string]struct {
+		require  *Require
+		release  *github_types.Release
+		errRegex string
+	}{
+		"nil require": {
+			require:  nil,
+			release:  release,
+			errRegex: "^$"},
+		"nil release": {
+			require:  &Require{IgnoreDrafts: true},
+			release:  nil,
+			errRegex: "^$"},
+		"no requirements": {
+			require:  &Require{},
+			release:  release,
+			errRegex: "^$"},
+		"ignore_drafts on a draft": {
+			require:  &Require{IgnoreDrafts: true},
+			release:  release,
+			errRegex: `^release "1.2.3" is a draft$`},
+		"ignore_drafts on a published release": {
+			require: &Require{IgnoreDrafts: true},
+			release: &github_types.Release{
+				TagName: "1.2.3"},
+			errRegex: "^$"},
+		"regex_branch match": {
+			require:  &Require{RegexBranch: "^main$"},
+			release:  release,
+			errRegex: "^$"},
+		"regex_branch no match": {
+			require:  &Require{RegexBranch: "^release/"},
+			release:  release,
+			errRegex: `^regex "\^release/" not matched on branch "main" for version "1.2.3"$`},
+		"regex_author match": {
+			require:  &Require{RegexAuthor: "^octo"},
+			release:  release,
+			errRegex: "^$"},
+		"regex_author no match": {
+			require:  &Require{RegexAuthor: "^bot$"},
+			release:  release,
+			errRegex: `^regex "\^bot\$" not matched on author "octocat" for version "1.2.3"$`},
+		"regex_author with no author": {
+			require: &Require{RegexAuthor: "^bot$"},
+			release: &github_types.Release{
+				TagName: "1.2.3"},
+			errRegex: `not matched on author "" for version`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN ReleaseCheck is called on it
+			err := tc.require.ReleaseCheck("1.2.3", tc.release, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			match := re.MatchString(e)
+			if !match {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
@@ -18,7 +18,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	command "github.com/release-argus/Argus/commands"
 	svcstatus "github.com/release-argus/Argus/service/status"
@@ -70,7 +73,17 @@ type Require struct {
 	RegexContent string            `yaml:"regex_content,omitempty" json:"regex_content,omitempty"` // "abc-[a-z]+-{{ version }}_amd64.deb" This regex must exist in the body of the URL to trigger new version actions
 	RegexVersion string            `yaml:"regex_version,omitempty" json:"regex_version,omitempty"` // "v*[0-9.]+" The version found must match this release to trigger new version actions
 	Command      command.Command   `yaml:"command,omitempty" json:"command,omitempty"`             // Require Command to pass
+	Expression   string            `yaml:"expression,omitempty" json:"expression,omitempty"`       // `bump == "minor" or has_asset("arm64")` This expression must be true for the release
 	Docker       *DockerCheck      `yaml:"docker,omitempty" json:"docker,omitempty"`               // Docker image tag requirements
+	HTTP         *HTTPCheck        `yaml:"http,omitempty" json:"http,omitempty"`                   // HTTP URL that must be available (e.g. a download)
+	Verify       *VerifyCheck      `yaml:"verify,omitempty" json:"verify,omitempty"`               // Artifact that must match its checksum (and signature)
+	MinAge       string            `yaml:"min_age,omitempty" json:"min_age,omitempty"`             // AhBmCs = Release must have been published/seen for A hours, B minutes and C seconds
+	IgnoreDrafts bool              `yaml:"ignore_drafts,omitempty" json:"ignore_drafts,omitempty"` // type:github - Ignore releases that are drafts
+	RegexBranch  string            `yaml:"regex_branch,omitempty" json:"regex_branch,omitempty"`   // type:github - "^main$" The target_commitish of the release must match this RegEx
+	RegexAuthor  string            `yaml:"regex_author,omitempty" json:"regex_author,omitempty"`   // type:github - "^octocat$" The login of the release author must match this RegEx
+
+	firstSeen      map[string]time.Time // Time each version was first seen (for MinAge when no publish time is known)
+	firstSeenMutex sync.Mutex           // Mutex for firstSeen
 }
 
 // String returns a string representation of the Require.
@@ -123,6 +136,24 @@ func (r *Require) CheckValues(prefix string) (errs error) {
 		}
 	}
 
+	// Branch RegEx
+	if r.RegexBranch != "" {
+		_, err := regexp.Compile(r.RegexBranch)
+		if err != nil {
+			errs = fmt.Errorf("%s%s  regex_branch: %q <invalid> (Invalid RegEx)\\",
+				util.ErrorToString(errs), prefix, r.RegexBranch)
+		}
+	}
+
+	// Author RegEx
+	if r.RegexAuthor != "" {
+		_, err := regexp.Compile(r.RegexAuthor)
+		if err != nil {
+			errs = fmt.Errorf("%s%s  regex_author: %q <invalid> (Invalid RegEx)\\",
+				util.ErrorToString(errs), prefix, r.RegexAuthor)
+		}
+	}
+
 	for i := range r.Command {
 		if !util.CheckTemplate(r.Command[i]) {
 			errs = fmt.Errorf("%s%s  command: %v (%q) <invalid> (didn't pass templating)\\",
@@ -131,11 +162,41 @@ func (r *Require) CheckValues(prefix string) (errs error) {
 		}
 	}
 
+	// Expression
+	if r.Expression != "" {
+		if err := util.CheckExpression(r.Expression, expressionVariables()); err != nil {
+			errs = fmt.Errorf("%s%s  expression: %q <invalid> (%s)\\",
+				util.ErrorToString(errs), prefix, r.Expression, err)
+		}
+	}
+
+	// Min Age
+	if r.MinAge != "" {
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(r.MinAge); err == nil {
+			r.MinAge += "s"
+		}
+		if _, err := time.ParseDuration(r.MinAge); err != nil {
+			errs = fmt.Errorf("%s%s  min_age: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, r.MinAge)
+		}
+	}
+
 	if err := r.Docker.CheckValues(prefix + "    "); err != nil {
 		errs = fmt.Errorf("%s%s  docker:\\%w",
 			util.ErrorToString(errs), prefix, err)
 	}
 
+	if err := r.HTTP.CheckValues(prefix + "    "); err != nil {
+		errs = fmt.Errorf("%s%s  http:\\%w",
+			util.ErrorToString(errs), prefix, err)
+	}
+
+	if err := r.Verify.CheckValues(prefix + "    "); err != nil {
+		errs = fmt.Errorf("%s%s  verify:\\%w",
+			util.ErrorToString(errs), prefix, err)
+	}
+
 	if errs != nil {
 		errs = fmt.Errorf("%srequire:\\%s",
 			prefix, util.ErrorToString(errs))
@@ -177,6 +238,29 @@ func RequireFromStr(jsonStr *string, previous *Require, logFrom *util.LogFrom) (
 		if !util.Contains(jsonKeys, "command") {
 			require.Command = previous.Command
 		}
+		if !util.Contains(jsonKeys, "expression") {
+			require.Expression = previous.Expression
+		}
+
+		if !util.Contains(jsonKeys, "min_age") {
+			require.MinAge = previous.MinAge
+		}
+
+		if !util.Contains(jsonKeys, "ignore_drafts") {
+			require.IgnoreDrafts = previous.IgnoreDrafts
+		}
+		if !util.Contains(jsonKeys, "regex_branch") {
+			require.RegexBranch = previous.RegexBranch
+		}
+		if !util.Contains(jsonKeys, "regex_author") {
+			require.RegexAuthor = previous.RegexAuthor
+		}
+		if !util.Contains(jsonKeys, "http") {
+			require.HTTP = previous.HTTP
+		}
+		if !util.Contains(jsonKeys, "verify") {
+			require.Verify = previous.Verify
+		}
 
 		// Default the Docker params
 		if previous.Docker != nil {
@@ -194,9 +278,16 @@ func RequireFromStr(jsonStr *string, previous *Require, logFrom *util.LogFrom) (
 					require.Docker.Image = previous.Docker.Image
 					sameDockerImageAndCredentials++
 				}
+				if !util.Contains(jsonKeys, "docker.registry") {
+					require.Docker.Registry = previous.Docker.Registry
+					sameDockerImageAndCredentials++
+				}
 				if !util.Contains(jsonKeys, "docker.tag") {
 					require.Docker.Tag = previous.Docker.Tag
 				}
+				if !util.Contains(jsonKeys, "docker.platforms") {
+					require.Docker.Platforms = previous.Docker.Platforms
+				}
 				if !util.Contains(jsonKeys, "docker.username") {
 					require.Docker.Username = previous.Docker.Username
 					sameDockerImageAndCredentials++
@@ -206,7 +297,7 @@ func RequireFromStr(jsonStr *string, previous *Require, logFrom *util.LogFrom) (
 					sameDockerImageAndCredentials++
 				}
 
-				if sameDockerImageAndCredentials == 4 {
+				if sameDockerImageAndCredentials == 5 {
 					require.Docker.queryToken = previous.Docker.queryToken
 					require.Docker.validUntil = previous.Docker.validUntil
 				}
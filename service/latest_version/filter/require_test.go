@@ -178,6 +178,25 @@ func TestRequire_CheckValues(t *testing.T) {
 				`^require:$`,
 				`^  regex_content: .* <invalid>.*templating`},
 		},
+		"valid expression": {
+			require: &Require{
+				Expression: `bump == "minor" or has_asset("arm64")`},
+			errRegex: []string{`^$`},
+		},
+		"invalid expression": {
+			require: &Require{
+				Expression: `bump ==`},
+			errRegex: []string{
+				`^require:$`,
+				`^  expression: "bump ==" <invalid> \(col 6 near "==": .*\)$`},
+		},
+		"expression with unknown variable": {
+			require: &Require{
+				Expression: `relase.prerelease or draft`},
+			errRegex: []string{
+				`^require:$`,
+				`^  expression: .* <invalid> \(unknown variable "relase"\)$`},
+		},
 		"valid regex_version": {
 			require: &Require{
 				RegexVersion: "[0-9]"},
@@ -221,10 +240,45 @@ func TestRequire_CheckValues(t *testing.T) {
 				`^  docker:$`,
 				`^    type: .* <invalid>`},
 		},
+		"valid min_age": {
+			require: &Require{
+				MinAge: "1h30m"},
+			errRegex: []string{`^$`},
+		},
+		"invalid min_age": {
+			require: &Require{
+				MinAge: "1 day"},
+			errRegex: []string{
+				`^require:$`,
+				`^  min_age: "1 day" <invalid>`},
+		},
+		"valid regex_branch and regex_author": {
+			require: &Require{
+				RegexBranch: "^main$",
+				RegexAuthor: "^octocat$"},
+			errRegex: []string{`^$`},
+		},
+		"invalid regex_branch": {
+			require: &Require{
+				RegexBranch: "[0-"},
+			errRegex: []string{
+				`^require:$`,
+				`^  regex_branch: "\[0-" <invalid>`},
+		},
+		"invalid regex_author": {
+			require: &Require{
+				RegexAuthor: "[0-"},
+			errRegex: []string{
+				`^require:$`,
+				`^  regex_author: "\[0-" <invalid>`},
+		},
 		"all possible errors": {
 			require: &Require{
 				RegexContent: "[0-",
 				RegexVersion: "[0-",
+				RegexBranch:  "[0-",
+				RegexAuthor:  "[0-",
+				MinAge:       "foo",
 				Docker: NewDockerCheck(
 					"foo",
 					"", "", "", "", "", time.Now(), nil)},
@@ -232,6 +286,9 @@ func TestRequire_CheckValues(t *testing.T) {
 				`^require:$`,
 				`^  regex_content: .* <invalid>`,
 				`^  regex_version: .* <invalid>`,
+				`^  regex_branch: .* <invalid>`,
+				`^  regex_author: .* <invalid>`,
+				`^  min_age: "foo" <invalid>`,
 				`^  docker:$`,
 				`^    type: .* <invalid>`},
 		},
@@ -327,6 +384,16 @@ func TestRequire_FromStr(t *testing.T) {
 			want: &Require{
 				RegexVersion: "foo"},
 		},
+		"release metadata filters defined": {
+			jsonStr: test.StringPtr(`{
+				"ignore_drafts": true,
+				"regex_branch": "^main$",
+				"regex_author": "^octocat$"}`),
+			want: &Require{
+				IgnoreDrafts: true,
+				RegexBranch:  "^main$",
+				RegexAuthor:  "^octocat$"},
+		},
 		"RegexContent from str, RegexVersion from default": {
 			jsonStr: test.StringPtr(`{
 				"regex_content": "foo"}`),
@@ -597,7 +664,7 @@ func TestRequire_FromStr(t *testing.T) {
 				}
 				if got.String() != tc.want.String() {
 					t.Errorf("\nwant: %v\ngot:  %v",
-						*tc.want, *got)
+						tc.want.String(), got.String())
 				}
 
 				//(pointer to the default if jsonStr was invalid)
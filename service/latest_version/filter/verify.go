@@ -0,0 +1,331 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	net_url "net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/release-argus/Argus/ratelimit"
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	// verifySignatureTypes that can be used.
+	verifySignatureTypes = []string{"minisign", "cosign"}
+	// verifyMaxChecksumsSize is the max size of the checksums and signature files.
+	verifyMaxChecksumsSize int64 = 1 << 20
+	// verifyTimeout is the time a download can take (longer than util.HTTPTimeout, as artifacts can be large).
+	verifyTimeout = 10 * time.Minute
+)
+
+// VerifyCheck will download the artifact of a version and verify it against
+// the checksums file (and a detached signature of that file).
+type VerifyCheck struct {
+	URL               string `yaml:"url,omitempty" json:"url,omitempty"`                                 // Artifact URL, e.g. "https://example.com/app-{{ version }}.tar.gz"
+	ChecksumsURL      string `yaml:"checksums_url,omitempty" json:"checksums_url,omitempty"`             // SHA256SUMS URL, e.g. "https://example.com/{{ version }}/SHA256SUMS"
+	Filename          string `yaml:"filename,omitempty" json:"filename,omitempty"`                       // Name of the artifact in the checksums file (default: the filename of the URL)
+	SignatureURL      string `yaml:"signature_url,omitempty" json:"signature_url,omitempty"`             // Detached signature of the checksums file, e.g. "https://example.com/{{ version }}/SHA256SUMS.minisig"
+	SignatureType     string `yaml:"signature_type,omitempty" json:"signature_type,omitempty"`           // minisign (default)/cosign
+	PublicKey         string `yaml:"public_key,omitempty" json:"public_key,omitempty"`                   // Public key to verify the signature with (minisign key, or cosign PEM)
+	AllowInvalidCerts bool   `yaml:"allow_invalid_certs,omitempty" json:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+
+	verified map[string]verifyResult // Results of the versions verified, so they're only downloaded once
+	mutex    sync.RWMutex            // Mutex for verified
+}
+
+// verifyResult is the outcome of verifying a version.
+type verifyResult struct {
+	artifact *util.ArtifactInfo
+	err      error
+}
+
+// String returns a string representation of the VerifyCheck.
+func (v *VerifyCheck) String(prefix string) (str string) {
+	if v != nil {
+		str = util.ToYAMLString(v, prefix)
+	}
+	return
+}
+
+// GetSignatureType of the VerifyCheck (defaults to minisign).
+func (v *VerifyCheck) GetSignatureType() string {
+	if v.SignatureType == "" {
+		return "minisign"
+	}
+	return v.SignatureType
+}
+
+// GetFilename of the artifact to look for in the checksums file for `version`.
+func (v *VerifyCheck) GetFilename(version string) string {
+	if v.Filename != "" {
+		return util.TemplateString(v.Filename, util.ServiceInfo{LatestVersion: version})
+	}
+
+	url := util.TemplateString(v.URL, util.ServiceInfo{LatestVersion: version})
+	if parsed, err := net_url.Parse(url); err == nil {
+		url = parsed.Path
+	}
+	return path.Base(url)
+}
+
+// ArtifactInfo returns the artifact verified for `version` (nil if it hasn't passed verification).
+func (v *VerifyCheck) ArtifactInfo(version string) *util.ArtifactInfo {
+	if v == nil {
+		return nil
+	}
+
+	result, _ := v.result(version)
+	return result.artifact
+}
+
+// VerifyCheck will download the artifact of `version` and verify its SHA256 against the
+// checksums file (after verifying the signature of that, if wanted), returning an error if not.
+func (r *Require) VerifyCheck(ctx context.Context, version string, logFrom *util.LogFrom) error {
+	if r == nil || r.Verify == nil {
+		return nil
+	}
+
+	if result, cached := r.Verify.result(version); cached {
+		return result.err
+	}
+
+	artifact, downloaded, err := r.Verify.verify(ctx, version, logFrom)
+	// Only remember the results that weren't from a failed download.
+	if downloaded {
+		r.Verify.setResult(version, verifyResult{artifact: artifact, err: err})
+	}
+	if err != nil {
+		jLog.Warn(err, logFrom, true)
+		return err
+	}
+
+	jLog.Verbose(
+		fmt.Sprintf("verify %q passed for version %q (sha256:%s)",
+			artifact.URL, version, artifact.SHA256),
+		logFrom, true)
+	return nil
+}
+
+// result of verifying `version`, and whether it has been verified before.
+func (v *VerifyCheck) result(version string) (result verifyResult, cached bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	result, cached = v.verified[version]
+	return
+}
+
+// setResult of verifying `version`.
+func (v *VerifyCheck) setResult(version string, result verifyResult) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.verified == nil {
+		v.verified = make(map[string]verifyResult, 1)
+	}
+	v.verified[version] = result
+}
+
+// verify does the verification of the artifact of `version`,
+// returning whether everything needed was downloaded.
+func (v *VerifyCheck) verify(ctx context.Context, version string, logFrom *util.LogFrom) (*util.ArtifactInfo, bool, error) {
+	serviceInfo := util.ServiceInfo{LatestVersion: version}
+	checksumsURL := util.TemplateString(v.ChecksumsURL, serviceInfo)
+	checksums, err := v.download(ctx, checksumsURL, verifyMaxChecksumsSize, logFrom)
+	if err != nil {
+		return nil, false, fmt.Errorf("verify %q failed for version %q: %w",
+			checksumsURL, version, err)
+	}
+
+	// Signature of the checksums file.
+	var signatureType string
+	if v.SignatureURL != "" {
+		signatureType = v.GetSignatureType()
+		signatureURL := util.TemplateString(v.SignatureURL, serviceInfo)
+		signature, err := v.download(ctx, signatureURL, verifyMaxChecksumsSize, logFrom)
+		if err != nil {
+			return nil, false, fmt.Errorf("verify %q failed for version %q: %w",
+				signatureURL, version, err)
+		}
+		if err := verifySignature(signatureType, v.PublicKey, checksums, signature); err != nil {
+			return nil, true, fmt.Errorf("%s signature of %q failed verification for version %q: %w",
+				signatureType, checksumsURL, version, err)
+		}
+	}
+
+	// Checksum of the artifact.
+	filename := v.GetFilename(version)
+	wantChecksum := checksumFor(checksums, filename)
+	if wantChecksum == "" {
+		return nil, true, fmt.Errorf("%q not found in %q for version %q",
+			filename, checksumsURL, version)
+	}
+	url := util.TemplateString(v.URL, serviceInfo)
+	hasher := sha256.New()
+	if err := v.request(ctx, url, logFrom, func(body io.Reader) error {
+		_, err := io.Copy(hasher, body)
+		return err
+	}); err != nil {
+		return nil, false, fmt.Errorf("verify %q failed for version %q: %w",
+			url, version, err)
+	}
+	gotChecksum := hex.EncodeToString(hasher.Sum(nil))
+	if gotChecksum != wantChecksum {
+		return nil, true, fmt.Errorf("sha256 of %q (%s) does not match %q in %q (%s) for version %q",
+			url, gotChecksum, filename, checksumsURL, wantChecksum, version)
+	}
+
+	return &util.ArtifactInfo{
+		URL:           url,
+		Filename:      filename,
+		SHA256:        gotChecksum,
+		SignatureType: signatureType}, true, nil
+}
+
+// checksumFor returns the (lowercase) SHA256 of `filename` in the `checksums` file,
+// or "" if it isn't listed.
+//
+// Lines are in the sha256sum format, "<sha256>  <filename>" (or "<sha256> *<filename>").
+func checksumFor(checksums []byte, filename string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")
+		if name == filename || path.Base(name) == filename {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+// download returns the body of `url`, failing if it's larger than `maxSize` bytes.
+func (v *VerifyCheck) download(ctx context.Context, url string, maxSize int64, logFrom *util.LogFrom) (body []byte, err error) {
+	err = v.request(ctx, url, logFrom, func(reader io.Reader) error {
+		body, err = io.ReadAll(io.LimitReader(reader, maxSize+1))
+		if err == nil && int64(len(body)) > maxSize {
+			err = fmt.Errorf("larger than %d bytes", maxSize)
+		}
+		return err
+	})
+	return
+}
+
+// request does a GET on `url`, giving the body to `read` if it gets a 200.
+func (v *VerifyCheck) request(ctx context.Context, url string, logFrom *util.LogFrom, read func(body io.Reader) error) error {
+	// HTTPS insecure skip verify.
+	customTransport := &http.Transport{}
+	if v.AllowInvalidCerts {
+		customTransport = http.DefaultTransport.(*http.Transport).Clone()
+		//#nosec G402 -- explicitly wanted InsecureSkipVerify
+		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Connection", "close")
+
+	// Send the request.
+	client := &http.Client{
+		Transport: ratelimit.Transport(customTransport, logFrom),
+		Timeout:   verifyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Don't log the whole certificate error.
+		if strings.Contains(err.Error(), "x509") {
+			err = fmt.Errorf("x509 (certificate invalid)")
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return read(resp.Body)
+}
+
+// CheckValues of the VerifyCheck.
+func (v *VerifyCheck) CheckValues(prefix string) (errs error) {
+	if v == nil {
+		return
+	}
+
+	if v.URL == "" {
+		errs = fmt.Errorf("%s%surl: <required> (URL of the artifact, e.g. 'https://example.com/app-{{ version }}.tar.gz')\\",
+			util.ErrorToString(errs), prefix)
+	} else if !util.CheckTemplate(v.URL) {
+		errs = fmt.Errorf("%s%surl: %q <invalid> (didn't pass templating)\\",
+			util.ErrorToString(errs), prefix, v.URL)
+	}
+
+	if v.ChecksumsURL == "" {
+		errs = fmt.Errorf("%s%schecksums_url: <required> (URL of the SHA256SUMS file)\\",
+			util.ErrorToString(errs), prefix)
+	} else if !util.CheckTemplate(v.ChecksumsURL) {
+		errs = fmt.Errorf("%s%schecksums_url: %q <invalid> (didn't pass templating)\\",
+			util.ErrorToString(errs), prefix, v.ChecksumsURL)
+	}
+
+	if v.Filename != "" && !util.CheckTemplate(v.Filename) {
+		errs = fmt.Errorf("%s%sfilename: %q <invalid> (didn't pass templating)\\",
+			util.ErrorToString(errs), prefix, v.Filename)
+	}
+
+	if v.SignatureType != "" && !util.Contains(verifySignatureTypes, v.SignatureType) {
+		errs = fmt.Errorf("%s%ssignature_type: %q <invalid> (expected one of %v)\\",
+			util.ErrorToString(errs), prefix, v.SignatureType, verifySignatureTypes)
+	}
+
+	if v.SignatureURL == "" {
+		if v.PublicKey != "" {
+			errs = fmt.Errorf("%s%ssignature_url: <required> (public_key was given)\\",
+				util.ErrorToString(errs), prefix)
+		}
+	} else {
+		if !util.CheckTemplate(v.SignatureURL) {
+			errs = fmt.Errorf("%s%ssignature_url: %q <invalid> (didn't pass templating)\\",
+				util.ErrorToString(errs), prefix, v.SignatureURL)
+		}
+		if v.PublicKey == "" {
+			errs = fmt.Errorf("%s%spublic_key: <required> (to verify the signature_url with)\\",
+				util.ErrorToString(errs), prefix)
+		} else if util.Contains(verifySignatureTypes, v.GetSignatureType()) {
+			if err := checkPublicKey(v.GetSignatureType(), v.PublicKey); err != nil {
+				errs = fmt.Errorf("%s%spublic_key: <invalid> (%s)\\",
+					util.ErrorToString(errs), prefix, err)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,196 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignPublicKey is a decoded minisign public key.
+type minisignPublicKey struct {
+	keyID []byte
+	key   ed25519.PublicKey
+}
+
+// checkPublicKey returns an error if `publicKey` can't be used for `signatureType` signatures.
+func checkPublicKey(signatureType string, publicKey string) (err error) {
+	switch signatureType {
+	case "cosign":
+		_, err = parseCosignPublicKey(publicKey)
+	default:
+		_, err = parseMinisignPublicKey(publicKey)
+	}
+	return
+}
+
+// verifySignature returns an error if `signature` isn't a valid `signatureType` signature
+// of `data` with the `publicKey`.
+func verifySignature(signatureType string, publicKey string, data []byte, signature []byte) error {
+	switch signatureType {
+	case "cosign":
+		return verifyCosign(publicKey, data, signature)
+	default:
+		return verifyMinisign(publicKey, data, signature)
+	}
+}
+
+// nonEmptyLines returns the lines of `text` that aren't empty.
+func nonEmptyLines(text string) (lines []string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
+// parseMinisignPublicKey parses a minisign `publicKey`, either just the base64 key,
+// or the whole .pub file (with its untrusted comment).
+func parseMinisignPublicKey(publicKey string) (*minisignPublicKey, error) {
+	lines := nonEmptyLines(publicKey)
+	if len(lines) == 0 {
+		return nil, errors.New("empty minisign public key")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(lines[len(lines)-1])
+	if err != nil {
+		return nil, fmt.Errorf("minisign public key is not base64: %w", err)
+	}
+	if len(decoded) != 2+8+ed25519.PublicKeySize || string(decoded[:2]) != "Ed" {
+		return nil, errors.New("not a minisign Ed25519 public key")
+	}
+
+	return &minisignPublicKey{
+		keyID: decoded[2:10],
+		key:   ed25519.PublicKey(decoded[10:])}, nil
+}
+
+// verifyMinisign verifies the minisign `signature` file of `data`.
+//
+// The signature file is made up of 4 lines:
+//
+//	untrusted comment: <text>
+//	base64(<signature algorithm><key id><signature>)
+//	trusted comment: <text>
+//	base64(<global signature of the signature and the trusted comment>)
+func verifyMinisign(publicKey string, data []byte, signature []byte) error {
+	key, err := parseMinisignPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	lines := nonEmptyLines(string(signature))
+	if len(lines) != 4 {
+		return fmt.Errorf("invalid minisign signature file (expected 4 lines, got %d)",
+			len(lines))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(decoded) != 2+8+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	algorithm, keyID, sig := string(decoded[:2]), decoded[2:10], decoded[10:]
+
+	// Key ID
+	if string(keyID) != string(key.keyID) {
+		return fmt.Errorf("signed with key %016X, not %016X",
+			binary.LittleEndian.Uint64(keyID), binary.LittleEndian.Uint64(key.keyID))
+	}
+
+	// Signature
+	message := data
+	switch algorithm {
+	case "Ed":
+	case "ED":
+		hash := blake2b.Sum512(data)
+		message = hash[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", algorithm)
+	}
+	if !ed25519.Verify(key.key, message, sig) {
+		return errors.New("signature mismatch")
+	}
+
+	// Trusted comment
+	trustedComment, found := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !found {
+		return errors.New("invalid minisign signature file (no trusted comment)")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign global signature")
+	}
+	if !ed25519.Verify(key.key, append(sig, trustedComment...), globalSig) {
+		return errors.New("trusted comment signature mismatch")
+	}
+	return nil
+}
+
+// parseCosignPublicKey parses a PEM encoded (ECDSA/Ed25519/RSA) `publicKey`.
+func parseCosignPublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("not a PEM encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// verifyCosign verifies the base64 `signature` of `data`, as given by `cosign sign-blob`.
+func verifyCosign(publicKey string, data []byte, signature []byte) error {
+	key, err := parseCosignPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("signature is not base64: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	var valid bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	if !valid {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,555 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package filter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/release-argus/Argus/util"
+	"golang.org/x/crypto/blake2b"
+)
+
+// testMinisign returns a minisign public key, and a function to sign data with its private key.
+func testMinisign(t *testing.T, keyID string) (publicKey string, sign func(data []byte, prehash bool) []byte) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKey = "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append([]byte("Ed"+keyID), pub...))
+
+	sign = func(data []byte, prehash bool) []byte {
+		algorithm := "Ed"
+		if prehash {
+			algorithm = "ED"
+			hash := blake2b.Sum512(data)
+			data = hash[:]
+		}
+		sig := ed25519.Sign(priv, data)
+		trustedComment := "timestamp:1700000000\tfile:SHA256SUMS"
+		globalSig := ed25519.Sign(priv, append(append([]byte{}, sig...), trustedComment...))
+		return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+			base64.StdEncoding.EncodeToString(append([]byte(algorithm+keyID), sig...)),
+			trustedComment,
+			base64.StdEncoding.EncodeToString(globalSig)))
+	}
+	return
+}
+
+// testCosign returns a PEM encoded ECDSA public key, and a function to sign data with its private key.
+func testCosign(t *testing.T) (publicKey string, sign func(data []byte) []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	publicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	sign = func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		sig, _ := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+		return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+	}
+	return
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte("0123  app.tar.gz\n")
+	minisignKey, minisignSign := testMinisign(t, "12345678")
+	_, otherMinisignSign := testMinisign(t, "87654321")
+	sameIDMinisignKey, _ := testMinisign(t, "12345678")
+	cosignKey, cosignSign := testCosign(t)
+	otherCosignKey, _ := testCosign(t)
+	// GIVEN a signature of data
+	tests := map[string]struct {
+		signatureType string
+		publicKey     string
+		data          []byte
+		signature     []byte
+		errRegex      string
+	}{
+		"minisign, prehashed": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			signature:     minisignSign(data, true),
+			errRegex:      `^$`},
+		"minisign, legacy": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			signature:     minisignSign(data, false),
+			errRegex:      `^$`},
+		"minisign, just the base64 key": {
+			signatureType: "minisign",
+			publicKey:     strings.Split(minisignKey, "\n")[1],
+			signature:     minisignSign(data, true),
+			errRegex:      `^$`},
+		"minisign, data changed": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			data:          []byte("4567  app.tar.gz\n"),
+			signature:     minisignSign(data, true),
+			errRegex:      `^signature mismatch$`},
+		"minisign, other key": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			signature:     otherMinisignSign(data, true),
+			errRegex:      `^signed with key 3132333435363738, not 3837363534333231$`},
+		"minisign, other key with same key id": {
+			signatureType: "minisign",
+			publicKey:     sameIDMinisignKey,
+			signature:     minisignSign(data, true),
+			errRegex:      `^signature mismatch$`},
+		"minisign, trusted comment changed": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			signature: []byte(strings.Replace(string(minisignSign(data, true)),
+				"timestamp:1700000000", "timestamp:1800000000", 1)),
+			errRegex: `^trusted comment signature mismatch$`},
+		"minisign, not a signature file": {
+			signatureType: "minisign",
+			publicKey:     minisignKey,
+			signature:     []byte("foo"),
+			errRegex:      `^invalid minisign signature file \(expected 4 lines, got 1\)$`},
+		"cosign": {
+			signatureType: "cosign",
+			publicKey:     cosignKey,
+			signature:     cosignSign(data),
+			errRegex:      `^$`},
+		"cosign, data changed": {
+			signatureType: "cosign",
+			publicKey:     cosignKey,
+			data:          []byte("4567  app.tar.gz\n"),
+			signature:     cosignSign(data),
+			errRegex:      `^signature mismatch$`},
+		"cosign, other key": {
+			signatureType: "cosign",
+			publicKey:     otherCosignKey,
+			signature:     cosignSign(data),
+			errRegex:      `^signature mismatch$`},
+		"cosign, signature not base64": {
+			signatureType: "cosign",
+			publicKey:     cosignKey,
+			signature:     []byte("!!!"),
+			errRegex:      `^signature is not base64`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.data == nil {
+				tc.data = data
+			}
+
+			// WHEN verifySignature is called on it
+			err := verifySignature(tc.signatureType, tc.publicKey, tc.data, tc.signature)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestRequire_VerifyCheck(t *testing.T) {
+	artifact := []byte("app v1.2.3")
+	artifactSum := sha256.Sum256(artifact)
+	checksums := []byte(fmt.Sprintf("%x  ./app-1.2.3.tar.gz\n%x *app-1.2.3.zip\n",
+		artifactSum, sha256.Sum256([]byte("something else"))))
+	minisignKey, minisignSign := testMinisign(t, "12345678")
+	otherMinisignKey, _ := testMinisign(t, "12345678")
+	cosignKey, cosignSign := testCosign(t)
+	files := map[string][]byte{
+		"/1.2.3/app-1.2.3.tar.gz":      artifact,
+		"/1.2.3/app-1.2.3.zip":         artifact,
+		"/1.2.3/SHA256SUMS":            checksums,
+		"/1.2.3/SHA256SUMS.minisig":    minisignSign(checksums, true),
+		"/1.2.3/SHA256SUMS.cosign.sig": cosignSign(checksums)}
+	// GIVEN a Require with a VerifyCheck
+	tests := map[string]struct {
+		verify        *VerifyCheck
+		errRegex      string
+		wantSignature string
+	}{
+		"nil VerifyCheck": {
+			verify:   nil,
+			errRegex: `^$`},
+		"checksum matches": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS"},
+			errRegex: `^$`},
+		"checksum doesn't match": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.zip",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS"},
+			errRegex: `^sha256 of ".*/1.2.3/app-1.2.3.zip" \([0-9a-f]{64}\) does not match "app-1.2.3.zip" in ".*/1.2.3/SHA256SUMS" \([0-9a-f]{64}\) for version "1.2.3"$`},
+		"filename override": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.zip",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS",
+				Filename:     "app-{{ version }}.tar.gz"},
+			errRegex: `^$`},
+		"artifact not in checksums": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.deb",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS"},
+			errRegex: `^"app-1.2.3.deb" not found in ".*/1.2.3/SHA256SUMS" for version "1.2.3"$`},
+		"artifact not available": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz?missing",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS",
+				Filename:     "app-{{ version }}.tar.gz"},
+			errRegex: `^verify ".*/1.2.3/app-1.2.3.tar.gz\?missing" failed for version "1.2.3": status 404$`},
+		"checksums not available": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL: "/{{ version }}/SHA512SUMS"},
+			errRegex: `^verify ".*/1.2.3/SHA512SUMS" failed for version "1.2.3": status 404$`},
+		"minisign signature valid": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS",
+				SignatureURL: "/{{ version }}/SHA256SUMS.minisig",
+				PublicKey:    minisignKey},
+			errRegex:      `^$`,
+			wantSignature: "minisign"},
+		"minisign signature from another key": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS",
+				SignatureURL: "/{{ version }}/SHA256SUMS.minisig",
+				PublicKey:    otherMinisignKey},
+			errRegex: `^minisign signature of ".*/1.2.3/SHA256SUMS" failed verification for version "1.2.3": signature mismatch$`},
+		"cosign signature valid": {
+			verify: &VerifyCheck{
+				URL:           "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL:  "/{{ version }}/SHA256SUMS",
+				SignatureURL:  "/{{ version }}/SHA256SUMS.cosign.sig",
+				SignatureType: "cosign",
+				PublicKey:     cosignKey},
+			errRegex:      `^$`,
+			wantSignature: "cosign"},
+		"signature not available": {
+			verify: &VerifyCheck{
+				URL:          "/{{ version }}/app-{{ version }}.tar.gz",
+				ChecksumsURL: "/{{ version }}/SHA256SUMS",
+				SignatureURL: "/{{ version }}/SHA256SUMS.asc",
+				PublicKey:    minisignKey},
+			errRegex: `^verify ".*/1.2.3/SHA256SUMS.asc" failed for version "1.2.3": status 404$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := files[r.URL.Path]
+				if !ok || r.URL.RawQuery != "" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(body)
+			}))
+			t.Cleanup(server.Close)
+			if tc.verify != nil {
+				tc.verify.URL = server.URL + tc.verify.URL
+				tc.verify.ChecksumsURL = server.URL + tc.verify.ChecksumsURL
+				if tc.verify.SignatureURL != "" {
+					tc.verify.SignatureURL = server.URL + tc.verify.SignatureURL
+				}
+			}
+			require := Require{Verify: tc.verify}
+
+			// WHEN VerifyCheck is called on it
+			err := require.VerifyCheck(context.Background(), "1.2.3", &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the verified artifact is recorded when it passes
+			got := require.Verify.ArtifactInfo("1.2.3")
+			if err != nil || tc.verify == nil {
+				if got != nil {
+					t.Errorf("want no artifact, got %v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("want an artifact, got nil")
+			}
+			if got.SHA256 != hex.EncodeToString(artifactSum[:]) {
+				t.Errorf("want sha256 %x, got %q",
+					artifactSum, got.SHA256)
+			}
+			if got.SignatureType != tc.wantSignature {
+				t.Errorf("want signature_type %q, got %q",
+					tc.wantSignature, got.SignatureType)
+			}
+		})
+	}
+}
+
+func TestRequire_VerifyCheck_Cached(t *testing.T) {
+	artifact := []byte("app v1.2.3")
+	checksums := []byte(fmt.Sprintf("%x  app-1.2.3.tar.gz\n",
+		sha256.Sum256(artifact)))
+	// GIVEN a Require with a VerifyCheck that has already been run on a version
+	tests := map[string]struct {
+		checksums    []byte
+		status       int
+		wantRequests int32
+		errRegex     string
+	}{
+		"passed - not downloaded again": {
+			checksums:    checksums,
+			status:       http.StatusOK,
+			wantRequests: 2,
+			errRegex:     `^$`},
+		"checksum mismatch - not downloaded again": {
+			checksums:    []byte(fmt.Sprintf("%x  app-1.2.3.tar.gz\n", sha256.Sum256([]byte("other")))),
+			status:       http.StatusOK,
+			wantRequests: 2,
+			errRegex:     `^sha256 of .* does not match `},
+		"download failed - retried": {
+			checksums:    checksums,
+			status:       http.StatusBadGateway,
+			wantRequests: 2,
+			errRegex:     `^verify ".*/SHA256SUMS" failed for version "1.2.3": status 502$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				switch r.URL.Path {
+				case "/SHA256SUMS":
+					w.Write(tc.checksums)
+				default:
+					w.Write(artifact)
+				}
+			}))
+			t.Cleanup(server.Close)
+			require := Require{Verify: &VerifyCheck{
+				URL:          server.URL + "/app-{{ version }}.tar.gz",
+				ChecksumsURL: server.URL + "/SHA256SUMS"}}
+			firstErr := require.VerifyCheck(context.Background(), "1.2.3", &util.LogFrom{})
+
+			// WHEN VerifyCheck is called on that version again
+			err := require.VerifyCheck(context.Background(), "1.2.3", &util.LogFrom{})
+
+			// THEN it gives the same result
+			if util.ErrorToString(err) != util.ErrorToString(firstErr) {
+				t.Errorf("want the same err as the first check %q, got %q",
+					util.ErrorToString(firstErr), util.ErrorToString(err))
+			}
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the files were only downloaded again if the first download failed
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("want %d requests, got %d",
+					tc.wantRequests, got)
+			}
+		})
+	}
+}
+
+func TestVerifyCheck_GetFilename(t *testing.T) {
+	// GIVEN a VerifyCheck
+	tests := map[string]struct {
+		verify *VerifyCheck
+		want   string
+	}{
+		"filename of the url": {
+			verify: &VerifyCheck{URL: "https://example.com/{{ version }}/app-{{ version }}.tar.gz"},
+			want:   "app-1.2.3.tar.gz"},
+		"filename of the url, without the query": {
+			verify: &VerifyCheck{URL: "https://example.com/download/app.tar.gz?version={{ version }}"},
+			want:   "app.tar.gz"},
+		"filename override": {
+			verify: &VerifyCheck{
+				URL:      "https://example.com/download?version={{ version }}",
+				Filename: "app_{{ version }}_linux_amd64.tar.gz"},
+			want: "app_1.2.3_linux_amd64.tar.gz"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN GetFilename is called on it
+			got := tc.verify.GetFilename("1.2.3")
+
+			// THEN the filename is what we expect
+			if got != tc.want {
+				t.Errorf("want: %q\ngot:  %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVerifyCheck_CheckValues(t *testing.T) {
+	minisignKey, _ := testMinisign(t, "12345678")
+	cosignKey, _ := testCosign(t)
+	// GIVEN a VerifyCheck
+	tests := map[string]struct {
+		verify   *VerifyCheck
+		errRegex []string
+	}{
+		"nil": {
+			verify:   nil,
+			errRegex: []string{`^$`}},
+		"valid, unsigned": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/app-{{ version }}.tar.gz",
+				ChecksumsURL: "https://example.com/{{ version }}/SHA256SUMS"},
+			errRegex: []string{`^$`}},
+		"valid, minisign": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/app-{{ version }}.tar.gz",
+				ChecksumsURL: "https://example.com/{{ version }}/SHA256SUMS",
+				SignatureURL: "https://example.com/{{ version }}/SHA256SUMS.minisig",
+				PublicKey:    minisignKey},
+			errRegex: []string{`^$`}},
+		"valid, cosign": {
+			verify: &VerifyCheck{
+				URL:           "https://example.com/app-{{ version }}.tar.gz",
+				ChecksumsURL:  "https://example.com/{{ version }}/SHA256SUMS",
+				SignatureURL:  "https://example.com/{{ version }}/SHA256SUMS.sig",
+				SignatureType: "cosign",
+				PublicKey:     cosignKey},
+			errRegex: []string{`^$`}},
+		"no urls": {
+			verify: &VerifyCheck{},
+			errRegex: []string{
+				`^url: <required>`,
+				`^checksums_url: <required>`}},
+		"urls fail templating": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/{{ version }",
+				ChecksumsURL: "https://example.com/{{ version }",
+				Filename:     "{{ version }",
+				SignatureURL: "https://example.com/{{ version }",
+				PublicKey:    minisignKey},
+			errRegex: []string{
+				`^url: "[^"]+" <invalid> \(didn't pass templating\)`,
+				`^checksums_url: "[^"]+" <invalid> \(didn't pass templating\)`,
+				`^filename: "[^"]+" <invalid> \(didn't pass templating\)`,
+				`^signature_url: "[^"]+" <invalid> \(didn't pass templating\)`}},
+		"invalid signature_type": {
+			verify: &VerifyCheck{
+				URL:           "https://example.com/app.tar.gz",
+				ChecksumsURL:  "https://example.com/SHA256SUMS",
+				SignatureType: "gpg"},
+			errRegex: []string{
+				`^signature_type: "gpg" <invalid>`}},
+		"public_key without signature_url": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/app.tar.gz",
+				ChecksumsURL: "https://example.com/SHA256SUMS",
+				PublicKey:    minisignKey},
+			errRegex: []string{
+				`^signature_url: <required>`}},
+		"signature_url without public_key": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/app.tar.gz",
+				ChecksumsURL: "https://example.com/SHA256SUMS",
+				SignatureURL: "https://example.com/SHA256SUMS.minisig"},
+			errRegex: []string{
+				`^public_key: <required>`}},
+		"cosign key for minisign": {
+			verify: &VerifyCheck{
+				URL:          "https://example.com/app.tar.gz",
+				ChecksumsURL: "https://example.com/SHA256SUMS",
+				SignatureURL: "https://example.com/SHA256SUMS.minisig",
+				PublicKey:    cosignKey},
+			errRegex: []string{
+				`^public_key: <invalid> \(minisign public key is not base64`}},
+		"minisign key for cosign": {
+			verify: &VerifyCheck{
+				URL:           "https://example.com/app.tar.gz",
+				ChecksumsURL:  "https://example.com/SHA256SUMS",
+				SignatureURL:  "https://example.com/SHA256SUMS.sig",
+				SignatureType: "cosign",
+				PublicKey:     minisignKey},
+			errRegex: []string{
+				`^public_key: <invalid> \(not a PEM encoded public key\)`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN CheckValues is called on it
+			err := tc.verify.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strings"
 
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
 	"github.com/release-argus/Argus/util"
 )
 
@@ -84,3 +85,48 @@ func (l *Lookup) GetURL() string {
 	}
 	return url
 }
+
+// LatestRelease returns the metadata of the release of the latest version (type:github only).
+func (l *Lookup) LatestRelease() *github_types.Release {
+	if l.GitHubData == nil {
+		return nil
+	}
+
+	return l.GitHubData.LatestRelease()
+}
+
+// LatestImage returns the container image found by require.docker for the latest version.
+func (l *Lookup) LatestImage() *util.ImageInfo {
+	if l.Status == nil {
+		return nil
+	}
+
+	return l.Status.LatestVersionImage()
+}
+
+// LatestArtifact returns the artifact verified by require.verify for the latest version.
+func (l *Lookup) LatestArtifact() *util.ArtifactInfo {
+	if l.Status == nil {
+		return nil
+	}
+
+	return l.Status.LatestVersionArtifact()
+}
+
+// setLatestRelease will store the metadata of the release of the latest version in `result`,
+// what the Require found for it, and the GitHub release (type:github only).
+func (l *Lookup) setLatestRelease(result *sourceResult) {
+	var image *util.ImageInfo
+	var artifact *util.ArtifactInfo
+	if require := result.lookup.Require; require != nil {
+		image = require.Docker.ImageInfo(result.version)
+		artifact = require.Verify.ArtifactInfo(result.version)
+	}
+	l.Status.SetLatestVersionRequire(result.version, image, artifact)
+
+	if l.GitHubData == nil {
+		return
+	}
+
+	l.GitHubData.SetLatestRelease(result.release)
+}
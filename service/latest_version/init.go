@@ -41,6 +41,7 @@ func (l *Lookup) Init(
 	hardDefaults *LookupDefaults,
 	status *svcstatus.Status,
 	options *opt.Options,
+	notify *shoutrrr.Slice,
 ) {
 	if l.Type == "github" {
 		l.GitHubData = NewGitHubData("", nil)
@@ -50,6 +51,8 @@ func (l *Lookup) Init(
 	l.HardDefaults = hardDefaults
 	l.Status = status
 	l.Options = options
+	l.Notify = notify
+	l.initSources()
 
 	l.Require.Init(status, &defaults.Require)
 }
@@ -69,6 +72,12 @@ func (l *Lookup) InitMetrics() {
 		"",
 		"",
 		"FAIL")
+	metric.InitPrometheusCounter(metric.LatestVersionQueryMetric,
+		*l.Status.ServiceID,
+		"",
+		"",
+		"WITHDRAWN")
+	l.initSourceMetrics()
 }
 
 // DeleteMetrics for this Lookup.
@@ -87,4 +96,10 @@ func (l *Lookup) DeleteMetrics() {
 		"",
 		"",
 		"FAIL")
+	metric.DeletePrometheusCounter(metric.LatestVersionQueryMetric,
+		*l.Status.ServiceID,
+		"",
+		"",
+		"WITHDRAWN")
+	l.deleteSourceMetrics()
 }
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/test"
@@ -39,7 +40,7 @@ func TestLookup_Metrics(t *testing.T) {
 	// THEN it can be collected
 	// counters
 	gotC := testutil.CollectAndCount(metric.LatestVersionQueryMetric)
-	wantC := 2
+	wantC := 3
 	if (gotC - hadC) != wantC {
 		t.Errorf("%d Counter metrics's were initialised, expecting %d",
 			(gotC - hadC), wantC)
@@ -84,12 +85,14 @@ func TestLookup_Init(t *testing.T) {
 	*lookup.Status.ServiceID += "TestInit"
 	status := svcstatus.Status{ServiceID: test.StringPtr("test")}
 	var options opt.Options
+	var notify shoutrrr.Slice
 
 	// WHEN Init is called on it
 	lookup.Init(
 		&defaults, &hardDefaults,
 		&status,
-		&options)
+		&options,
+		&notify)
 
 	// THEN pointers to those vars are handed out to the Lookup
 	// defaults
@@ -112,4 +115,9 @@ func TestLookup_Init(t *testing.T) {
 		t.Errorf("Options were not handed to the Lookup correctly\n want: %v\ngot:  %v",
 			&options, lookup.Options)
 	}
+	// notify
+	if lookup.Notify != &notify {
+		t.Errorf("Notify was not handed to the Lookup correctly\n want: %v\ngot:  %v",
+			&notify, lookup.Notify)
+	}
 }
@@ -16,6 +16,7 @@ package latestver
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/release-argus/Argus/ratelimit"
 	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
 	"github.com/release-argus/Argus/util"
 	metric "github.com/release-argus/Argus/web/metrics"
@@ -35,16 +37,12 @@ import (
 // otherwise returns false.
 //
 // checkNumber - 0 for first check, 1 for second check (if the first check found a new version)
-func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
-	rawBody, err := l.httpRequest(logFrom)
-	if err != nil {
-		return false, err
-	}
-
-	version, err := l.GetVersion(rawBody, logFrom)
-	if err != nil {
-		return false, err
+func (l *Lookup) query(ctx context.Context, logFrom *util.LogFrom, checkNumber int) (bool, error) {
+	result := l.queryLatest(ctx, logFrom)
+	if result.err != nil {
+		return false, result.err
 	}
+	version := result.version
 
 	l.Status.SetLastQueried("")
 	wantSemanticVersioning := l.Options.GetSemanticVersioning()
@@ -56,8 +54,14 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 		if checkNumber == 0 {
 			msg := fmt.Sprintf("Possibly found a new version (From %q to %q). Checking again", latestVersion, version)
 			jLog.Verbose(msg, logFrom, latestVersion != "")
-			time.Sleep(time.Second)
-			return l.query(logFrom, 1)
+			timer := time.NewTimer(time.Second)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return false, ctx.Err()
+			}
+			return l.query(ctx, logFrom, 1)
 		}
 
 		if wantSemanticVersioning {
@@ -72,6 +76,17 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 
 			// Check for a progressive change in version.
 			if latestVersion != "" {
+				// Roll back if the latest version was withdrawn (no longer listed) at the source.
+				// (only with semantic versioning, as we need to know the version is older)
+				if latestSemVer, err := semver.NewVersion(latestVersion); err == nil &&
+					newVersion.LessThan(latestSemVer) &&
+					result.lookup.versionWithdrawn(latestVersion, result.rawBody, logFrom) {
+					l.Status.ResetRegexMisses()
+					l.setLatestRelease(&result)
+					l.rollbackWithdrawnVersion(latestVersion, version, logFrom)
+					return false, nil
+				}
+
 				oldVersion, err := semver.NewVersion(l.Status.DeployedVersion())
 				// If the old version is not a semantic version, then we can't compare it.
 				// (if we switched to semantic versioning with non-semantic versions tracked)
@@ -84,7 +99,7 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 						err := fmt.Errorf("queried version %q is less than the deployed version %q",
 							version, l.Status.LatestVersion())
 						jLog.Warn(err, logFrom, true)
-						return false, err
+						return false, &queryError{sentinel: ErrOlderVersion, err: err}
 					}
 				}
 			}
@@ -92,6 +107,7 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 
 		// Found new version, so reset regex misses.
 		l.Status.ResetRegexMisses()
+		l.setLatestRelease(&result)
 
 		// First version found.
 		if l.Status.LatestVersion() == "" {
@@ -115,6 +131,8 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 		return true, nil
 	}
 
+	// Refresh the release metadata (e.g. edited release notes).
+	l.setLatestRelease(&result)
 	msg := fmt.Sprintf("Staying on %q as that's the latest version in the second check", version)
 	jLog.Verbose(msg, logFrom, checkNumber == 1)
 	// Announce `LastQueried`
@@ -126,9 +144,10 @@ func (l *Lookup) query(logFrom *util.LogFrom, checkNumber int) (bool, error) {
 // Query the Lookup, updating Service.Status.LatestVersion
 // and returning true if a new release was found.
 //
+// ctx - cancels the query (e.g. when the Service is deleted/edited)
 // metrics - if true, set Prometheus metrics based on the query
-func (l *Lookup) Query(metrics bool, logFrom *util.LogFrom) (newVersion bool, err error) {
-	newVersion, err = l.query(logFrom, 0)
+func (l *Lookup) Query(ctx context.Context, metrics bool, logFrom *util.LogFrom) (newVersion bool, err error) {
+	newVersion, err = l.query(ctx, logFrom, 0)
 
 	if metrics {
 		l.queryMetrics(err)
@@ -137,6 +156,38 @@ func (l *Lookup) Query(metrics bool, logFrom *util.LogFrom) (newVersion bool, er
 	return
 }
 
+var (
+	// ErrNoMatchingRelease is the error of a query that found no release matching
+	// the url_commands and/or require (e.g. waiting on require.min_age/docker/http/verify).
+	ErrNoMatchingRelease = errors.New("no releases were found matching the url_commands and/or require")
+	// ErrOlderVersion is the error of a query that found a version older than the deployed version.
+	ErrOlderVersion = errors.New("queried version is less than the deployed version")
+)
+
+// queryError is an `err` that is also the `sentinel` error.
+type queryError struct {
+	sentinel error
+	err      error
+}
+
+// Error returns the message of the underlying error.
+func (e *queryError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the sentinel and underlying errors.
+func (e *queryError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+// QueryFailed returns whether the `err` from Query is a failed query, rather than no release
+// matching the url_commands/require (ErrNoMatchingRelease), or an older version (ErrOlderVersion).
+func QueryFailed(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrNoMatchingRelease) &&
+		!errors.Is(err, ErrOlderVersion)
+}
+
 // queryMetrics sets the Prometheus metrics for the LatestVersion query.
 func (l *Lookup) queryMetrics(err error) {
 	// If it failed
@@ -154,6 +205,10 @@ func (l *Lookup) queryMetrics(err error) {
 			metric.SetPrometheusGauge(metric.LatestVersionQueryLiveness,
 				*l.Status.ServiceID,
 				4)
+		case strings.HasPrefix(e, "min_age "):
+			metric.SetPrometheusGauge(metric.LatestVersionQueryLiveness,
+				*l.Status.ServiceID,
+				5)
 		default:
 			metric.IncreasePrometheusCounter(metric.LatestVersionQueryMetric,
 				*l.Status.ServiceID,
@@ -176,7 +231,7 @@ func (l *Lookup) queryMetrics(err error) {
 	}
 }
 
-func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err error) {
+func (l *Lookup) httpRequest(ctx context.Context, logFrom *util.LogFrom) (rawBodyPtr *[]byte, err error) {
 	customTransport := &http.Transport{}
 	// HTTPS insecure skip verify.
 	if l.GetAllowInvalidCerts() {
@@ -185,7 +240,7 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err err
 		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
-	req, err := http.NewRequest(http.MethodGet, l.GetURL(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.GetURL(), nil)
 	if err != nil {
 		err = fmt.Errorf("failed creating http request for %q: %w",
 			l.URL, err)
@@ -207,8 +262,15 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err err
 			req.Header.Set("If-None-Match", eTag)
 		}
 	}
+	// Bearer/OAuth2/API key auth
+	if err = l.Auth.Apply(req, l.GetAllowInvalidCerts()); err != nil {
+		jLog.Error(err, logFrom, true)
+		return
+	}
 
-	client := &http.Client{Transport: customTransport}
+	client := &http.Client{
+		Transport: ratelimit.Transport(customTransport, logFrom),
+		Timeout:   util.HTTPTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		// Don't crash on invalid certs.
@@ -221,6 +283,11 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err err
 		return
 	}
 
+	// Get a new OAuth2 token for the next query.
+	if resp.StatusCode == http.StatusUnauthorized {
+		l.Auth.Rejected()
+	}
+
 	// Read the response body.
 	defer resp.Body.Close()
 	var rawBody []byte
@@ -240,7 +307,7 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err err
 				l.GitHubData.SetTagFallback()
 				if l.GitHubData.TagFallback() {
 					jLog.Verbose(fmt.Sprintf("/releases gave %v, trying /tags", string(rawBody)), logFrom, true)
-					rawBodyPtr, err = l.httpRequest(logFrom)
+					rawBodyPtr, err = l.httpRequest(ctx, logFrom)
 				}
 				// Has tags/releases
 			} else {
@@ -256,7 +323,7 @@ func (l *Lookup) httpRequest(logFrom *util.LogFrom) (rawBodyPtr *[]byte, err err
 				l.GitHubData.SetTagFallback()
 				if l.GitHubData.TagFallback() {
 					jLog.Verbose("no tags found on /releases, trying /tags", logFrom, true)
-					rawBodyPtr, err = l.httpRequest(logFrom)
+					rawBodyPtr, err = l.httpRequest(ctx, logFrom)
 				}
 			}
 		}
@@ -283,7 +350,9 @@ func (l *Lookup) GetVersions(
 		// Filter releases
 		filteredReleases = l.filterGitHubReleases(logFrom)
 		if len(filteredReleases) == 0 {
-			err = fmt.Errorf("no releases were found matching the url_commands")
+			err = &queryError{
+				sentinel: ErrNoMatchingRelease,
+				err:      errors.New("no releases were found matching the url_commands")}
 			jLog.Warn(err, logFrom, true)
 			return
 		}
@@ -302,28 +371,63 @@ func (l *Lookup) GetVersions(
 }
 
 // GetVersion will return the latest version from rawBody matching the URLCommands and Regex requirements
-func (l *Lookup) GetVersion(rawBody *[]byte, logFrom *util.LogFrom) (version string, err error) {
-	var filteredReleases []github_types.Release
+func (l *Lookup) GetVersion(ctx context.Context, rawBody *[]byte, logFrom *util.LogFrom) (version string, err error) {
+	version, _, err = l.getRelease(ctx, rawBody, logFrom)
+	return
+}
+
+// getRelease will return the latest version (and its release) from rawBody matching the URLCommands
+// and Regex requirements
+func (l *Lookup) getRelease(
+	ctx context.Context,
+	rawBody *[]byte,
+	logFrom *util.LogFrom,
+) (version string, release *github_types.Release, err error) {
+	filteredReleases, err := l.getReleases(rawBody, logFrom)
+	if err != nil {
+		return
+	}
+
+	return l.requireRelease(ctx, filteredReleases, rawBody, logFrom)
+}
+
+// getReleases will return the releases from rawBody matching the URLCommands (newest first).
+func (l *Lookup) getReleases(
+	rawBody *[]byte,
+	logFrom *util.LogFrom,
+) (filteredReleases []github_types.Release, err error) {
 	// rawBody length = 0 if GitHub ETag is unchanged
 	if len(*rawBody) != 0 {
 		filteredReleases, err = l.GetVersions(rawBody, logFrom)
-		if err != nil {
-			return
-		}
 	} else if l.Type == "github" {
 		// ReCheck this ETag's filteredReleases incase filters/releases changed
 		jLog.Verbose("Using cached releases (ETag unchanged)", logFrom, true)
 		filteredReleases = l.filterGitHubReleases(logFrom)
 	}
+	return
+}
 
-	wantSemanticVersioning := l.Options.GetSemanticVersioning()
+// releaseVersion returns the version of the `release`.
+func (l *Lookup) releaseVersion(release *github_types.Release) string {
+	if l.Options.GetSemanticVersioning() && l.Type != "url" {
+		return release.SemanticVersion.String()
+	}
+	return release.TagName
+}
+
+// requireRelease will return the newest of `filteredReleases` (and its version) that passes the Require checks.
+func (l *Lookup) requireRelease(
+	ctx context.Context,
+	filteredReleases []github_types.Release,
+	rawBody *[]byte,
+	logFrom *util.LogFrom,
+) (version string, release *github_types.Release, err error) {
+	var pendingVersion, pendingVersionEligible, pendingVersionSeen string
 	for i := range filteredReleases {
-		version = filteredReleases[i].TagName
-		if wantSemanticVersioning && l.Type != "url" {
-			version = filteredReleases[i].SemanticVersion.String()
-		}
+		version = l.releaseVersion(&filteredReleases[i])
 
 		if l.Require == nil {
+			release = &filteredReleases[i]
 			break
 		}
 
@@ -333,6 +437,25 @@ func (l *Lookup) GetVersion(rawBody *[]byte, logFrom *util.LogFrom) (version str
 			continue
 		}
 
+		// GitHub release metadata (draft/branch/author)
+		if l.Type == "github" {
+			if err = l.Require.ReleaseCheck(version, &filteredReleases[i], logFrom); err != nil {
+				continue
+			}
+		}
+
+		// If the release hasn't been published/seen for long enough
+		if err = l.Require.MinAgeCheck(version, filteredReleases[i].PublishedAt, logFrom); err != nil {
+			// Track the newest release waiting on min_age
+			if pendingVersion == "" {
+				pendingVersion = version
+				pendingVersionEligible = l.Require.EligibleAt(version, filteredReleases[i].PublishedAt).
+					Format(time.RFC3339)
+				pendingVersionSeen = l.Require.FirstSeenAt(version).Format(time.RFC3339)
+			}
+			continue
+		}
+
 		// Content RegEx
 		var body interface{}
 		if l.Type == "github" {
@@ -347,13 +470,18 @@ func (l *Lookup) GetVersion(rawBody *[]byte, logFrom *util.LogFrom) (version str
 			continue
 		}
 
+		// If the Expression isn't true for this release
+		if err = l.Require.ExpressionCheck(version, &filteredReleases[i], logFrom); err != nil {
+			continue
+		}
+
 		// If the Command didn't return successfully
 		if err = l.Require.ExecCommand(logFrom); err != nil {
 			continue
 		}
 
 		// If the Docker tag doesn't exist
-		if err = l.Require.DockerTagCheck(version); err != nil {
+		if err = l.Require.DockerTagCheck(ctx, version, logFrom); err != nil {
 			if strings.HasSuffix(err.Error(), "\n") {
 				err = errors.New(strings.TrimSuffix(err.Error(), "\n"))
 			}
@@ -366,11 +494,28 @@ func (l *Lookup) GetVersion(rawBody *[]byte, logFrom *util.LogFrom) (version str
 					l.Require.Docker.GetType(), l.Require.Docker.Image, l.Require.Docker.GetTag(version)),
 				logFrom, true)
 		}
+
+		// If the HTTP URL isn't available
+		if err = l.Require.HTTPRequestCheck(ctx, version, logFrom); err != nil {
+			continue
+		}
+
+		// If the artifact doesn't match its checksum/signature
+		if err = l.Require.VerifyCheck(ctx, version, logFrom); err != nil {
+			continue
+		}
+		release = &filteredReleases[i]
 		break
 	}
+	if l.Require != nil && l.Require.MinAge != "" {
+		l.Status.SetPendingVersion(pendingVersion, pendingVersionEligible, pendingVersionSeen)
+	}
 	if version == "" {
-		err = fmt.Errorf("no releases were found matching the url_commands and/or require")
+		err = ErrNoMatchingRelease
 		jLog.Warn(err, logFrom, true)
+	} else if release == nil && err != nil {
+		// Every release was rejected, so give the reason for the last.
+		err = &queryError{sentinel: ErrNoMatchingRelease, err: err}
 	}
 	return
 }
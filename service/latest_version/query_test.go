@@ -17,12 +17,18 @@
 package latestver
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/release-argus/Argus/service/auth"
 	"github.com/release-argus/Argus/service/latest_version/filter"
 	"github.com/release-argus/Argus/test"
 	"github.com/release-argus/Argus/util"
@@ -60,7 +66,7 @@ func TestLookup_HTTPRequest(t *testing.T) {
 			lookup.URL = tc.url
 
 			// WHEN httpRequest is called on it
-			_, err := lookup.httpRequest(&util.LogFrom{})
+			_, err := lookup.httpRequest(context.Background(), &util.LogFrom{})
 
 			// THEN any err is expected
 			e := util.ErrorToString(err)
@@ -77,6 +83,106 @@ func TestLookup_HTTPRequest(t *testing.T) {
 	}
 }
 
+func TestLookup_HTTPRequest_Cancelled(t *testing.T) {
+	// GIVEN a Lookup of a server that doesn't respond
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(stop)
+		server.Close()
+	})
+	lookup := testLookup(true, false)
+	lookup.URL = server.URL
+	// AND a context that's cancelled during the request
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	// WHEN httpRequest is called on it
+	start := time.Now()
+	_, err := lookup.httpRequest(ctx, &util.LogFrom{})
+
+	// THEN the request is cancelled
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v",
+			err)
+	}
+	// AND it didn't wait for the server
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("want the request cancelled quickly, took %s",
+			took)
+	}
+}
+
+func TestLookup_HTTPRequest_Auth(t *testing.T) {
+	// GIVEN a Lookup of a server that requires auth
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "v1.2.3")
+	}))
+	t.Cleanup(server.Close)
+	tests := map[string]struct {
+		auth     *auth.Auth
+		wantBody string
+		errRegex string
+	}{
+		"no auth": {
+			wantBody: "",
+			errRegex: `^$`},
+		"bearer": {
+			auth: &auth.Auth{
+				Type:  "bearer",
+				Token: "abc"},
+			wantBody: "v1.2.3",
+			errRegex: `^$`},
+		"oauth2 with an unreachable token_url": {
+			auth: &auth.Auth{
+				Type:         "oauth2",
+				TokenURL:     "http://127.0.0.1:0/token",
+				ClientID:     "id",
+				ClientSecret: "secret"},
+			wantBody: "",
+			errRegex: `^oauth2 token request to "[^"]+" failed: `},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(true, false)
+			lookup.URL = server.URL
+			lookup.Auth = tc.auth
+
+			// WHEN httpRequest is called on it
+			body, err := lookup.httpRequest(context.Background(), &util.LogFrom{})
+
+			// THEN any err is expected
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the body is what we expect
+			gotBody := ""
+			if body != nil {
+				gotBody = string(*body)
+			}
+			if gotBody != tc.wantBody {
+				t.Errorf("want body %q, got %q",
+					tc.wantBody, gotBody)
+			}
+		})
+	}
+}
+
 func TestLookup_Query(t *testing.T) {
 	// GIVEN a Lookup
 	tests := map[string]struct {
@@ -247,7 +353,7 @@ func TestLookup_Query(t *testing.T) {
 				lookup.Require.Docker = tc.requireDockerCheck
 
 				// WHEN Query is called on it
-				_, err := lookup.Query(true, &util.LogFrom{})
+				_, err := lookup.Query(context.Background(), true, &util.LogFrom{})
 
 				// THEN any err is expected
 				stdout := releaseStdout()
@@ -307,7 +413,7 @@ func TestLookup_Query__EmptyListETagChanged(t *testing.T) {
 		lookup.URLCommands[0].Regex = test.StringPtr("v([0-9.]+)")
 
 		// WHEN Query is called on it
-		_, err := lookup.Query(true, &util.LogFrom{})
+		_, err := lookup.Query(context.Background(), true, &util.LogFrom{})
 
 		// THEN any err is expected
 		stdout := releaseStdout()
@@ -389,7 +495,7 @@ no releases were found matching the url_commands and/or require`},
 					lookup.Require = &filter.Require{}
 				}
 
-				_, err := lookup.Query(true, &util.LogFrom{})
+				_, err := lookup.Query(context.Background(), true, &util.LogFrom{})
 				if err != nil {
 					errors += "--" + err.Error()
 				}
@@ -419,3 +525,52 @@ no releases were found matching the url_commands and/or require`},
 		})
 	}
 }
+
+func TestQueryFailed(t *testing.T) {
+	// GIVEN an error from Query
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil": {
+			err:  nil,
+			want: false},
+		"request failed": {
+			err:  errors.New("x509 (certificate invalid)"),
+			want: true},
+		"not semantic": {
+			err:  errors.New(`failed converting "foo" to a semantic version.`),
+			want: true},
+		"older version": {
+			err: &queryError{
+				sentinel: ErrOlderVersion,
+				err:      errors.New(`queried version "1.2.3" is less than the deployed version "1.2.4"`)},
+			want: false},
+		"no releases": {
+			err:  ErrNoMatchingRelease,
+			want: false},
+		"every release rejected by require": {
+			err: &queryError{
+				sentinel: ErrNoMatchingRelease,
+				err:      errors.New(`min_age 1h0m0s not reached for version "1.2.3" (eligible at 2024-01-01T01:00:00Z)`)},
+			want: false},
+		"wrapped": {
+			err:  fmt.Errorf("all sources failed: %w", ErrNoMatchingRelease),
+			want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN QueryFailed is called on it
+			got := QueryFailed(tc.err)
+
+			// THEN it returns whether the query failed
+			if got != tc.want {
+				t.Errorf("want: %t\ngot:  %t",
+					tc.want, got)
+			}
+		})
+	}
+}
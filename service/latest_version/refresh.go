@@ -15,6 +15,7 @@
 package latestver
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/release-argus/Argus/service/latest_version/filter"
@@ -96,6 +97,8 @@ func (l *Lookup) applyOverrides(
 		useUsePreRelease,
 		l.Defaults,
 		l.HardDefaults)
+	// Auth isn't overridable.
+	lookup.Auth = l.Auth
 	lookup.Status = &svcstatus.Status{
 		ServiceID: serviceID}
 	lookup.Options.Defaults = l.Options.Defaults
@@ -180,7 +183,7 @@ func (l *Lookup) Refresh(
 		usePreRelease != nil
 
 	// Query the lookup.
-	_, err = lookup.Query(!overrides, logFrom)
+	_, err = lookup.Query(context.Background(), !overrides, logFrom)
 	if err != nil {
 		return
 	}
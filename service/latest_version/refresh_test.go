@@ -17,6 +17,7 @@
 package latestver
 
 import (
+	"context"
 	"os"
 	"regexp"
 	"testing"
@@ -353,11 +354,11 @@ func TestLookup_ApplyOverrides(t *testing.T) {
 
 func TestLookup_Refresh(t *testing.T) {
 	testURL := testLookup(true, true)
-	testURL.Query(true, &util.LogFrom{})
+	testURL.Query(context.Background(), true, &util.LogFrom{})
 	testVersionURL := testURL.Status.LatestVersion()
 	testGitHub := testLookup(false, false)
 	testGitHub.AccessToken = test.StringPtr(os.Getenv("GITHUB_TOKEN"))
-	testGitHub.Query(true, &util.LogFrom{})
+	testGitHub.Query(context.Background(), true, &util.LogFrom{})
 	testVersionGitHub := testGitHub.Status.LatestVersion()
 
 	// GIVEN a Lookup and various json strings to override parts of it
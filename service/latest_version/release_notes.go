@@ -0,0 +1,241 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latestver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/release-argus/Argus/ratelimit"
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	changelogHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	changelogVersionRegex = regexp.MustCompile(`v?([0-9]+\.[0-9]+(?:\.[0-9]+)?(?:-[0-9A-Za-z.-]+)?)`)
+)
+
+// ReleaseNote is the release notes of a single version.
+type ReleaseNote struct {
+	Version     string `json:"version"`
+	Body        string `json:"body,omitempty"`
+	URL         string `json:"url,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+// ReleaseNotes returns the release notes of every version after the DeployedVersion
+// up to and including the LatestVersion (newest first).
+//
+// The notes come from the ChangelogURL if set, otherwise from the GitHub release bodies.
+func (l *Lookup) ReleaseNotes(logFrom *util.LogFrom) (notes []ReleaseNote, err error) {
+	to := l.Status.LatestVersion()
+	if to == "" {
+		return
+	}
+	from := l.Status.DeployedVersion()
+
+	var candidates []ReleaseNote
+	switch {
+	case l.ChangelogURL != "":
+		var body string
+		body, err = l.getChangelog(logFrom)
+		if err != nil {
+			return
+		}
+		candidates = parseChangelog(body)
+	case l.Type == "github":
+		candidates = l.gitHubReleaseNotes(logFrom)
+	default:
+		return
+	}
+
+	notes = selectReleaseNotes(candidates, from, to, l.Options.GetSemanticVersioning())
+	return
+}
+
+// CacheReleaseNotes will cache the ReleaseNotes (as Markdown) in the Status,
+// unless they're already cached for the DeployedVersion and LatestVersion.
+func (l *Lookup) CacheReleaseNotes(logFrom *util.LogFrom) {
+	if l.Status.ReleaseNotesCached() {
+		return
+	}
+	from, to := l.Status.DeployedVersion(), l.Status.LatestVersion()
+
+	var notes []ReleaseNote
+	// No versions after the deployed version.
+	if from != to {
+		var err error
+		if notes, err = l.ReleaseNotes(logFrom); err != nil {
+			// Try again next time.
+			jLog.Warn(err, logFrom, true)
+			return
+		}
+	}
+
+	sections := make([]string, len(notes))
+	for i := range notes {
+		sections[i] = fmt.Sprintf("## %s\n\n%s", notes[i].Version, notes[i].Body)
+	}
+	l.Status.SetReleaseNotes(from, to, strings.Join(sections, "\n\n"))
+}
+
+// gitHubReleaseNotes returns the release notes of the GitHub releases (newest first).
+func (l *Lookup) gitHubReleaseNotes(logFrom *util.LogFrom) (notes []ReleaseNote) {
+	if l.GitHubData == nil {
+		return
+	}
+
+	semanticVersioning := l.Options.GetSemanticVersioning()
+	releases := l.filterGitHubReleases(logFrom)
+	notes = make([]ReleaseNote, len(releases))
+	for i := range releases {
+		version := releases[i].TagName
+		if semanticVersioning && releases[i].SemanticVersion != nil {
+			version = releases[i].SemanticVersion.String()
+		}
+		notes[i] = ReleaseNote{
+			Version:     version,
+			Body:        releases[i].Body,
+			URL:         releases[i].HTMLURL,
+			PublishedAt: releases[i].PublishedAt}
+	}
+	return
+}
+
+// getChangelog returns the body of the ChangelogURL.
+func (l *Lookup) getChangelog(logFrom *util.LogFrom) (body string, err error) {
+	customTransport := &http.Transport{}
+	// HTTPS insecure skip verify.
+	if l.GetAllowInvalidCerts() {
+		customTransport = http.DefaultTransport.(*http.Transport).Clone()
+		//#nosec G402 -- explicitly wanted InsecureSkipVerify
+		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	changelogURL := util.TemplateString(l.ChangelogURL,
+		util.ServiceInfo{LatestVersion: l.Status.LatestVersion()})
+	req, err := http.NewRequest(http.MethodGet, changelogURL, nil)
+	if err != nil {
+		err = fmt.Errorf("failed creating http request for %q: %w",
+			changelogURL, err)
+		return
+	}
+	req.Header.Set("Connection", "close")
+
+	client := &http.Client{
+		Transport: ratelimit.Transport(customTransport, logFrom),
+		Timeout:   util.HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("changelog_url %q returned status %d",
+			changelogURL, resp.StatusCode)
+		return
+	}
+
+	rawBody, err := io.ReadAll(resp.Body)
+	body = string(rawBody)
+	jLog.Debug(fmt.Sprintf("changelog_url %q gave %d bytes", changelogURL, len(rawBody)),
+		logFrom, err == nil)
+	return
+}
+
+// parseChangelog will split a Markdown CHANGELOG into a ReleaseNote for every heading containing a version.
+//
+// A version section continues until the next heading of the same or higher level.
+func parseChangelog(changelog string) (notes []ReleaseNote) {
+	var (
+		current      *ReleaseNote
+		currentLevel int
+		body         []string
+	)
+	finish := func() {
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			notes = append(notes, *current)
+		}
+		current = nil
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(changelog, "\r\n", "\n"), "\n") {
+		heading := changelogHeadingRegex.FindStringSubmatch(line)
+		// Not a heading, or a sub-heading of this version.
+		if heading == nil || (current != nil && len(heading[1]) > currentLevel) {
+			if current != nil {
+				body = append(body, line)
+			}
+			continue
+		}
+
+		finish()
+		if version := changelogVersionRegex.FindStringSubmatch(heading[2]); version != nil {
+			current = &ReleaseNote{Version: version[1]}
+			currentLevel = len(heading[1])
+		}
+	}
+	finish()
+
+	return
+}
+
+// selectReleaseNotes returns the `notes` with a version after `from`, up to and including `to`.
+//
+// With semantic versioning, the versions are compared, otherwise `notes` are assumed to be ordered newest first.
+func selectReleaseNotes(notes []ReleaseNote, from string, to string, semanticVersioning bool) (selected []ReleaseNote) {
+	if semanticVersioning {
+		toVersion, toErr := semver.NewVersion(to)
+		fromVersion, fromErr := semver.NewVersion(from)
+		if toErr == nil {
+			for i := range notes {
+				version, err := semver.NewVersion(notes[i].Version)
+				if err != nil ||
+					version.GreaterThan(toVersion) ||
+					(fromErr == nil && !version.GreaterThan(fromVersion)) {
+					continue
+				}
+				selected = append(selected, notes[i])
+			}
+			return
+		}
+	}
+
+	// Positional
+	from = strings.TrimPrefix(from, "v")
+	to = strings.TrimPrefix(to, "v")
+	found := false
+	for i := range notes {
+		version := strings.TrimPrefix(notes[i].Version, "v")
+		if version == from {
+			break
+		}
+		if version == to {
+			found = true
+		}
+		if found {
+			selected = append(selected, notes[i])
+		}
+	}
+	return
+}
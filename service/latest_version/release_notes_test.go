@@ -0,0 +1,284 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package latestver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/util"
+)
+
+var testChangelog = `# Changelog
+
+## [Unreleased]
+- something in progress
+
+## [1.3.0] - 2023-03-01
+### Added
+- feature c
+
+## [1.2.1] - 2023-02-01
+- fix b
+
+## [1.2.0] - 2023-01-01
+- feature a
+`
+
+func TestParseChangelog(t *testing.T) {
+	// GIVEN a CHANGELOG
+	tests := map[string]struct {
+		changelog string
+		want      []ReleaseNote
+	}{
+		"empty": {
+			changelog: "",
+			want:      nil},
+		"no versions": {
+			changelog: "# Changelog\n\nNothing here",
+			want:      nil},
+		"keep-a-changelog": {
+			changelog: testChangelog,
+			want: []ReleaseNote{
+				{Version: "1.3.0", Body: "### Added\n- feature c"},
+				{Version: "1.2.1", Body: "- fix b"},
+				{Version: "1.2.0", Body: "- feature a"}}},
+		"v prefix and CRLF": {
+			changelog: "# v2.0.0\r\nbreaking\r\n# v1.0.0 (initial)\r\nfirst",
+			want: []ReleaseNote{
+				{Version: "2.0.0", Body: "breaking"},
+				{Version: "1.0.0", Body: "first"}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN parseChangelog is called on it
+			got := parseChangelog(tc.changelog)
+
+			// THEN the release notes are split as expected
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("want:\n%q\ngot:\n%q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectReleaseNotes(t *testing.T) {
+	// GIVEN release notes, and the versions to get the notes between
+	notes := []ReleaseNote{
+		{Version: "1.3.0"},
+		{Version: "1.2.1"},
+		{Version: "1.2.0"},
+		{Version: "1.1.0"}}
+	tests := map[string]struct {
+		from, to           string
+		semanticVersioning bool
+		want               []string
+	}{
+		"semantic - between": {
+			from: "1.1.0", to: "1.2.1",
+			semanticVersioning: true,
+			want:               []string{"1.2.1", "1.2.0"}},
+		"semantic - from not listed": {
+			from: "1.1.5", to: "1.3.0",
+			semanticVersioning: true,
+			want:               []string{"1.3.0", "1.2.1", "1.2.0"}},
+		"semantic - no from": {
+			from: "", to: "1.2.0",
+			semanticVersioning: true,
+			want:               []string{"1.2.0", "1.1.0"}},
+		"semantic - same version": {
+			from: "1.3.0", to: "1.3.0",
+			semanticVersioning: true,
+			want:               nil},
+		"positional - between": {
+			from: "1.1.0", to: "1.2.1",
+			semanticVersioning: false,
+			want:               []string{"1.2.1", "1.2.0"}},
+		"positional - v prefix": {
+			from: "v1.2.0", to: "v1.3.0",
+			semanticVersioning: false,
+			want:               []string{"1.3.0", "1.2.1"}},
+		"positional - to not found": {
+			from: "1.1.0", to: "2.0.0",
+			semanticVersioning: false,
+			want:               nil},
+		"non-semantic to falls back to positional": {
+			from: "1.2.0", to: "1.3.0-foo_bar",
+			semanticVersioning: true,
+			want:               nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN selectReleaseNotes is called
+			got := selectReleaseNotes(notes, tc.from, tc.to, tc.semanticVersioning)
+
+			// THEN the expected versions are selected
+			var gotVersions []string
+			for i := range got {
+				gotVersions = append(gotVersions, got[i].Version)
+			}
+			if fmt.Sprint(gotVersions) != fmt.Sprint(tc.want) {
+				t.Errorf("want %v, got %v",
+					tc.want, gotVersions)
+			}
+		})
+	}
+}
+
+func TestLookup_ReleaseNotes(t *testing.T) {
+	// GIVEN a Lookup with a deployed and latest version
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/CHANGELOG-1.3.0.md" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, testChangelog)
+	}))
+	t.Cleanup(server.Close)
+	tests := map[string]struct {
+		urlType       bool
+		changelogURL  string
+		latestVersion string
+		wantVersions  []string
+		wantMarkdown  string
+		errRegex      string
+	}{
+		"no latest version": {
+			latestVersion: "",
+			errRegex:      "^$"},
+		"github release bodies": {
+			latestVersion: "1.3.0",
+			wantVersions:  []string{"1.3.0", "1.2.1"},
+			wantMarkdown:  "## 1.3.0\n\nnotes c\n\n## 1.2.1\n\nnotes b",
+			errRegex:      "^$"},
+		"url without changelog_url": {
+			urlType:       true,
+			latestVersion: "1.3.0",
+			errRegex:      "^$"},
+		"changelog_url": {
+			urlType:       true,
+			changelogURL:  server.URL + "/CHANGELOG-{{ version }}.md",
+			latestVersion: "1.3.0",
+			wantVersions:  []string{"1.3.0", "1.2.1"},
+			wantMarkdown:  "## 1.3.0\n\n### Added\n- feature c\n\n## 1.2.1\n\n- fix b",
+			errRegex:      "^$"},
+		"changelog_url not found": {
+			changelogURL:  server.URL + "/unknown",
+			latestVersion: "1.3.0",
+			errRegex:      `changelog_url ".+/unknown" returned status 404`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(tc.urlType, false)
+			lookup.ChangelogURL = tc.changelogURL
+			if !tc.urlType {
+				lookup.GitHubData.SetReleases([]github_types.Release{
+					{TagName: "v1.3.0", Body: "notes c"},
+					{TagName: "v1.2.1", Body: "notes b"},
+					{TagName: "v1.2.0", Body: "notes a"}})
+			}
+			lookup.Status.SetDeployedVersion("1.2.0", false)
+			lookup.Status.SetLatestVersion(tc.latestVersion, false)
+
+			// WHEN ReleaseNotes is called on it
+			got, err := lookup.ReleaseNotes(&util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the notes are for the expected versions
+			var gotVersions []string
+			for i := range got {
+				gotVersions = append(gotVersions, got[i].Version)
+			}
+			if fmt.Sprint(gotVersions) != fmt.Sprint(tc.wantVersions) {
+				t.Errorf("want versions %v, got %v",
+					tc.wantVersions, gotVersions)
+			}
+			// AND CacheReleaseNotes caches them as Markdown (unless they failed)
+			lookup.CacheReleaseNotes(&util.LogFrom{})
+			if gotMarkdown := lookup.Status.ReleaseNotes(); gotMarkdown != tc.wantMarkdown {
+				t.Errorf("want Markdown:\n%q\ngot:\n%q",
+					tc.wantMarkdown, gotMarkdown)
+			}
+			if gotCached := lookup.Status.ReleaseNotesCached(); gotCached != (err == nil) {
+				t.Errorf("want cached=%t, got %t",
+					err == nil, gotCached)
+			}
+		})
+	}
+}
+
+func TestLookup_CacheReleaseNotes(t *testing.T) {
+	// GIVEN a Lookup with a changelog_url
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, testChangelog)
+	}))
+	t.Cleanup(server.Close)
+	lookup := testLookup(true, false)
+	lookup.ChangelogURL = server.URL + "/CHANGELOG.md"
+	lookup.Status.SetDeployedVersion("1.2.0", false)
+	lookup.Status.SetLatestVersion("1.3.0", false)
+
+	// WHEN CacheReleaseNotes is called multiple times for the same versions
+	lookup.CacheReleaseNotes(&util.LogFrom{})
+	lookup.CacheReleaseNotes(&util.LogFrom{})
+
+	// THEN the changelog is only fetched once
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("want the changelog fetched once, got %d",
+			got)
+	}
+	if got := lookup.Status.ReleaseNotes(); got == "" {
+		t.Error("want the release notes cached, got none")
+	}
+
+	// WHEN the deployed version becomes the latest version
+	lookup.Status.SetDeployedVersion("1.3.0", false)
+	lookup.CacheReleaseNotes(&util.LogFrom{})
+
+	// THEN there are no release notes, and the changelog isn't fetched
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("want the changelog not to be fetched again, got %d requests",
+			got)
+	}
+	if got := lookup.Status.ReleaseNotes(); got != "" {
+		t.Errorf("want no release notes, got %q",
+			got)
+	}
+}
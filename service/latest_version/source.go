@@ -0,0 +1,403 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latestver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/service/latest_version/filter"
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+// Strategies for combining the versions of multiple sources.
+const (
+	StrategyFirstSuccess = "first_success" // Use the first source that doesn't error (in order)
+	StrategyAgree        = "agree"         // Only accept a version once `quorum` sources report it
+)
+
+// Source is an additional source to query for the latest version.
+type Source struct {
+	Type        string `yaml:"type,omitempty" json:"type,omitempty"` // "github"/"URL"
+	URL         string `yaml:"url,omitempty" json:"url,omitempty"`   // type:URL - "https://example.com", type:github - "owner/repo" or "https://github.com/owner/repo".
+	LookupBase  `yaml:",inline" json:",inline"`
+	URLCommands filter.URLCommandSlice `yaml:"url_commands,omitempty" json:"url_commands,omitempty"` // Commands to filter the release from the URL request
+
+	lookup *Lookup // Lookup used to query this Source
+}
+
+// sourceResult is the result of querying a source.
+type sourceResult struct {
+	lookup   *Lookup                // Lookup of the source
+	rawBody  *[]byte                // Body of the query
+	releases []github_types.Release // Releases matching the url_commands (newest first)
+	version  string                 // Version found
+	release  *github_types.Release  // Release of the version (type:github)
+	err      error                  // Error from the query
+}
+
+// initSources gives each of the Sources a Lookup that shares this Lookup's
+// Require, Options, Status and Defaults.
+//
+// The Require is only checked on the releases of the source that decides the version (see queryLatest).
+func (l *Lookup) initSources() {
+	for _, source := range l.Sources {
+		var githubData *GitHubData
+		if source.Type == "github" {
+			githubData = NewGitHubData("", nil)
+		}
+		urlCommands := source.URLCommands
+		source.lookup = New(
+			source.AccessToken,
+			source.AllowInvalidCerts,
+			githubData,
+			l.Options,
+			l.Require,
+			l.Status,
+			source.Type,
+			source.URL,
+			&urlCommands,
+			source.UsePreRelease,
+			l.Defaults,
+			l.HardDefaults)
+	}
+}
+
+// GetStrategy returns the Strategy, defaulting to first_success.
+func (l *Lookup) GetStrategy() string {
+	if l.Strategy == "" {
+		return StrategyFirstSuccess
+	}
+	return l.Strategy
+}
+
+// GetQuorum returns the number of sources that have to agree on a version
+// (defaults to 2, capped at the number of sources).
+func (l *Lookup) GetQuorum() int {
+	sources := len(l.Sources) + 1
+	quorum := l.Quorum
+	if quorum == 0 {
+		quorum = 2
+	}
+	if quorum > sources {
+		quorum = sources
+	}
+	return quorum
+}
+
+// sourceName returns the name of this Lookup as a source, e.g. "github:release-argus/Argus".
+func (l *Lookup) sourceName() string {
+	return fmt.Sprintf("%s:%s", l.Type, l.URL)
+}
+
+// querySource queries just this Lookup, returning the version found.
+func (l *Lookup) querySource(ctx context.Context, logFrom *util.LogFrom) (result sourceResult) {
+	result.lookup = l
+	result.rawBody, result.err = l.httpRequest(ctx, logFrom)
+	if result.err == nil {
+		result.version, result.release, result.err = l.getRelease(ctx, result.rawBody, logFrom)
+	}
+	return
+}
+
+// querySourceReleases queries just this Lookup, returning its newest release matching the url_commands
+// (without checking the Require).
+func (l *Lookup) querySourceReleases(ctx context.Context, logFrom *util.LogFrom) (result sourceResult) {
+	result.lookup = l
+	result.rawBody, result.err = l.httpRequest(ctx, logFrom)
+	if result.err == nil {
+		result.releases, result.err = l.getReleases(result.rawBody, logFrom)
+	}
+	if result.err == nil {
+		if len(result.releases) == 0 {
+			result.err = ErrNoMatchingRelease
+			return
+		}
+		result.release = &result.releases[0]
+		result.version = l.releaseVersion(result.release)
+	}
+	l.sourceMetrics(result.err)
+	return
+}
+
+// queryLatest queries the source(s) and returns the result from the deciding source.
+//
+// With multiple sources, the Require is only checked on the releases of the deciding source.
+func (l *Lookup) queryLatest(ctx context.Context, logFrom *util.LogFrom) (result sourceResult) {
+	if len(l.Sources) == 0 {
+		return l.querySource(ctx, logFrom)
+	}
+
+	lookups := make([]*Lookup, 0, len(l.Sources)+1)
+	lookups = append(lookups, l)
+	for _, source := range l.Sources {
+		lookups = append(lookups, source.lookup)
+	}
+
+	switch l.GetStrategy() {
+	case StrategyAgree:
+		result = l.queryAgree(ctx, lookups, logFrom)
+	default:
+		result = l.queryFirstSuccess(ctx, lookups, logFrom)
+	}
+	if result.err != nil {
+		return
+	}
+
+	result.version, result.release, result.err = result.lookup.requireRelease(
+		ctx, result.releases, result.rawBody, sourceLogFrom(logFrom, result.lookup))
+	if result.err == nil {
+		l.Status.SetLatestVersionSource(result.lookup.sourceName())
+	}
+	return
+}
+
+// queryFirstSuccess returns the result of the first of `lookups` to not error.
+func (l *Lookup) queryFirstSuccess(ctx context.Context, lookups []*Lookup, logFrom *util.LogFrom) (result sourceResult) {
+	results := make([]sourceResult, 0, len(lookups))
+	for _, lookup := range lookups {
+		result = lookup.querySourceReleases(ctx, sourceLogFrom(logFrom, lookup))
+		if result.err == nil {
+			return
+		}
+		results = append(results, result)
+	}
+
+	result.lookup = l
+	result.err = allSourcesFailed(results)
+	return
+}
+
+// queryAgree returns the result for the newest version listed by at least Quorum of `lookups`
+// (querying them all at once).
+//
+// Each source counts towards every version in its releases, so a source that's a release behind
+// still agrees on the versions before. With semantic versioning, the newest of the versions that
+// reach the Quorum is used, otherwise the first to reach it in the releases of the earliest source.
+func (l *Lookup) queryAgree(ctx context.Context, lookups []*Lookup, logFrom *util.LogFrom) (result sourceResult) {
+	results := make([]sourceResult, len(lookups))
+	var wg sync.WaitGroup
+	for i, lookup := range lookups {
+		wg.Add(1)
+		go func(i int, lookup *Lookup) {
+			defer wg.Done()
+			results[i] = lookup.querySourceReleases(ctx, sourceLogFrom(logFrom, lookup))
+		}(i, lookup)
+	}
+	wg.Wait()
+
+	return l.agreedResult(results, logFrom)
+}
+
+// agreedResult returns the result of the newest version listed by at least Quorum of the `results`,
+// with the releases of the source that listed it first cut down to just that version.
+func (l *Lookup) agreedResult(results []sourceResult, logFrom *util.LogFrom) (result sourceResult) {
+	quorum := l.GetQuorum()
+	semanticVersioning := l.Options.GetSemanticVersioning()
+
+	// Count the sources listing each version.
+	counts := make(map[string]int)
+	var failed int
+	for i := range results {
+		if results[i].err != nil {
+			failed++
+			continue
+		}
+		listed := make(map[string]bool, len(results[i].releases))
+		for j := range results[i].releases {
+			version := results[i].lookup.releaseVersion(&results[i].releases[j])
+			if !listed[version] {
+				listed[version] = true
+				counts[version]++
+			}
+		}
+	}
+	if failed == len(results) {
+		result.lookup = l
+		result.err = allSourcesFailed(results)
+		jLog.Warn(result.err, logFrom, true)
+		return
+	}
+
+	var chosen *sourceResult
+	var chosenIndex int
+	var chosenSemVer *semver.Version
+	for i := range results {
+		if results[i].err != nil {
+			continue
+		}
+		for j := range results[i].releases {
+			version := results[i].lookup.releaseVersion(&results[i].releases[j])
+			if counts[version] < quorum {
+				continue
+			}
+			if chosen == nil {
+				chosen, chosenIndex = &results[i], j
+				if !semanticVersioning {
+					break
+				}
+				chosenSemVer, _ = semver.NewVersion(version)
+				continue
+			}
+			// Prefer the newest of the agreed versions.
+			if semVer, err := semver.NewVersion(version); err == nil &&
+				chosenSemVer != nil && semVer.GreaterThan(chosenSemVer) {
+				chosen, chosenIndex, chosenSemVer = &results[i], j, semVer
+			}
+		}
+		if chosen != nil && !semanticVersioning {
+			break
+		}
+	}
+
+	if chosen == nil {
+		found := make([]string, 0, len(results))
+		for i := range results {
+			if results[i].err == nil {
+				found = append(found, fmt.Sprintf("%s=%q", results[i].lookup.sourceName(), results[i].version))
+			}
+		}
+		result.lookup = l
+		result.err = &queryError{
+			sentinel: ErrNoMatchingRelease,
+			err: fmt.Errorf("no version was reported by %d sources (found [%s])",
+				quorum, strings.Join(found, ", "))}
+		jLog.Warn(result.err, logFrom, true)
+		return
+	}
+
+	// Only the agreed version can be used.
+	chosen.releases = chosen.releases[chosenIndex : chosenIndex+1]
+	chosen.release = &chosen.releases[0]
+	chosen.version = chosen.lookup.releaseVersion(chosen.release)
+	return *chosen
+}
+
+// allSourcesFailed returns the error for the failed `results` of every source,
+// which is an ErrNoMatchingRelease if that's what they all failed with.
+func allSourcesFailed(results []sourceResult) error {
+	errs := make([]string, len(results))
+	noMatch := true
+	for i := range results {
+		errs[i] = fmt.Sprintf("%s: %s", results[i].lookup.sourceName(), results[i].err)
+		noMatch = noMatch && errors.Is(results[i].err, ErrNoMatchingRelease)
+	}
+
+	err := fmt.Errorf("all sources failed:\n%s",
+		strings.Join(errs, "\n"))
+	if noMatch {
+		return &queryError{sentinel: ErrNoMatchingRelease, err: err}
+	}
+	return err
+}
+
+// sourceLogFrom returns the LogFrom for querying the `lookup` source.
+func sourceLogFrom(logFrom *util.LogFrom, lookup *Lookup) *util.LogFrom {
+	return &util.LogFrom{Primary: logFrom.Primary, Secondary: lookup.sourceName()}
+}
+
+// sourceMetrics sets the Prometheus metrics for a query of this source.
+func (l *Lookup) sourceMetrics(err error) {
+	if l.Status.ServiceID == nil {
+		return
+	}
+
+	result := "SUCCESS"
+	if err != nil {
+		result = "FAIL"
+	}
+	metric.IncreasePrometheusCounter(metric.LatestVersionSourceMetric,
+		l.sourceName(),
+		*l.Status.ServiceID,
+		"",
+		result)
+}
+
+// initSourceMetrics for the sources of this Lookup.
+func (l *Lookup) initSourceMetrics() {
+	if len(l.Sources) == 0 {
+		return
+	}
+
+	lookups := []*Lookup{l}
+	for _, source := range l.Sources {
+		lookups = append(lookups, source.lookup)
+	}
+	for _, lookup := range lookups {
+		for _, result := range []string{"SUCCESS", "FAIL"} {
+			metric.InitPrometheusCounter(metric.LatestVersionSourceMetric,
+				lookup.sourceName(),
+				*l.Status.ServiceID,
+				"",
+				result)
+		}
+	}
+}
+
+// deleteSourceMetrics for the sources of this Lookup.
+func (l *Lookup) deleteSourceMetrics() {
+	if len(l.Sources) == 0 {
+		return
+	}
+
+	lookups := []*Lookup{l}
+	for _, source := range l.Sources {
+		lookups = append(lookups, source.lookup)
+	}
+	for _, lookup := range lookups {
+		for _, result := range []string{"SUCCESS", "FAIL"} {
+			metric.DeletePrometheusCounter(metric.LatestVersionSourceMetric,
+				lookup.sourceName(),
+				*l.Status.ServiceID,
+				"",
+				result)
+		}
+	}
+}
+
+// CheckValues of the Source.
+func (s *Source) CheckValues(prefix string) (errs error) {
+	if s.URL == "" {
+		errs = fmt.Errorf("%s%surl: <required> e.g. github:'release-argus/Argus' or url:'https://example.com'\\",
+			util.ErrorToString(errs), prefix)
+	}
+	if s.Type != "url" && s.Type != "github" {
+		errType := "<required>"
+		if s.Type != "" {
+			errType = fmt.Sprintf("%q <invalid>", s.Type)
+		}
+		errs = fmt.Errorf("%s%stype: %s e.g. github or url\\",
+			util.ErrorToString(errs), prefix, errType)
+	}
+	if s.Type == "github" && strings.Count(s.URL, "/") > 1 {
+		parts := strings.Split(s.URL, "/")
+		s.URL = strings.Join(parts[len(parts)-2:], "/")
+		if s.lookup != nil {
+			s.lookup.URL = s.URL
+		}
+	}
+
+	if urlCommandErrs := s.URLCommands.CheckValues(prefix); urlCommandErrs != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), urlCommandErrs)
+	}
+	return
+}
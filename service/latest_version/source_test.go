@@ -0,0 +1,398 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package latestver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/service/latest_version/filter"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+)
+
+func TestLookup_GetQuorum(t *testing.T) {
+	// GIVEN a Lookup with Sources and a Quorum
+	tests := map[string]struct {
+		sources int
+		quorum  int
+		want    int
+	}{
+		"default": {
+			sources: 2, quorum: 0, want: 2},
+		"default capped at the number of sources": {
+			sources: 0, quorum: 0, want: 1},
+		"set": {
+			sources: 3, quorum: 3, want: 3},
+		"set above the number of sources": {
+			sources: 1, quorum: 5, want: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := Lookup{Quorum: tc.quorum}
+			for i := 0; i < tc.sources; i++ {
+				lookup.Sources = append(lookup.Sources, &Source{})
+			}
+
+			// WHEN GetQuorum is called
+			got := lookup.GetQuorum()
+
+			// THEN the quorum is what we expect
+			if got != tc.want {
+				t.Errorf("want %d, got %d",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup_QueryLatest(t *testing.T) {
+	// GIVEN a Lookup with multiple Sources
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			// Drop the connection.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case "/none":
+			// No releases.
+		default:
+			fmt.Fprintf(w, "ver%s", strings.TrimPrefix(r.URL.Path, "/"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	tests := map[string]struct {
+		strategy        string
+		quorum          int
+		primary         string
+		sources         []string
+		regexVersion    string
+		wantVersion     string
+		wantSource      string
+		errRegex        string
+		wantNoMatch     bool
+		wantRegexMisses uint
+	}{
+		"no sources": {
+			primary:     "1.2.3",
+			wantVersion: "1.2.3",
+			wantSource:  "",
+			errRegex:    "^$"},
+		"first_success - primary succeeds": {
+			primary:     "1.2.3",
+			sources:     []string{"1.2.4"},
+			wantVersion: "1.2.3",
+			wantSource:  "/1.2.3",
+			errRegex:    "^$"},
+		"first_success - falls back to a source": {
+			primary:     "fail",
+			sources:     []string{"fail", "1.2.4"},
+			wantVersion: "1.2.4",
+			wantSource:  "/1.2.4",
+			errRegex:    "^$"},
+		"first_success - all fail": {
+			primary:  "fail",
+			sources:  []string{"fail"},
+			errRegex: "^all sources failed"},
+		"first_success - all have no releases": {
+			primary:     "none",
+			sources:     []string{"none"},
+			errRegex:    `^all sources failed:\nurl:.+/none: no releases were found matching the url_commands and/or require\n`,
+			wantNoMatch: true},
+		"first_success - require only checked on the first success": {
+			primary:         "1.2.3",
+			sources:         []string{"1.2.4"},
+			regexVersion:    `^1\.2\.4$`,
+			wantVersion:     "1.2.3",
+			errRegex:        `^regex not matched on version "1.2.3"$`,
+			wantNoMatch:     true,
+			wantRegexMisses: 1},
+		"agree - quorum reached": {
+			strategy:    StrategyAgree,
+			primary:     "1.2.4",
+			sources:     []string{"1.2.3", "1.2.3"},
+			wantVersion: "1.2.3",
+			wantSource:  "/1.2.3",
+			errRegex:    "^$"},
+		"agree - quorum reached despite a failure": {
+			strategy:    StrategyAgree,
+			primary:     "fail",
+			sources:     []string{"1.2.3", "1.2.3"},
+			wantVersion: "1.2.3",
+			wantSource:  "/1.2.3",
+			errRegex:    "^$"},
+		"agree - newest of the agreed versions": {
+			strategy:    StrategyAgree,
+			quorum:      1,
+			primary:     "1.2.3",
+			sources:     []string{"1.2.4"},
+			wantVersion: "1.2.4",
+			wantSource:  "/1.2.4",
+			errRegex:    "^$"},
+		"agree - require only checked on the agreed version": {
+			strategy:        StrategyAgree,
+			primary:         "1.2.3",
+			sources:         []string{"1.2.3", "1.2.3"},
+			regexVersion:    `^1\.2\.4$`,
+			wantVersion:     "1.2.3",
+			errRegex:        `^regex not matched on version "1.2.3"$`,
+			wantNoMatch:     true,
+			wantRegexMisses: 1},
+		"agree - all fail": {
+			strategy: StrategyAgree,
+			primary:  "fail",
+			sources:  []string{"fail"},
+			errRegex: `^all sources failed:\nurl:.+/fail: .*\nurl:.+/fail: `},
+		"agree - quorum not reached": {
+			strategy:    StrategyAgree,
+			primary:     "1.2.3",
+			sources:     []string{"1.2.4", "fail"},
+			errRegex:    `^no version was reported by 2 sources \(found \[url:.+/1.2.3="1.2.3", url:.+/1.2.4="1.2.4"\]\)$`,
+			wantNoMatch: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(true, false)
+			lookup.URL = server.URL + "/" + tc.primary
+			lookup.Strategy = tc.strategy
+			lookup.Quorum = tc.quorum
+			lookup.Require.RegexVersion = tc.regexVersion
+			lookup.Require.Status = lookup.Status
+			for _, source := range tc.sources {
+				lookup.Sources = append(lookup.Sources, &Source{
+					Type:       "url",
+					URL:        server.URL + "/" + source,
+					LookupBase: LookupBase{AllowInvalidCerts: test.BoolPtr(false)},
+					URLCommands: filter.URLCommandSlice{
+						{Type: "regex", Regex: test.StringPtr("ver([0-9.]+)")}}})
+			}
+			lookup.initSources()
+
+			// WHEN queryLatest is called on it
+			got := lookup.queryLatest(context.Background(), &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(got.err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND it's only an ErrNoMatchingRelease when no release passed the require
+			if gotNoMatch := errors.Is(got.err, ErrNoMatchingRelease); gotNoMatch != tc.wantNoMatch {
+				t.Errorf("want errors.Is(err, ErrNoMatchingRelease)=%t, got %t",
+					tc.wantNoMatch, gotNoMatch)
+			}
+			// AND the require was only checked on the deciding source
+			if got := lookup.Status.RegexMissesVersion(); got != tc.wantRegexMisses {
+				t.Errorf("want %d version regex misses, got %d",
+					tc.wantRegexMisses, got)
+			}
+			// AND the version is what we expect
+			if got.version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, got.version)
+			}
+			// AND the deciding source is recorded in the Status
+			gotSource := lookup.Status.LatestVersionSource()
+			if tc.wantSource == "" && gotSource != "" ||
+				!strings.HasSuffix(gotSource, tc.wantSource) {
+				t.Errorf("want source ending %q, got %q",
+					tc.wantSource, gotSource)
+			}
+		})
+	}
+}
+
+func TestLookup_AgreedResult(t *testing.T) {
+	// GIVEN the releases of multiple sources
+	tests := map[string]struct {
+		quorum             int
+		semanticVersioning bool
+		releases           [][]string
+		wantVersion        string
+		wantSource         int
+		errRegex           string
+	}{
+		"all on the same newest version": {
+			quorum:             2,
+			semanticVersioning: true,
+			releases:           [][]string{{"1.2.4", "1.2.3"}, {"1.2.4", "1.2.3"}},
+			wantVersion:        "1.2.4",
+			errRegex:           "^$"},
+		"a source a release behind": {
+			quorum:             2,
+			semanticVersioning: true,
+			releases:           [][]string{{"1.2.4", "1.2.3"}, {"1.2.3", "1.2.2"}},
+			wantVersion:        "1.2.3",
+			errRegex:           "^$"},
+		"a source a release behind is outvoted": {
+			quorum:             2,
+			semanticVersioning: true,
+			releases:           [][]string{{"1.2.3", "1.2.2"}, {"1.2.4", "1.2.3"}, {"1.2.4", "1.2.3"}},
+			wantVersion:        "1.2.4",
+			wantSource:         1,
+			errRegex:           "^$"},
+		"without semantic versioning, the first agreed version of the earliest source": {
+			quorum:      2,
+			releases:    [][]string{{"b", "a"}, {"a", "b"}},
+			wantVersion: "b",
+			errRegex:    "^$"},
+		"no version reaches the quorum": {
+			quorum:             3,
+			semanticVersioning: true,
+			releases:           [][]string{{"1.2.4", "1.2.3"}, {"1.2.3"}, {"1.2.5"}},
+			errRegex:           `^no version was reported by 3 sources`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(true, false)
+			lookup.Quorum = tc.quorum
+			lookup.Options.SemanticVersioning = &tc.semanticVersioning
+			results := make([]sourceResult, len(tc.releases))
+			for i, versions := range tc.releases {
+				results[i].lookup = lookup
+				rawBody := []byte(fmt.Sprint(i))
+				results[i].rawBody = &rawBody
+				for _, version := range versions {
+					results[i].releases = append(results[i].releases, github_types.Release{TagName: version})
+				}
+				results[i].release = &results[i].releases[0]
+				results[i].version = versions[0]
+				if i != 0 {
+					lookup.Sources = append(lookup.Sources, &Source{})
+				}
+			}
+
+			// WHEN agreedResult is called on them
+			got := lookup.agreedResult(results, &util.LogFrom{})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(got.err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND a quorum miss isn't a failed query
+			if got.err != nil {
+				if !errors.Is(got.err, ErrNoMatchingRelease) {
+					t.Errorf("want errors.Is(err, ErrNoMatchingRelease), got %v",
+						got.err)
+				}
+				return
+			}
+			// AND the agreed version is what we expect
+			if got.version != tc.wantVersion {
+				t.Errorf("want version %q, got %q",
+					tc.wantVersion, got.version)
+			}
+			// AND it's from the source we expect, with only that release
+			if len(got.releases) != 1 || got.releases[0].TagName != tc.wantVersion {
+				t.Errorf("want releases of just %q, got %v",
+					tc.wantVersion, got.releases)
+			}
+			if got.rawBody != results[tc.wantSource].rawBody {
+				t.Errorf("want the result of source %d",
+					tc.wantSource)
+			}
+		})
+	}
+}
+
+func TestLookup_CheckValuesSources(t *testing.T) {
+	// GIVEN a Lookup with Sources
+	tests := map[string]struct {
+		strategy string
+		quorum   int
+		sources  []*Source
+		wantURL  string
+		errRegex string
+	}{
+		"valid": {
+			strategy: StrategyAgree,
+			quorum:   2,
+			sources: []*Source{
+				{Type: "url", URL: "https://example.com"}},
+			errRegex: "^$"},
+		"github url is trimmed": {
+			sources: []*Source{
+				{Type: "github", URL: "https://github.com/release-argus/Argus"}},
+			wantURL:  "release-argus/Argus",
+			errRegex: "^$"},
+		"invalid strategy": {
+			strategy: "majority",
+			errRegex: `^strategy: "majority" <invalid>`},
+		"quorum above the number of sources": {
+			quorum: 3,
+			sources: []*Source{
+				{Type: "url", URL: "https://example.com"}},
+			errRegex: `^quorum: 3 <invalid>`},
+		"source without type or url": {
+			sources: []*Source{
+				{}},
+			errRegex: `^sources:\\  item_0:\\    url: <required>.*\\    type: <required>`},
+		"source with invalid url_commands": {
+			sources: []*Source{
+				{Type: "url", URL: "https://example.com",
+					URLCommands: filter.URLCommandSlice{{Type: "regex"}}}},
+			errRegex: `^sources:\\  item_0:\\    url_commands:\\.*regex: <required>`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := Lookup{
+				Strategy: tc.strategy,
+				Quorum:   tc.quorum,
+				Sources:  tc.sources}
+
+			// WHEN checkValuesSources is called on it
+			err := lookup.checkValuesSources("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the github URL is trimmed to owner/repo
+			if tc.wantURL != "" && lookup.Sources[0].URL != tc.wantURL {
+				t.Errorf("want url %q, got %q",
+					tc.wantURL, lookup.Sources[0].URL)
+			}
+		})
+	}
+}
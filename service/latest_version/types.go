@@ -15,8 +15,11 @@
 package latestver
 
 import (
+	"context"
 	"sync"
 
+	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/service/auth"
 	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
 	"github.com/release-argus/Argus/service/latest_version/filter"
 	opt "github.com/release-argus/Argus/service/options"
@@ -55,7 +58,7 @@ func FindEmptyListETag(accessToken string) {
 	// Fallback to /tags to stop the /tags fallback query if on /releases
 	lookup.GitHubData.SetTagFallback()
 	//nolint:errcheck
-	lookup.httpRequest(&util.LogFrom{Primary: "FindEmptyListETag"})
+	lookup.httpRequest(context.Background(), &util.LogFrom{Primary: "FindEmptyListETag"})
 
 	setEmptyListETag(lookup.GitHubData.ETag())
 }
@@ -105,13 +108,21 @@ type Lookup struct {
 	Type        string `yaml:"type,omitempty" json:"type,omitempty"` // "github"/"URL"
 	URL         string `yaml:"url,omitempty" json:"url,omitempty"`   // type:URL - "https://example.com", type:github - "owner/repo" or "https://github.com/owner/repo".
 	LookupBase  `yaml:",inline" json:",inline"`
+	Auth        *auth.Auth             `yaml:"auth,omitempty" json:"auth,omitempty"`                 // type:URL - Bearer token/OAuth2 client-credentials/API key auth
 	URLCommands filter.URLCommandSlice `yaml:"url_commands,omitempty" json:"url_commands,omitempty"` // Commands to filter the release from the URL request
 	Require     *filter.Require        `yaml:"require,omitempty" json:"require,omitempty"`           // Options to require before a release is considered valid
 
+	Sources  []*Source `yaml:"sources,omitempty" json:"sources,omitempty"`   // Additional sources to query
+	Strategy string    `yaml:"strategy,omitempty" json:"strategy,omitempty"` // How to combine the sources - first_success/agree
+	Quorum   int       `yaml:"quorum,omitempty" json:"quorum,omitempty"`     // Number of sources that have to agree on a version (strategy:agree)
+
+	ChangelogURL string `yaml:"changelog_url,omitempty" json:"changelog_url,omitempty"` // URL of a Markdown CHANGELOG to get the release notes from (instead of the GitHub release bodies)
+
 	GitHubData *GitHubData `yaml:"-" json:"-"` // GitHub Conditional Request vars
 
 	Options *opt.Options      `yaml:"-" json:"-"` // Options
 	Status  *svcstatus.Status `yaml:"-" json:"-"` // Service Status
+	Notify  *shoutrrr.Slice   `yaml:"-" json:"-"` // Service Notify's to notify when the latest version is withdrawn
 
 	Defaults     *LookupDefaults `yaml:"-" json:"-"` // Defaults
 	HardDefaults *LookupDefaults `yaml:"-" json:"-"` // Hard Defaults
@@ -167,9 +178,10 @@ func (l *Lookup) String(prefix string) (str string) {
 
 // GitHubData is data needed in GitHub requests
 type GitHubData struct {
-	eTag        string                 // GitHub ETag for conditional requests https://docs.github.com/en/rest/overview/resources-in-the-rest-api#conditional-requestsl
-	releases    []github_types.Release // Track the ETag releases until they're usable
-	tagFallback bool                   // Whether we've fallen back to using /tags instead of /releases
+	eTag          string                 // GitHub ETag for conditional requests https://docs.github.com/en/rest/overview/resources-in-the-rest-api#conditional-requestsl
+	releases      []github_types.Release // Track the ETag releases until they're usable
+	tagFallback   bool                   // Whether we've fallen back to using /tags instead of /releases
+	latestRelease *github_types.Release  // The release of the latest version
 
 	mutex sync.RWMutex `json:"-"` // Mutex to protect the GitHubData
 }
@@ -269,6 +281,22 @@ func (g *GitHubData) SetTagFallback() {
 	g.tagFallback = !g.tagFallback
 }
 
+// LatestRelease of the GitHubData.
+func (g *GitHubData) LatestRelease() *github_types.Release {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.latestRelease
+}
+
+// SetLatestRelease of the GitHubData.
+func (g *GitHubData) SetLatestRelease(release *github_types.Release) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.latestRelease = release
+}
+
 // Copy the ETag and Releases for the GitHubData.
 func (g *GitHubData) Copy(from *GitHubData) {
 	g.mutex.Lock()
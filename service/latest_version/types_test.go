@@ -223,6 +223,24 @@ func TestGitHubData_TagFallback(t *testing.T) {
 	}
 }
 
+func TestGitHubData_LatestRelease(t *testing.T) {
+	// GIVEN a GitHubData
+	gd := NewGitHubData("", nil)
+	if gd.LatestRelease() != nil {
+		t.Fatalf("latestRelease wasn't nil initially")
+	}
+	release := &github_types.Release{TagName: "v1.2.3"}
+
+	// WHEN SetLatestRelease is called
+	gd.SetLatestRelease(release)
+
+	// THEN LatestRelease returns that release
+	if got := gd.LatestRelease(); got != release {
+		t.Errorf("got %v, want %v",
+			got, release)
+	}
+}
+
 func TestGitHubData_Copy(t *testing.T) {
 	// GIVEN a fresh GitHubData and a GitHubData to copy from
 	tests := map[string]struct {
@@ -55,6 +55,27 @@ func (l *Lookup) CheckValues(prefix string) (errs error) {
 		l.URL = strings.Join(parts[len(parts)-2:], "/")
 	}
 
+	if l.Auth != nil {
+		if l.Type != "url" {
+			errs = fmt.Errorf("%s%s  auth: <invalid> (only supported with type: url)\\",
+				util.ErrorToString(errs), prefix)
+		}
+		if err := l.Auth.CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  auth:\\%w",
+				util.ErrorToString(errs), prefix, err)
+		}
+	}
+
+	if sourceErrs := l.checkValuesSources(prefix + "  "); sourceErrs != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), sourceErrs)
+	}
+
+	if l.ChangelogURL != "" && !util.CheckTemplate(l.ChangelogURL) {
+		errs = fmt.Errorf("%s%s  changelog_url: %q <invalid> (didn't pass templating)\\",
+			util.ErrorToString(errs), prefix, l.ChangelogURL)
+	}
+
 	if requireErrs := l.Require.CheckValues(prefix + "  "); requireErrs != nil {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), requireErrs)
@@ -71,3 +92,28 @@ func (l *Lookup) CheckValues(prefix string) (errs error) {
 
 	return
 }
+
+// checkValuesSources checks the Sources, Strategy and Quorum.
+func (l *Lookup) checkValuesSources(prefix string) (errs error) {
+	if l.Strategy != "" && l.Strategy != StrategyFirstSuccess && l.Strategy != StrategyAgree {
+		errs = fmt.Errorf("%s%sstrategy: %q <invalid> (expected one of [%s, %s])\\",
+			util.ErrorToString(errs), prefix, l.Strategy, StrategyFirstSuccess, StrategyAgree)
+	}
+	if l.Quorum < 0 || l.Quorum > len(l.Sources)+1 {
+		errs = fmt.Errorf("%s%squorum: %d <invalid> (must be between 1 and the number of sources, %d)\\",
+			util.ErrorToString(errs), prefix, l.Quorum, len(l.Sources)+1)
+	}
+
+	var sourceErrs error
+	for index, source := range l.Sources {
+		if err := source.CheckValues(prefix + "    "); err != nil {
+			sourceErrs = fmt.Errorf("%s%s  item_%d:\\%w",
+				util.ErrorToString(sourceErrs), prefix, index, err)
+		}
+	}
+	if sourceErrs != nil {
+		errs = fmt.Errorf("%s%ssources:\\%w",
+			util.ErrorToString(errs), prefix, sourceErrs)
+	}
+	return
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/release-argus/Argus/service/auth"
 	"github.com/release-argus/Argus/service/latest_version/filter"
 	"github.com/release-argus/Argus/test"
 	"github.com/release-argus/Argus/util"
@@ -82,12 +83,14 @@ func TestLookupDefaults_CheckValues(t *testing.T) {
 func TestLookup_CheckValues(t *testing.T) {
 	// GIVEN a Lookup
 	tests := map[string]struct {
-		lType       *string
-		url         *string
-		wantURL     *string
-		require     *filter.Require
-		urlCommands *filter.URLCommandSlice
-		errRegex    []string
+		lType        *string
+		url          *string
+		wantURL      *string
+		require      *filter.Require
+		urlCommands  *filter.URLCommandSlice
+		auth         *auth.Auth
+		changelogURL string
+		errRegex     []string
 	}{
 		"valid": {
 			errRegex: []string{},
@@ -115,6 +118,16 @@ func TestLookup_CheckValues(t *testing.T) {
 			url:      test.StringPtr("https://github.com/release-argus/Argus"),
 			wantURL:  test.StringPtr("release-argus/Argus"),
 		},
+		"valid changelog_url": {
+			errRegex:     []string{},
+			changelogURL: "https://example.com/{{ version }}/CHANGELOG.md",
+		},
+		"invalid changelog_url": {
+			errRegex: []string{
+				`^latest_version:$`,
+				`^  changelog_url: "[^"]+" <invalid>`},
+			changelogURL: "https://example.com/{{ version }/CHANGELOG.md",
+		},
 		"invalid require": {
 			errRegex: []string{
 				`^latest_version:$`,
@@ -122,6 +135,32 @@ func TestLookup_CheckValues(t *testing.T) {
 				`^    regex_content: "[^"]+" <invalid>`},
 			require: &filter.Require{RegexContent: "[0-"},
 		},
+		"valid auth": {
+			errRegex: []string{},
+			lType:    test.StringPtr("url"),
+			url:      test.StringPtr("https://example.com"),
+			auth: &auth.Auth{
+				Type: "api_key",
+				Key:  "abc"},
+		},
+		"auth with type github": {
+			errRegex: []string{
+				`^latest_version:$`,
+				`^  auth: <invalid> \(only supported with type: url\)$`},
+			auth: &auth.Auth{
+				Type: "api_key",
+				Key:  "abc"},
+		},
+		"invalid auth": {
+			errRegex: []string{
+				`^latest_version:$`,
+				`^  auth:$`,
+				`^    key: <required>`},
+			lType: test.StringPtr("url"),
+			url:   test.StringPtr("https://example.com"),
+			auth: &auth.Auth{
+				Type: "api_key"},
+		},
 		"invalid urlCommands": {
 			errRegex: []string{
 				`^latest_version:$`,
@@ -162,6 +201,8 @@ func TestLookup_CheckValues(t *testing.T) {
 			if tc.urlCommands != nil {
 				lookup.URLCommands = *tc.urlCommands
 			}
+			lookup.Auth = tc.auth
+			lookup.ChangelogURL = tc.changelogURL
 
 			// WHEN CheckValues is called
 			err := lookup.CheckValues("")
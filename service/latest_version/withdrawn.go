@@ -0,0 +1,117 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latestver
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+// githubReleasesPerPage is the number of releases/tags GitHub gives per page (the default per_page).
+const githubReleasesPerPage = 30
+
+// versionWithdrawn returns whether `version` is conclusively no longer available from the source.
+//
+// This is decided from the unfiltered data of the query, so changing the url_commands/use_prerelease/require
+// doesn't make a version look withdrawn. Registry yank/retract markers aren't read;
+// a version is only withdrawn once it's no longer listed.
+//
+// type:github - no release/tag (before the url_commands are applied, or after) has `version`,
+// and the list wasn't a full page (as `version` may just be on the next page).
+// type:url - `version` no longer appears anywhere in the body of the URL
+// (as a whole version, so "1.2.3" isn't found in "1.2.30").
+//
+// Only used with semantic versioning, as otherwise a version older than the withdrawn one
+// can't be told apart from a new release, so is treated as new.
+func (l *Lookup) versionWithdrawn(version string, rawBody *[]byte, logFrom *util.LogFrom) bool {
+	if l.Type == "github" {
+		releases := l.GitHubData.Releases()
+		if len(releases) == 0 || len(releases) >= githubReleasesPerPage {
+			return false
+		}
+		for i := range releases {
+			if l.releaseHasVersion(&releases[i], version, logFrom) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// url service
+	return rawBody != nil && len(*rawBody) != 0 && !containsVersion(string(*rawBody), version)
+}
+
+// releaseHasVersion returns whether the tag (or name) of the unfiltered `release` is for `version`,
+// either containing it, or giving it with the url_commands.
+func (l *Lookup) releaseHasVersion(release *github_types.Release, version string, logFrom *util.LogFrom) bool {
+	tag := release.TagName
+	if tag == "" {
+		tag = release.Name
+	}
+	if containsVersion(tag, version) {
+		return true
+	}
+
+	tagVersion, err := l.URLCommands.Run(tag, logFrom)
+	if err != nil {
+		return false
+	}
+	if tagVersion == version {
+		return true
+	}
+	semVer, err := semver.NewVersion(tagVersion)
+	return err == nil && semVer.String() == version
+}
+
+// containsVersion returns whether `version` is in `body` as a whole version,
+// i.e. not part of a longer version like "11.2.3", "1.2.30", "1.2.3.4" or "1.2.3-rc1".
+func containsVersion(body string, version string) bool {
+	re := regexp.MustCompile(
+		`(?:^|[^0-9.])` + regexp.QuoteMeta(version) + `(?:$|[^0-9A-Za-z_+.-]|\.(?:$|[^0-9A-Za-z]))`)
+	return re.MatchString(body)
+}
+
+// rollbackWithdrawnVersion will roll LatestVersion back from `withdrawn` to `version`,
+// clearing any approval/skip of `withdrawn`, and notifying of the rollback.
+func (l *Lookup) rollbackWithdrawnVersion(withdrawn string, version string, logFrom *util.LogFrom) {
+	msg := fmt.Sprintf("Latest version %q was withdrawn, rolling back to %q", withdrawn, version)
+	jLog.Warn(msg, logFrom, true)
+
+	// Don't act on the withdrawn version.
+	if approved := l.Status.ApprovedVersion(); approved == withdrawn || approved == "SKIP_"+withdrawn {
+		l.Status.SetApprovedVersion("", true)
+	}
+
+	l.Status.SetLatestVersion(version, true)
+	l.Status.AnnounceQueryNewVersion()
+	metric.IncreasePrometheusCounter(metric.LatestVersionQueryMetric,
+		*l.Status.ServiceID,
+		"",
+		"",
+		"WITHDRAWN")
+
+	// Notify
+	serviceInfo := &util.ServiceInfo{
+		ID:            *l.Status.ServiceID,
+		URL:           l.ServiceURL(true),
+		WebURL:        l.Status.GetWebURL(),
+		LatestVersion: version}
+	go l.Notify.Send("Release withdrawn", msg, serviceInfo, false)
+}
@@ -0,0 +1,199 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package latestver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
+	"github.com/release-argus/Argus/service/latest_version/filter"
+	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
+	metric "github.com/release-argus/Argus/web/metrics"
+)
+
+func TestLookup_VersionWithdrawn(t *testing.T) {
+	// GIVEN a Lookup and the releases/body from a query
+	fullPage := make([]github_types.Release, githubReleasesPerPage)
+	for i := range fullPage {
+		fullPage[i].TagName = fmt.Sprintf("1.1.%d", i)
+	}
+	tests := map[string]struct {
+		urlType       bool
+		releases      []github_types.Release
+		urlCommands   filter.URLCommandSlice
+		body          string
+		usePreRelease bool
+		want          bool
+	}{
+		"github - version still listed": {
+			releases: []github_types.Release{
+				{TagName: "1.2.4"},
+				{TagName: "1.2.3"}},
+			want: false},
+		"github - version deleted": {
+			releases: []github_types.Release{
+				{TagName: "1.2.2"},
+				{TagName: "1.2.1"}},
+			want: true},
+		"github - version changed to a pre-release": {
+			releases: []github_types.Release{
+				{TagName: "1.2.3", PreRelease: true},
+				{TagName: "1.2.2"}},
+			want: false},
+		"github - version tagged with a prefix": {
+			releases: []github_types.Release{
+				{TagName: "v1.2.3"},
+				{TagName: "v1.2.2"}},
+			want: false},
+		"github - version no longer matching the url_commands": {
+			releases: []github_types.Release{
+				{TagName: "v1.2.3"},
+				{TagName: "release-1.2.2"}},
+			urlCommands: filter.URLCommandSlice{
+				{Type: "regex", Regex: test.StringPtr(`release-([0-9.]+)`)}},
+			want: false},
+		"github - version given by the url_commands": {
+			releases: []github_types.Release{
+				{TagName: "release_1_2_3"},
+				{TagName: "release_1_2_2"}},
+			urlCommands: filter.URLCommandSlice{
+				{Type: "regex", Regex: test.StringPtr(`release_([0-9_]+)`)},
+				{Type: "replace", Old: test.StringPtr("_"), New: test.StringPtr(".")}},
+			want: false},
+		"github - version not on a full page": {
+			releases: fullPage,
+			want:     false},
+		"github - no releases": {
+			want: false},
+		"github - version changed to a pre-release, but pre-releases are wanted": {
+			releases: []github_types.Release{
+				{TagName: "1.2.3", PreRelease: true},
+				{TagName: "1.2.2"}},
+			usePreRelease: true,
+			want:          false},
+		"url - version still in body": {
+			urlType: true,
+			body:    "ver1.2.2 ver1.2.3",
+			want:    false},
+		"url - version removed from body": {
+			urlType: true,
+			body:    "ver1.2.2",
+			want:    true},
+		"url - empty body": {
+			urlType: true,
+			body:    "",
+			want:    false},
+		"url - version at the end of a sentence": {
+			urlType: true,
+			body:    "The latest release is v1.2.3.",
+			want:    false},
+		"url - only a longer patch version in body": {
+			urlType: true,
+			body:    "ver1.2.30 ver1.2.2",
+			want:    true},
+		"url - only a longer major version in body": {
+			urlType: true,
+			body:    "ver11.2.3",
+			want:    true},
+		"url - only a pre-release of the version in body": {
+			urlType: true,
+			body:    "ver1.2.3-rc1",
+			want:    true},
+		"url - only a longer version with more parts in body": {
+			urlType: true,
+			body:    "ver1.2.3.4",
+			want:    true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(tc.urlType, false)
+			lookup.UsePreRelease = &tc.usePreRelease
+			if !tc.urlType {
+				lookup.GitHubData.SetReleases(tc.releases)
+				lookup.URLCommands = tc.urlCommands
+			}
+			body := []byte(tc.body)
+
+			// WHEN versionWithdrawn is called for a version
+			got := lookup.versionWithdrawn("1.2.3", &body, &util.LogFrom{})
+
+			// THEN the result is as expected
+			if got != tc.want {
+				t.Errorf("want %t, got %t",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup_RollbackWithdrawnVersion(t *testing.T) {
+	// GIVEN a Lookup with a LatestVersion that has been withdrawn
+	tests := map[string]struct {
+		approvedVersion     string
+		wantApprovedVersion string
+	}{
+		"withdrawn version not approved": {
+			approvedVersion:     "1.2.0",
+			wantApprovedVersion: "1.2.0"},
+		"withdrawn version approved": {
+			approvedVersion:     "1.2.3",
+			wantApprovedVersion: ""},
+		"withdrawn version skipped": {
+			approvedVersion:     "SKIP_1.2.3",
+			wantApprovedVersion: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := testLookup(false, false)
+			*lookup.Status.ServiceID = name
+			lookup.Status.SetLatestVersion("1.2.3", false)
+			lookup.Status.SetApprovedVersion(tc.approvedVersion, false)
+			hadWithdrawn := testutil.ToFloat64(metric.LatestVersionQueryMetric.WithLabelValues(
+				*lookup.Status.ServiceID, "WITHDRAWN"))
+
+			// WHEN rollbackWithdrawnVersion is called on it
+			lookup.rollbackWithdrawnVersion("1.2.3", "1.2.2", &util.LogFrom{})
+
+			// THEN the LatestVersion is rolled back
+			if got := lookup.Status.LatestVersion(); got != "1.2.2" {
+				t.Errorf("LatestVersion - want %q, got %q",
+					"1.2.2", got)
+			}
+			// AND the ApprovedVersion is reset if it was for the withdrawn version
+			if got := lookup.Status.ApprovedVersion(); got != tc.wantApprovedVersion {
+				t.Errorf("ApprovedVersion - want %q, got %q",
+					tc.wantApprovedVersion, got)
+			}
+			// AND the rollback is recorded in the metrics
+			gotWithdrawn := testutil.ToFloat64(metric.LatestVersionQueryMetric.WithLabelValues(
+				*lookup.Status.ServiceID, "WITHDRAWN"))
+			if gotWithdrawn-hadWithdrawn != 1 {
+				t.Errorf("WITHDRAWN metric - want %d, got %d",
+					1, int(gotWithdrawn-hadWithdrawn))
+			}
+		})
+	}
+}
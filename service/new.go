@@ -16,12 +16,14 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	shoutrrr_vars "github.com/release-argus/Argus/notifiers/shoutrrr/types"
+	"github.com/release-argus/Argus/service/auth"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	latestver "github.com/release-argus/Argus/service/latest_version"
 	"github.com/release-argus/Argus/util"
@@ -154,6 +156,29 @@ func (s *Service) giveSecretsLatestVersion(oldLatestVersion *latestver.Lookup) {
 			s.LatestVersion.Require.Docker != nil && s.LatestVersion.Require.Docker.Token == "<secret>" {
 			s.LatestVersion.Require.Docker.Token = oldLatestVersion.Require.Docker.Token
 		}
+		// with the Require.HTTP referencing the oldService's credentials
+		if oldLatestVersion.Require != nil && oldLatestVersion.Require.HTTP != nil &&
+			s.LatestVersion.Require.HTTP != nil {
+			oldHTTP := oldLatestVersion.Require.HTTP
+			newHTTP := s.LatestVersion.Require.HTTP
+			if newHTTP.BasicAuth != nil && newHTTP.BasicAuth.Password == "<secret>" &&
+				oldHTTP.BasicAuth != nil {
+				newHTTP.BasicAuth.Password = oldHTTP.BasicAuth.Password
+			}
+			for i := range newHTTP.Headers {
+				if newHTTP.Headers[i].Value == "<secret>" && i < len(oldHTTP.Headers) {
+					newHTTP.Headers[i].Value = oldHTTP.Headers[i].Value
+				}
+			}
+		}
+	}
+	// Auth referencing the oldService's Auth secrets
+	giveSecretsAuth(s.LatestVersion.Auth, oldLatestVersion.Auth)
+	// Sources referencing the oldService's AccessToken at the same index
+	for i, source := range s.LatestVersion.Sources {
+		if util.DefaultIfNil(source.AccessToken) == "<secret>" && i < len(oldLatestVersion.Sources) {
+			source.AccessToken = oldLatestVersion.Sources[i].AccessToken
+		}
 	}
 	// GitHubData
 	if s.LatestVersion.Type == "github" && oldLatestVersion.Type == "github" {
@@ -173,6 +198,8 @@ func (s *Service) giveSecretsDeployedVersion(oldDeployedVersion *deployedver.Loo
 		s.DeployedVersionLookup.BasicAuth.Password = oldDeployedVersion.BasicAuth.Password
 	}
 
+	giveSecretsAuth(s.DeployedVersionLookup.Auth, oldDeployedVersion.Auth)
+
 	// If we have headers in old and new
 	if len(s.DeployedVersionLookup.Headers) != 0 &&
 		len(oldDeployedVersion.Headers) != 0 {
@@ -197,6 +224,23 @@ func (s *Service) giveSecretsDeployedVersion(oldDeployedVersion *deployedver.Loo
 	}
 }
 
+// giveSecretsAuth from the `oldAuth`
+func giveSecretsAuth(newAuth *auth.Auth, oldAuth *auth.Auth) {
+	if newAuth == nil || oldAuth == nil {
+		return
+	}
+
+	if newAuth.Token == "<secret>" {
+		newAuth.Token = oldAuth.Token
+	}
+	if newAuth.ClientSecret == "<secret>" {
+		newAuth.ClientSecret = oldAuth.ClientSecret
+	}
+	if newAuth.Key == "<secret>" {
+		newAuth.Key = oldAuth.Key
+	}
+}
+
 // giveSecretsNotify from the `oldNotifies`
 func (s *Service) giveSecretsNotify(oldNotifies *shoutrrr.Slice, secretRefs *map[string]oldStringIndex) {
 	//nolint:typecheck
@@ -348,6 +392,7 @@ func (s *Service) CheckFetches() (err error) {
 		s.Status.SetDeployedVersion("", false)
 
 		_, err = s.LatestVersion.Query(
+			context.Background(),
 			false,
 			&logFrom)
 		if err != nil {
@@ -361,6 +406,7 @@ func (s *Service) CheckFetches() (err error) {
 	if s.DeployedVersionLookup != nil {
 		var version string
 		version, err = s.DeployedVersionLookup.Query(
+			context.Background(),
 			false,
 			&logFrom)
 		if err != nil {
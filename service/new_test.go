@@ -18,6 +18,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"regexp"
@@ -27,6 +28,7 @@ import (
 
 	command "github.com/release-argus/Argus/commands"
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/service/auth"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	latestver "github.com/release-argus/Argus/service/latest_version"
 	"github.com/release-argus/Argus/service/latest_version/filter"
@@ -182,6 +184,41 @@ func TestService_GiveSecretsLatestVersion(t *testing.T) {
 						"", "", "", "", "", "", time.Now(), nil)},
 				nil, "", "", nil, nil, nil, nil),
 		},
+		"give old Require.HTTP credentials": {
+			latestVersion: &latestver.Lookup{
+				Require: &filter.Require{
+					HTTP: &filter.HTTPCheck{
+						BasicAuth: &filter.HTTPBasicAuth{Username: "user", Password: "<secret>"},
+						Headers: []filter.HTTPHeader{
+							{Key: "X-Token", Value: "<secret>"},
+							{Key: "X-New", Value: "new"}}}}},
+			otherLV: &latestver.Lookup{
+				Require: &filter.Require{
+					HTTP: &filter.HTTPCheck{
+						BasicAuth: &filter.HTTPBasicAuth{Username: "user", Password: "pass"},
+						Headers: []filter.HTTPHeader{
+							{Key: "X-Token", Value: "token"}}}}},
+			expected: &latestver.Lookup{
+				Require: &filter.Require{
+					HTTP: &filter.HTTPCheck{
+						BasicAuth: &filter.HTTPBasicAuth{Username: "user", Password: "pass"},
+						Headers: []filter.HTTPHeader{
+							{Key: "X-Token", Value: "token"},
+							{Key: "X-New", Value: "new"}}}}},
+		},
+		"give old Sources AccessToken": {
+			latestVersion: &latestver.Lookup{
+				Sources: []*latestver.Source{
+					{LookupBase: latestver.LookupBase{AccessToken: test.StringPtr("<secret>")}},
+					{LookupBase: latestver.LookupBase{AccessToken: test.StringPtr("<secret>")}}}},
+			otherLV: &latestver.Lookup{
+				Sources: []*latestver.Source{
+					{LookupBase: latestver.LookupBase{AccessToken: test.StringPtr("foo")}}}},
+			expected: &latestver.Lookup{
+				Sources: []*latestver.Source{
+					{LookupBase: latestver.LookupBase{AccessToken: test.StringPtr("foo")}},
+					{LookupBase: latestver.LookupBase{AccessToken: test.StringPtr("<secret>")}}}},
+		},
 		"GitHubData carried over if type still 'github'": {
 			latestVersion: &latestver.Lookup{
 				Type: "github"},
@@ -192,6 +229,23 @@ func TestService_GiveSecretsLatestVersion(t *testing.T) {
 				Type:       "github",
 				GitHubData: &githubData},
 		},
+		"give old Auth secrets": {
+			latestVersion: &latestver.Lookup{
+				Type: "url",
+				Auth: &auth.Auth{
+					Type:  "bearer",
+					Token: "<secret>"}},
+			otherLV: &latestver.Lookup{
+				Type: "url",
+				Auth: &auth.Auth{
+					Type:  "bearer",
+					Token: "abc"}},
+			expected: &latestver.Lookup{
+				Type: "url",
+				Auth: &auth.Auth{
+					Type:  "bearer",
+					Token: "abc"}},
+		},
 		"GitHubData not carried over if type wasn't 'github'": {
 			latestVersion: &latestver.Lookup{
 				Type: "github"},
@@ -244,6 +298,27 @@ func TestService_GiveSecretsLatestVersion(t *testing.T) {
 					tc.expected.Require.Docker.Token, gotLV.Require.Docker.Token)
 			}
 
+			// Require.HTTP
+			if tc.expected.Require != nil && tc.expected.Require.HTTP != nil &&
+				gotLV.Require.HTTP.String("") != tc.expected.Require.HTTP.String("") {
+				t.Errorf("Expected Require.HTTP:\n%s\ngot:\n%s",
+					tc.expected.Require.HTTP.String(""), gotLV.Require.HTTP.String(""))
+			}
+
+			// Sources
+			for i := range tc.expected.Sources {
+				if util.DefaultIfNil(gotLV.Sources[i].AccessToken) != util.DefaultIfNil(tc.expected.Sources[i].AccessToken) {
+					t.Errorf("Expected Sources[%d].AccessToken to be %q, got %q",
+						i, util.DefaultIfNil(tc.expected.Sources[i].AccessToken), util.DefaultIfNil(gotLV.Sources[i].AccessToken))
+				}
+			}
+
+			// Auth
+			if gotLV.Auth.String("") != tc.expected.Auth.String("") {
+				t.Errorf("Expected Auth:\n%s\ngot:\n%s",
+					tc.expected.Auth.String(""), gotLV.Auth.String(""))
+			}
+
 			// GitHubData
 			if gotLV.GitHubData != tc.expected.GitHubData {
 				t.Errorf("Expected GitHubData to be %v, got %q",
@@ -518,6 +593,40 @@ func TestService_GiveSecretsDeployedVersion(t *testing.T) {
 				Headers: []oldIntIndex{
 					{OldIndex: nil}, {OldIndex: test.IntPtr(1)}}},
 		},
+		"referencing old Auth secrets": {
+			deployedVersion: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "<secret>"}},
+			otherDV: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "secret"}},
+			expected: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "secret"}},
+		},
+		"changed Auth secrets": {
+			deployedVersion: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type: "api_key",
+					Key:  "new"}},
+			otherDV: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type: "api_key",
+					Key:  "old"}},
+			expected: &deployedver.Lookup{
+				Auth: &auth.Auth{
+					Type: "api_key",
+					Key:  "new"}},
+		},
 		"swap header values": {
 			deployedVersion: &deployedver.Lookup{
 				Headers: []deployedver.Header{
@@ -567,6 +676,11 @@ func TestService_GiveSecretsDeployedVersion(t *testing.T) {
 						util.DefaultIfNil(tc.expected.BasicAuth), util.DefaultIfNil(gotDV.BasicAuth))
 				}
 			}
+			// Auth
+			if gotDV.Auth.String("") != tc.expected.Auth.String("") {
+				t.Errorf("Expected Auth:\n%s\ngot:\n%s",
+					tc.expected.Auth.String(""), gotDV.Auth.String(""))
+			}
 			// Headers
 			if len(gotDV.Headers) != len(tc.expected.Headers) {
 				t.Errorf("Expected %q, got %q",
@@ -3140,9 +3254,9 @@ func TestFromPayload(t *testing.T) {
 func TestService_CheckFetches(t *testing.T) {
 	// GIVEN a Service
 	testLV := testLatestVersion("url", false)
-	testLV.Query(false, &util.LogFrom{})
+	testLV.Query(context.Background(), false, &util.LogFrom{})
 	testDVL := testDeployedVersionLookup(false)
-	v, _ := testDVL.Query(false, &util.LogFrom{})
+	v, _ := testDVL.Query(context.Background(), false, &util.LogFrom{})
 	testDVL.Status.SetDeployedVersion(v, false)
 	tests := map[string]struct {
 		svc                  *Service
@@ -16,6 +16,7 @@ package opt
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"strconv"
 	"time"
 
@@ -25,6 +26,11 @@ import (
 // OptionsBase is the base struct for Options.
 type OptionsBase struct {
 	Interval           string `yaml:"interval,omitempty" json:"interval,omitempty"`                       // AhBmCs = Sleep A hours, B minutes and C seconds between queries.
+	Schedule           string `yaml:"schedule,omitempty" json:"schedule,omitempty"`                       // Cron expression(s) of when to query, separated by ';' (instead of every Interval).
+	Timezone           string `yaml:"timezone,omitempty" json:"timezone,omitempty"`                       // IANA timezone of the Schedule and QuietHours (default local).
+	QuietHours         string `yaml:"quiet_hours,omitempty" json:"quiet_hours,omitempty"`                 // '[DAYS ]HH:MM-HH:MM' windows to not query in, separated by ';' (queries are deferred until they end).
+	Jitter             string `yaml:"jitter,omitempty" json:"jitter,omitempty"`                           // AhBmCs = Delay each query by a random duration up to this.
+	BackoffMax         string `yaml:"backoff_max,omitempty" json:"backoff_max,omitempty"`                 // AhBmCs = Cap on the Interval doubling for each consecutive failed query.
 	SemanticVersioning *bool  `yaml:"semantic_versioning,omitempty" json:"semantic_versioning,omitempty"` // default - true = Version has to follow semantic versioning (https://semver.org/) and be greater than the previous to trigger anything.
 }
 
@@ -90,6 +96,140 @@ func (o *Options) GetInterval() string {
 		o.HardDefaults.Interval)
 }
 
+// GetSchedule of queries for this Service's latest version.
+func (o *Options) GetSchedule() string {
+	return util.FirstNonDefault(
+		o.Schedule,
+		o.Defaults.Schedule,
+		o.HardDefaults.Schedule)
+}
+
+// GetTimezone of the Schedule and QuietHours.
+func (o *Options) GetTimezone() string {
+	return util.FirstNonDefault(
+		o.Timezone,
+		o.Defaults.Timezone,
+		o.HardDefaults.Timezone)
+}
+
+// GetQuietHours that queries are deferred until the end of.
+func (o *Options) GetQuietHours() string {
+	return util.FirstNonDefault(
+		o.QuietHours,
+		o.Defaults.QuietHours,
+		o.HardDefaults.QuietHours)
+}
+
+// GetJitter is the maximum random delay added to each query.
+func (o *Options) GetJitter() string {
+	return util.FirstNonDefault(
+		o.Jitter,
+		o.Defaults.Jitter,
+		o.HardDefaults.Jitter)
+}
+
+// GetJitterDuration returns the maximum random delay added to each query.
+func (o *Options) GetJitterDuration() time.Duration {
+	d, _ := time.ParseDuration(o.GetJitter())
+	return d
+}
+
+// GetBackoffMax is the cap on the backoff after consecutive failed queries.
+func (o *Options) GetBackoffMax() string {
+	return util.FirstNonDefault(
+		o.BackoffMax,
+		o.Defaults.BackoffMax,
+		o.HardDefaults.BackoffMax)
+}
+
+// GetBackoffMaxDuration returns the cap on the backoff after consecutive failed queries.
+func (o *Options) GetBackoffMaxDuration() time.Duration {
+	d, _ := time.ParseDuration(o.GetBackoffMax())
+	return d
+}
+
+// GetBackoffDuration returns the time to wait after `failures` consecutive failed queries,
+// the Interval doubled for each failure, up to BackoffMax (0 when there are no failures).
+func (o *Options) GetBackoffDuration(failures uint) time.Duration {
+	if failures == 0 {
+		return 0
+	}
+
+	interval := o.GetIntervalDuration()
+	// Never query more often than the Interval.
+	limit := max(o.GetBackoffMaxDuration(), interval)
+	backoff := interval
+	for i := uint(0); i < failures && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+// GetLocation returns the location of the Timezone (default local).
+func (o *Options) GetLocation() *time.Location {
+	if timezone := o.GetTimezone(); timezone != "" {
+		if location, err := time.LoadLocation(timezone); err == nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+// NextQuery returns the time of the query after one at `last` (and not before `now`),
+// from the Schedule (or Interval), backed off after `failures` consecutive failed queries,
+// delayed by up to the Jitter, and deferred until the end of any QuietHours it falls in.
+func (o *Options) NextQuery(last time.Time, now time.Time, failures uint) time.Time {
+	backoff := o.GetBackoffDuration(failures)
+	var next time.Time
+	if schedules, _ := parseSchedule(o.GetSchedule()); len(schedules) != 0 {
+		// Skip the scheduled queries before the backoff has passed.
+		if backoff > 0 {
+			last = last.Add(backoff - time.Second)
+		}
+		last = last.In(o.GetLocation())
+		for _, schedule := range schedules {
+			if scheduled := schedule.Next(last); next.IsZero() || scheduled.Before(next) {
+				next = scheduled
+			}
+		}
+	} else {
+		next = last.Add(max(o.GetIntervalDuration(), backoff))
+	}
+
+	// Missed, so query now.
+	if next.Before(now) {
+		next = now
+	}
+	// Spread out the queries of Services that are due at the same time.
+	if jitter := o.GetJitterDuration(); jitter > 0 {
+		next = next.Add(rand.N(jitter))
+	}
+	return o.DeferQuietHours(next)
+}
+
+// DeferQuietHours returns `t`, or the end of the QuietHours that it's in.
+func (o *Options) DeferQuietHours(t time.Time) time.Time {
+	windows, _ := parseQuietHours(o.GetQuietHours())
+	if len(windows) == 0 {
+		return t
+	}
+
+	t = t.In(o.GetLocation())
+	// Windows may overlap/follow each other, but can't cover the whole week
+	// (parseQuietHours), so stop after a week in case of DST changes.
+	limit := t.AddDate(0, 0, 8)
+	for deferred := true; deferred && t.Before(limit); {
+		deferred = false
+		for _, window := range windows {
+			if end, quiet := window.endOf(t); quiet {
+				t = end
+				deferred = true
+			}
+		}
+	}
+	return t
+}
+
 // GetSemanticVersioning will return whether Semantic Versioning should be used for this Service.
 func (o *Options) GetSemanticVersioning() bool {
 	return *util.FirstNonNilPtr(
@@ -129,6 +269,47 @@ func (o *OptionsBase) CheckValues(prefix string) (errs error) {
 		}
 	}
 
+	// Jitter
+	if o.Jitter != "" {
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(o.Jitter); err == nil {
+			o.Jitter += "s"
+		}
+		if d, err := time.ParseDuration(o.Jitter); err != nil || d < 0 {
+			errs = fmt.Errorf("%s%s  jitter: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, o.Jitter)
+		}
+	}
+	// BackoffMax
+	if o.BackoffMax != "" {
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(o.BackoffMax); err == nil {
+			o.BackoffMax += "s"
+		}
+		if d, err := time.ParseDuration(o.BackoffMax); err != nil || d < 0 {
+			errs = fmt.Errorf("%s%s  backoff_max: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, o.BackoffMax)
+		}
+	}
+
+	// Schedule
+	if _, err := parseSchedule(o.Schedule); err != nil {
+		errs = fmt.Errorf("%s%s  schedule: <invalid> %s (Use cron format 'MINUTE HOUR DAY MONTH WEEKDAY', separated by ';')\\",
+			util.ErrorToString(errs), prefix, err)
+	}
+	// Timezone
+	if o.Timezone != "" {
+		if _, err := time.LoadLocation(o.Timezone); err != nil {
+			errs = fmt.Errorf("%s%s  timezone: %q <invalid> (Use an IANA timezone, e.g. 'Europe/London')\\",
+				util.ErrorToString(errs), prefix, o.Timezone)
+		}
+	}
+	// QuietHours
+	if _, err := parseQuietHours(o.QuietHours); err != nil {
+		errs = fmt.Errorf("%s%s  quiet_hours: <invalid> %s (Use '[DAYS ]HH:MM-HH:MM', e.g. 'Mon-Fri 22:00-06:00', separated by ';')\\",
+			util.ErrorToString(errs), prefix, err)
+	}
+
 	if errs != nil {
 		errs = fmt.Errorf("%soptions:\\%w",
 			prefix, errs)
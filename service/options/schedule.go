@@ -0,0 +1,218 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// splitList returns the trimmed, non-empty items of the ';'-separated `str`.
+func splitList(str string) (items []string) {
+	for _, item := range strings.Split(str, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
+
+// parseSchedule returns the cron schedules of the ';'-separated expressions in `str`.
+func parseSchedule(str string) ([]cron.Schedule, error) {
+	var schedules []cron.Schedule
+	for _, expression := range splitList(str) {
+		schedule, err := cron.ParseStandard(expression)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", expression, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// quietWindow of the QuietHours.
+type quietWindow struct {
+	days  [7]bool // Days the window starts on (indexed by time.Weekday).
+	start int     // Minutes after midnight that the window starts.
+	end   int     // Minutes after midnight that the window ends (the next day if <= start).
+}
+
+// weekdays by their 3-letter abbreviation.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday}
+
+// parseQuietHours returns the windows of the ';'-separated `str`,
+// each in the format '[DAYS ]HH:MM-HH:MM', e.g. 'Sat,Sun 02:00-06:00' or '22:00-06:00'.
+func parseQuietHours(str string) ([]quietWindow, error) {
+	var windows []quietWindow
+	for _, item := range splitList(str) {
+		window, err := parseQuietWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", item, err)
+		}
+		windows = append(windows, window)
+	}
+	if coversWeek(windows) {
+		return nil, errors.New("covers the whole week, so would never query")
+	}
+	return windows, nil
+}
+
+// minutesInWeek is the number of minutes in a week.
+const minutesInWeek = 7 * 24 * 60
+
+// coversWeek returns whether the `windows` cover every minute of the week.
+func coversWeek(windows []quietWindow) bool {
+	if len(windows) == 0 {
+		return false
+	}
+
+	var covered [minutesInWeek]bool
+	for _, window := range windows {
+		length := window.end - window.start
+		if length <= 0 {
+			length += 24 * 60
+		}
+		for day, active := range window.days {
+			if !active {
+				continue
+			}
+			start := day*24*60 + window.start
+			for minute := start; minute < start+length; minute++ {
+				covered[minute%minutesInWeek] = true
+			}
+		}
+	}
+
+	for _, quiet := range covered {
+		if !quiet {
+			return false
+		}
+	}
+	return true
+}
+
+// parseQuietWindow returns the window of `str` in the format '[DAYS ]HH:MM-HH:MM'.
+func parseQuietWindow(str string) (window quietWindow, err error) {
+	fields := strings.Fields(str)
+	var times string
+	switch len(fields) {
+	case 1:
+		times = fields[0]
+		for i := range window.days {
+			window.days[i] = true
+		}
+	case 2:
+		times = fields[1]
+		if window.days, err = parseDays(fields[0]); err != nil {
+			return
+		}
+	default:
+		err = errors.New("want '[DAYS ]HH:MM-HH:MM'")
+		return
+	}
+
+	start, end, found := strings.Cut(times, "-")
+	if !found {
+		err = errors.New("want '[DAYS ]HH:MM-HH:MM'")
+		return
+	}
+	if window.start, err = parseClock(start); err != nil {
+		return
+	}
+	if window.end, err = parseClock(end); err != nil {
+		return
+	}
+	if window.start == window.end {
+		err = errors.New("start and end are the same")
+	}
+	return
+}
+
+// parseDays returns the days of the ','-separated days/ranges in `str`, e.g. 'Mon-Fri,Sun'.
+func parseDays(str string) (days [7]bool, err error) {
+	for _, item := range strings.Split(str, ",") {
+		from, to, isRange := strings.Cut(item, "-")
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			err = fmt.Errorf("unknown day %q (use Mon, Tue, ...)", from)
+			return
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(to)]; !ok {
+				err = fmt.Errorf("unknown day %q (use Mon, Tue, ...)", to)
+				return
+			}
+		}
+		// Ranges may wrap around the end of the week, e.g. 'Fri-Mon'.
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return
+}
+
+// parseClock returns the minutes after midnight of `str` in the format 'HH:MM' (up to 24:00).
+func parseClock(str string) (int, error) {
+	hours, minutes, found := strings.Cut(str, ":")
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if !found || errH != nil || errM != nil ||
+		h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", str)
+	}
+	return h*60 + m, nil
+}
+
+// endOf returns the end of the window that `t` is in, and whether it's in the window.
+func (w quietWindow) endOf(t time.Time) (time.Time, bool) {
+	// The window started on this day, or the previous day if it passes midnight.
+	for _, offset := range []int{0, -1} {
+		day := t.AddDate(0, 0, offset)
+		if !w.days[day.Weekday()] {
+			continue
+		}
+
+		start := clockOn(day, w.start)
+		end := clockOn(day, w.end)
+		if w.end <= w.start {
+			end = clockOn(day.AddDate(0, 0, 1), w.end)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clockOn returns the time `minutes` after midnight on the day of `t`.
+func clockOn(t time.Time, minutes int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+}
@@ -0,0 +1,429 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package opt
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+func TestOptions_GetSchedule(t *testing.T) {
+	// GIVEN Options
+	tests := map[string]struct {
+		root        string
+		dfault      string
+		hardDefault string
+		want        string
+	}{
+		"root overrides all": {
+			want:        "0 * * * *",
+			root:        "0 * * * *",
+			dfault:      "@daily",
+			hardDefault: "@daily",
+		},
+		"default overrides hardDefault": {
+			want:        "0 * * * *",
+			root:        "",
+			dfault:      "0 * * * *",
+			hardDefault: "@daily",
+		},
+		"hardDefault is last resort": {
+			want:        "0 * * * *",
+			root:        "",
+			dfault:      "",
+			hardDefault: "0 * * * *",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := testOptions()
+			options.Schedule = tc.root
+			options.Defaults.Schedule = tc.dfault
+			options.HardDefaults.Schedule = tc.hardDefault
+			options.QuietHours = tc.root
+			options.Defaults.QuietHours = tc.dfault
+			options.HardDefaults.QuietHours = tc.hardDefault
+			options.Timezone = tc.root
+			options.Defaults.Timezone = tc.dfault
+			options.HardDefaults.Timezone = tc.hardDefault
+
+			// WHEN GetSchedule, GetQuietHours and GetTimezone are called
+			gotSchedule := options.GetSchedule()
+			gotQuietHours := options.GetQuietHours()
+			gotTimezone := options.GetTimezone()
+
+			// THEN the function returns the correct result
+			if gotSchedule != tc.want || gotQuietHours != tc.want || gotTimezone != tc.want {
+				t.Errorf("want: %q\ngot:  %q, %q, %q",
+					tc.want, gotSchedule, gotQuietHours, gotTimezone)
+			}
+		})
+	}
+}
+
+func TestOptions_NextQuery(t *testing.T) {
+	// 2024-01-01 is a Monday.
+	at := func(str string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", str)
+		return t
+	}
+	// GIVEN Options with an interval/schedule and quiet hours
+	tests := map[string]struct {
+		interval   string
+		schedule   string
+		timezone   string
+		quietHours string
+		backoffMax string
+		failures   uint
+		last, now  time.Time
+		want       time.Time
+	}{
+		"interval after the last query": {
+			interval: "10m",
+			last:     at("2024-01-01 10:00"),
+			now:      at("2024-01-01 10:05"),
+			want:     at("2024-01-01 10:10")},
+		"interval missed is now": {
+			interval: "10m",
+			last:     at("2024-01-01 08:00"),
+			now:      at("2024-01-01 10:05"),
+			want:     at("2024-01-01 10:05")},
+		"never queried is now": {
+			interval: "10m",
+			now:      at("2024-01-01 10:05"),
+			want:     at("2024-01-01 10:05")},
+		"schedule - every 15 minutes on weekdays in working hours": {
+			schedule: "*/15 8-17 * * 1-5; 0 * * * *",
+			last:     at("2024-01-01 10:00"),
+			now:      at("2024-01-01 10:00"),
+			want:     at("2024-01-01 10:15")},
+		"schedule - hourly outside working hours": {
+			schedule: "*/15 8-17 * * 1-5; 0 * * * *",
+			last:     at("2024-01-01 17:45"),
+			now:      at("2024-01-01 17:45"),
+			want:     at("2024-01-01 18:00")},
+		"schedule - hourly at the weekend": {
+			schedule: "*/15 8-17 * * 1-5; 0 * * * *",
+			last:     at("2024-01-06 10:00"),
+			now:      at("2024-01-06 10:00"),
+			want:     at("2024-01-06 11:00")},
+		"schedule missed is now": {
+			schedule: "0 * * * *",
+			last:     at("2024-01-01 08:00"),
+			now:      at("2024-01-01 10:05"),
+			want:     at("2024-01-01 10:05")},
+		"schedule never queried is now": {
+			schedule: "0 * * * *",
+			now:      at("2024-01-01 10:05"),
+			want:     at("2024-01-01 10:05")},
+		"schedule in a timezone": {
+			schedule: "0 9 * * *",
+			timezone: "America/New_York",
+			last:     at("2024-01-01 00:00"),
+			now:      at("2024-01-01 00:00"),
+			want:     at("2024-01-01 14:00")},
+		"schedule with CRON_TZ": {
+			schedule: "CRON_TZ=America/New_York 0 9 * * *",
+			last:     at("2024-01-01 00:00"),
+			now:      at("2024-01-01 00:00"),
+			want:     at("2024-01-01 14:00")},
+		"interval in quiet hours is deferred": {
+			interval:   "10m",
+			quietHours: "Sat 02:00-06:00",
+			last:       at("2024-01-06 01:55"),
+			now:        at("2024-01-06 01:55"),
+			want:       at("2024-01-06 06:00")},
+		"interval outside the days of the quiet hours": {
+			interval:   "10m",
+			quietHours: "Sat 02:00-06:00",
+			last:       at("2024-01-07 01:55"),
+			now:        at("2024-01-07 01:55"),
+			want:       at("2024-01-07 02:05")},
+		"quiet hours past midnight": {
+			interval:   "10m",
+			quietHours: "22:00-06:00",
+			last:       at("2024-01-01 21:55"),
+			now:        at("2024-01-01 21:55"),
+			want:       at("2024-01-02 06:00")},
+		"quiet hours past midnight on the day it started": {
+			interval:   "10m",
+			quietHours: "Mon 22:00-06:00",
+			last:       at("2024-01-02 01:00"),
+			now:        at("2024-01-02 01:00"),
+			want:       at("2024-01-02 06:00")},
+		"schedule in quiet hours is deferred": {
+			schedule:   "0 * * * *",
+			quietHours: "Mon-Fri 12:00-12:30",
+			last:       at("2024-01-01 11:00"),
+			now:        at("2024-01-01 11:00"),
+			want:       at("2024-01-01 12:30")},
+		"adjoining quiet hours": {
+			interval:   "10m",
+			quietHours: "11:00-12:00; 10:00-11:00",
+			last:       at("2024-01-01 09:55"),
+			now:        at("2024-01-01 09:55"),
+			want:       at("2024-01-01 12:00")},
+		"quiet hours across days": {
+			interval:   "10m",
+			quietHours: "Sat,Sun 00:00-24:00; 20:00-08:00",
+			last:       at("2024-01-05 19:55"),
+			now:        at("2024-01-05 19:55"),
+			want:       at("2024-01-08 08:00")},
+		"quiet hours covering the whole week are ignored": {
+			interval:   "10m",
+			quietHours: "00:00-24:00",
+			last:       at("2024-01-01 09:55"),
+			now:        at("2024-01-01 09:55"),
+			want:       at("2024-01-01 10:05")},
+		"interval backed off after failures": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   2,
+			last:       at("2024-01-01 10:00"),
+			now:        at("2024-01-01 10:00"),
+			want:       at("2024-01-01 10:40")},
+		"interval backoff capped": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   10,
+			last:       at("2024-01-01 10:00"),
+			now:        at("2024-01-01 10:00"),
+			want:       at("2024-01-01 11:00")},
+		"interval backoff disabled": {
+			interval:   "10m",
+			backoffMax: "0s",
+			failures:   10,
+			last:       at("2024-01-01 10:00"),
+			now:        at("2024-01-01 10:00"),
+			want:       at("2024-01-01 10:10")},
+		"schedule backed off skips scheduled queries": {
+			schedule:   "*/5 * * * *",
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   1,
+			last:       at("2024-01-01 10:00"),
+			now:        at("2024-01-01 10:00"),
+			want:       at("2024-01-01 10:20")},
+		"quiet hours in a timezone": {
+			interval:   "10m",
+			timezone:   "America/New_York",
+			quietHours: "02:00-06:00",
+			last:       at("2024-01-01 06:55"),
+			now:        at("2024-01-01 06:55"),
+			want:       at("2024-01-01 11:00")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := testOptions()
+			options.Interval = tc.interval
+			options.Schedule = tc.schedule
+			options.Timezone = tc.timezone
+			if tc.timezone == "" {
+				options.Timezone = "UTC"
+			}
+			options.QuietHours = tc.quietHours
+			options.BackoffMax = tc.backoffMax
+
+			// WHEN NextQuery is called
+			got := options.NextQuery(tc.last, tc.now, tc.failures)
+
+			// THEN the next query is at the time expected
+			if !got.Equal(tc.want) {
+				t.Errorf("want: %s\ngot:  %s",
+					tc.want, got.UTC())
+			}
+		})
+	}
+}
+
+func TestOptions_NextQuery_Jitter(t *testing.T) {
+	// GIVEN Options with a jitter
+	options := testOptions()
+	options.Interval = "10m"
+	options.Jitter = "1m"
+	last := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	earliest := last.Add(10 * time.Minute)
+	latest := earliest.Add(time.Minute)
+
+	// WHEN NextQuery is called multiple times
+	seen := map[time.Time]bool{}
+	for i := 0; i < 50; i++ {
+		got := options.NextQuery(last, last, 0)
+
+		// THEN the next query is delayed by up to the jitter
+		if got.Before(earliest) || !got.Before(latest) {
+			t.Fatalf("want next query in [%s, %s), got %s",
+				earliest, latest, got)
+		}
+		seen[got] = true
+	}
+	// AND the delay varies
+	if len(seen) == 1 {
+		t.Errorf("want varying next queries, got the same one 50 times")
+	}
+}
+
+func TestOptions_GetBackoffDuration(t *testing.T) {
+	// GIVEN Options with an interval and backoff_max
+	tests := map[string]struct {
+		interval   string
+		backoffMax string
+		failures   uint
+		want       time.Duration
+	}{
+		"no failures": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   0,
+			want:       0},
+		"doubles for each failure": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   2,
+			want:       40 * time.Minute},
+		"capped at backoff_max": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   3,
+			want:       time.Hour},
+		"never less than the interval": {
+			interval:   "2h",
+			backoffMax: "1h",
+			failures:   3,
+			want:       2 * time.Hour},
+		"many failures don't overflow": {
+			interval:   "10m",
+			backoffMax: "1h",
+			failures:   1000,
+			want:       time.Hour},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := testOptions()
+			options.Interval = tc.interval
+			options.BackoffMax = tc.backoffMax
+
+			// WHEN GetBackoffDuration is called
+			got := options.GetBackoffDuration(tc.failures)
+
+			// THEN the backoff is as expected
+			if got != tc.want {
+				t.Errorf("want: %s\ngot:  %s",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOptions_CheckValues_Schedule(t *testing.T) {
+	// GIVEN Options with a schedule/timezone/quiet hours
+	tests := map[string]struct {
+		schedule   string
+		timezone   string
+		quietHours string
+		jitter     string
+		backoffMax string
+		errRegex   string
+	}{
+		"valid": {
+			schedule:   "*/15 8-17 * * 1-5; @hourly",
+			timezone:   "Europe/London",
+			quietHours: "Sat,Sun 02:00-06:00; Fri-Mon 22:00-24:00; 12:00-12:30",
+			errRegex:   `^$`},
+		"invalid schedule": {
+			schedule: "0 * * * *; */15 25 * * *",
+			errRegex: `^options:\\  schedule: <invalid> "\*/15 25 \* \* \*": .*\\$`},
+		"invalid timezone": {
+			timezone: "Mars/Olympus",
+			errRegex: `^options:\\  timezone: "Mars/Olympus" <invalid>`},
+		"invalid quiet_hours day": {
+			quietHours: "Sat,Caturday 02:00-06:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> "Sat,Caturday 02:00-06:00": unknown day "Caturday"`},
+		"invalid quiet_hours time": {
+			quietHours: "02:00-25:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> "02:00-25:00": invalid time "25:00"`},
+		"invalid quiet_hours format": {
+			quietHours: "02:00 to 06:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> "02:00 to 06:00": want '\[DAYS \]HH:MM-HH:MM'`},
+		"quiet_hours that start and end at the same time": {
+			quietHours: "02:00-02:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> "02:00-02:00": start and end are the same`},
+		"quiet_hours covering the whole day": {
+			quietHours: "00:00-24:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> covers the whole week, so would never query`},
+		"quiet_hours covering the whole week between windows": {
+			quietHours: "20:00-08:00; 08:00-20:00",
+			errRegex:   `^options:\\  quiet_hours: <invalid> covers the whole week, so would never query`},
+		"quiet_hours covering all but part of the week": {
+			quietHours: "20:00-08:00; Mon-Sat 08:00-20:00",
+			errRegex:   `^$`},
+		"invalid jitter": {
+			jitter:   "1x",
+			errRegex: `^options:\\  jitter: "1x" <invalid>`},
+		"negative jitter": {
+			jitter:   "-1m",
+			errRegex: `^options:\\  jitter: "-1m" <invalid>`},
+		"invalid backoff_max": {
+			backoffMax: "1x",
+			errRegex:   `^options:\\  backoff_max: "1x" <invalid>`},
+		"integer jitter and backoff_max are seconds": {
+			jitter:     "30",
+			backoffMax: "3600",
+			errRegex:   `^$`},
+		"all invalid": {
+			schedule:   "foo",
+			timezone:   "bar",
+			quietHours: "baz",
+			errRegex:   `^options:\\  schedule: <invalid>.*\\  timezone: .*\\  quiet_hours: <invalid>.*\\$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := testOptions()
+			options.Schedule = tc.schedule
+			options.Timezone = tc.timezone
+			options.QuietHours = tc.quietHours
+			options.Jitter = tc.jitter
+			options.BackoffMax = tc.backoffMax
+
+			// WHEN CheckValues is called
+			err := options.CheckValues("")
+
+			// THEN it err's when expected
+			e := util.ErrorToString(err)
+			re := regexp.MustCompile(tc.errRegex)
+			if !re.MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
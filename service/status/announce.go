@@ -51,7 +51,10 @@ func (s *Status) AnnounceQuery() {
 		ServiceData: &api_type.ServiceSummary{
 			ID: *s.ServiceID,
 			Status: &api_type.Status{
-				LastQueried: s.LastQueried()}}})
+				LastQueried:   s.LastQueried(),
+				NextQuery:     s.NextQuery(),
+				QueryFailures: s.QueryFailures(),
+				Backoff:       s.Backoff()}}})
 
 	s.SendAnnounce(&payloadData)
 }
@@ -76,6 +79,24 @@ func (s *Status) AnnounceQueryNewVersion() {
 	s.SendAnnounce(&payloadData)
 }
 
+// AnnouncePending version (waiting on require.min_age) to the `s.AnnounceChannel`
+// (Broadcast to all WebSocket clients).
+func (s *Status) AnnouncePending() {
+	var payloadData []byte
+
+	payloadData, _ = json.Marshal(api_type.WebSocketMessage{
+		Page:    "APPROVALS",
+		Type:    "VERSION",
+		SubType: "PENDING",
+		ServiceData: &api_type.ServiceSummary{
+			ID: *s.ServiceID,
+			Status: &api_type.Status{
+				PendingVersion:         s.PendingVersion(),
+				PendingVersionEligible: s.PendingVersionEligible()}}})
+
+	s.SendAnnounce(&payloadData)
+}
+
 // AnnounceUpdate being applied to the `s.AnnounceChannel`
 // (Broadcast to all WebSocket clients).
 func (s *Status) AnnounceUpdate() {
@@ -95,6 +116,24 @@ func (s *Status) AnnounceUpdate() {
 	s.SendAnnounce(&payloadData)
 }
 
+// AnnounceDeployedVersions of the targets to the `s.AnnounceChannel`
+// (Broadcast to all WebSocket clients).
+func (s *Status) AnnounceDeployedVersions() {
+	var payloadData []byte
+
+	payloadData, _ = json.Marshal(api_type.WebSocketMessage{
+		Page:    "APPROVALS",
+		Type:    "VERSION",
+		SubType: "DEPLOYED_VERSIONS",
+		ServiceData: &api_type.ServiceSummary{
+			ID: *s.ServiceID,
+			Status: &api_type.Status{
+				DeployedVersions:     s.DeployedVersions(),
+				DeployedVersionDrift: s.DeployedVersionDrift()}}})
+
+	s.SendAnnounce(&payloadData)
+}
+
 // AnnounceAction on an update (skip/approve) to the `s.AnnounceChannel`
 // (Broadcast to all WebSocket clients).
 func (s *Status) announceApproved() {
@@ -116,6 +116,59 @@ func TestStatus_AnnounceQuery(t *testing.T) {
 	}
 }
 
+func TestStatus_AnnouncePending(t *testing.T) {
+	// GIVEN a Status and an AnnounceChannel that may be nil
+	tests := map[string]struct {
+		nilChannel bool
+	}{
+		"nil channel doesn't crash": {
+			nilChannel: true},
+		"non-nil sends correct data": {
+			nilChannel: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status := testStatus()
+			if tc.nilChannel {
+				status.AnnounceChannel = nil
+			}
+			status.pendingVersion = "3.3.3"
+			status.pendingVersionEligible = "2003-03-03T03:03:03Z"
+			wantID := *status.ServiceID
+
+			// WHEN AnnouncePending is called on it
+			status.AnnouncePending()
+
+			// THEN the message is received
+			if tc.nilChannel {
+				return
+			}
+			gotData := <-*status.AnnounceChannel
+			var got api_type.WebSocketMessage
+			json.Unmarshal(gotData, &got)
+			if got.SubType != "PENDING" {
+				t.Errorf("SubType - got %q, want %q",
+					got.SubType, "PENDING")
+			}
+			if got.ServiceData.ID != wantID {
+				t.Fatalf("ID - got %q, want %q",
+					got.ServiceData.ID, wantID)
+			}
+			if got.ServiceData.Status.PendingVersion != "3.3.3" {
+				t.Errorf("PendingVersion - got %q, want %q",
+					got.ServiceData.Status.PendingVersion, "3.3.3")
+			}
+			if got.ServiceData.Status.PendingVersionEligible != "2003-03-03T03:03:03Z" {
+				t.Errorf("PendingVersionEligible - got %q, want %q",
+					got.ServiceData.Status.PendingVersionEligible, "2003-03-03T03:03:03Z")
+			}
+		})
+	}
+}
+
 func TestStatus_AnnounceQueryNewVersion(t *testing.T) {
 	// GIVEN a Status and an AnnounceChannel that may be nil
 	tests := map[string]struct {
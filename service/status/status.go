@@ -59,17 +59,31 @@ type Status struct {
 	ServiceID *string `yaml:"-" json:"-"` // ID of the Service
 	WebURL    *string `yaml:"-" json:"-"` // Web URL of the Service
 
-	approvedVersion          string       // The version that's been approved
-	deployedVersion          string       // Track the deployed version of the service from the last successful WebHook.
-	deployedVersionTimestamp string       // UTC timestamp of DeployedVersion being changed.
-	latestVersion            string       // Latest version found from query().
-	latestVersionTimestamp   string       // UTC timestamp of LatestVersion being changed.
-	lastQueried              string       // UTC timestamp that version was last queried/checked.
-	regexMissesContent       uint         // Counter for the number of regex misses on URL content.
-	regexMissesVersion       uint         // Counter for the number of regex misses on version.
-	Fails                    Fails        // Track the Notify/WebHook fails
-	deleting                 bool         // Flag to indicate the service is being deleted
-	mutex                    sync.RWMutex // Lock for the Status
+	approvedVersion          string                   // The version that's been approved
+	deployedVersion          string                   // Track the deployed version of the service from the last successful WebHook.
+	deployedVersionTimestamp string                   // UTC timestamp of DeployedVersion being changed.
+	deployedVersions         map[string]string        // Deployed version of each target (deployed_version.targets).
+	latestVersion            string                   // Latest version found from query().
+	latestVersionTimestamp   string                   // UTC timestamp of LatestVersion being changed.
+	latestVersionSource      string                   // Source that decided the LatestVersion (when there are multiple sources).
+	latestVersionImage       *util.ImageInfo          // Container image found by require.docker for latestVersionRequireOf.
+	latestVersionArtifact    *util.ArtifactInfo       // Artifact verified by require.verify for latestVersionRequireOf.
+	latestVersionRequireOf   string                   // Version that latestVersionImage/latestVersionArtifact are of.
+	lastQueried              string                   // UTC timestamp that version was last queried/checked.
+	nextQuery                string                   // UTC timestamp that version will next be queried/checked.
+	queryFailures            uint                     // Counter for the number of consecutive failed queries.
+	backoff                  time.Duration            // Time waited until the next query because of the failed queries.
+	pendingVersion           string                   // Newest version found that is waiting on require.min_age.
+	pendingVersionEligible   string                   // UTC timestamp that PendingVersion passes require.min_age.
+	pendingVersionSeen       string                   // UTC timestamp that PendingVersion was first seen.
+	releaseNotes             string                   // Release notes (Markdown) of the versions after releaseNotesOf[0], up to releaseNotesOf[1].
+	releaseNotesOf           [2]string                // Deployed and latest versions that releaseNotes are of.
+	serviceInfo              func() *util.ServiceInfo // Info of the Service for templating (SetServiceInfo).
+	regexMissesContent       uint                     // Counter for the number of regex misses on URL content.
+	regexMissesVersion       uint                     // Counter for the number of regex misses on version.
+	Fails                    Fails                    // Track the Notify/WebHook fails
+	deleting                 bool                     // Flag to indicate the service is being deleted
+	mutex                    sync.RWMutex             // Lock for the Status
 }
 
 // New Status struct.
@@ -107,7 +121,14 @@ func (s *Status) String() string {
 		{Name: "deployed_version_timestamp", Value: s.deployedVersionTimestamp},
 		{Name: "latest_version", Value: s.latestVersion},
 		{Name: "latest_version_timestamp", Value: s.latestVersionTimestamp},
+		{Name: "latest_version_source", Value: s.latestVersionSource},
 		{Name: "last_queried", Value: s.lastQueried},
+		{Name: "next_query", Value: s.nextQuery},
+		{Name: "query_failures", Value: s.queryFailures},
+		{Name: "backoff", Value: s.backoffString()},
+		{Name: "pending_version", Value: s.pendingVersion},
+		{Name: "pending_version_eligible", Value: s.pendingVersionEligible},
+		{Name: "pending_version_seen", Value: s.pendingVersionSeen},
 		{Name: "regex_misses_content", Value: s.regexMissesContent},
 		{Name: "regex_misses_version", Value: s.regexMissesVersion},
 		{Name: "fails", Value: &s.Fails},
@@ -176,6 +197,59 @@ func (s *Status) SetLastQueried(t string) {
 	}
 }
 
+// NextQuery time of the LatestVersion.
+func (s *Status) NextQuery() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.nextQuery
+}
+
+// SetNextQuery will update NextQuery to `t`.
+func (s *Status) SetNextQuery(t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nextQuery = t.UTC().Format(time.RFC3339)
+}
+
+// QueryFailures will return the number of consecutive failed queries.
+func (s *Status) QueryFailures() uint {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.queryFailures
+}
+
+// Backoff will return the time waited until the next query because of the failed queries.
+func (s *Status) Backoff() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.backoffString()
+}
+
+// backoffString returns the Backoff as a string (empty when not backing off).
+func (s *Status) backoffString() string {
+	if s.backoff == 0 {
+		return ""
+	}
+	return s.backoff.String()
+}
+
+// SetBackoff will update QueryFailures to `failures` and Backoff to `backoff`,
+// as well as the LatestVersionQueryBackoff metric.
+func (s *Status) SetBackoff(failures uint, backoff time.Duration) {
+	s.mutex.Lock()
+	{
+		s.queryFailures = failures
+		s.backoff = backoff
+	}
+	s.mutex.Unlock()
+
+	if s.ServiceID != nil {
+		metric.SetPrometheusGauge(metric.LatestVersionQueryBackoff,
+			*s.ServiceID,
+			backoff.Seconds())
+	}
+}
+
 // ApprovedVersion returns the ApprovedVersion.
 func (s *Status) ApprovedVersion() string {
 	s.mutex.RLock()
@@ -256,6 +330,67 @@ func (s *Status) SetDeployedVersion(version string, writeToDB bool) {
 	}
 }
 
+// DeployedVersions returns the deployed version of each target.
+func (s *Status) DeployedVersions() map[string]string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.deployedVersions == nil {
+		return nil
+	}
+
+	versions := make(map[string]string, len(s.deployedVersions))
+	for target, version := range s.deployedVersions {
+		versions[target] = version
+	}
+	return versions
+}
+
+// SetDeployedVersions will set the deployed version of each target,
+// returning whether they changed.
+func (s *Status) SetDeployedVersions(versions map[string]string) (changed bool) {
+	s.mutex.Lock()
+	{
+		changed = len(versions) != len(s.deployedVersions)
+		for target, version := range versions {
+			if previous, ok := s.deployedVersions[target]; !ok || previous != version {
+				changed = true
+			}
+		}
+		if changed {
+			s.deployedVersions = make(map[string]string, len(versions))
+			for target, version := range versions {
+				s.deployedVersions[target] = version
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	s.mutex.RLock()
+	s.setDeployedVersionDriftMetric()
+	s.mutex.RUnlock()
+	return
+}
+
+// DeployedVersionDrift returns whether the targets have different deployed versions.
+func (s *Status) DeployedVersionDrift() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.deployedVersionDrift()
+}
+
+// deployedVersionDrift returns whether the targets have different deployed versions.
+func (s *Status) deployedVersionDrift() bool {
+	var first string
+	for _, version := range s.deployedVersions {
+		if first == "" {
+			first = version
+		} else if version != first {
+			return true
+		}
+	}
+	return false
+}
+
 // DeployedVersionTimestamp returns the DeployedVersionTimestamp.
 func (s *Status) DeployedVersionTimestamp() string {
 	s.mutex.RLock()
@@ -322,6 +457,167 @@ func (s *Status) SetLatestVersionTimestamp(timestamp string) {
 	s.mutex.Unlock()
 }
 
+// LatestVersionSource returns the source that decided the LatestVersion.
+func (s *Status) LatestVersionSource() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.latestVersionSource
+}
+
+// SetLatestVersionSource will set LatestVersionSource to `source`.
+func (s *Status) SetLatestVersionSource(source string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latestVersionSource = source
+}
+
+// LatestVersionImage returns the container image found by require.docker for the LatestVersion
+// (nil if it wasn't found for this version).
+func (s *Status) LatestVersionImage() *util.ImageInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.latestVersionRequireOf != s.latestVersion {
+		return nil
+	}
+	return s.latestVersionImage
+}
+
+// LatestVersionArtifact returns the artifact verified by require.verify for the LatestVersion
+// (nil if it wasn't verified for this version).
+func (s *Status) LatestVersionArtifact() *util.ArtifactInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.latestVersionRequireOf != s.latestVersion {
+		return nil
+	}
+	return s.latestVersionArtifact
+}
+
+// SetLatestVersionRequire will record what the Require found for the latest `version`,
+// the container `image` (require.docker) and the `artifact` (require.verify).
+func (s *Status) SetLatestVersionRequire(version string, image *util.ImageInfo, artifact *util.ArtifactInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latestVersionImage = image
+	s.latestVersionArtifact = artifact
+	s.latestVersionRequireOf = version
+}
+
+// PendingVersion returns the version waiting on require.min_age (if any).
+func (s *Status) PendingVersion() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.pendingVersion
+}
+
+// PendingVersionEligible returns the UTC timestamp that PendingVersion passes require.min_age.
+func (s *Status) PendingVersionEligible() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.pendingVersionEligible
+}
+
+// PendingVersionSeen returns the UTC timestamp that PendingVersion was first seen.
+func (s *Status) PendingVersionSeen() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.pendingVersionSeen
+}
+
+// SetPendingVersion will set PendingVersion to `version`, PendingVersionEligible to `eligible`
+// and PendingVersionSeen to `seen`, announcing the change if the version/eligible differs from
+// what was there before, and writing the version/seen to the database if they changed.
+//
+// An empty `version` clears the pending version.
+func (s *Status) SetPendingVersion(version string, eligible string, seen string) {
+	if version == "" {
+		eligible = ""
+		seen = ""
+	}
+
+	s.mutex.Lock()
+	changed := s.pendingVersion != version || s.pendingVersionEligible != eligible
+	changedDB := s.pendingVersion != version || s.pendingVersionSeen != seen
+	{
+		s.pendingVersion = version
+		s.pendingVersionEligible = eligible
+		s.pendingVersionSeen = seen
+	}
+	s.mutex.Unlock()
+
+	if s.ServiceID == nil {
+		return
+	}
+	if changedDB {
+		s.mutex.RLock()
+		s.sendDatabase(&dbtype.Message{
+			ServiceID: *s.ServiceID,
+			Cells: []dbtype.Cell{
+				{Column: "pending_version", Value: version},
+				{Column: "pending_version_seen", Value: seen}}})
+		s.mutex.RUnlock()
+	}
+	if changed {
+		s.AnnouncePending()
+	}
+}
+
+// SetPendingVersionSeen will set PendingVersion to `version` and PendingVersionSeen to `seen`
+// (e.g. from the database), without announcing it.
+func (s *Status) SetPendingVersionSeen(version string, seen string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pendingVersion = version
+	s.pendingVersionSeen = seen
+}
+
+// ServiceInfo returns the info of the Service for templating
+// (only the LatestVersion if no SetServiceInfo).
+func (s *Status) ServiceInfo() *util.ServiceInfo {
+	s.mutex.RLock()
+	serviceInfo := s.serviceInfo
+	s.mutex.RUnlock()
+	if serviceInfo != nil {
+		return serviceInfo()
+	}
+
+	return &util.ServiceInfo{LatestVersion: s.LatestVersion()}
+}
+
+// SetServiceInfo sets the function that returns the info of the Service for templating.
+func (s *Status) SetServiceInfo(serviceInfo func() *util.ServiceInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.serviceInfo = serviceInfo
+}
+
+// ReleaseNotes returns the release notes (Markdown) cached for the versions after the DeployedVersion,
+// up to the LatestVersion (empty if they're not cached for these versions).
+func (s *Status) ReleaseNotes() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.releaseNotesOf != [2]string{s.deployedVersion, s.latestVersion} {
+		return ""
+	}
+	return s.releaseNotes
+}
+
+// ReleaseNotesCached returns whether the release notes are cached for the DeployedVersion and LatestVersion.
+func (s *Status) ReleaseNotesCached() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.releaseNotesOf == [2]string{s.deployedVersion, s.latestVersion}
+}
+
+// SetReleaseNotes will cache the release `notes` (Markdown) of the versions after `from`, up to `to`.
+func (s *Status) SetReleaseNotes(from string, to string, notes string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.releaseNotes = notes
+	s.releaseNotesOf = [2]string{from, to}
+}
+
 // RegexMissContent will increment the count of RegEx misses on content.
 func (s *Status) RegexMissContent() {
 	s.mutex.Lock()
@@ -444,6 +740,21 @@ func (s *Status) setLatestVersionIsDeployedMetric() {
 		value)
 }
 
+// setDeployedVersionDriftMetric will set the metric for whether the targets have different deployed versions.
+func (s *Status) setDeployedVersionDriftMetric() {
+	if s.ServiceID == nil || s.deployedVersions == nil {
+		return
+	}
+
+	value := float64(0) // All the same version
+	if s.deployedVersionDrift() {
+		value = 1 // Different versions
+	}
+	metric.SetPrometheusGauge(metric.DeployedVersionDrift,
+		*s.ServiceID,
+		value)
+}
+
 // InitMetrics for the Status.
 func (s *Status) InitMetrics() {
 	if s == nil || s.ServiceID == nil {
@@ -461,4 +772,8 @@ func (s *Status) DeleteMetrics() {
 
 	metric.DeletePrometheusGauge(metric.LatestVersionIsDeployed,
 		*s.ServiceID)
+	metric.DeletePrometheusGauge(metric.DeployedVersionDrift,
+		*s.ServiceID)
+	metric.DeletePrometheusGauge(metric.LatestVersionQueryBackoff,
+		*s.ServiceID)
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	dbtype "github.com/release-argus/Argus/db/types"
 	"github.com/release-argus/Argus/test"
+	"github.com/release-argus/Argus/util"
 	metric "github.com/release-argus/Argus/web/metrics"
 )
 
@@ -180,6 +181,174 @@ func TestStatus_SetLastQueried(t *testing.T) {
 	}
 }
 
+func TestStatus_SetNextQuery(t *testing.T) {
+	// GIVEN we have a Status
+	var status Status
+	location, _ := time.LoadLocation("America/New_York")
+	next := time.Date(2024, 1, 1, 9, 0, 0, 0, location)
+
+	// WHEN we SetNextQuery
+	status.SetNextQuery(next)
+
+	// THEN NextQuery will be that time in UTC
+	want := "2024-01-01T14:00:00Z"
+	if got := status.NextQuery(); got != want {
+		t.Errorf("want: %q\ngot:  %q",
+			want, got)
+	}
+}
+
+func TestStatus_SetBackoff(t *testing.T) {
+	// GIVEN a Status
+	tests := map[string]struct {
+		failures    uint
+		backoff     time.Duration
+		wantBackoff string
+	}{
+		"backing off": {
+			failures:    2,
+			backoff:     40 * time.Minute,
+			wantBackoff: "40m0s"},
+		"reset": {
+			failures:    0,
+			backoff:     0,
+			wantBackoff: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var status Status
+			status.Init(
+				0, 0, 0,
+				test.StringPtr("TestStatus_SetBackoff_"+name),
+				test.StringPtr(""))
+			status.SetBackoff(5, time.Hour)
+
+			// WHEN SetBackoff is called
+			status.SetBackoff(tc.failures, tc.backoff)
+
+			// THEN QueryFailures and Backoff are set
+			if got := status.QueryFailures(); got != tc.failures {
+				t.Errorf("want %d QueryFailures, got %d",
+					tc.failures, got)
+			}
+			if got := status.Backoff(); got != tc.wantBackoff {
+				t.Errorf("want Backoff %q, got %q",
+					tc.wantBackoff, got)
+			}
+			// AND the LatestVersionQueryBackoff metric is set
+			gotMetric := testutil.ToFloat64(metric.LatestVersionQueryBackoff.WithLabelValues(*status.ServiceID))
+			if gotMetric != tc.backoff.Seconds() {
+				t.Errorf("want LatestVersionQueryBackoff metric %f, got %f",
+					tc.backoff.Seconds(), gotMetric)
+			}
+		})
+	}
+}
+
+func TestStatus_ReleaseNotes(t *testing.T) {
+	// GIVEN a Status with release notes cached for its versions
+	tests := map[string]struct {
+		deployedVersion string
+		latestVersion   string
+		want            string
+		wantCached      bool
+	}{
+		"same versions": {
+			deployedVersion: "1.0.0",
+			latestVersion:   "1.2.0",
+			want:            "## 1.2.0\n\nnotes",
+			wantCached:      true},
+		"latest version changed": {
+			deployedVersion: "1.0.0",
+			latestVersion:   "1.3.0",
+			want:            ""},
+		"deployed version changed": {
+			deployedVersion: "1.2.0",
+			latestVersion:   "1.2.0",
+			want:            ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var status Status
+			status.SetDeployedVersion("1.0.0", false)
+			status.SetLatestVersion("1.2.0", false)
+			status.SetReleaseNotes("1.0.0", "1.2.0", "## 1.2.0\n\nnotes")
+
+			// WHEN the versions are set
+			status.SetDeployedVersion(tc.deployedVersion, false)
+			status.SetLatestVersion(tc.latestVersion, false)
+
+			// THEN the release notes are only given for the versions they're of
+			if got := status.ReleaseNotes(); got != tc.want {
+				t.Errorf("want ReleaseNotes %q, got %q",
+					tc.want, got)
+			}
+			if got := status.ReleaseNotesCached(); got != tc.wantCached {
+				t.Errorf("want ReleaseNotesCached %t, got %t",
+					tc.wantCached, got)
+			}
+		})
+	}
+}
+
+func TestStatus_LatestVersionRequire(t *testing.T) {
+	// GIVEN a Status with the image/artifact of a version recorded
+	tests := map[string]struct {
+		imageOf       string
+		latestVersion string
+		want          string
+	}{
+		"image of the latest version": {
+			imageOf:       "1.2.0",
+			latestVersion: "1.2.0",
+			want:          "sha256:abc"},
+		"image of a version that wasn't accepted": {
+			imageOf:       "1.3.0",
+			latestVersion: "1.2.0",
+			want:          ""},
+		"latest version changed": {
+			imageOf:       "1.2.0",
+			latestVersion: "1.3.0",
+			want:          ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var status Status
+			status.SetLatestVersionRequire(tc.imageOf,
+				&util.ImageInfo{Digest: "sha256:abc"},
+				&util.ArtifactInfo{SHA256: "abc"})
+
+			// WHEN the LatestVersion is set
+			status.SetLatestVersion(tc.latestVersion, false)
+
+			// THEN the image is only given for the version it's of
+			var got string
+			if image := status.LatestVersionImage(); image != nil {
+				got = image.Digest
+			}
+			if got != tc.want {
+				t.Errorf("want digest %q, got %q",
+					tc.want, got)
+			}
+			// AND so is the artifact
+			gotArtifact := status.LatestVersionArtifact()
+			if (gotArtifact != nil) != (tc.want != "") {
+				t.Errorf("want artifact %t, got %v",
+					tc.want != "", gotArtifact)
+			}
+		})
+	}
+}
+
 func TestStatus_ApprovedVersion(t *testing.T) {
 	deployedVersion := "0.0.1"
 	latestVersion := "0.0.3"
@@ -349,6 +518,79 @@ func TestStatus_DeployedVersion(t *testing.T) {
 	}
 }
 
+func TestStatus_SetDeployedVersions(t *testing.T) {
+	// GIVEN a Status with the deployed versions of some targets
+	tests := map[string]struct {
+		previous    map[string]string
+		versions    map[string]string
+		wantChanged bool
+		wantDrift   bool
+	}{
+		"first versions": {
+			versions:    map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			wantChanged: true},
+		"unchanged": {
+			previous: map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			versions: map[string]string{"a": "1.2.3", "b": "1.2.3"}},
+		"version changed": {
+			previous:    map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			versions:    map[string]string{"a": "1.2.3", "b": "1.2.4"},
+			wantChanged: true,
+			wantDrift:   true},
+		"target removed": {
+			previous:    map[string]string{"a": "1.2.3", "b": "1.2.4"},
+			versions:    map[string]string{"a": "1.2.3"},
+			wantChanged: true},
+		"target renamed": {
+			previous:    map[string]string{"a": "1.2.3", "b": "1.2.3"},
+			versions:    map[string]string{"a": "1.2.3", "c": "1.2.3"},
+			wantChanged: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status := New(
+				nil, nil, nil,
+				"", "", "", "", "", "")
+			status.Init(
+				0, 0, 0,
+				&name,
+				nil)
+			status.SetDeployedVersions(tc.previous)
+
+			// WHEN SetDeployedVersions is called on it
+			gotChanged := status.SetDeployedVersions(tc.versions)
+
+			// THEN whether it changed is returned
+			if gotChanged != tc.wantChanged {
+				t.Errorf("want changed=%t, got %t",
+					tc.wantChanged, gotChanged)
+			}
+			// AND the versions are stored
+			if got := status.DeployedVersions(); fmt.Sprint(got) != fmt.Sprint(tc.versions) {
+				t.Errorf("want versions %v, got %v",
+					tc.versions, got)
+			}
+			// AND the drift is what we expect
+			if got := status.DeployedVersionDrift(); got != tc.wantDrift {
+				t.Errorf("want drift=%t, got %t",
+					tc.wantDrift, got)
+			}
+			got := testutil.ToFloat64(metric.DeployedVersionDrift.WithLabelValues(name))
+			want := float64(0)
+			if tc.wantDrift {
+				want = 1
+			}
+			if got != want {
+				t.Errorf("want DeployedVersionDrift metric %f, got %f",
+					want, got)
+			}
+		})
+	}
+}
+
 func TestStatus_LatestVersion(t *testing.T) {
 	// GIVEN a Status
 	approvedVersion := "0.0.2"
@@ -988,3 +1230,143 @@ func TestStatus_InitMetrics_DeleteMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestStatus_PendingVersion(t *testing.T) {
+	// GIVEN a Status with a PendingVersion that may already be set
+	tests := map[string]struct {
+		startVersion, startEligible, startSeen string
+		version, eligible, seen                string
+		wantVersion, wantEligible, wantSeen    string
+		wantAnnounce, wantDB                   bool
+	}{
+		"set from empty": {
+			version:      "1.2.3",
+			eligible:     "2020-01-01T00:00:00Z",
+			seen:         "2019-12-31T00:00:00Z",
+			wantVersion:  "1.2.3",
+			wantEligible: "2020-01-01T00:00:00Z",
+			wantSeen:     "2019-12-31T00:00:00Z",
+			wantAnnounce: true,
+			wantDB:       true},
+		"unchanged doesn't announce": {
+			startVersion:  "1.2.3",
+			startEligible: "2020-01-01T00:00:00Z",
+			startSeen:     "2019-12-31T00:00:00Z",
+			version:       "1.2.3",
+			eligible:      "2020-01-01T00:00:00Z",
+			seen:          "2019-12-31T00:00:00Z",
+			wantVersion:   "1.2.3",
+			wantEligible:  "2020-01-01T00:00:00Z",
+			wantSeen:      "2019-12-31T00:00:00Z",
+			wantAnnounce:  false,
+			wantDB:        false},
+		"loaded from the database gets its eligible announced": {
+			startVersion: "1.2.3",
+			startSeen:    "2019-12-31T00:00:00Z",
+			version:      "1.2.3",
+			eligible:     "2020-01-01T00:00:00Z",
+			seen:         "2019-12-31T00:00:00Z",
+			wantVersion:  "1.2.3",
+			wantEligible: "2020-01-01T00:00:00Z",
+			wantSeen:     "2019-12-31T00:00:00Z",
+			wantAnnounce: true,
+			wantDB:       false},
+		"newer pending version": {
+			startVersion:  "1.2.3",
+			startEligible: "2020-01-01T00:00:00Z",
+			startSeen:     "2019-12-31T00:00:00Z",
+			version:       "1.2.4",
+			eligible:      "2020-01-02T00:00:00Z",
+			seen:          "2020-01-01T00:00:00Z",
+			wantVersion:   "1.2.4",
+			wantEligible:  "2020-01-02T00:00:00Z",
+			wantSeen:      "2020-01-01T00:00:00Z",
+			wantAnnounce:  true,
+			wantDB:        true},
+		"cleared also clears eligible and seen": {
+			startVersion:  "1.2.3",
+			startEligible: "2020-01-01T00:00:00Z",
+			startSeen:     "2019-12-31T00:00:00Z",
+			version:       "",
+			eligible:      "2020-01-01T00:00:00Z",
+			seen:          "2019-12-31T00:00:00Z",
+			wantAnnounce:  true,
+			wantDB:        true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status := testStatus()
+			status.pendingVersion = tc.startVersion
+			status.pendingVersionEligible = tc.startEligible
+			status.pendingVersionSeen = tc.startSeen
+
+			// WHEN SetPendingVersion is called on it
+			status.SetPendingVersion(tc.version, tc.eligible, tc.seen)
+
+			// THEN the PendingVersion is set
+			if got := status.PendingVersion(); got != tc.wantVersion {
+				t.Errorf("PendingVersion - want %q, got %q",
+					tc.wantVersion, got)
+			}
+			// AND the PendingVersionEligible is set
+			if got := status.PendingVersionEligible(); got != tc.wantEligible {
+				t.Errorf("PendingVersionEligible - want %q, got %q",
+					tc.wantEligible, got)
+			}
+			// AND the PendingVersionSeen is set
+			if got := status.PendingVersionSeen(); got != tc.wantSeen {
+				t.Errorf("PendingVersionSeen - want %q, got %q",
+					tc.wantSeen, got)
+			}
+			// AND it was only announced if changed
+			gotAnnounces := len(*status.AnnounceChannel)
+			if tc.wantAnnounce != (gotAnnounces == 1) {
+				t.Errorf("announce - want %t, got %d messages",
+					tc.wantAnnounce, gotAnnounces)
+			}
+			// AND the version/seen were only sent to the database if changed
+			gotDB := len(*status.DatabaseChannel)
+			if tc.wantDB != (gotDB == 1) {
+				t.Fatalf("database - want %t, got %d messages",
+					tc.wantDB, gotDB)
+			}
+			if tc.wantDB {
+				msg := <-*status.DatabaseChannel
+				if len(msg.Cells) != 2 ||
+					msg.Cells[0].Column != "pending_version" || msg.Cells[0].Value != tc.wantVersion ||
+					msg.Cells[1].Column != "pending_version_seen" || msg.Cells[1].Value != tc.wantSeen {
+					t.Errorf("database - want pending_version=%q, pending_version_seen=%q, got %v",
+						tc.wantVersion, tc.wantSeen, msg.Cells)
+				}
+			}
+		})
+	}
+}
+
+func TestStatus_SetPendingVersionSeen(t *testing.T) {
+	// GIVEN a Status
+	status := testStatus()
+
+	// WHEN SetPendingVersionSeen is called on it
+	status.SetPendingVersionSeen("1.2.3", "2020-01-01T00:00:00Z")
+
+	// THEN the PendingVersion and PendingVersionSeen are set
+	if got := status.PendingVersion(); got != "1.2.3" {
+		t.Errorf("PendingVersion - want %q, got %q",
+			"1.2.3", got)
+	}
+	if got := status.PendingVersionSeen(); got != "2020-01-01T00:00:00Z" {
+		t.Errorf("PendingVersionSeen - want %q, got %q",
+			"2020-01-01T00:00:00Z", got)
+	}
+	// AND it wasn't announced or sent to the database
+	if got := len(*status.AnnounceChannel); got != 0 {
+		t.Errorf("announce - want 0 messages, got %d", got)
+	}
+	if got := len(*status.DatabaseChannel); got != 0 {
+		t.Errorf("database - want 0 messages, got %d", got)
+	}
+}
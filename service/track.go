@@ -15,10 +15,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/release-argus/Argus/scheduler"
+	deployedver "github.com/release-argus/Argus/service/deployed_version"
+	latestver "github.com/release-argus/Argus/service/latest_version"
 	"github.com/release-argus/Argus/util"
 )
 
@@ -28,28 +32,29 @@ func (s *Slice) Track(ordering *[]string, orderMutex *sync.RWMutex) {
 	defer orderMutex.RUnlock()
 	for _, key := range *ordering {
 		// Skip inactive Services (and services that were deleted on startup)
-		if !(*s)[key].Options.GetActive() || (*s)[key] == nil {
+		if (*s)[key] == nil || !(*s)[key].Options.GetActive() {
 			continue
 		}
 		(*s)[key].Options.Active = nil
 
+		every := "every " + (*s)[key].Options.GetInterval()
+		if schedule := (*s)[key].Options.GetSchedule(); schedule != "" {
+			every = fmt.Sprintf("on the schedule %q", schedule)
+		}
 		jLog.Verbose(
-			fmt.Sprintf("Tracking %s at %s every %s",
-				(*s)[key].ID, (*s)[key].LatestVersion.ServiceURL(true), (*s)[key].Options.GetInterval()),
+			fmt.Sprintf("Tracking %s at %s %s",
+				(*s)[key].ID, (*s)[key].LatestVersion.ServiceURL(true), every),
 			&util.LogFrom{Primary: (*s)[key].ID},
 			true)
 
-		// Track this Service in a infinite loop goroutine.
-		go (*s)[key].Track()
-
-		// Space out the tracking of each Service.
-		time.Sleep(time.Second / 2)
+		// Schedule the tracking of this Service.
+		(*s)[key].Track()
 	}
 }
 
-// Track the Service and send Notify messages (Service.Notify) as
+// Track the Service on the scheduler, sending Notify messages (Service.Notify) as
 // well as WebHooks (Service.WebHook) when a new release is spotted.
-// It sleeps for Service.Interval between each check.
+// It queries on the Service.Schedule (or every Service.Interval), outside the Service.QuietHours.
 func (s *Service) Track() {
 	// Skip inactive Services
 	if !s.Options.GetActive() {
@@ -58,36 +63,93 @@ func (s *Service) Track() {
 	}
 	s.ResetMetrics()
 
-	// If this Service was last queried less than interval ago, wait until interval has elapsed.
+	// If this Service was last queried recently, wait until the next query is due.
 	lastQueriedAt, _ := time.Parse(time.RFC3339, s.Status.LastQueried())
-	if time.Since(lastQueriedAt) < s.Options.GetIntervalDuration() {
-		time.Sleep(s.Options.GetIntervalDuration() - time.Since(lastQueriedAt))
-	}
+	nextQuery := s.Options.NextQuery(lastQueriedAt, time.Now(), s.Status.QueryFailures())
+	s.Status.SetNextQuery(nextQuery)
+	delay := max(time.Until(nextQuery), 0)
 
-	// Track the deployed version in an infinite loop goroutine.
-	go func() {
-		time.Sleep(2 * time.Second) // Give LatestVersion some time to query first.
-
-		go s.DeployedVersionLookup.Track()
-	}()
+	// Track the deployed version once LatestVersion has been queried
+	// (or now if it won't be queried straight away).
+	trackingDeployedVersion := delay != 0
+	if trackingDeployedVersion {
+		s.DeployedVersionLookup.Track()
+	}
 
-	// Track forever.
 	logFrom := util.LogFrom{Primary: s.ID}
-	for {
+	scheduler.Add(TrackID(s.ID), delay, func(ctx context.Context) time.Duration {
 		// If we're deleting this Service, stop tracking it.
 		if s.Status.Deleting() {
-			return
+			return 0
 		}
+		start := time.Now()
+		nextQuery := s.Options.NextQuery(start, start, s.Status.QueryFailures())
+		s.Status.SetNextQuery(nextQuery)
 
 		// If new release found by this query.
-		newVersion, _ := s.LatestVersion.Query(true, &logFrom)
+		newVersion, err := s.LatestVersion.Query(ctx, true, &logFrom)
+		// Stop if the Service was deleted/edited during the query.
+		if ctx.Err() != nil {
+			return 0
+		}
+
+		// Back off after consecutive failed queries, and reset on success.
+		nextQuery = s.backoff(latestver.QueryFailed(err), start, nextQuery, &logFrom)
+		// Cache the release notes for the actions/templates (if the versions changed).
+		if err == nil {
+			s.LatestVersion.CacheReleaseNotes(&logFrom)
+		}
 
 		// If a new version was found
 		if newVersion {
-			go s.HandleUpdateActions(true)
+			GoAction(func() { s.HandleUpdateActions(true) })
+		}
+
+		if !trackingDeployedVersion {
+			trackingDeployedVersion = true
+			s.DeployedVersionLookup.Track()
 		}
 
-		// Sleep interval between checks.
-		time.Sleep(s.Options.GetIntervalDuration())
+		// Query again at the next query time.
+		return max(time.Until(nextQuery), time.Second)
+	})
+}
+
+// backoff updates the consecutive failed queries of the Service after a query at `start`,
+// returning the next query time (`nextQuery` unless the backoff changed).
+func (s *Service) backoff(failed bool, start time.Time, nextQuery time.Time, logFrom *util.LogFrom) time.Time {
+	failures := s.Status.QueryFailures()
+	switch {
+	case failed:
+		failures++
+	case failures != 0:
+		failures = 0
+	default:
+		return nextQuery
+	}
+
+	backoff := s.Options.GetBackoffDuration(failures)
+	s.Status.SetBackoff(failures, backoff)
+	nextQuery = s.Options.NextQuery(start, time.Now(), failures)
+	s.Status.SetNextQuery(nextQuery)
+	s.Status.AnnounceQuery()
+	if failed {
+		jLog.Verbose(
+			fmt.Sprintf("%d consecutive failed queries, backing off until %s",
+				failures, nextQuery.UTC().Format(time.RFC3339)),
+			logFrom, true)
 	}
+	return nextQuery
+}
+
+// TrackID returns the ID of the scheduled job tracking the latest version of the Service with `serviceID`.
+func TrackID(serviceID string) string {
+	return serviceID + "/latest_version"
+}
+
+// Untrack the Service, removing its jobs from the scheduler.
+func (s *Service) Untrack() {
+	scheduler.Remove(
+		TrackID(s.ID),
+		deployedver.TrackID(s.ID))
 }
@@ -17,6 +17,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 	"testing"
@@ -103,7 +104,7 @@ func TestSlice_Track(t *testing.T) {
 
 func TestService_Track(t *testing.T) {
 	testSVC := testService("TestService_Track", "url")
-	testSVC.LatestVersion.Query(false, &util.LogFrom{})
+	testSVC.LatestVersion.Query(context.Background(), false, &util.LogFrom{})
 	testLatestVersion := testSVC.Status.LatestVersion()
 	// GIVEN a Service
 	tests := map[string]struct {
@@ -415,3 +416,78 @@ func TestService_Track(t *testing.T) {
 		})
 	}
 }
+
+func TestService_Backoff(t *testing.T) {
+	// GIVEN a Service with an interval of 10m, backing off up to 1h
+	tests := map[string]struct {
+		failures     uint
+		failed       bool
+		wantFailures uint
+		wantBackoff  time.Duration
+	}{
+		"success without failures": {
+			failures:     0,
+			failed:       false,
+			wantFailures: 0,
+			wantBackoff:  0},
+		"first failure": {
+			failures:     0,
+			failed:       true,
+			wantFailures: 1,
+			wantBackoff:  20 * time.Minute},
+		"consecutive failure": {
+			failures:     1,
+			failed:       true,
+			wantFailures: 2,
+			wantBackoff:  40 * time.Minute},
+		"consecutive failure capped": {
+			failures:     5,
+			failed:       true,
+			wantFailures: 6,
+			wantBackoff:  time.Hour},
+		"success resets": {
+			failures:     5,
+			failed:       false,
+			wantFailures: 0,
+			wantBackoff:  0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := testService(name, "url")
+			svc.Options.Interval = "10m"
+			svc.Options.BackoffMax = "1h"
+			svc.Status.SetBackoff(tc.failures, svc.Options.GetBackoffDuration(tc.failures))
+			start := time.Now()
+			nextQuery := svc.Options.NextQuery(start, start, tc.failures)
+			svc.Status.SetNextQuery(nextQuery)
+
+			// WHEN backoff is called after a query
+			got := svc.backoff(tc.failed, start, nextQuery, &util.LogFrom{Primary: name})
+
+			// THEN the failures are counted
+			if gotFailures := svc.Status.QueryFailures(); gotFailures != tc.wantFailures {
+				t.Errorf("want %d consecutive failures, got %d",
+					tc.wantFailures, gotFailures)
+			}
+			// AND the next query is backed off
+			wantNext := start.Add(max(tc.wantBackoff, svc.Options.GetIntervalDuration()))
+			if !got.Equal(wantNext) {
+				t.Errorf("want next query at %s, got %s",
+					wantNext, got)
+			}
+			if gotNext := svc.Status.NextQuery(); gotNext != wantNext.UTC().Format(time.RFC3339) {
+				t.Errorf("want NextQuery %q, got %q",
+					wantNext.UTC().Format(time.RFC3339), gotNext)
+			}
+			// AND the backoff metric is set
+			gotMetric := testutil.ToFloat64(metric.LatestVersionQueryBackoff.WithLabelValues(svc.ID))
+			if gotMetric != tc.wantBackoff.Seconds() {
+				t.Errorf("want backoff metric %f, got %f",
+					tc.wantBackoff.Seconds(), gotMetric)
+			}
+		})
+	}
+}
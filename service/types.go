@@ -15,6 +15,8 @@
 package service
 
 import (
+	"sync"
+
 	command "github.com/release-argus/Argus/commands"
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
@@ -27,7 +29,8 @@ import (
 )
 
 var (
-	jLog *util.JLog
+	jLog    *util.JLog
+	actions sync.WaitGroup // Actions in-flight (Commands running, Notifies/WebHooks sending).
 )
 
 // Slice is a slice mapping of Service.
@@ -56,8 +59,9 @@ type Service struct {
 	DeployedVersionLookup *deployedver.Lookup `yaml:"deployed_version,omitempty" json:"deployed_version,omitempty"` // Var to scrape the Service's current deployed version
 	Notify                shoutrrr.Slice      `yaml:"notify,omitempty" json:"notify,omitempty"`                     // Service-specific Shoutrrr vars
 	notifyFromDefaults    bool
-	CommandController     *command.Controller `yaml:"-" json:"-"`                                 // The controller for the OS Commands that tracks fails and has the announce channel
-	Command               command.Slice       `yaml:"command,omitempty" json:"command,omitempty"` // OS Commands to run on new release
+	CommandController     *command.Controller `yaml:"-" json:"-"`                                         // The controller for the OS Commands that tracks fails and has the announce channel
+	Command               command.Slice       `yaml:"command,omitempty" json:"command,omitempty"`         // OS Commands to run on new release
+	CommandSSH            string              `yaml:"command_ssh,omitempty" json:"command_ssh,omitempty"` // Name of the SSH profile to run the Commands with
 	commandFromDefaults   bool
 	WebHook               webhook.Slice `yaml:"webhook,omitempty" json:"webhook,omitempty"` // Service-specific WebHook vars
 	webhookFromDefaults   bool
@@ -103,7 +107,48 @@ func (s *Service) Summary() (summary *apitype.ServiceSummary) {
 			DeployedVersionTimestamp: s.Status.DeployedVersionTimestamp(),
 			LatestVersion:            s.Status.LatestVersion(),
 			LatestVersionTimestamp:   s.Status.LatestVersionTimestamp(),
-			LastQueried:              s.Status.LastQueried()}}
+			LastQueried:              s.Status.LastQueried(),
+			NextQuery:                s.Status.NextQuery(),
+			QueryFailures:            s.Status.QueryFailures(),
+			Backoff:                  s.Status.Backoff(),
+			PendingVersion:           s.Status.PendingVersion(),
+			PendingVersionEligible:   s.Status.PendingVersionEligible(),
+			LatestVersionSource:      s.Status.LatestVersionSource(),
+			DeployedVersions:         s.Status.DeployedVersions(),
+			DeployedVersionDrift:     s.Status.DeployedVersionDrift()}}
+	if release := s.LatestVersion.LatestRelease().Info(); release != nil {
+		summary.Status.LatestVersionRelease = &apitype.Release{
+			Name:            release.Name,
+			TagName:         release.TagName,
+			PreRelease:      release.PreRelease,
+			Draft:           release.Draft,
+			PublishedAt:     release.PublishedAt,
+			Body:            release.Body,
+			URL:             release.URL,
+			TargetCommitish: release.TargetCommitish,
+			Author:          release.Author}
+	}
+	if image := s.LatestVersion.LatestImage(); image != nil {
+		summary.Status.LatestVersionImage = &apitype.Image{
+			Image:     image.Image,
+			Tag:       image.Tag,
+			Digest:    image.Digest,
+			Reference: image.Reference(),
+			Platforms: make([]apitype.ImagePlatform, len(image.Platforms))}
+		for i, platform := range image.Platforms {
+			summary.Status.LatestVersionImage.Platforms[i] = apitype.ImagePlatform{
+				Platform: platform.Platform,
+				Digest:   platform.Digest,
+				Size:     platform.Size}
+		}
+	}
+	if artifact := s.LatestVersion.LatestArtifact(); artifact != nil {
+		summary.Status.LatestVersionArtifact = &apitype.Artifact{
+			URL:           artifact.URL,
+			Filename:      artifact.Filename,
+			SHA256:        artifact.SHA256,
+			SignatureType: artifact.SignatureType}
+	}
 	return
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	latestver "github.com/release-argus/Argus/service/latest_version"
+	github_types "github.com/release-argus/Argus/service/latest_version/api_type"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
 	"github.com/release-argus/Argus/test"
@@ -358,6 +359,26 @@ func TestService_Summary(t *testing.T) {
 				WebHook:                  test.IntPtr(3),
 				Status:                   &apitype.Status{}},
 		},
+		"github release metadata": {
+			svc: &Service{
+				LatestVersion: latestver.Lookup{
+					Type:       "github",
+					GitHubData: testGitHubDataWithLatestRelease()}},
+			want: &apitype.ServiceSummary{
+				Type:                     test.StringPtr("github"),
+				Icon:                     test.StringPtr(""),
+				IconLinkTo:               test.StringPtr(""),
+				HasDeployedVersionLookup: test.BoolPtr(false),
+				Command:                  test.IntPtr(0),
+				WebHook:                  test.IntPtr(0),
+				Status: &apitype.Status{
+					LatestVersionRelease: &apitype.Release{
+						TagName:         "v1.2.3",
+						Body:            "notes",
+						URL:             "https://github.com/release-argus/Argus/releases/tag/v1.2.3",
+						TargetCommitish: "main",
+						Author:          "octocat"}}},
+		},
 		"only status": {
 			svc: &Service{
 				Status: svcstatus.Status{}},
@@ -488,3 +509,14 @@ func TestService_UsingDefaults(t *testing.T) {
 		})
 	}
 }
+
+func testGitHubDataWithLatestRelease() *latestver.GitHubData {
+	githubData := latestver.NewGitHubData("", nil)
+	githubData.SetLatestRelease(&github_types.Release{
+		TagName:         "v1.2.3",
+		Body:            "notes",
+		HTMLURL:         "https://github.com/release-argus/Argus/releases/tag/v1.2.3",
+		TargetCommitish: "main",
+		Author:          &github_types.Author{Login: "octocat"}})
+	return githubData
+}
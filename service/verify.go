@@ -17,6 +17,7 @@ package service
 import (
 	"fmt"
 
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 )
 
@@ -48,6 +49,10 @@ func (s *Defaults) CheckValues(prefix string) (errs error) {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), latestVersionErrs)
 	}
+	if deployedVersionErrs := s.DeployedVersionLookup.CheckValues(prefix); deployedVersionErrs != nil {
+		errs = fmt.Errorf("%s%w",
+			util.ErrorToString(errs), deployedVersionErrs)
+	}
 
 	return
 }
@@ -75,6 +80,12 @@ func (s *Service) CheckValues(prefix string) (errs error) {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), commandErrs)
 	}
+	if s.CommandSSH != "" {
+		if err := ssh.CheckProfile(s.CommandSSH); err != nil {
+			errs = fmt.Errorf("%s%scommand_ssh: %s\\",
+				util.ErrorToString(errs), errPrefix, err)
+		}
+	}
 	if webhookErrs := s.WebHook.CheckValues(errPrefix); webhookErrs != nil {
 		errs = fmt.Errorf("%s%w",
 			util.ErrorToString(errs), webhookErrs)
@@ -203,6 +203,16 @@ func TestService_CheckValues(t *testing.T) {
 		dashboardOptions DashboardOptions
 		errRegex         []string
 	}{
+		"command_ssh with unknown profile": {
+			svc: &Service{
+				ID: "test", CommandSSH: "unknown"},
+			latestVersion: latestver.Lookup{
+				Type: "github", URL: "release-argus/Argus"},
+			commands: command.Slice{{"bash", "update.sh"}},
+			errRegex: []string{
+				`^test:$`,
+				`^  command_ssh: "unknown" <invalid> \(no ssh profiles are defined\)$`},
+		},
 		"options with errs": {
 			svc: &Service{
 				ID: "test", Comment: "foo_comment"},
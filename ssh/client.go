@@ -0,0 +1,274 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/release-argus/Argus/util"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	clients      = make(map[string]*cryptossh.Client) // Connections, shared per user@host:port.
+	dialMutexes  = make(map[string]*sync.Mutex)       // Only dial each user@host:port once at a time.
+	clientsMutex sync.Mutex                           // Mutex for clients and dialMutexes.
+)
+
+// Run `command` on the host of the Profile `name`,
+// returning its stdout and stderr.
+func Run(ctx context.Context, name string, command []string) (stdout []byte, stderr []byte, err error) {
+	profile := GetProfile(name)
+	if profile == nil {
+		err = fmt.Errorf("ssh profile %q not found", name)
+		return
+	}
+
+	return profile.Run(ctx, command)
+}
+
+// Run `command` on the host of the Profile,
+// returning its stdout and stderr.
+func (p *Profile) Run(ctx context.Context, command []string) (stdout []byte, stderr []byte, err error) {
+	session, err := p.newSession()
+	if err != nil {
+		return
+	}
+	defer session.Close()
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	session.Stdout = &stdoutBuf
+	session.Stderr = &stderrBuf
+	if err = session.Start(Quote(command)); err != nil {
+		err = fmt.Errorf("ssh %s: %w", p.key(), err)
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err = <-done:
+		stdout, stderr = stdoutBuf.Bytes(), stderrBuf.Bytes()
+	case <-ctx.Done():
+		//#nosec G104 -- the session is closed regardless
+		//nolint:errcheck // ^
+		session.Signal(cryptossh.SIGKILL)
+		err = ctx.Err()
+	}
+	return
+}
+
+// newSession returns a new session on the (shared) connection to the host,
+// reconnecting if that connection has been lost.
+func (p *Profile) newSession() (*cryptossh.Session, error) {
+	client, err := p.client(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err == nil {
+		return session, nil
+	}
+
+	// The connection may have been closed, reconnect.
+	if client, err = p.client(client); err != nil {
+		return nil, err
+	}
+	session, err = client.NewSession()
+	if err != nil {
+		err = fmt.Errorf("ssh %s: %w", p.key(), err)
+	}
+	return session, err
+}
+
+// client returns the connection to the host, connecting if there isn't one,
+// or the current one is `stale`.
+//
+// Connecting only blocks the other callers for the same host.
+func (p *Profile) client(stale *cryptossh.Client) (*cryptossh.Client, error) {
+	key := p.key()
+	if client := getClient(key, stale); client != nil {
+		return client, nil
+	}
+
+	// Only dial once, those waiting will get this connection.
+	dialMutex := getDialMutex(key)
+	dialMutex.Lock()
+	defer dialMutex.Unlock()
+	if client := getClient(key, stale); client != nil {
+		return client, nil
+	}
+
+	client, err := p.dial()
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", key, err)
+	}
+	clientsMutex.Lock()
+	clients[key] = client
+	clientsMutex.Unlock()
+
+	// Forget the connection when it's closed.
+	go func() {
+		//nolint:errcheck
+		client.Wait()
+		clientsMutex.Lock()
+		defer clientsMutex.Unlock()
+		if clients[key] == client {
+			delete(clients, key)
+		}
+	}()
+
+	return client, nil
+}
+
+// getClient returns the connection with `key`, closing (and forgetting) it if it's `stale`.
+func getClient(key string, stale *cryptossh.Client) *cryptossh.Client {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	client := clients[key]
+	if client == nil {
+		return nil
+	}
+	// Still connected (or has already been reconnected).
+	if client != stale {
+		return client
+	}
+	client.Close()
+	delete(clients, key)
+	return nil
+}
+
+// getDialMutex returns the mutex for dialing `key`.
+func getDialMutex(key string) *sync.Mutex {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	dialMutex := dialMutexes[key]
+	if dialMutex == nil {
+		dialMutex = &sync.Mutex{}
+		dialMutexes[key] = dialMutex
+	}
+	return dialMutex
+}
+
+// dial the host of the Profile.
+func (p *Profile) dial() (*cryptossh.Client, error) {
+	hostKeyCallback, err := knownhosts.New(p.GetKnownHosts())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	config := &cryptossh.ClientConfig{
+		User:            p.GetUser(),
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         p.GetTimeout()}
+
+	// Private key
+	if p.Key != "" {
+		signer, err := p.signer()
+		if err != nil {
+			return nil, err
+		}
+		config.Auth = append(config.Auth, cryptossh.PublicKeys(signer))
+	}
+
+	// SSH agent
+	if p.Agent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, errors.New("agent: SSH_AUTH_SOCK isn't set")
+		}
+		agentConn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("agent: %w", err)
+		}
+		// Only needed for the handshake.
+		defer agentConn.Close()
+		config.Auth = append(config.Auth,
+			cryptossh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	return cryptossh.Dial("tcp", p.GetAddress(), config)
+}
+
+// signer of the private Key.
+func (p *Profile) signer() (cryptossh.Signer, error) {
+	path := util.EvalEnvVars(p.Key)
+	//#nosec G304 -- Key from the config
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	var signer cryptossh.Signer
+	if p.Passphrase != "" {
+		signer, err = cryptossh.ParsePrivateKeyWithPassphrase(key, []byte(util.EvalEnvVars(p.Passphrase)))
+	} else {
+		signer, err = cryptossh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %q: %w", path, err)
+	}
+	return signer, nil
+}
+
+// CloseAll of the connections.
+func CloseAll() {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	for key, client := range clients {
+		client.Close()
+		delete(clients, key)
+	}
+}
+
+// Quote the `command` args for the shell of the remote host.
+func Quote(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg != "" && strings.IndexFunc(arg, needsQuote) == -1 {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// needsQuote returns whether `r` has to be quoted in a shell argument.
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z',
+		r >= 'A' && r <= 'Z',
+		r >= '0' && r <= '9',
+		strings.ContainsRune("-_./=:,+@%", r):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,336 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package ssh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+func TestProfile_Run(t *testing.T) {
+	// GIVEN a Profile of an SSH server
+	_, profile := newTestServer(t)
+	tests := map[string]struct {
+		command    []string
+		timeout    time.Duration
+		wantStdout string
+		wantStderr string
+		errRegex   string
+	}{
+		"command": {
+			command:    []string{"echo", "1.2.3"},
+			wantStdout: "1.2.3",
+			errRegex:   `^$`},
+		"args are quoted": {
+			command:    []string{"echo", "it's", "a b", "1.2.3"},
+			wantStdout: `'it'\''s' 'a b' 1.2.3`,
+			errRegex:   `^$`},
+		"failing command": {
+			command:    []string{"fail"},
+			wantStderr: "oops",
+			errRegex:   `^Process exited with status 1$`},
+		"unknown command": {
+			command:    []string{"foo"},
+			wantStderr: "foo: command not found",
+			errRegex:   `^Process exited with status 127$`},
+		"command that times out": {
+			command:  []string{"sleep"},
+			timeout:  100 * time.Millisecond,
+			errRegex: `^context deadline exceeded$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			timeout := tc.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			// WHEN Run is called on it
+			stdout, stderr, err := profile.Run(ctx, tc.command)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the output is what we expect
+			if string(stdout) != tc.wantStdout {
+				t.Errorf("want stdout %q, got %q",
+					tc.wantStdout, string(stdout))
+			}
+			if string(stderr) != tc.wantStderr {
+				t.Errorf("want stderr %q, got %q",
+					tc.wantStderr, string(stderr))
+			}
+		})
+	}
+}
+
+func TestProfile_Run_ConnectionReuse(t *testing.T) {
+	// GIVEN a Profile of an SSH server
+	server, profile := newTestServer(t)
+	run := func() {
+		t.Helper()
+		stdout, _, err := profile.Run(context.Background(), []string{"echo", "hi"})
+		if err != nil || string(stdout) != "hi" {
+			t.Fatalf("want %q, got %q (err=%v)",
+				"hi", string(stdout), err)
+		}
+	}
+
+	// WHEN it's Run multiple times
+	for i := 0; i < 3; i++ {
+		run()
+	}
+	// THEN the connection is reused
+	if got := server.Connections(); got != 1 {
+		t.Errorf("want 1 connection after 3 runs, got %d", got)
+	}
+
+	// WHEN the server drops the connection
+	server.dropConnections()
+	run()
+	// THEN it reconnects
+	if got := server.Connections(); got != 2 {
+		t.Errorf("want 2 connections after the connection was dropped, got %d", got)
+	}
+
+	// WHEN the connections are closed
+	CloseAll()
+	run()
+	// THEN it reconnects
+	if got := server.Connections(); got != 3 {
+		t.Errorf("want 3 connections after CloseAll, got %d", got)
+	}
+}
+
+func TestProfile_Run_ConcurrentConnect(t *testing.T) {
+	// GIVEN a Profile of an SSH server that isn't connected to yet
+	server, profile := newTestServer(t)
+	runs := 5
+
+	// WHEN it's Run multiple times at once
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := profile.Run(context.Background(), []string{"echo", "hi"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// THEN they all succeed
+	for err := range errs {
+		if err != nil {
+			t.Errorf("want no error, got %v", err)
+		}
+	}
+	// AND only one connection was made
+	if got := server.Connections(); got != 1 {
+		t.Errorf("want 1 connection after %d concurrent runs, got %d",
+			runs, got)
+	}
+}
+
+func TestProfile_Run_ConnectionErrors(t *testing.T) {
+	// GIVEN a Profile that can't connect to an SSH server
+	_, validProfile := newTestServer(t)
+	_, otherProfile := newTestServer(t)
+	tests := map[string]struct {
+		profile  func() *Profile
+		errRegex string
+	}{
+		"host key mismatch": {
+			profile: func() *Profile {
+				profile := *validProfile
+				// known_hosts of a different server on the same address
+				otherKnownHosts, _ := os.ReadFile(otherProfile.KnownHosts)
+				otherKnownHosts = regexp.MustCompile(`^\S+`).ReplaceAll(otherKnownHosts,
+					regexp.MustCompile(`^\S+`).Find(mustReadFile(t, validProfile.KnownHosts)))
+				profile.KnownHosts = filepath.Join(t.TempDir(), "known_hosts")
+				os.WriteFile(profile.KnownHosts, otherKnownHosts, 0600)
+				return &profile
+			},
+			errRegex: `^ssh argus@[^:]+:[0-9]+: ssh: handshake failed: knownhosts: key mismatch$`},
+		"unknown host": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.KnownHosts = otherProfile.KnownHosts
+				return &profile
+			},
+			errRegex: `^ssh argus@[^:]+:[0-9]+: ssh: handshake failed: knownhosts: key is unknown$`},
+		"known_hosts doesn't exist": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.KnownHosts = filepath.Join(t.TempDir(), "known_hosts")
+				return &profile
+			},
+			errRegex: `^ssh [^ ]+: failed to read known_hosts: open [^ ]+: no such file or directory$`},
+		"unknown key": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.Key = otherProfile.Key
+				return &profile
+			},
+			errRegex: `^ssh [^ ]+: ssh: handshake failed: ssh: unable to authenticate`},
+		"key doesn't exist": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.Key = filepath.Join(t.TempDir(), "id_ed25519")
+				return &profile
+			},
+			errRegex: `^ssh [^ ]+: failed to read key: open [^ ]+: no such file or directory$`},
+		"invalid key": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.Key = validProfile.KnownHosts
+				return &profile
+			},
+			errRegex: `^ssh [^ ]+: failed to parse key "[^"]+": ssh: no key found$`},
+		"agent without SSH_AUTH_SOCK": {
+			profile: func() *Profile {
+				profile := *validProfile
+				profile.Key = ""
+				profile.Agent = true
+				return &profile
+			},
+			errRegex: `^ssh [^ ]+: agent: SSH_AUTH_SOCK isn't set$`},
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			profile := tc.profile()
+			// Don't reuse the connection of another test.
+			CloseAll()
+
+			// WHEN Run is called on it
+			_, _, err := profile.Run(context.Background(), []string{"echo", "hi"})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Errorf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	// GIVEN Profiles
+	_, profile := newTestServer(t)
+	SetProfiles(Profiles{"test": profile})
+	t.Cleanup(func() { SetProfiles(nil) })
+	tests := map[string]struct {
+		name       string
+		wantStdout string
+		errRegex   string
+	}{
+		"profile exists": {
+			name:       "test",
+			wantStdout: "hi",
+			errRegex:   `^$`},
+		"profile doesn't exist": {
+			name:     "unknown",
+			errRegex: `^ssh profile "unknown" not found$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN Run is called with the name of a Profile
+			stdout, _, err := Run(context.Background(), tc.name, []string{"echo", "hi"})
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Fatalf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+			// AND the output is what we expect
+			if string(stdout) != tc.wantStdout {
+				t.Errorf("want stdout %q, got %q",
+					tc.wantStdout, string(stdout))
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	// GIVEN a command
+	tests := map[string]struct {
+		command []string
+		want    string
+	}{
+		"no quoting needed": {
+			command: []string{"cat", "/opt/app/VERSION"},
+			want:    "cat /opt/app/VERSION"},
+		"spaces": {
+			command: []string{"echo", "hello world"},
+			want:    "echo 'hello world'"},
+		"single quotes": {
+			command: []string{"echo", "it's"},
+			want:    `echo 'it'\''s'`},
+		"shell characters": {
+			command: []string{"sh", "-c", "app --version | head -1"},
+			want:    "sh -c 'app --version | head -1'"},
+		"empty arg": {
+			command: []string{"echo", ""},
+			want:    "echo ''"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN Quote is called on it
+			got := Quote(tc.command)
+
+			// THEN the command is quoted as expected
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+// mustReadFile returns the contents of the file at `path`.
+func mustReadFile(t *testing.T, path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %q: %s", path, err)
+	}
+	return data
+}
@@ -0,0 +1,201 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer is an SSH server on localhost that runs:
+//
+// "echo ..." - writes what's after 'echo ' to stdout,
+// "fail" - writes "oops" to stderr and exits with 1,
+// "sleep" - runs until the session is closed.
+type testServer struct {
+	listener    net.Listener
+	connections int32 // Connections made to the server.
+
+	mutex sync.Mutex
+	conns []*cryptossh.ServerConn
+}
+
+// newTestServer starts a testServer, returning it and a Profile that can connect to it.
+func newTestServer(t *testing.T) (*testServer, *Profile) {
+	dir := t.TempDir()
+
+	// Host key
+	_, hostKey, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := cryptossh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("failed to create the host key: %s", err)
+	}
+	// Client key
+	clientPublicKey, clientKey, _ := ed25519.GenerateKey(rand.Reader)
+	clientSSHKey, _ := cryptossh.NewPublicKey(clientPublicKey)
+	keyBlock, err := cryptossh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatalf("failed to marshal the client key: %s", err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(keyBlock), 0600); err != nil {
+		t.Fatalf("failed to write the client key: %s", err)
+	}
+
+	config := &cryptossh.ServerConfig{
+		PublicKeyCallback: func(conn cryptossh.ConnMetadata, key cryptossh.PublicKey) (*cryptossh.Permissions, error) {
+			if conn.User() == "argus" && bytes.Equal(key.Marshal(), clientSSHKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %q", conn.User())
+		}}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	server := &testServer{listener: listener}
+	go server.serve(config)
+	t.Cleanup(server.close)
+
+	// known_hosts
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(listener.Addr().String())}, hostSigner.PublicKey())
+	if err := os.WriteFile(knownHostsPath, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %s", err)
+	}
+
+	return server, &Profile{
+		Host:       listener.Addr().String(),
+		User:       "argus",
+		Key:        keyPath,
+		KnownHosts: knownHostsPath}
+}
+
+// Connections made to the server.
+func (s *testServer) Connections() int32 {
+	return atomic.LoadInt32(&s.connections)
+}
+
+// dropConnections closes the connections to the server.
+func (s *testServer) dropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// close the server.
+func (s *testServer) close() {
+	s.listener.Close()
+	s.dropConnections()
+}
+
+// serve connections until the listener is closed.
+func (s *testServer) serve(config *cryptossh.ServerConfig) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			serverConn, channels, requests, err := cryptossh.NewServerConn(conn, config)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			atomic.AddInt32(&s.connections, 1)
+			s.mutex.Lock()
+			s.conns = append(s.conns, serverConn)
+			s.mutex.Unlock()
+
+			go cryptossh.DiscardRequests(requests)
+			for newChannel := range channels {
+				if newChannel.ChannelType() != "session" {
+					//nolint:errcheck
+					newChannel.Reject(cryptossh.UnknownChannelType, "unknown channel type")
+					continue
+				}
+				channel, channelRequests, err := newChannel.Accept()
+				if err != nil {
+					continue
+				}
+				go handleSession(channel, channelRequests)
+			}
+		}()
+	}
+}
+
+// handleSession runs the "exec" request of the session.
+func handleSession(channel cryptossh.Channel, requests <-chan *cryptossh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			//nolint:errcheck
+			req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := cryptossh.Unmarshal(req.Payload, &payload); err != nil {
+			//nolint:errcheck
+			req.Reply(false, nil)
+			return
+		}
+		//nolint:errcheck
+		req.Reply(true, nil)
+
+		var exitStatus uint32
+		switch {
+		case strings.HasPrefix(payload.Command, "echo "):
+			fmt.Fprint(channel, strings.TrimPrefix(payload.Command, "echo "))
+		case payload.Command == "fail":
+			fmt.Fprint(channel.Stderr(), "oops")
+			exitStatus = 1
+		case payload.Command == "sleep":
+			// Wait for the session to be closed.
+			for range requests {
+			}
+			return
+		default:
+			fmt.Fprintf(channel.Stderr(), "%s: command not found", payload.Command)
+			exitStatus = 127
+		}
+		//nolint:errcheck
+		channel.SendRequest("exit-status", false,
+			cryptossh.Marshal(struct{ Status uint32 }{exitStatus}))
+		return
+	}
+}
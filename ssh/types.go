@@ -0,0 +1,121 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+var (
+	profiles      Profiles     // Profiles that can be used by name.
+	profilesMutex sync.RWMutex // Mutex for profiles.
+)
+
+// Profiles of SSH connections, mapped by name.
+type Profiles map[string]*Profile
+
+// Profile of an SSH connection.
+type Profile struct {
+	Host       string `yaml:"host,omitempty" json:"host,omitempty"`               // host[:port] to connect to (default port 22)
+	User       string `yaml:"user,omitempty" json:"user,omitempty"`               // User to connect as
+	Key        string `yaml:"key,omitempty" json:"key,omitempty"`                 // Path of the private key to authenticate with
+	Passphrase string `yaml:"passphrase,omitempty" json:"passphrase,omitempty"`   // Passphrase of the private key
+	Agent      bool   `yaml:"agent,omitempty" json:"agent,omitempty"`             // Authenticate with the keys of the SSH agent at $SSH_AUTH_SOCK
+	KnownHosts string `yaml:"known_hosts,omitempty" json:"known_hosts,omitempty"` // Path of the known_hosts file to verify the host key with (default ~/.ssh/known_hosts)
+	Timeout    string `yaml:"timeout,omitempty" json:"timeout,omitempty"`         // AhBmCs = Time to wait for the connection (default 10s)
+}
+
+// String returns a string representation of the Profiles.
+func (p *Profiles) String(prefix string) (str string) {
+	if p != nil && len(*p) != 0 {
+		str = util.ToYAMLString(p, prefix)
+	}
+	return
+}
+
+// Names of the Profiles, sorted.
+func (p *Profiles) Names() []string {
+	if p == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(*p))
+	for name := range *p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetProfiles that can be used by name.
+func SetProfiles(newProfiles Profiles) {
+	profilesMutex.Lock()
+	defer profilesMutex.Unlock()
+
+	profiles = newProfiles
+}
+
+// GetProfile returns the Profile with `name`, or nil if it doesn't exist.
+func GetProfile(name string) *Profile {
+	profilesMutex.RLock()
+	defer profilesMutex.RUnlock()
+
+	return profiles[name]
+}
+
+// GetAddress of the Host, with the default port (22) if it doesn't include one.
+func (p *Profile) GetAddress() string {
+	host := util.EvalEnvVars(p.Host)
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(host, "22")
+	}
+	return host
+}
+
+// GetUser to connect as.
+func (p *Profile) GetUser() string {
+	return util.EvalEnvVars(p.User)
+}
+
+// GetKnownHosts returns the path of the known_hosts file,
+// defaulting to ~/.ssh/known_hosts.
+func (p *Profile) GetKnownHosts() string {
+	if p.KnownHosts != "" {
+		return util.EvalEnvVars(p.KnownHosts)
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// GetTimeout of the connection.
+func (p *Profile) GetTimeout() time.Duration {
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil || timeout <= 0 {
+		return 10 * time.Second
+	}
+	return timeout
+}
+
+// key the connections to this Profile are shared under.
+func (p *Profile) key() string {
+	return p.GetUser() + "@" + p.GetAddress()
+}
@@ -0,0 +1,94 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProfile_Gets(t *testing.T) {
+	// GIVEN a Profile
+	home, _ := os.UserHomeDir()
+	os.Setenv("TEST_SSH_GETS_HOST", "legacy.example.com")
+	tests := map[string]struct {
+		profile        Profile
+		wantAddress    string
+		wantKnownHosts string
+		wantTimeout    time.Duration
+	}{
+		"defaults": {
+			profile: Profile{
+				Host: "legacy.example.com"},
+			wantAddress:    "legacy.example.com:22",
+			wantKnownHosts: filepath.Join(home, ".ssh", "known_hosts"),
+			wantTimeout:    10 * time.Second},
+		"port, known_hosts and timeout": {
+			profile: Profile{
+				Host:       "legacy.example.com:2222",
+				KnownHosts: "/etc/argus/known_hosts",
+				Timeout:    "3s"},
+			wantAddress:    "legacy.example.com:2222",
+			wantKnownHosts: "/etc/argus/known_hosts",
+			wantTimeout:    3 * time.Second},
+		"IPv6": {
+			profile: Profile{
+				Host: "::1"},
+			wantAddress:    "[::1]:22",
+			wantKnownHosts: filepath.Join(home, ".ssh", "known_hosts"),
+			wantTimeout:    10 * time.Second},
+		"IPv6 with port": {
+			profile: Profile{
+				Host: "[::1]:2222"},
+			wantAddress:    "[::1]:2222",
+			wantKnownHosts: filepath.Join(home, ".ssh", "known_hosts"),
+			wantTimeout:    10 * time.Second},
+		"host from env": {
+			profile: Profile{
+				Host: "${TEST_SSH_GETS_HOST}"},
+			wantAddress:    "legacy.example.com:22",
+			wantKnownHosts: filepath.Join(home, ".ssh", "known_hosts"),
+			wantTimeout:    10 * time.Second},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN the Gets are called on it
+			address := tc.profile.GetAddress()
+			knownHosts := tc.profile.GetKnownHosts()
+			timeout := tc.profile.GetTimeout()
+
+			// THEN they return what we expect
+			if address != tc.wantAddress {
+				t.Errorf("GetAddress() - want %q, got %q",
+					tc.wantAddress, address)
+			}
+			if knownHosts != tc.wantKnownHosts {
+				t.Errorf("GetKnownHosts() - want %q, got %q",
+					tc.wantKnownHosts, knownHosts)
+			}
+			if timeout != tc.wantTimeout {
+				t.Errorf("GetTimeout() - want %s, got %s",
+					tc.wantTimeout, timeout)
+			}
+		})
+	}
+}
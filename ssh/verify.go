@@ -0,0 +1,110 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/release-argus/Argus/util"
+)
+
+// CheckValues of the Profiles.
+func (p *Profiles) CheckValues(prefix string) (errs error) {
+	if p == nil {
+		return
+	}
+
+	for _, name := range p.Names() {
+		if err := (*p)[name].CheckValues(prefix + "    "); err != nil {
+			errs = fmt.Errorf("%s%s  %s:\\%w",
+				util.ErrorToString(errs), prefix, name, err)
+		}
+	}
+
+	if errs != nil {
+		errs = fmt.Errorf("%sssh:\\%w",
+			prefix, errs)
+	}
+	return
+}
+
+// CheckValues of the Profile.
+func (p *Profile) CheckValues(prefix string) (errs error) {
+	if p == nil {
+		errs = fmt.Errorf("%shost: <required> (host[:port] to connect to)\\",
+			prefix)
+		return
+	}
+
+	if p.Host == "" {
+		errs = fmt.Errorf("%s%shost: <required> (host[:port] to connect to)\\",
+			util.ErrorToString(errs), prefix)
+	}
+	if p.User == "" {
+		errs = fmt.Errorf("%s%suser: <required> (user to connect as)\\",
+			util.ErrorToString(errs), prefix)
+	}
+	if p.Key == "" && !p.Agent {
+		errs = fmt.Errorf("%s%skey: <required> (path of the private key to authenticate with, or use 'agent: true')\\",
+			util.ErrorToString(errs), prefix)
+	}
+	if p.Passphrase != "" && p.Key == "" {
+		errs = fmt.Errorf("%s%spassphrase: <invalid> (only used with key)\\",
+			util.ErrorToString(errs), prefix)
+	}
+
+	// Timeout
+	if p.Timeout != "" {
+		// Default to seconds when an integer is provided
+		if _, err := strconv.Atoi(p.Timeout); err == nil {
+			p.Timeout += "s"
+		}
+		if timeout, err := time.ParseDuration(p.Timeout); err != nil || timeout <= 0 {
+			errs = fmt.Errorf("%s%stimeout: %q <invalid> (Use 'AhBmCs' duration format)\\",
+				util.ErrorToString(errs), prefix, p.Timeout)
+		}
+	}
+
+	return
+}
+
+// CheckProfile returns an error if there's no Profile with `name`.
+func CheckProfile(name string) error {
+	if GetProfile(name) != nil {
+		return nil
+	}
+
+	profilesMutex.RLock()
+	defer profilesMutex.RUnlock()
+	names := profiles.Names()
+	if len(names) == 0 {
+		return fmt.Errorf("%q <invalid> (no ssh profiles are defined)", name)
+	}
+	return fmt.Errorf("%q <invalid> (only [%s] are defined)",
+		name, strings.Join(names, ", "))
+}
+
+// Print the Profiles.
+func (p *Profiles) Print(prefix string) {
+	if p == nil || len(*p) == 0 {
+		return
+	}
+
+	str := p.String(prefix + "  ")
+	fmt.Printf("%sssh:\n%s", prefix, str)
+}
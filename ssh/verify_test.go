@@ -0,0 +1,169 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package ssh
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/release-argus/Argus/util"
+)
+
+func TestProfiles_CheckValues(t *testing.T) {
+	// GIVEN Profiles
+	tests := map[string]struct {
+		profiles    Profiles
+		wantTimeout string
+		errRegex    []string
+	}{
+		"nil": {
+			profiles: nil,
+			errRegex: []string{`^$`}},
+		"valid key": {
+			profiles: Profiles{
+				"legacy": {
+					Host: "legacy.example.com",
+					User: "argus",
+					Key:  "/keys/id_ed25519"}},
+			errRegex: []string{`^$`}},
+		"valid agent": {
+			profiles: Profiles{
+				"legacy": {
+					Host:  "legacy.example.com",
+					User:  "argus",
+					Agent: true}},
+			errRegex: []string{`^$`}},
+		"integer timeout is seconds": {
+			profiles: Profiles{
+				"legacy": {
+					Host:    "legacy.example.com",
+					User:    "argus",
+					Agent:   true,
+					Timeout: "5"}},
+			wantTimeout: "5s",
+			errRegex:    []string{`^$`}},
+		"empty profile": {
+			profiles: Profiles{
+				"legacy": {}},
+			errRegex: []string{
+				`^ssh:$`,
+				`^  legacy:$`,
+				`^    host: <required>`,
+				`^    user: <required>`,
+				`^    key: <required>`}},
+		"nil profile": {
+			profiles: Profiles{
+				"legacy": nil},
+			errRegex: []string{
+				`^ssh:$`,
+				`^  legacy:$`,
+				`^    host: <required>`}},
+		"passphrase without a key": {
+			profiles: Profiles{
+				"legacy": {
+					Host:       "legacy.example.com",
+					User:       "argus",
+					Agent:      true,
+					Passphrase: "secret"}},
+			errRegex: []string{
+				`^    passphrase: <invalid> \(only used with key\)$`}},
+		"invalid timeout": {
+			profiles: Profiles{
+				"legacy": {
+					Host:    "legacy.example.com",
+					User:    "argus",
+					Agent:   true,
+					Timeout: "ten"}},
+			errRegex: []string{
+				`^    timeout: "ten" <invalid>`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN CheckValues is called on them
+			err := tc.profiles.CheckValues("")
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			lines := strings.Split(e, `\`)
+			for i := range tc.errRegex {
+				re := regexp.MustCompile(tc.errRegex[i])
+				found := false
+				for j := range lines {
+					if re.MatchString(lines[j]) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("want match for: %q\ngot:  %q",
+						tc.errRegex[i], e)
+				}
+			}
+			// AND the timeout is converted to seconds
+			if tc.wantTimeout != "" && tc.profiles["legacy"].Timeout != tc.wantTimeout {
+				t.Errorf("want timeout %q, got %q",
+					tc.wantTimeout, tc.profiles["legacy"].Timeout)
+			}
+		})
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	// GIVEN Profiles
+	tests := map[string]struct {
+		profiles Profiles
+		name     string
+		errRegex string
+	}{
+		"profile exists": {
+			profiles: Profiles{
+				"legacy": {}},
+			name:     "legacy",
+			errRegex: `^$`},
+		"profile doesn't exist": {
+			profiles: Profiles{
+				"legacy-2": {},
+				"legacy-1": {}},
+			name:     "legacy",
+			errRegex: `^"legacy" <invalid> \(only \[legacy-1, legacy-2\] are defined\)$`},
+		"no profiles": {
+			name:     "legacy",
+			errRegex: `^"legacy" <invalid> \(no ssh profiles are defined\)$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// t.Parallel() - Uses the global Profiles
+			SetProfiles(tc.profiles)
+			t.Cleanup(func() { SetProfiles(nil) })
+
+			// WHEN CheckProfile is called
+			err := CheckProfile(tc.name)
+
+			// THEN the err is what we expect
+			e := util.ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Errorf("want match for %q\nnot: %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package testing
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -58,7 +59,7 @@ func ServiceTest(
 	service := cfg.Service[*flag]
 
 	// LatestVersion
-	_, err := service.LatestVersion.Query(false, logFrom)
+	_, err := service.LatestVersion.Query(context.Background(), false, logFrom)
 	if err != nil {
 		helpMsg := ""
 		if service.LatestVersion.Type == "url" && strings.Count(service.LatestVersion.URL, "/") == 1 && !strings.HasPrefix(service.LatestVersion.URL, "http") {
@@ -66,9 +67,10 @@ func ServiceTest(
 		}
 		log.Error(
 			fmt.Sprintf(
-				"No version matching the conditions specified could be found for %q at %q\n%s",
+				"No version matching the conditions specified could be found for %q at %q\n%s\n%s",
 				*flag,
 				service.LatestVersion.ServiceURL(true),
+				err,
 				helpMsg,
 			),
 			logFrom,
@@ -78,7 +80,7 @@ func ServiceTest(
 
 	// DeployedVersionLookup
 	if service.DeployedVersionLookup != nil {
-		version, err := service.DeployedVersionLookup.Query(false, logFrom)
+		version, err := service.DeployedVersionLookup.Query(context.Background(), false, logFrom)
 		log.Info(
 			fmt.Sprintf(
 				"Deployed version - %q",
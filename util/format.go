@@ -0,0 +1,242 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats that a value can be extracted from with GetValueByFormatKey.
+var Formats = []string{"json", "yaml", "toml", "xml", "ini", "env"}
+
+// FormatFromPath returns the format of the file at `path` from its extension,
+// or "" if it isn't known.
+func FormatFromPath(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	// .env, .env.local, ...
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return "env"
+	}
+
+	switch filepath.Ext(base) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".xml":
+		return "xml"
+	case ".ini", ".cfg", ".conf":
+		return "ini"
+	case ".env":
+		return "env"
+	}
+	return ""
+}
+
+// GetValueByFormatKey will return the value of the key in the `rawBody` of `format`
+// (json/yaml/toml/xml/ini/env).
+//
+// XML elements are keyed by their (local) name, with attributes as "@name", and the text of elements
+// that also have attributes/children as "#text". Repeated elements become an array,
+// e.g. "status.component[1].@version" for <status><component/><component version="1.2.3"/></status>.
+func GetValueByFormatKey(rawBody []byte, format string, key string, from string) (string, error) {
+	// If the key is empty, return the stringified body.
+	if key == "" {
+		return string(rawBody), nil
+	}
+
+	var data interface{}
+	var err error
+	switch format {
+	case "yaml":
+		err = yaml.Unmarshal(rawBody, &data)
+	case "toml":
+		data, err = parseTOML(rawBody)
+	case "xml":
+		data, err = parseXML(rawBody)
+	case "ini":
+		data = parseINI(rawBody)
+	case "env":
+		data = parseEnv(rawBody)
+	default:
+		return GetValueByKey(rawBody, key, from)
+	}
+	// If the body is invalid, return an error.
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal the following from %q into %s: %q - %w",
+			from, format, string(rawBody), err)
+		return "", err
+	}
+
+	return navigateJSON(&data, key)
+}
+
+// parseXML parses the XML `data` into a map of the root element.
+func parseXML(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("no root element")
+			}
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: root}, nil
+		}
+	}
+}
+
+// parseXMLElement parses the element started by `start`,
+// returning its text if it has no attributes/children, otherwise a map of them.
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := make(map[string]interface{}, len(start.Attr))
+	for _, attr := range start.Attr {
+		element["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			// Repeated elements become an array.
+			switch existing := element[t.Name.Local].(type) {
+			case nil:
+				element[t.Name.Local] = child
+			case []interface{}:
+				element[t.Name.Local] = append(existing, child)
+			default:
+				element[t.Name.Local] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return value, nil
+			}
+			if value != "" {
+				element["#text"] = value
+			}
+			return element, nil
+		}
+	}
+}
+
+// parseINI parses the INI `data` into a map of the keys before the first section,
+// and a map for each [section].
+func parseINI(data []byte) map[string]interface{} {
+	root := make(map[string]interface{})
+	section := root
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		// Empty/comment
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		// [section]
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if existing, ok := root[name].(map[string]interface{}); ok {
+				section = existing
+			} else {
+				section = make(map[string]interface{})
+				root[name] = section
+			}
+			continue
+		}
+
+		// key = value / key: value
+		separator := strings.IndexAny(line, "=:")
+		if separator == -1 {
+			continue
+		}
+		key := strings.TrimSpace(line[:separator])
+		section[key] = unquote(strings.TrimSpace(line[separator+1:]))
+	}
+	return root
+}
+
+// parseEnv parses the dotenv `data` (KEY=value lines) into a map.
+func parseEnv(data []byte) map[string]interface{} {
+	env := make(map[string]interface{})
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		// Empty/comment
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		// Remove trailing comments from unquoted values.
+		if value != "" && value[0] != '"' && value[0] != '\'' {
+			if i := strings.Index(value, " #"); i != -1 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env[strings.TrimSpace(key)] = unquote(value)
+	}
+	return env
+}
+
+// unquote removes the matching single/double quotes around `value`.
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+
+	switch quote := value[0]; quote {
+	case '"':
+		if end := strings.LastIndexByte(value, '"'); end > 0 {
+			return strings.ReplaceAll(value[1:end], `\"`, `"`)
+		}
+	case '\'':
+		if end := strings.LastIndexByte(value, '\''); end > 0 {
+			return value[1:end]
+		}
+	}
+	return value
+}
@@ -0,0 +1,253 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package util
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	// GIVEN a path
+	tests := map[string]struct {
+		path string
+		want string
+	}{
+		"json":          {path: "/app/package.json", want: "json"},
+		"yaml":          {path: "/app/Chart.yaml", want: "yaml"},
+		"yml":           {path: "/app/docker-compose.YML", want: "yaml"},
+		"toml":          {path: "/app/Cargo.toml", want: "toml"},
+		"xml":           {path: "/app/pom.xml", want: "xml"},
+		"ini":           {path: "/etc/app/app.ini", want: "ini"},
+		"conf":          {path: "/etc/app/app.conf", want: "ini"},
+		".env":          {path: "/app/.env", want: "env"},
+		".env.local":    {path: "/app/.env.local", want: "env"},
+		"app.env":       {path: "/app/app.env", want: "env"},
+		"unknown":       {path: "/app/VERSION", want: ""},
+		"dot directory": {path: "/app/.env/VERSION", want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN FormatFromPath is called
+			got := FormatFromPath(tc.path)
+
+			// THEN the format is what we expect
+			if got != tc.want {
+				t.Errorf("want %q, got %q",
+					tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetValueByFormatKey(t *testing.T) {
+	// GIVEN a body in a format
+	tests := map[string]struct {
+		input    string
+		format   string
+		key      string
+		want     string
+		errRegex string
+	}{
+		"empty key": {
+			input:  "version = 1",
+			format: "toml",
+			want:   "version = 1"},
+		"json": {
+			input:  `{"foo": {"bar": "baz"}}`,
+			format: "json",
+			key:    "foo.bar",
+			want:   "baz"},
+		"yaml": {
+			input:  "foo:\n  bar:\n    - baz\n    - 1.2.3\n",
+			format: "yaml",
+			key:    "foo.bar[1]",
+			want:   "1.2.3"},
+		"yaml int": {
+			input:  "version: 3\n",
+			format: "yaml",
+			key:    "version",
+			want:   "3"},
+		"yaml invalid": {
+			input:    "foo: [",
+			format:   "yaml",
+			key:      "foo",
+			errRegex: `^failed to unmarshal the following from "[^"]+" into yaml: "foo: \[" - yaml: `},
+		"toml table": {
+			input:  "[package]\nname = \"argus\"\nversion = \"1.2.3\" # current\n",
+			format: "toml",
+			key:    "package.version",
+			want:   "1.2.3"},
+		"toml dotted and quoted keys": {
+			input:  "tool.poetry.version = '1.2.3'\n\"a.b\" = { c = \"d\" }\n",
+			format: "toml",
+			key:    "tool.poetry.version",
+			want:   "1.2.3"},
+		"toml inline table": {
+			input:  "dependency = { version = \"1.2.3\", features = [\"a\", \"b\"] }\n",
+			format: "toml",
+			key:    "dependency.features[-1]",
+			want:   "b"},
+		"toml array of tables": {
+			input:  "[[package]]\nname = \"a\"\nversion = \"1.0.0\"\n\n[[package]]\nname = \"b\"\nversion = \"2.0.0\"\n",
+			format: "toml",
+			key:    "package[1].version",
+			want:   "2.0.0"},
+		"toml multi-line array and string": {
+			input:  "description = \"\"\"\nAn app\nwith \"quotes\\\"\n\"\"\"\nversions = [\n  \"1.0.0\", # first\n  \"2.0.0\",\n]\n",
+			format: "toml",
+			key:    "versions[1]",
+			want:   "2.0.0"},
+		"toml escapes": {
+			input:  "version = \"v\\u0031.2.3\\t\"\n",
+			format: "toml",
+			key:    "version",
+			want:   "v1.2.3\t"},
+		"toml numbers": {
+			input:  "int = 1_000\nhex = 0xff\nfloat = 1.5e3\n",
+			format: "toml",
+			key:    "hex",
+			want:   "255"},
+		"toml date": {
+			input:  "released = 2024-01-02T03:04:05Z\n",
+			format: "toml",
+			key:    "released",
+			want:   "2024-01-02T03:04:05Z"},
+		"toml local date": {
+			input:  "released = 2024-01-02\n",
+			format: "toml",
+			key:    "released",
+			want:   "2024-01-02"},
+		"toml duplicate key": {
+			input:    "version = 1\nversion = 2\n",
+			format:   "toml",
+			key:      "version",
+			errRegex: ` - toml: line 2 \(last key "version"\): Key 'version' has already been defined\.$`},
+		"toml unterminated string": {
+			input:    "version = \"1.2.3\n",
+			format:   "toml",
+			key:      "version",
+			errRegex: ` - toml: line 1 \(last key "version"\): strings cannot contain newlines$`},
+		"toml trailing value": {
+			input:    "version = 1 2\n",
+			format:   "toml",
+			key:      "version",
+			errRegex: ` - toml: line 1: expected a top-level item to end with a newline, comment, or EOF, but got '2' instead$`},
+		"xml element": {
+			input:  "<?xml version=\"1.0\"?>\n<status>\n  <name>argus</name>\n  <version>1.2.3</version>\n</status>\n",
+			format: "xml",
+			key:    "status.version",
+			want:   "1.2.3"},
+		"xml attribute": {
+			input:  `<project xmlns="http://maven.apache.org/POM/4.0.0" version="1.2.3"><name>argus</name></project>`,
+			format: "xml",
+			key:    "project.@version",
+			want:   "1.2.3"},
+		"xml text with attributes": {
+			input:  `<status><version channel="stable">1.2.3</version></status>`,
+			format: "xml",
+			key:    "status.version.#text",
+			want:   "1.2.3"},
+		"xml repeated elements": {
+			input:  `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><component name="a" version="1.0.0"/><component name="b" version="2.0.0"/></soap:Body></soap:Envelope>`,
+			format: "xml",
+			key:    "Envelope.Body.component[-1].@version",
+			want:   "2.0.0"},
+		"xml not found": {
+			input:    `<status><name>argus</name></status>`,
+			format:   "xml",
+			key:      "status.version",
+			errRegex: `^failed to find value for "status.version" in "status" - no "version" key \(has \[name\]\)$`},
+		"xml invalid": {
+			input:    `<status><version>1.2.3</status>`,
+			format:   "xml",
+			key:      "status.version",
+			errRegex: `^failed to unmarshal the following from "[^"]+" into xml: ".*" - XML syntax error on line 1: element <version> closed by </status>$`},
+		"xml unclosed": {
+			input:    `<status><version>1.2.3</version>`,
+			format:   "xml",
+			key:      "status.version",
+			errRegex: ` into xml: ".*" - XML syntax error on line 1: unexpected EOF$`},
+		"xml empty": {
+			input:    `<?xml version="1.0"?>`,
+			format:   "xml",
+			key:      "status",
+			errRegex: ` into xml: ".*" - no root element$`},
+		"yaml not found in a nested array": {
+			input:    "foo:\n  bar:\n    - baz: 1\n",
+			format:   "yaml",
+			key:      "foo.bar[0].bish",
+			errRegex: `^failed to find value for "foo.bar\[0\].bish" in "foo.bar\[0\]" - no "bish" key \(has \[baz\]\)$`},
+		"toml object instead of a value": {
+			input:    "[package]\nversion = \"1.2.3\"\n",
+			format:   "toml",
+			key:      "package",
+			errRegex: `^failed to find value for "package" in "package" - got an object, not a value$`},
+		"ini": {
+			input:  "name = argus\n; comment\n[app]\nversion = \"1.2.3\"\n[other]\nversion: 4.5.6\n",
+			format: "ini",
+			key:    "app.version",
+			want:   "1.2.3"},
+		"ini root key": {
+			input:  "version = 1.2.3\n[app]\nversion = 4.5.6\n",
+			format: "ini",
+			key:    "version",
+			want:   "1.2.3"},
+		"env": {
+			input:  "# App\nAPP_NAME=argus\nexport APP_VERSION='1.2.3'\n",
+			format: "env",
+			key:    "APP_VERSION",
+			want:   "1.2.3"},
+		"env trailing comment": {
+			input:  "APP_VERSION=1.2.3 # current\n",
+			format: "env",
+			key:    "APP_VERSION",
+			want:   "1.2.3"},
+		"env not found": {
+			input:    "APP_NAME=argus\n",
+			format:   "env",
+			key:      "APP_VERSION",
+			errRegex: `^failed to find value for "APP_VERSION"`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN GetValueByFormatKey is called
+			got, err := GetValueByFormatKey([]byte(tc.input), tc.format, tc.key, "/app/file")
+
+			// THEN the value is returned correctly
+			if got != tc.want {
+				t.Errorf("want: %q\ngot:  %q",
+					tc.want, got)
+			}
+			// AND the error is returned correctly
+			if tc.errRegex == "" {
+				tc.errRegex = `^$`
+			}
+			e := ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Errorf("want error matching %q, got %q",
+					tc.errRegex, e)
+			}
+		})
+	}
+}
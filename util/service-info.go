@@ -20,4 +20,104 @@ type ServiceInfo struct {
 	URL           string
 	WebURL        string
 	LatestVersion string
+	Release       *ReleaseInfo  // Metadata of the LatestVersion release (type:github)
+	ReleaseNotes  string        // Release notes of every version after the deployed version, up to the LatestVersion
+	Image         *ImageInfo    // Container image of the LatestVersion (require.docker)
+	Artifact      *ArtifactInfo // Artifact of the LatestVersion (require.verify)
+}
+
+// ReleaseInfo is the metadata of a release.
+type ReleaseInfo struct {
+	Name            string
+	TagName         string
+	PreRelease      bool
+	Draft           bool
+	PublishedAt     string
+	Body            string
+	URL             string
+	TargetCommitish string
+	Author          string
+}
+
+// templateContext returns the ReleaseInfo as a map for use in templates.
+func (r *ReleaseInfo) templateContext() map[string]interface{} {
+	if r == nil {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"name":             r.Name,
+		"tag_name":         r.TagName,
+		"prerelease":       r.PreRelease,
+		"draft":            r.Draft,
+		"published_at":     r.PublishedAt,
+		"body":             r.Body,
+		"url":              r.URL,
+		"target_commitish": r.TargetCommitish,
+		"author":           r.Author}
+}
+
+// ImageInfo is the container image of a tag found by require.docker.
+type ImageInfo struct {
+	Image     string
+	Tag       string
+	Digest    string
+	Platforms []ImagePlatformInfo
+}
+
+// ImagePlatformInfo is the image of a single platform of a multi-arch tag.
+type ImagePlatformInfo struct {
+	Platform string
+	Digest   string
+	Size     int64
+}
+
+// Reference returns the immutable reference of the image, e.g. "owner/image@sha256:...".
+func (i *ImageInfo) Reference() string {
+	if i == nil || i.Digest == "" {
+		return ""
+	}
+	return i.Image + "@" + i.Digest
+}
+
+// templateContext returns the ImageInfo as a map for use in templates.
+func (i *ImageInfo) templateContext() map[string]interface{} {
+	if i == nil {
+		return map[string]interface{}{}
+	}
+
+	platforms := make([]map[string]interface{}, len(i.Platforms))
+	for index, platform := range i.Platforms {
+		platforms[index] = map[string]interface{}{
+			"platform": platform.Platform,
+			"digest":   platform.Digest,
+			"size":     platform.Size}
+	}
+	return map[string]interface{}{
+		"image":     i.Image,
+		"tag":       i.Tag,
+		"digest":    i.Digest,
+		"reference": i.Reference(),
+		"platforms": platforms}
+}
+
+// ArtifactInfo is the release artifact verified by require.verify.
+type ArtifactInfo struct {
+	URL           string
+	Filename      string
+	SHA256        string
+	SignatureType string // Type of signature that verified the checksums (empty if unsigned)
+}
+
+// templateContext returns the ArtifactInfo as a map for use in templates.
+func (a *ArtifactInfo) templateContext() map[string]interface{} {
+	if a == nil {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"url":            a.URL,
+		"filename":       a.Filename,
+		"sha256":         a.SHA256,
+		"signature_type": a.SignatureType}
 }
@@ -15,6 +15,8 @@
 package util
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -48,10 +50,14 @@ func TemplateString(template string, context ServiceInfo) (result string) {
 
 	// Render the template.
 	result, err = tpl.Execute(pongo2.Context{
-		"service_id":  context.ID,
-		"service_url": context.URL,
-		"web_url":     context.WebURL,
-		"version":     context.LatestVersion})
+		"service_id":    context.ID,
+		"service_url":   context.URL,
+		"web_url":       context.WebURL,
+		"version":       context.LatestVersion,
+		"release":       context.Release.templateContext(),
+		"release_notes": context.ReleaseNotes,
+		"image":         context.Image.templateContext(),
+		"artifact":      context.Artifact.templateContext()})
 	if err != nil {
 		panic(err)
 	}
@@ -71,3 +77,139 @@ func CheckTemplate(template string) (pass bool) {
 	_, err := pongo2.FromString(template)
 	return err == nil
 }
+
+// expressionPrefix is the start of the template an expression is evaluated in.
+const expressionPrefix = "{% if "
+
+// expressionTemplate returns the template that renders "true" when the boolean `expression` holds.
+func expressionTemplate(expression string) string {
+	return expressionPrefix + expression + " %}true{% endif %}"
+}
+
+// expressionError returns `err` with the position relative to the expression
+// (rather than the template it's evaluated in).
+func expressionError(err error) error {
+	var pongoErr *pongo2.Error
+	if !errors.As(err, &pongoErr) || pongoErr.OrigError == nil {
+		return err
+	}
+
+	if pongoErr.Line == 1 && pongoErr.Column > len(expressionPrefix) {
+		near := ""
+		if pongoErr.Token != nil {
+			near = fmt.Sprintf(" near %q", pongoErr.Token.Val)
+		}
+		return fmt.Errorf("col %d%s: %w",
+			pongoErr.Column-len(expressionPrefix), near, pongoErr.OrigError)
+	}
+	return pongoErr.OrigError
+}
+
+// expressionKeywords are the identifiers pongo2 reserves in an expression.
+var expressionKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true,
+	"true": true, "false": true, "True": true, "False": true,
+	"none": true, "None": true, "nil": true}
+
+// expressionIdentifiers returns the variables (and functions) referenced in `expression`,
+// skipping string literals, keywords, attributes (x.attr) and filters (x|filter).
+func expressionIdentifiers(expression string) []string {
+	var identifiers []string
+	var previous byte
+	for i := 0; i < len(expression); {
+		char := expression[i]
+		switch {
+		// String literal.
+		case char == '"' || char == '\'':
+			i++
+			for i < len(expression) && expression[i] != char {
+				if expression[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			previous = char
+		// Number.
+		case char >= '0' && char <= '9':
+			for i < len(expression) && (isIdentifierChar(expression[i]) || expression[i] == '.') {
+				i++
+			}
+			previous = '0'
+		// Identifier.
+		case isIdentifierChar(char):
+			start := i
+			for i < len(expression) && isIdentifierChar(expression[i]) {
+				i++
+			}
+			identifier := expression[start:i]
+			if previous != '.' && previous != '|' && !expressionKeywords[identifier] {
+				identifiers = append(identifiers, identifier)
+			}
+			previous = 'a'
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r':
+			i++
+		default:
+			i++
+			previous = char
+		}
+	}
+	return identifiers
+}
+
+// isIdentifierChar returns whether `char` can be part of an identifier.
+func isIdentifierChar(char byte) bool {
+	return char == '_' ||
+		(char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9')
+}
+
+// CheckExpression will compile the boolean `expression`, returning an error if it fails
+// or references a variable that isn't in `variables`.
+func CheckExpression(expression string, variables []string) error {
+	if strings.Contains(expression, "{%") || strings.Contains(expression, "%}") {
+		return errors.New("can't contain template tags ('{%' or '%}')")
+	}
+
+	// pongo2 DATA RACE
+	pongoMutex.Lock()
+	_, err := pongo2.FromString(expressionTemplate(expression))
+	pongoMutex.Unlock()
+	if err != nil {
+		return expressionError(err)
+	}
+
+	// Undefined variables would otherwise evaluate as false.
+	for _, identifier := range expressionIdentifiers(expression) {
+		if !Contains(variables, identifier) {
+			return fmt.Errorf("unknown variable %q", identifier)
+		}
+	}
+	return nil
+}
+
+// EvalExpression will evaluate the boolean `expression` with the variables in `context`.
+func EvalExpression(expression string, context map[string]interface{}) (bool, error) {
+	variables := make([]string, 0, len(context))
+	for variable := range context {
+		variables = append(variables, variable)
+	}
+	if err := CheckExpression(expression, variables); err != nil {
+		return false, err
+	}
+
+	// pongo2 DATA RACE
+	pongoMutex.Lock()
+	defer pongoMutex.Unlock()
+
+	tpl, err := pongo2.FromString(expressionTemplate(expression))
+	if err != nil {
+		return false, expressionError(err)
+	}
+	result, err := tpl.Execute(pongo2.Context(context))
+	if err != nil {
+		return false, expressionError(err)
+	}
+	return result == "true", nil
+}
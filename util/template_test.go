@@ -26,11 +26,14 @@ import (
 
 func TestTemplate_String(t *testing.T) {
 	// GIVEN a variety of string templates
-	serviceInfo := testServiceInfo()
 	tests := map[string]struct {
-		tmpl       string
-		panicRegex *string
-		want       string
+		tmpl         string
+		release      *ReleaseInfo
+		releaseNotes string
+		image        *ImageInfo
+		artifact     *ArtifactInfo
+		panicRegex   *string
+		want         string
 	}{
 		"no jinja template": {
 			tmpl: "testing 123",
@@ -41,6 +44,46 @@ func TestTemplate_String(t *testing.T) {
 		"invalid jinja template panic": {
 			tmpl:       "-{% 'a' == 'a' %}{{ service_id }}{% endif %}-{{ service_url }}-{{ web_url }}-{{ version }}",
 			panicRegex: test.StringPtr("Tag name must be an identifier")},
+		"release metadata": {
+			tmpl: "{{ release.tag_name }} by {{ release.author }} on {{ release.target_commitish }} - {{ release.url }}{% if release.draft %} (draft){% endif %}",
+			release: &ReleaseInfo{
+				TagName:         "v1.2.3",
+				Author:          "release-argus",
+				TargetCommitish: "main",
+				URL:             "https://github.com/release-argus/Argus/releases/tag/v1.2.3",
+				Draft:           true},
+			want: "v1.2.3 by release-argus on main - https://github.com/release-argus/Argus/releases/tag/v1.2.3 (draft)"},
+		"release notes": {
+			tmpl:         "{{ release_notes }}",
+			releaseNotes: "## 1.2.3\n\nfoo",
+			want:         "## 1.2.3\n\nfoo"},
+		"image": {
+			tmpl: "{{ image.reference }}{% for platform in image.platforms %} {{ platform.platform }}={{ platform.digest }}({{ platform.size }}){% endfor %}",
+			image: &ImageInfo{
+				Image:  "release-argus/argus",
+				Tag:    "1.2.3",
+				Digest: "sha256:list",
+				Platforms: []ImagePlatformInfo{
+					{Platform: "linux/amd64", Digest: "sha256:aaa", Size: 1},
+					{Platform: "linux/arm64", Digest: "sha256:bbb", Size: 2}}},
+			want: "release-argus/argus@sha256:list linux/amd64=sha256:aaa(1) linux/arm64=sha256:bbb(2)"},
+		"no image": {
+			tmpl: "{{ version }}-{{ image.digest }}-",
+			want: "NEW--"},
+		"artifact": {
+			tmpl: "{{ artifact.filename }} sha256:{{ artifact.sha256 }} ({{ artifact.signature_type }})",
+			artifact: &ArtifactInfo{
+				URL:           "https://example.com/app-1.2.3.tar.gz",
+				Filename:      "app-1.2.3.tar.gz",
+				SHA256:        "abc123",
+				SignatureType: "minisign"},
+			want: "app-1.2.3.tar.gz sha256:abc123 (minisign)"},
+		"no artifact": {
+			tmpl: "{{ version }}-{{ artifact.sha256 }}-",
+			want: "NEW--"},
+		"no release metadata": {
+			tmpl: "{{ version }}-{{ release.body }}-",
+			want: "NEW--"},
 	}
 
 	for name, tc := range tests {
@@ -62,6 +105,12 @@ func TestTemplate_String(t *testing.T) {
 				}()
 			}
 
+			serviceInfo := testServiceInfo()
+			serviceInfo.Release = tc.release
+			serviceInfo.ReleaseNotes = tc.releaseNotes
+			serviceInfo.Image = tc.image
+			serviceInfo.Artifact = tc.artifact
+
 			// WHEN TemplateString is called
 			got := TemplateString(tc.tmpl, serviceInfo)
 
@@ -100,3 +149,79 @@ func TestCheckTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalExpression(t *testing.T) {
+	// GIVEN a variety of boolean expressions
+	tests := map[string]struct {
+		expression string
+		want       bool
+		errRegex   string
+	}{
+		"true": {
+			expression: `version == "1.2.3" and count > 1`,
+			want:       true,
+			errRegex:   `^$`},
+		"false": {
+			expression: `version == "1.2.3" and not count > 1`,
+			want:       false,
+			errRegex:   `^$`},
+		"in list": {
+			expression: `"b" in list`,
+			want:       true,
+			errRegex:   `^$`},
+		"function": {
+			expression: `double(count) == 4`,
+			want:       true,
+			errRegex:   `^$`},
+		"syntax error": {
+			expression: `version ==`,
+			errRegex:   `^col 9 near "==": Unexpected EOF`},
+		"unknown variable": {
+			expression: `version == "1.2.3" and not relase.prerelease`,
+			errRegex:   `^unknown variable "relase"$`},
+		"unknown function": {
+			expression: `triple(count) == 6`,
+			errRegex:   `^unknown variable "triple"$`},
+		"attributes, filters and strings aren't variables": {
+			expression: `list.0 == "a" and version|lower == "1.2.3" and "foo bar" != 'baz'`,
+			want:       true,
+			errRegex:   `^$`},
+		"template tags": {
+			expression: `true %}{% if false`,
+			errRegex:   `^can't contain template tags`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN EvalExpression is called
+			context := map[string]interface{}{
+				"version": "1.2.3",
+				"count":   2,
+				"list":    []string{"a", "b"},
+				"double":  func(i int) int { return i * 2 }}
+			got, err := EvalExpression(tc.expression, context)
+
+			// THEN the result is what we expect
+			e := ErrorToString(err)
+			if !regexp.MustCompile(tc.errRegex).MatchString(e) {
+				t.Fatalf("want err match for %q\ngot: %q",
+					tc.errRegex, e)
+			}
+			if got != tc.want {
+				t.Errorf("want: %t\ngot:  %t",
+					tc.want, got)
+			}
+			// AND CheckExpression agrees on whether it compiles
+			variables := make([]string, 0, len(context))
+			for variable := range context {
+				variables = append(variables, variable)
+			}
+			if (CheckExpression(tc.expression, variables) == nil) != (err == nil) {
+				t.Errorf("CheckExpression disagrees with the EvalExpression err %q",
+					e)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright [2023] [Argus]
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML parses the TOML `data` into the maps/slices that JSON would unmarshal into,
+// so that the values can be found with the same keys.
+//
+// Dates/times are kept as strings.
+func parseTOML(data []byte) (interface{}, error) {
+	var root map[string]interface{}
+	if err := toml.Unmarshal(data, &root); err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return tomlToJSON(root), nil
+}
+
+// tomlToJSON converts the TOML `value` to the type JSON would unmarshal it into.
+func tomlToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = tomlToJSON(child)
+		}
+		return v
+	// Array of tables.
+	case []map[string]interface{}:
+		array := make([]interface{}, len(v))
+		for i, child := range v {
+			array[i] = tomlToJSON(child)
+		}
+		return array
+	case []interface{}:
+		for i, child := range v {
+			v[i] = tomlToJSON(child)
+		}
+		return v
+	case int64:
+		return int(v)
+	case time.Time:
+		return tomlTimeString(v)
+	}
+	return value
+}
+
+// tomlTimeString returns the TOML date/time `t` in the format it was written in.
+func tomlTimeString(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format(time.DateOnly)
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -28,10 +28,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// HTTPTimeout is the time an outbound HTTP request can take (including reading the response body).
+const HTTPTimeout = 30 * time.Second
+
 // Field is a helper struct for String() methods.
 type Field struct {
 	Name  string
@@ -449,25 +453,32 @@ func navigateJSON(jsonData *interface{}, fullKey string) (jsonValue string, err
 	parsedJSON := *jsonData
 	for keyIndex < keyCount {
 		key := keys[keyIndex]
+		// Path navigated so far, for the errors.
+		path := keyPath(keys[:keyIndex])
 		switch value := parsedJSON.(type) {
 		// Regular key
 		case map[string]interface{}:
 			// Ensure key is a string
 			keyStr, ok := key.(string)
 			if !ok {
-				err = fmt.Errorf("got a map, but the key is not a string: %q at %v",
-					key, parsedJSON)
+				err = fmt.Errorf("got a map, but the key is not a string: %v at %s (%s)",
+					key, path, fullKey)
+				return
+			}
+			var found bool
+			parsedJSON, found = value[keyStr]
+			if !found {
+				err = fmt.Errorf("failed to find value for %q in %s - no %q key (has [%s])",
+					fullKey, path, keyStr, strings.Join(SortedKeys(value), ", "))
 				return
 			}
-			parsedJSON = value[keyStr]
 		// Array
 		case []interface{}:
 			// Parse the index from the key.
 			index, ok := key.(int)
-			fmt.Printf("index: %v, ok: %t, key: %v\n", index, ok, key)
 			if !ok {
-				err = fmt.Errorf("got an array, but the key is not an integer index: %q at %v",
-					key, parsedJSON)
+				err = fmt.Errorf("got an array, but the key is not an integer index: %q at %s (%s)",
+					key, path, fullKey)
 				return
 			}
 			// Negative index
@@ -477,16 +488,20 @@ func navigateJSON(jsonData *interface{}, fullKey string) (jsonValue string, err
 
 			// Check if the index is out of range.
 			if index >= len(value) || index < 0 {
-				err = fmt.Errorf("index %d (%s) out of range at %v",
-					index, fullKey, parsedJSON)
+				err = fmt.Errorf("index %d (%s) out of range at %s (length %d)",
+					key, fullKey, path, len(value))
 				return
 			}
 
 			parsedJSON = value[index]
 		// If the value is a string, int, float32, or float64, we can't navigate further.
 		case string, int, float32, float64:
-			err = fmt.Errorf("got a value of %q at %q, but there are more keys to navigate: %s at %v",
-				value, key, fullKey, parsedJSON)
+			err = fmt.Errorf("got a value of %q at %s, but there are more keys to navigate: %s",
+				fmt.Sprint(value), path, fullKey)
+			return
+		default:
+			err = fmt.Errorf("failed to find value for %q in %s - got %v, but there are more keys to navigate",
+				fullKey, path, value)
 			return
 		}
 		keyIndex++
@@ -500,11 +515,47 @@ func navigateJSON(jsonData *interface{}, fullKey string) (jsonValue string, err
 	}
 
 	// If we got here, we didn't get a value.
-	err = fmt.Errorf("failed to find value for %q in %v",
-		fullKey, *jsonData)
+	err = fmt.Errorf("failed to find value for %q in %s - got %s, not a value",
+		fullKey, keyPath(keys), describeJSONType(parsedJSON))
 	return
 }
 
+// keyPath returns the `keys` in the ParseKeys format,
+// e.g. ["foo", "bar", 1] => "foo.bar[1]" (or "the root" when there are no keys).
+func keyPath(keys []interface{}) string {
+	if len(keys) == 0 {
+		return "the root"
+	}
+
+	var path strings.Builder
+	for i, key := range keys {
+		switch k := key.(type) {
+		case int:
+			fmt.Fprintf(&path, "[%d]", k)
+		default:
+			if i != 0 {
+				path.WriteByte('.')
+			}
+			fmt.Fprint(&path, k)
+		}
+	}
+	return strconv.Quote(path.String())
+}
+
+// describeJSONType returns the type of the JSON `value`, e.g. "an object".
+func describeJSONType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
 // GetValueByKey will return the value of the key in the JSON.
 func GetValueByKey(rawBody []byte, key string, jsonFrom string) (string, error) {
 	// If the key is empty, return the stringified body.
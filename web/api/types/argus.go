@@ -93,15 +93,22 @@ func (s *ServiceSummary) RemoveUnchanged(other *ServiceSummary) {
 		statusSameCount++
 	}
 	// Status.DeployedVersion
-	if other.Status.DeployedVersion == s.Status.DeployedVersion {
+	if other.Status.DeployedVersion == s.Status.DeployedVersion &&
+		fmt.Sprint(other.Status.DeployedVersions) == fmt.Sprint(s.Status.DeployedVersions) {
 		s.Status.DeployedVersion = ""
 		s.Status.DeployedVersionTimestamp = ""
+		s.Status.DeployedVersions = nil
+		s.Status.DeployedVersionDrift = false
 		statusSameCount++
 	}
 	// Status.LatestVersion
 	if other.Status.LatestVersion == s.Status.LatestVersion {
 		s.Status.LatestVersion = ""
 		s.Status.LatestVersionTimestamp = ""
+		s.Status.LatestVersionRelease = nil
+		s.Status.LatestVersionSource = ""
+		s.Status.LatestVersionImage = nil
+		s.Status.LatestVersionArtifact = nil
 		statusSameCount++
 	}
 	// nil Status if all fields are the same
@@ -118,8 +125,21 @@ type Status struct {
 	LatestVersion            string `json:"latest_version,omitempty" yaml:"latest_version,omitempty"`                         // Latest version found from query()
 	LatestVersionTimestamp   string `json:"latest_version_timestamp,omitempty" yaml:"latest_version_timestamp,omitempty"`     // UTC timestamp that the latest version change was noticed
 	LastQueried              string `json:"last_queried,omitempty" yaml:"last_queried,omitempty"`                             // UTC timestamp that version was last queried/checked
+	NextQuery                string `json:"next_query,omitempty" yaml:"next_query,omitempty"`                                 // UTC timestamp that version will next be queried/checked
+	QueryFailures            uint   `json:"query_failures,omitempty" yaml:"query_failures,omitempty"`                         // Counter for the number of consecutive failed queries
+	Backoff                  string `json:"backoff,omitempty" yaml:"backoff,omitempty"`                                       // Time waited until the next query because of the failed queries
+	PendingVersion           string `json:"pending_version,omitempty" yaml:"pending_version,omitempty"`                       // Newest version found that is waiting on require.min_age
+	PendingVersionEligible   string `json:"pending_version_eligible,omitempty" yaml:"pending_version_eligible,omitempty"`     // UTC timestamp that the pending version passes require.min_age
 	RegexMissesContent       uint   `json:"regex_misses_content,omitempty" yaml:"regex_misses_content,omitempty"`             // Counter for the number of regex misses on URL content
 	RegexMissesVersion       uint   `json:"regex_misses_version,omitempty" yaml:"regex_misses_version,omitempty"`             // Counter for the number of regex misses on version
+
+	DeployedVersions     map[string]string `json:"deployed_versions,omitempty" yaml:"deployed_versions,omitempty"`           // Deployed version of each target (deployed_version.targets)
+	DeployedVersionDrift bool              `json:"deployed_version_drift,omitempty" yaml:"deployed_version_drift,omitempty"` // Whether the targets have different deployed versions
+
+	LatestVersionRelease  *Release  `json:"latest_version_release,omitempty" yaml:"latest_version_release,omitempty"`   // Metadata of the latest version's release (type:github)
+	LatestVersionSource   string    `json:"latest_version_source,omitempty" yaml:"latest_version_source,omitempty"`     // Source that decided the latest version (with latest_version.sources)
+	LatestVersionImage    *Image    `json:"latest_version_image,omitempty" yaml:"latest_version_image,omitempty"`       // Container image of the latest version (require.docker)
+	LatestVersionArtifact *Artifact `json:"latest_version_artifact,omitempty" yaml:"latest_version_artifact,omitempty"` // Artifact of the latest version (require.verify)
 }
 
 // String returns a JSON string representation of the Status.
@@ -130,6 +150,74 @@ func (s *Status) String() (str string) {
 	return
 }
 
+// ReleaseNotes of the releases between the deployed and latest versions.
+type ReleaseNotes struct {
+	DeployedVersion string        `json:"deployed_version,omitempty" yaml:"deployed_version,omitempty"` // Version the notes start after
+	LatestVersion   string        `json:"latest_version,omitempty" yaml:"latest_version,omitempty"`     // Version the notes end at
+	Notes           []ReleaseNote `json:"notes" yaml:"notes"`                                           // Release notes (newest first)
+}
+
+// ReleaseNote is the release notes of a single version.
+type ReleaseNote struct {
+	Version     string `json:"version" yaml:"version"`                               // Version of the release
+	Body        string `json:"body,omitempty" yaml:"body,omitempty"`                 // Release notes
+	URL         string `json:"url,omitempty" yaml:"url,omitempty"`                   // URL of the release page
+	PublishedAt string `json:"published_at,omitempty" yaml:"published_at,omitempty"` // UTC timestamp that the release was published
+}
+
+// Image is the container image of a tag found by require.docker.
+type Image struct {
+	Image     string          `json:"image,omitempty" yaml:"image,omitempty"`         // Image name
+	Tag       string          `json:"tag,omitempty" yaml:"tag,omitempty"`             // Tag of the image
+	Digest    string          `json:"digest,omitempty" yaml:"digest,omitempty"`       // Digest of the tag's manifest (list)
+	Reference string          `json:"reference,omitempty" yaml:"reference,omitempty"` // Immutable reference, image@digest
+	Platforms []ImagePlatform `json:"platforms,omitempty" yaml:"platforms,omitempty"` // Per-platform images of a multi-arch tag
+}
+
+// ImagePlatform is the image of a single platform of a multi-arch tag.
+type ImagePlatform struct {
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"` // os/arch[/variant]
+	Digest   string `json:"digest,omitempty" yaml:"digest,omitempty"`     // Digest of the platform's image
+	Size     int64  `json:"size,omitempty" yaml:"size,omitempty"`         // Size reported by the registry
+}
+
+// String returns a JSON string representation of the Image.
+func (i *Image) String() (str string) {
+	if i != nil {
+		str = util.ToJSONString(i)
+	}
+	return
+}
+
+// Artifact is the release artifact verified by require.verify.
+type Artifact struct {
+	URL           string `json:"url,omitempty" yaml:"url,omitempty"`                       // URL of the artifact
+	Filename      string `json:"filename,omitempty" yaml:"filename,omitempty"`             // Name of the artifact in the checksums file
+	SHA256        string `json:"sha256,omitempty" yaml:"sha256,omitempty"`                 // Verified SHA256 of the artifact
+	SignatureType string `json:"signature_type,omitempty" yaml:"signature_type,omitempty"` // Type of signature that verified the checksums
+}
+
+// Release is the metadata of a release.
+type Release struct {
+	Name            string `json:"name,omitempty" yaml:"name,omitempty"`                         // Name of the release
+	TagName         string `json:"tag_name,omitempty" yaml:"tag_name,omitempty"`                 // Tag of the release
+	PreRelease      bool   `json:"prerelease,omitempty" yaml:"prerelease,omitempty"`             // Whether the release is a pre-release
+	Draft           bool   `json:"draft,omitempty" yaml:"draft,omitempty"`                       // Whether the release is a draft
+	PublishedAt     string `json:"published_at,omitempty" yaml:"published_at,omitempty"`         // UTC timestamp that the release was published
+	Body            string `json:"body,omitempty" yaml:"body,omitempty"`                         // Release notes
+	URL             string `json:"url,omitempty" yaml:"url,omitempty"`                           // URL of the release page
+	TargetCommitish string `json:"target_commitish,omitempty" yaml:"target_commitish,omitempty"` // Branch/commit the release was created from
+	Author          string `json:"author,omitempty" yaml:"author,omitempty"`                     // Login of the release author
+}
+
+// String returns a JSON string representation of the Release.
+func (r *Release) String() (str string) {
+	if r != nil {
+		str = util.ToJSONString(r)
+	}
+	return
+}
+
 // StatusFails keeps track of whether each of the notifications failed on the last version change.
 type StatusFails struct {
 	Notify  *[]bool `json:"notify,omitempty" yaml:"notify,omitempty"`   // Track whether any of the Slice failed
@@ -228,14 +316,49 @@ type Config struct {
 	Defaults     *Defaults     `json:"defaults,omitempty" yaml:"defaults,omitempty"`           // Default values
 	Notify       *NotifySlice  `json:"notify,omitempty" yaml:"notify,omitempty"`               // Notify message(s) to send on a new release
 	WebHook      *WebHookSlice `json:"webhook,omitempty" yaml:"webhook,omitempty"`             // WebHook(s) to send on a new release
+	SSH          SSHProfiles   `json:"ssh,omitempty" yaml:"ssh,omitempty"`                     // SSH connection profiles
 	Service      *ServiceSlice `json:"service,omitempty" yaml:"service,omitempty"`             // The service(s) to monitor
 	Order        []string      `json:"order,omitempty" yaml:"order,omitempty"`                 // Ordering for the Service(s) in the WebUI
 }
 
+// SSHProfiles is a map of SSHProfile.
+type SSHProfiles map[string]*SSHProfile
+
+// SSHProfile is an SSH connection profile.
+type SSHProfile struct {
+	Host       string `json:"host,omitempty" yaml:"host,omitempty"`               // host[:port] to connect to
+	User       string `json:"user,omitempty" yaml:"user,omitempty"`               // User to connect as
+	Key        string `json:"key,omitempty" yaml:"key,omitempty"`                 // Path of the private key
+	Passphrase string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`   // Passphrase of the private key
+	Agent      bool   `json:"agent,omitempty" yaml:"agent,omitempty"`             // Authenticate with the SSH agent
+	KnownHosts string `json:"known_hosts,omitempty" yaml:"known_hosts,omitempty"` // Path of the known_hosts file
+	Timeout    string `json:"timeout,omitempty" yaml:"timeout,omitempty"`         // Time to wait for the connection
+}
+
 // Settings contains settings for the program.
 type Settings struct {
-	Log LogSettings `json:"log,omitempty" yaml:"log,omitempty"`
-	Web WebSettings `json:"web,omitempty" yaml:"web,omitempty"`
+	Log       LogSettings        `json:"log,omitempty" yaml:"log,omitempty"`
+	Web       WebSettings        `json:"web,omitempty" yaml:"web,omitempty"`
+	RateLimit *RateLimitSettings `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+}
+
+// RateLimitSettings contains the limits on the outbound requests to each host.
+type RateLimitSettings struct {
+	RateLimit `json:",inline" yaml:",inline"` // Limit of each host that doesn't match any of the hosts
+	Hosts     []*RateLimitHost                `json:"hosts,omitempty" yaml:"hosts,omitempty"` // Limits of the hosts matching a pattern
+}
+
+// RateLimit on the requests to a host.
+type RateLimit struct {
+	Requests      *uint  `json:"requests,omitempty" yaml:"requests,omitempty"`             // Requests allowed to the host per interval
+	Interval      string `json:"interval,omitempty" yaml:"interval,omitempty"`             // Interval that the requests are allowed in
+	MaxConcurrent *uint  `json:"max_concurrent,omitempty" yaml:"max_concurrent,omitempty"` // Requests allowed to the host at once
+}
+
+// RateLimitHost is the RateLimit of the hosts matching the host pattern.
+type RateLimitHost struct {
+	Host      string `json:"host,omitempty" yaml:"host,omitempty"` // Pattern of the hosts
+	RateLimit `json:",inline" yaml:",inline"`
 }
 
 // LogSettings contains web settings for the program.
@@ -347,6 +470,7 @@ type Service struct {
 	Options               *ServiceOptions        `json:"options,omitempty" yaml:"options,omitempty"`                   // Options to give the Service
 	LatestVersion         *LatestVersion         `json:"latest_version,omitempty" yaml:"latest_version,omitempty"`     // Latest version lookup for the Service
 	Command               *CommandSlice          `json:"command,omitempty" yaml:"command,omitempty"`                   // OS Commands to run on new release
+	CommandSSH            string                 `json:"command_ssh,omitempty" yaml:"command_ssh,omitempty"`           // SSH profile to run the Commands with
 	Notify                *NotifySlice           `json:"notify,omitempty" yaml:"notify,omitempty"`                     // Service-specific Notify vars
 	WebHook               *WebHookSlice          `json:"webhook,omitempty" yaml:"webhook,omitempty"`                   // Service-specific WebHook vars
 	DeployedVersionLookup *DeployedVersionLookup `json:"deployed_version,omitempty" yaml:"deployed_version,omitempty"` // Var to scrape the Service's current deployed version
@@ -379,6 +503,11 @@ type ServiceDefaults struct {
 type ServiceOptions struct {
 	Active             *bool  `json:"active,omitempty" yaml:"active,omitempty"`                           // Active Service?
 	Interval           string `json:"interval,omitempty" yaml:"interval,omitempty"`                       // AhBmCs = Sleep A hours, B minutes and C seconds between queries
+	Schedule           string `json:"schedule,omitempty" yaml:"schedule,omitempty"`                       // Cron expression(s) of when to query
+	Timezone           string `json:"timezone,omitempty" yaml:"timezone,omitempty"`                       // Timezone of the schedule/quiet_hours
+	QuietHours         string `json:"quiet_hours,omitempty" yaml:"quiet_hours,omitempty"`                 // Windows to not query in
+	Jitter             string `json:"jitter,omitempty" yaml:"jitter,omitempty"`                           // Maximum random delay added to each query
+	BackoffMax         string `json:"backoff_max,omitempty" yaml:"backoff_max,omitempty"`                 // Cap on the backoff after consecutive failed queries
 	SemanticVersioning *bool  `json:"semantic_versioning,omitempty" yaml:"semantic_versioning,omitempty"` // default - true = Version has to be greater than the previous to trigger alerts/WebHooks
 }
 
@@ -397,8 +526,23 @@ type LatestVersion struct {
 	AccessToken       string                `json:"access_token,omitempty" yaml:"access_token,omitempty"`               // GitHub access token to use
 	AllowInvalidCerts *bool                 `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
 	UsePreRelease     *bool                 `json:"use_prerelease,omitempty" yaml:"use_prerelease,omitempty"`           // Whether GitHub prereleases should be used
+	Auth              *Auth                 `json:"auth,omitempty" yaml:"auth,omitempty"`                               // Bearer/OAuth2/API key auth for the URL request
 	URLCommands       *URLCommandSlice      `json:"url_commands,omitempty" yaml:"url_commands,omitempty"`               // Commands to filter the release from the URL request
 	Require           *LatestVersionRequire `json:"require,omitempty" yaml:"require,omitempty"`                         // Requirements for the version to be considered valid
+	Sources           []LatestVersionSource `json:"sources,omitempty" yaml:"sources,omitempty"`                         // Additional sources to query
+	Strategy          string                `json:"strategy,omitempty" yaml:"strategy,omitempty"`                       // How to combine the sources, first_success/agree
+	Quorum            int                   `json:"quorum,omitempty" yaml:"quorum,omitempty"`                           // Number of sources that have to agree on a version
+	ChangelogURL      string                `json:"changelog_url,omitempty" yaml:"changelog_url,omitempty"`             // URL of a CHANGELOG to get the release notes from
+}
+
+// LatestVersionSource is an additional source of a LatestVersion.
+type LatestVersionSource struct {
+	Type              string           `json:"type,omitempty" yaml:"type,omitempty"`                               // Source Type, github/url
+	URL               string           `json:"url,omitempty" yaml:"url,omitempty"`                                 // URL to query
+	AccessToken       string           `json:"access_token,omitempty" yaml:"access_token,omitempty"`               // GitHub access token to use
+	AllowInvalidCerts *bool            `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	UsePreRelease     *bool            `json:"use_prerelease,omitempty" yaml:"use_prerelease,omitempty"`           // Whether GitHub prereleases should be used
+	URLCommands       *URLCommandSlice `json:"url_commands,omitempty" yaml:"url_commands,omitempty"`               // Commands to filter the release from the URL request
 }
 
 // String returns a string representation of the LatestVersion.
@@ -435,8 +579,15 @@ func (r *LatestVersionRequireDefaults) String() string {
 type LatestVersionRequire struct {
 	Command      []string            `json:"command,omitempty" yaml:"command,omitempty"`             // Require Command to pass
 	Docker       *RequireDockerCheck `json:"docker,omitempty" yaml:"docker,omitempty"`               // Docker image tag requirements
+	Expression   string              `json:"expression,omitempty" yaml:"expression,omitempty"`       // This expression must be true for the release
+	HTTP         *RequireHTTPCheck   `json:"http,omitempty" yaml:"http,omitempty"`                   // HTTP URL that must be available
+	Verify       *RequireVerifyCheck `json:"verify,omitempty" yaml:"verify,omitempty"`               // Artifact that must match its checksum (and signature)
+	MinAge       string              `json:"min_age,omitempty" yaml:"min_age,omitempty"`             // AhBmCs = Release must have been published/seen for A hours, B minutes and C seconds
 	RegexContent string              `json:"regex_content,omitempty" yaml:"regex_content,omitempty"` // "abc-[a-z]+-{{ version }}_amd64.deb" This regex must exist in the body of the URL to trigger new version actions
 	RegexVersion string              `json:"regex_version,omitempty" yaml:"regex_version,omitempty"` // "v*[0-9.]+" The version found must match this release to trigger new version actions
+	IgnoreDrafts bool                `json:"ignore_drafts,omitempty" yaml:"ignore_drafts,omitempty"` // type:github - Ignore releases that are drafts
+	RegexBranch  string              `json:"regex_branch,omitempty" yaml:"regex_branch,omitempty"`   // type:github - The target_commitish of the release must match this RegEx
+	RegexAuthor  string              `json:"regex_author,omitempty" yaml:"regex_author,omitempty"`   // type:github - The login of the release author must match this RegEx
 }
 
 // String returns a string representation of the LatestVersionRequire.
@@ -469,26 +620,100 @@ type RequireDockerCheckDefaults struct {
 }
 
 type RequireDockerCheck struct {
-	Type     string `json:"type,omitempty" yaml:"type,omitempty"`         // Where to check, e.g. hub (DockerHub), GHCR, Quay
-	Image    string `json:"image,omitempty" yaml:"image,omitempty"`       // Image to check
-	Tag      string `json:"tag,omitempty" yaml:"tag,omitempty"`           // Tag to check for
-	Username string `json:"username,omitempty" yaml:"username,omitempty"` // Username to get a new token
-	Token    string `json:"token,omitempty" yaml:"token,omitempty"`       // Token to get the token for the queries
+	Type      string   `json:"type,omitempty" yaml:"type,omitempty"`           // Where to check, e.g. hub (DockerHub), GHCR, Quay, registry
+	Registry  string   `json:"registry,omitempty" yaml:"registry,omitempty"`   // URL of the registry (type:registry)
+	Image     string   `json:"image,omitempty" yaml:"image,omitempty"`         // Image to check
+	Tag       string   `json:"tag,omitempty" yaml:"tag,omitempty"`             // Tag to check for
+	Platforms []string `json:"platforms,omitempty" yaml:"platforms,omitempty"` // Platforms the tag has to be available for
+	Username  string   `json:"username,omitempty" yaml:"username,omitempty"`   // Username to get a new token
+	Token     string   `json:"token,omitempty" yaml:"token,omitempty"`         // Token to get the token for the queries
+}
+
+// RequireHTTPCheck is a HTTP URL that must be available for a version.
+type RequireHTTPCheck struct {
+	Method            string     `json:"method,omitempty" yaml:"method,omitempty"`                           // HEAD/GET
+	URL               string     `json:"url,omitempty" yaml:"url,omitempty"`                                 // URL to query
+	AllowInvalidCerts bool       `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	BasicAuth         *BasicAuth `json:"basic_auth,omitempty" yaml:"basic_auth,omitempty"`                   // Basic Auth credentials
+	Headers           []Header   `json:"headers,omitempty" yaml:"headers,omitempty"`                         // Request Headers
+	StatusCode        int        `json:"status_code,omitempty" yaml:"status_code,omitempty"`                 // Status code expected
+	RegexContent      string     `json:"regex_content,omitempty" yaml:"regex_content,omitempty"`             // This regex must exist in the body
+}
+
+// RequireVerifyCheck is an artifact that must match its checksum (and signature) for a version.
+type RequireVerifyCheck struct {
+	URL               string `json:"url,omitempty" yaml:"url,omitempty"`                                 // Artifact URL
+	ChecksumsURL      string `json:"checksums_url,omitempty" yaml:"checksums_url,omitempty"`             // SHA256SUMS URL
+	Filename          string `json:"filename,omitempty" yaml:"filename,omitempty"`                       // Name of the artifact in the checksums file
+	SignatureURL      string `json:"signature_url,omitempty" yaml:"signature_url,omitempty"`             // Detached signature of the checksums file
+	SignatureType     string `json:"signature_type,omitempty" yaml:"signature_type,omitempty"`           // minisign/cosign
+	PublicKey         string `json:"public_key,omitempty" yaml:"public_key,omitempty"`                   // Public key to verify the signature with
+	AllowInvalidCerts bool   `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
 }
 
 // DeployedVersionLookup of the service.
 type DeployedVersionLookup struct {
-	Method            string                 `json:"method,omitempty" yaml:"method,omitempty"`                           // HTTP method.
-	URL               string                 `json:"url,omitempty" yaml:"url,omitempty"`                                 // URL to query.
-	AllowInvalidCerts *bool                  `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
-	BasicAuth         *BasicAuth             `json:"basic_auth,omitempty" yaml:"basic_auth,omitempty"`                   // Basic Auth credentials.
-	Headers           []Header               `json:"headers,omitempty" yaml:"headers,omitempty"`                         // Request Headers.
-	Body              *string                `json:"body,omitempty" yaml:"body,omitempty"`                               // Request Body.
-	JSON              string                 `json:"json,omitempty" yaml:"json,omitempty"`                               // JSON key to use e.g. version_current.
-	Regex             string                 `json:"regex,omitempty" yaml:"regex,omitempty"`                             // Regex for the version.
-	RegexTemplate     *string                `json:"regex_template,omitempty" yaml:"regex_template,omitempty"`           // Template to apply to the RegEx match.
-	HardDefaults      *DeployedVersionLookup `json:"-" yaml:"-"`                                                         // Hardcoded default values.
-	Defaults          *DeployedVersionLookup `json:"-" yaml:"-"`                                                         // Default values.
+	Type              string                     `json:"type,omitempty" yaml:"type,omitempty"`                               // url/command/file/docker/kubernetes.
+	Method            string                     `json:"method,omitempty" yaml:"method,omitempty"`                           // HTTP method.
+	URL               string                     `json:"url,omitempty" yaml:"url,omitempty"`                                 // URL to query.
+	AllowInvalidCerts *bool                      `json:"allow_invalid_certs,omitempty" yaml:"allow_invalid_certs,omitempty"` // default - false = Disallows invalid HTTPS certificates
+	Interval          string                     `json:"interval,omitempty" yaml:"interval,omitempty"`                       // Interval between queries.
+	WatchInterval     string                     `json:"watch_interval,omitempty" yaml:"watch_interval,omitempty"`           // Interval between queries after actions have run.
+	WatchTimeout      string                     `json:"watch_timeout,omitempty" yaml:"watch_timeout,omitempty"`             // Time to watch for the approved version after actions have run.
+	BasicAuth         *BasicAuth                 `json:"basic_auth,omitempty" yaml:"basic_auth,omitempty"`                   // Basic Auth credentials.
+	Headers           []Header                   `json:"headers,omitempty" yaml:"headers,omitempty"`                         // Request Headers.
+	Auth              *Auth                      `json:"auth,omitempty" yaml:"auth,omitempty"`                               // Bearer/OAuth2/API key auth.
+	Body              *string                    `json:"body,omitempty" yaml:"body,omitempty"`                               // Request Body.
+	Command           []string                   `json:"command,omitempty" yaml:"command,omitempty"`                         // Command to run.
+	Timeout           string                     `json:"timeout,omitempty" yaml:"timeout,omitempty"`                         // Time the command can run for.
+	SSH               string                     `json:"ssh,omitempty" yaml:"ssh,omitempty"`                                 // SSH profile to run the command with.
+	Path              string                     `json:"path,omitempty" yaml:"path,omitempty"`                               // Path of the file to read.
+	Format            string                     `json:"format,omitempty" yaml:"format,omitempty"`                           // Format to get the Key from.
+	Key               string                     `json:"key,omitempty" yaml:"key,omitempty"`                                 // Key in the Format to use.
+	Docker            *DeployedVersionDocker     `json:"docker,omitempty" yaml:"docker,omitempty"`                           // Container to get the version of.
+	Kubernetes        *DeployedVersionKubernetes `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`                   // Workload to get the version of.
+	Targets           []string                   `json:"targets,omitempty" yaml:"targets,omitempty"`                         // Instances to query.
+	Policy            string                     `json:"policy,omitempty" yaml:"policy,omitempty"`                           // min/max/majority/all_equal.
+	NotifyDrift       bool                       `json:"notify_drift,omitempty" yaml:"notify_drift,omitempty"`               // Notify when the targets have different versions.
+	Header            string                     `json:"header,omitempty" yaml:"header,omitempty"`                           // Response header to get the version from.
+	Prometheus        *DeployedVersionPrometheus `json:"prometheus,omitempty" yaml:"prometheus,omitempty"`                   // Prometheus metric to get the version from.
+	JSON              string                     `json:"json,omitempty" yaml:"json,omitempty"`                               // JSON key to use e.g. version_current.
+	Regex             string                     `json:"regex,omitempty" yaml:"regex,omitempty"`                             // Regex for the version.
+	RegexTemplate     *string                    `json:"regex_template,omitempty" yaml:"regex_template,omitempty"`           // Template to apply to the RegEx match.
+	HardDefaults      *DeployedVersionLookup     `json:"-" yaml:"-"`                                                         // Hardcoded default values.
+	Defaults          *DeployedVersionLookup     `json:"-" yaml:"-"`                                                         // Default values.
+}
+
+// DeployedVersionPrometheus is the Prometheus metric to get the deployed version from.
+type DeployedVersionPrometheus struct {
+	Metric string            `json:"metric,omitempty" yaml:"metric,omitempty"` // Name of the metric.
+	Label  string            `json:"label,omitempty" yaml:"label,omitempty"`   // Label to use the value of.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"` // Labels the sample must have.
+}
+
+// DeployedVersionDocker is the container to get the deployed version of.
+type DeployedVersionDocker struct {
+	Host      string            `json:"host,omitempty" yaml:"host,omitempty"`           // Docker Engine API.
+	CACert    string            `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`     // Path of the CA certificate.
+	Cert      string            `json:"cert,omitempty" yaml:"cert,omitempty"`           // Path of the client certificate.
+	Key       string            `json:"key,omitempty" yaml:"key,omitempty"`             // Path of the client key.
+	Container string            `json:"container,omitempty" yaml:"container,omitempty"` // Name/ID of the container.
+	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`       // Labels to find the container by.
+	Source    string            `json:"source,omitempty" yaml:"source,omitempty"`       // tag/label/digest.
+	Label     string            `json:"label,omitempty" yaml:"label,omitempty"`         // Label to use (source:label).
+}
+
+// DeployedVersionKubernetes is the workload to get the deployed version of.
+type DeployedVersionKubernetes struct {
+	Kubeconfig   string `json:"kubeconfig,omitempty" yaml:"kubeconfig,omitempty"`       // Path of the kubeconfig.
+	Context      string `json:"context,omitempty" yaml:"context,omitempty"`             // Context of the kubeconfig.
+	Namespace    string `json:"namespace,omitempty" yaml:"namespace,omitempty"`         // Namespace of the workload.
+	Kind         string `json:"kind,omitempty" yaml:"kind,omitempty"`                   // deployment/statefulset/daemonset.
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`                   // Name of the workload.
+	Container    string `json:"container,omitempty" yaml:"container,omitempty"`         // Container to get the image tag of.
+	Source       string `json:"source,omitempty" yaml:"source,omitempty"`               // tag/label/annotation.
+	Key          string `json:"key,omitempty" yaml:"key,omitempty"`                     // Key of the label/annotation.
+	RequireReady bool   `json:"require_ready,omitempty" yaml:"require_ready,omitempty"` // Require all replicas to be updated and ready.
 }
 
 // String returns a JSON string representation of the DeployedVersionLookup.
@@ -505,6 +730,18 @@ type BasicAuth struct {
 	Password string `json:"password" yaml:"password"`
 }
 
+// Auth to use on the HTTP(s) request.
+type Auth struct {
+	Type         string   `json:"type,omitempty" yaml:"type,omitempty"`                   // bearer/oauth2/api_key
+	Token        string   `json:"token,omitempty" yaml:"token,omitempty"`                 // type:bearer - Token
+	TokenURL     string   `json:"token_url,omitempty" yaml:"token_url,omitempty"`         // type:oauth2 - URL to get the access token from
+	ClientID     string   `json:"client_id,omitempty" yaml:"client_id,omitempty"`         // type:oauth2 - Client ID
+	ClientSecret string   `json:"client_secret,omitempty" yaml:"client_secret,omitempty"` // type:oauth2 - Client Secret
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`               // type:oauth2 - Scopes to request
+	Header       string   `json:"header,omitempty" yaml:"header,omitempty"`               // type:api_key - Header to send the Key in
+	Key          string   `json:"key,omitempty" yaml:"key,omitempty"`                     // type:api_key - API Key
+}
+
 // Header to use in the HTTP request.
 type Header struct {
 	Key   string `json:"key" yaml:"key"`     // Header key, e.g. X-Sig
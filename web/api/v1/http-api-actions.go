@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/release-argus/Argus/service"
 	"github.com/release-argus/Argus/util"
 	api_type "github.com/release-argus/Argus/web/api/types"
 )
@@ -155,12 +156,12 @@ func (api *API) httpServiceRunActions(w http.ResponseWriter, r *http.Request) {
 	jLog.Info(msg, logFrom, true)
 	switch *payload.Target {
 	case "ARGUS_ALL", "ARGUS_FAILED":
-		go svc.HandleFailedActions()
+		service.GoAction(svc.HandleFailedActions)
 	default:
 		if strings.HasPrefix(*payload.Target, "webhook_") {
-			go svc.HandleWebHook(strings.TrimPrefix(*payload.Target, "webhook_"))
+			service.GoAction(func() { svc.HandleWebHook(strings.TrimPrefix(*payload.Target, "webhook_")) })
 		} else {
-			go svc.HandleCommand(strings.TrimPrefix(*payload.Target, "command_"))
+			service.GoAction(func() { svc.HandleCommand(strings.TrimPrefix(*payload.Target, "command_")) })
 		}
 	}
 }
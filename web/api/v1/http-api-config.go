@@ -39,7 +39,8 @@ func (api *API) httpConfig(w http.ResponseWriter, r *http.Request) {
 			ListenPort:  api.Config.Settings.Web.ListenPort,
 			CertFile:    api.Config.Settings.Web.CertFile,
 			KeyFile:     api.Config.Settings.Web.KeyFile,
-			RoutePrefix: api.Config.Settings.Web.RoutePrefix}}
+			RoutePrefix: api.Config.Settings.Web.RoutePrefix},
+		RateLimit: convertRateLimitSettings(api.Config.Settings.RateLimit)}
 
 	// Defaults
 	serviceLatestVersionRequireDefaults := convertAndCensorLatestVersionRequireDefaults(&api.Config.Defaults.Service.LatestVersion.Require)
@@ -93,6 +94,9 @@ func (api *API) httpConfig(w http.ResponseWriter, r *http.Request) {
 	// WebHook
 	cfg.WebHook = convertAndCensorWebHookSliceDefaults(&api.Config.WebHook)
 
+	// SSH
+	cfg.SSH = convertAndCensorSSHProfiles(&api.Config.SSH)
+
 	// Service
 	api.Config.OrderMutex.RLock()
 	serviceConfig := make(api_type.ServiceSlice, len(api.Config.Order))
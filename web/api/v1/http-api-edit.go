@@ -79,8 +79,10 @@ func (api *API) httpVersionRefreshUncreated(w http.ResponseWriter, r *http.Reque
 			getParam(&queryParams, "allow_invalid_certs"),
 			getParam(&queryParams, "basic_auth"),
 			getParam(&queryParams, "body"),
+			getParam(&queryParams, "format"),
 			getParam(&queryParams, "headers"),
 			getParam(&queryParams, "json"),
+			getParam(&queryParams, "key"),
 			getParam(&queryParams, "method"),
 			getParam(&queryParams, "regex"),
 			getParam(&queryParams, "regex_template"),
@@ -173,8 +175,10 @@ func (api *API) httpVersionRefresh(w http.ResponseWriter, r *http.Request) {
 			getParam(&queryParams, "allow_invalid_certs"),
 			getParam(&queryParams, "basic_auth"),
 			getParam(&queryParams, "body"),
+			getParam(&queryParams, "format"),
 			getParam(&queryParams, "headers"),
 			getParam(&queryParams, "json"),
+			getParam(&queryParams, "key"),
 			getParam(&queryParams, "method"),
 			getParam(&queryParams, "regex"),
 			getParam(&queryParams, "regex_template"),
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -152,8 +153,8 @@ func TestHTTP_VersionRefreshUncreated(t *testing.T) {
 func TestHTTP_VersionRefresh(t *testing.T) {
 	testSVC := testService("TestHTTP_VersionRefresh")
 	testSVC.LatestVersion.Status.SetLatestVersion("1.0.0", false)
-	testSVC.LatestVersion.Query(true, &util.LogFrom{})
-	v, _ := testSVC.DeployedVersionLookup.Query(true, &util.LogFrom{})
+	testSVC.LatestVersion.Query(context.Background(), true, &util.LogFrom{})
+	v, _ := testSVC.DeployedVersionLookup.Query(context.Background(), true, &util.LogFrom{})
 	testSVC.Status.SetDeployedVersion(v, false)
 	// GIVEN an API and a request to refresh the x_version of a service
 	file := "TestHTTP_VersionRefresh.yml"
@@ -481,8 +482,8 @@ func TestHTTP_OtherServiceDetails(t *testing.T) {
 func TestHTTP_ServiceEdit(t *testing.T) {
 	testSVC := testService("TestHTTP_ServiceEdit")
 	testSVC.LatestVersion.Status.SetLatestVersion("1.0.0", false)
-	testSVC.LatestVersion.Query(true, &util.LogFrom{})
-	v, _ := testSVC.DeployedVersionLookup.Query(true, &util.LogFrom{})
+	testSVC.LatestVersion.Query(context.Background(), true, &util.LogFrom{})
+	v, _ := testSVC.DeployedVersionLookup.Query(context.Background(), true, &util.LogFrom{})
 	testSVC.Status.SetDeployedVersion(v, false)
 	// GIVEN an API and a request to create/edit a service
 	file := "TestHTTP_ServiceEdit.yml"
@@ -60,3 +60,33 @@ func (api *API) httpServiceSummary(w http.ResponseWriter, r *http.Request) {
 	err := json.NewEncoder(w).Encode(summary)
 	jLog.Error(err, logFrom, err != nil)
 }
+
+func (api *API) httpServiceReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	logFrom := &util.LogFrom{Primary: "httpServiceReleaseNotes", Secondary: getIP(r)}
+	targetService, _ := url.QueryUnescape(mux.Vars(r)["service_name"])
+	jLog.Verbose(targetService, logFrom, true)
+
+	// Check Service still exists in this ordering
+	api.Config.OrderMutex.RLock()
+	service := api.Config.Service[targetService]
+	api.Config.OrderMutex.RUnlock()
+	if service == nil {
+		err := fmt.Sprintf("service %q not found", targetService)
+		jLog.Error(err, logFrom, true)
+		failRequest(&w, err, http.StatusNotFound)
+		return
+	}
+
+	// Get the release notes between the deployed and latest versions
+	notes, err := service.LatestVersion.ReleaseNotes(logFrom)
+	if err != nil {
+		jLog.Error(err, logFrom, true)
+		failRequest(&w, err.Error())
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(convertReleaseNotes(
+		service.Status.DeployedVersion(), service.Status.LatestVersion(),
+		notes))
+	jLog.Error(err, logFrom, err != nil)
+}
@@ -163,3 +163,103 @@ func TestHTTP_httpServiceSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTP_httpServiceReleaseNotes(t *testing.T) {
+	// GIVEN an API and a request for the release notes of a service
+	changelogServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/CHANGELOG.md" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "# 1.2.0\nfoo\n# 1.1.0\nbar\n# 1.0.0\nbaz")
+	}))
+	t.Cleanup(changelogServer.Close)
+	file := "TestHTTP_httpServiceReleaseNotes.yml"
+	api := testAPI(file)
+	defer func() {
+		os.RemoveAll(file)
+		if api.Config.Settings.Data.DatabaseFile != nil {
+			os.RemoveAll(*api.Config.Settings.Data.DatabaseFile)
+		}
+	}()
+
+	tests := map[string]struct {
+		serviceName    string
+		changelogURL   string
+		wantBody       string
+		wantStatusCode int
+	}{
+		"no release notes source": {
+			serviceName:    "TestHTTP_httpServiceReleaseNotes-none",
+			wantBody:       `^\{"deployed_version":"1.0.0","latest_version":"1.2.0","notes":\[\]\}`,
+			wantStatusCode: http.StatusOK,
+		},
+		"changelog_url": {
+			serviceName:  "TestHTTP_httpServiceReleaseNotes-changelog",
+			changelogURL: changelogServer.URL + "/CHANGELOG.md",
+			wantBody: `^\{"deployed_version":"1.0.0","latest_version":"1.2.0","notes":\[` +
+				`\{"version":"1.2.0","body":"foo"\},\{"version":"1.1.0","body":"bar"\}\]\}`,
+			wantStatusCode: http.StatusOK,
+		},
+		"changelog_url not found": {
+			serviceName:    "TestHTTP_httpServiceReleaseNotes-404",
+			changelogURL:   changelogServer.URL + "/unknown",
+			wantBody:       `\{"message":"changelog_url .+ returned status 404"`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		"unknown service": {
+			serviceName:    "bish-bash-bosh",
+			wantBody:       `\{"message":"service .+ not found"`,
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range tests {
+		if tc.serviceName == "bish-bash-bosh" {
+			continue
+		}
+		svc := testService(tc.serviceName)
+		svc.LatestVersion.ChangelogURL = tc.changelogURL
+		svc.Status.SetDeployedVersion("1.0.0", false)
+		svc.Status.SetLatestVersion("1.2.0", false)
+		api.Config.Service[svc.ID] = svc
+		api.Config.Order = append(api.Config.Order, svc.ID)
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			target := "/api/v1/service/release_notes/"
+			target += url.QueryEscape(tc.serviceName)
+
+			// WHEN that HTTP request is sent
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			vars := map[string]string{
+				"service_name": tc.serviceName}
+			req = mux.SetURLVars(req, vars)
+			w := httptest.NewRecorder()
+			api.httpServiceReleaseNotes(w, req)
+			res := w.Result()
+			defer res.Body.Close()
+
+			// THEN the expected status code is returned
+			if res.StatusCode != tc.wantStatusCode {
+				t.Errorf("Status code, expected a %d, not a %d",
+					tc.wantStatusCode, res.StatusCode)
+			}
+			// AND the expected body is returned as expected
+			data, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("unexpected error - %v",
+					err)
+			}
+			got := string(data)
+			re := regexp.MustCompile(tc.wantBody)
+			match := re.MatchString(got)
+			if !match {
+				t.Errorf("want match for %q\nnot: %q",
+					tc.wantBody, got)
+			}
+		})
+	}
+}
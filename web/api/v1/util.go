@@ -21,10 +21,13 @@ import (
 	command "github.com/release-argus/Argus/commands"
 	"github.com/release-argus/Argus/config"
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/service"
+	"github.com/release-argus/Argus/service/auth"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	latestver "github.com/release-argus/Argus/service/latest_version"
 	"github.com/release-argus/Argus/service/latest_version/filter"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/util"
 	api_type "github.com/release-argus/Argus/web/api/types"
 	"github.com/release-argus/Argus/webhook"
@@ -109,6 +112,11 @@ func convertAndCensorDefaults(input *config.Defaults) (defaults *api_type.Defaul
 		Service: api_type.ServiceDefaults{
 			Options: &api_type.ServiceOptions{
 				Interval:           input.Service.Options.Interval,
+				Schedule:           input.Service.Options.Schedule,
+				Timezone:           input.Service.Options.Timezone,
+				QuietHours:         input.Service.Options.QuietHours,
+				Jitter:             input.Service.Options.Jitter,
+				BackoffMax:         input.Service.Options.BackoffMax,
 				SemanticVersioning: input.Service.Options.SemanticVersioning},
 			LatestVersion: &api_type.LatestVersionDefaults{
 				AccessToken:       util.DefaultOrValue(input.Service.LatestVersion.AccessToken, "<secret>"),
@@ -116,7 +124,10 @@ func convertAndCensorDefaults(input *config.Defaults) (defaults *api_type.Defaul
 				UsePreRelease:     input.Service.LatestVersion.UsePreRelease,
 				Require:           convertAndCensorLatestVersionRequireDefaults(&input.Service.LatestVersion.Require)},
 			DeployedVersionLookup: &api_type.DeployedVersionLookup{
-				AllowInvalidCerts: input.Service.DeployedVersionLookup.AllowInvalidCerts},
+				AllowInvalidCerts: input.Service.DeployedVersionLookup.AllowInvalidCerts,
+				Interval:          input.Service.DeployedVersionLookup.Interval,
+				WatchInterval:     input.Service.DeployedVersionLookup.WatchInterval,
+				WatchTimeout:      input.Service.DeployedVersionLookup.WatchTimeout},
 			Dashboard: &api_type.DashboardOptions{
 				AutoApprove: input.Service.Dashboard.AutoApprove}},
 		Notify:  *convertAndCensorNotifySliceDefaults(&input.Notify),
@@ -171,18 +182,64 @@ func convertAndCensorLatestVersionRequire(require *filter.Require) (apiRequire *
 	var docker *api_type.RequireDockerCheck
 	if require.Docker != nil {
 		docker = &api_type.RequireDockerCheck{
-			Type:     require.Docker.Type,
-			Image:    require.Docker.Image,
-			Tag:      require.Docker.Tag,
-			Username: require.Docker.Username,
-			Token:    util.ValueIfNotDefault(require.Docker.Token, "<secret>")}
+			Type:      require.Docker.Type,
+			Registry:  require.Docker.Registry,
+			Image:     require.Docker.Image,
+			Tag:       require.Docker.Tag,
+			Platforms: require.Docker.Platforms,
+			Username:  require.Docker.Username,
+			Token:     util.ValueIfNotDefault(require.Docker.Token, "<secret>")}
+	}
+
+	var httpCheck *api_type.RequireHTTPCheck
+	if require.HTTP != nil {
+		httpCheck = &api_type.RequireHTTPCheck{
+			Method:            require.HTTP.Method,
+			URL:               require.HTTP.URL,
+			AllowInvalidCerts: require.HTTP.AllowInvalidCerts,
+			StatusCode:        require.HTTP.StatusCode,
+			RegexContent:      require.HTTP.RegexContent}
+		// Basic auth
+		if require.HTTP.BasicAuth != nil {
+			httpCheck.BasicAuth = &api_type.BasicAuth{
+				Username: require.HTTP.BasicAuth.Username,
+				Password: "<secret>"}
+		}
+		// Headers
+		if len(require.HTTP.Headers) != 0 {
+			httpCheck.Headers = make([]api_type.Header, len(require.HTTP.Headers))
+			for i := range require.HTTP.Headers {
+				httpCheck.Headers[i] = api_type.Header{
+					Key:   require.HTTP.Headers[i].Key,
+					Value: "<secret>"}
+			}
+		}
+	}
+
+	var verify *api_type.RequireVerifyCheck
+	if require.Verify != nil {
+		verify = &api_type.RequireVerifyCheck{
+			URL:               require.Verify.URL,
+			ChecksumsURL:      require.Verify.ChecksumsURL,
+			Filename:          require.Verify.Filename,
+			SignatureURL:      require.Verify.SignatureURL,
+			SignatureType:     require.Verify.SignatureType,
+			PublicKey:         require.Verify.PublicKey,
+			AllowInvalidCerts: require.Verify.AllowInvalidCerts}
 	}
 
 	apiRequire = &api_type.LatestVersionRequire{
 		Command:      require.Command,
 		Docker:       docker,
+		Expression:   require.Expression,
+		HTTP:         httpCheck,
+		Verify:       verify,
+		MinAge:       require.MinAge,
 		RegexContent: require.RegexContent,
-		RegexVersion: require.RegexVersion}
+		RegexVersion: require.RegexVersion,
+		IgnoreDrafts: require.IgnoreDrafts,
+		RegexBranch:  require.RegexBranch,
+		RegexAuthor:  require.RegexAuthor}
 	return
 }
 
@@ -217,12 +274,69 @@ func convertAndCensorLatestVersion(lv *latestver.Lookup) (apiLV *api_type.Latest
 		AccessToken:       util.DefaultOrValue(lv.AccessToken, "<secret>"),
 		AllowInvalidCerts: lv.AllowInvalidCerts,
 		UsePreRelease:     lv.UsePreRelease,
+		Auth:              convertAndCensorAuth(lv.Auth),
 		URLCommands:       convertURLCommandSlice(&lv.URLCommands),
-		Require:           convertAndCensorLatestVersionRequire(lv.Require)}
+		Require:           convertAndCensorLatestVersionRequire(lv.Require),
+		Strategy:          lv.Strategy,
+		Quorum:            lv.Quorum,
+		ChangelogURL:      lv.ChangelogURL}
+	if len(lv.Sources) != 0 {
+		apiLV.Sources = make([]api_type.LatestVersionSource, len(lv.Sources))
+		for i, source := range lv.Sources {
+			apiLV.Sources[i] = api_type.LatestVersionSource{
+				Type:              source.Type,
+				URL:               source.URL,
+				AccessToken:       util.DefaultOrValue(source.AccessToken, "<secret>"),
+				AllowInvalidCerts: source.AllowInvalidCerts,
+				UsePreRelease:     source.UsePreRelease,
+				URLCommands:       convertURLCommandSlice(&source.URLCommands)}
+		}
+	}
 
 	return
 }
 
+// convertAndCensorAuth will convert the Auth to API Type and censor its secrets.
+func convertAndCensorAuth(a *auth.Auth) (apiAuth *api_type.Auth) {
+	if a == nil {
+		return
+	}
+
+	apiAuth = &api_type.Auth{
+		Type:     a.Type,
+		TokenURL: a.TokenURL,
+		ClientID: a.ClientID,
+		Scopes:   a.Scopes,
+		Header:   a.Header}
+	// Censor the secrets that are set.
+	if a.Token != "" {
+		apiAuth.Token = "<secret>"
+	}
+	if a.ClientSecret != "" {
+		apiAuth.ClientSecret = "<secret>"
+	}
+	if a.Key != "" {
+		apiAuth.Key = "<secret>"
+	}
+	return
+}
+
+// convertReleaseNotes will convert the release notes between `deployedVersion` and `latestVersion` to API Type.
+func convertReleaseNotes(deployedVersion string, latestVersion string, notes []latestver.ReleaseNote) (apiNotes *api_type.ReleaseNotes) {
+	apiNotes = &api_type.ReleaseNotes{
+		DeployedVersion: deployedVersion,
+		LatestVersion:   latestVersion,
+		Notes:           make([]api_type.ReleaseNote, len(notes))}
+	for i := range notes {
+		apiNotes.Notes[i] = api_type.ReleaseNote{
+			Version:     notes[i].Version,
+			Body:        notes[i].Body,
+			URL:         notes[i].URL,
+			PublishedAt: notes[i].PublishedAt}
+	}
+	return
+}
+
 //
 // Deployed Version
 //
@@ -234,14 +348,61 @@ func convertAndCensorDeployedVersionLookup(dvl *deployedver.Lookup) (apiDVL *api
 	}
 	var headers []api_type.Header
 	apiDVL = &api_type.DeployedVersionLookup{
+		Type:              dvl.Type,
 		Method:            dvl.Method,
 		URL:               dvl.URL,
 		AllowInvalidCerts: dvl.AllowInvalidCerts,
+		Interval:          dvl.Interval,
+		WatchInterval:     dvl.WatchInterval,
+		WatchTimeout:      dvl.WatchTimeout,
 		Headers:           headers,
+		Auth:              convertAndCensorAuth(dvl.Auth),
 		Body:              dvl.Body,
+		Command:           dvl.Command,
+		Timeout:           dvl.Timeout,
+		SSH:               dvl.SSH,
+		Path:              dvl.Path,
+		Format:            dvl.Format,
+		Key:               dvl.Key,
+		Targets:           dvl.Targets,
+		Policy:            dvl.Policy,
+		NotifyDrift:       dvl.NotifyDrift,
+		Header:            dvl.Header,
 		JSON:              dvl.JSON,
 		Regex:             dvl.Regex,
 		RegexTemplate:     dvl.RegexTemplate}
+	// Prometheus
+	if dvl.Prometheus != nil {
+		apiDVL.Prometheus = &api_type.DeployedVersionPrometheus{
+			Metric: dvl.Prometheus.Metric,
+			Label:  dvl.Prometheus.Label,
+			Labels: dvl.Prometheus.Labels}
+	}
+	// Docker
+	if dvl.Docker != nil {
+		apiDVL.Docker = &api_type.DeployedVersionDocker{
+			Host:      dvl.Docker.Host,
+			CACert:    dvl.Docker.CACert,
+			Cert:      dvl.Docker.Cert,
+			Key:       dvl.Docker.Key,
+			Container: dvl.Docker.Container,
+			Labels:    dvl.Docker.Labels,
+			Source:    dvl.Docker.Source,
+			Label:     dvl.Docker.Label}
+	}
+	// Kubernetes
+	if dvl.Kubernetes != nil {
+		apiDVL.Kubernetes = &api_type.DeployedVersionKubernetes{
+			Kubeconfig:   dvl.Kubernetes.Kubeconfig,
+			Context:      dvl.Kubernetes.Context,
+			Namespace:    dvl.Kubernetes.Namespace,
+			Kind:         dvl.Kubernetes.Kind,
+			Name:         dvl.Kubernetes.Name,
+			Container:    dvl.Kubernetes.Container,
+			Source:       dvl.Kubernetes.Source,
+			Key:          dvl.Kubernetes.Key,
+			RequireReady: dvl.Kubernetes.RequireReady}
+	}
 	// Basic auth
 	if dvl.BasicAuth != nil {
 		apiDVL.BasicAuth = &api_type.BasicAuth{
@@ -258,6 +419,63 @@ func convertAndCensorDeployedVersionLookup(dvl *deployedver.Lookup) (apiDVL *api
 	return
 }
 
+//
+// SSH
+//
+
+// convertAndCensorSSHProfiles will convert the SSH Profiles to API Type and censor the passphrases.
+func convertAndCensorSSHProfiles(profiles *ssh.Profiles) (apiProfiles api_type.SSHProfiles) {
+	if profiles == nil || len(*profiles) == 0 {
+		return
+	}
+
+	apiProfiles = make(api_type.SSHProfiles, len(*profiles))
+	for name, profile := range *profiles {
+		if profile == nil {
+			continue
+		}
+		apiProfiles[name] = &api_type.SSHProfile{
+			Host:       profile.Host,
+			User:       profile.User,
+			Key:        profile.Key,
+			Agent:      profile.Agent,
+			KnownHosts: profile.KnownHosts,
+			Timeout:    profile.Timeout}
+		if profile.Passphrase != "" {
+			apiProfiles[name].Passphrase = "<secret>"
+		}
+	}
+	return
+}
+
+// convertRateLimitSettings converts ratelimit.Settings to api_type.RateLimitSettings.
+func convertRateLimitSettings(settings *ratelimit.Settings) (apiSettings *api_type.RateLimitSettings) {
+	if settings == nil {
+		return
+	}
+
+	apiSettings = &api_type.RateLimitSettings{
+		RateLimit: api_type.RateLimit{
+			Requests:      settings.Requests,
+			Interval:      settings.Interval,
+			MaxConcurrent: settings.MaxConcurrent}}
+	if len(settings.Hosts) != 0 {
+		apiSettings.Hosts = make([]*api_type.RateLimitHost, 0, len(settings.Hosts))
+		for _, host := range settings.Hosts {
+			if host == nil {
+				continue
+			}
+			apiSettings.Hosts = append(apiSettings.Hosts, &api_type.RateLimitHost{
+				Host: host.Host,
+				RateLimit: api_type.RateLimit{
+					Requests:      host.Requests,
+					Interval:      host.Interval,
+					MaxConcurrent: host.MaxConcurrent}})
+		}
+	}
+	return
+}
+
 //
 // Notify
 //
@@ -423,6 +641,11 @@ func convertAndCensorService(service *service.Service) (apiService *api_type.Ser
 	apiService.Options = &api_type.ServiceOptions{
 		Active:             service.Options.Active,
 		Interval:           service.Options.Interval,
+		Schedule:           service.Options.Schedule,
+		Timezone:           service.Options.Timezone,
+		QuietHours:         service.Options.QuietHours,
+		Jitter:             service.Options.Jitter,
+		BackoffMax:         service.Options.BackoffMax,
 		SemanticVersioning: service.Options.SemanticVersioning}
 
 	// LatestVersion
@@ -433,6 +656,7 @@ func convertAndCensorService(service *service.Service) (apiService *api_type.Ser
 	apiService.Notify = convertAndCensorNotifySlice(&service.Notify)
 	// Command
 	apiService.Command = convertCommandSlice(&service.Command)
+	apiService.CommandSSH = service.CommandSSH
 	// WebHook
 	apiService.WebHook = convertAndCensorWebHookSlice(&service.WebHook)
 
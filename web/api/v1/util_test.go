@@ -18,18 +18,22 @@ package v1
 
 import (
 	"crypto/sha256"
+	"reflect"
 	"testing"
 	"time"
 
 	command "github.com/release-argus/Argus/commands"
 	"github.com/release-argus/Argus/config"
 	"github.com/release-argus/Argus/notifiers/shoutrrr"
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/service"
+	"github.com/release-argus/Argus/service/auth"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	latestver "github.com/release-argus/Argus/service/latest_version"
 	"github.com/release-argus/Argus/service/latest_version/filter"
 	opt "github.com/release-argus/Argus/service/options"
 	svcstatus "github.com/release-argus/Argus/service/status"
+	"github.com/release-argus/Argus/ssh"
 	"github.com/release-argus/Argus/test"
 	api_type "github.com/release-argus/Argus/web/api/types"
 	"github.com/release-argus/Argus/webhook"
@@ -264,6 +268,61 @@ func TestConvertAndCensorNotifySlice(t *testing.T) {
 	}
 }
 
+func TestConvertAndCensorSSHProfiles(t *testing.T) {
+	// GIVEN SSH Profiles
+	tests := map[string]struct {
+		profiles *ssh.Profiles
+		want     api_type.SSHProfiles
+	}{
+		"nil": {
+			profiles: nil,
+			want:     nil},
+		"empty": {
+			profiles: &ssh.Profiles{},
+			want:     nil},
+		"copy and censor passphrase": {
+			profiles: &ssh.Profiles{
+				"legacy": {
+					Host:       "legacy.example.com:2222",
+					User:       "argus",
+					Key:        "/keys/id_ed25519",
+					Passphrase: "shazam",
+					KnownHosts: "/keys/known_hosts",
+					Timeout:    "5s"},
+				"agent": {
+					Host:  "other.example.com",
+					User:  "deploy",
+					Agent: true}},
+			want: api_type.SSHProfiles{
+				"legacy": {
+					Host:       "legacy.example.com:2222",
+					User:       "argus",
+					Key:        "/keys/id_ed25519",
+					Passphrase: "<secret>",
+					KnownHosts: "/keys/known_hosts",
+					Timeout:    "5s"},
+				"agent": {
+					Host:  "other.example.com",
+					User:  "deploy",
+					Agent: true}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN convertAndCensorSSHProfiles is called on it
+			got := convertAndCensorSSHProfiles(tc.profiles)
+
+			// THEN the Profiles are converted correctly
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want:\n%v\ngot:\n%v",
+					tc.want, got)
+			}
+		})
+	}
+}
+
 func TestConvertAndCensorWebHook(t *testing.T) {
 	// GIVEN a WebHook
 	tests := map[string]struct {
@@ -642,6 +701,42 @@ func TestConvertAndCensorLatestVersionRequire(t *testing.T) {
 					Username: "user",
 					Token:    "<secret>"}},
 		},
+		"http": {
+			input: &filter.Require{
+				HTTP: &filter.HTTPCheck{
+					Method:    "GET",
+					URL:       "https://example.com/app-{{ version }}.tar.gz",
+					BasicAuth: &filter.HTTPBasicAuth{Username: "user", Password: "pass"},
+					Headers: []filter.HTTPHeader{
+						{Key: "X-Token", Value: "token"}},
+					StatusCode:   200,
+					RegexContent: "{{ version }}"}},
+			want: &api_type.LatestVersionRequire{
+				HTTP: &api_type.RequireHTTPCheck{
+					Method:    "GET",
+					URL:       "https://example.com/app-{{ version }}.tar.gz",
+					BasicAuth: &api_type.BasicAuth{Username: "user", Password: "<secret>"},
+					Headers: []api_type.Header{
+						{Key: "X-Token", Value: "<secret>"}},
+					StatusCode:   200,
+					RegexContent: "{{ version }}"}},
+		},
+		"verify": {
+			input: &filter.Require{
+				Verify: &filter.VerifyCheck{
+					URL:           "https://example.com/app-{{ version }}.tar.gz",
+					ChecksumsURL:  "https://example.com/{{ version }}/SHA256SUMS",
+					SignatureURL:  "https://example.com/{{ version }}/SHA256SUMS.minisig",
+					SignatureType: "minisign",
+					PublicKey:     "RWQ..."}},
+			want: &api_type.LatestVersionRequire{
+				Verify: &api_type.RequireVerifyCheck{
+					URL:           "https://example.com/app-{{ version }}.tar.gz",
+					ChecksumsURL:  "https://example.com/{{ version }}/SHA256SUMS",
+					SignatureURL:  "https://example.com/{{ version }}/SHA256SUMS.minisig",
+					SignatureType: "minisign",
+					PublicKey:     "RWQ..."}},
+		},
 		"filled": {
 			input: &filter.Require{
 				Status: svcstatus.New(
@@ -652,7 +747,11 @@ func TestConvertAndCensorLatestVersionRequire(t *testing.T) {
 					time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)),
 				RegexContent: ".*",
 				RegexVersion: `([0-9.]+)`,
+				IgnoreDrafts: true,
+				RegexBranch:  "^main$",
+				RegexAuthor:  "^octocat$",
 				Command:      command.Command{"echo", "hello"},
+				Expression:   `bump == "minor"`,
 				Docker: filter.NewDockerCheck(
 					"hub",
 					"release-argus/argus", "{{ version }}",
@@ -660,7 +759,8 @@ func TestConvertAndCensorLatestVersionRequire(t *testing.T) {
 					"", time.Now(),
 					nil)},
 			want: &api_type.LatestVersionRequire{
-				Command: []string{"echo", "hello"},
+				Command:    []string{"echo", "hello"},
+				Expression: `bump == "minor"`,
 				Docker: &api_type.RequireDockerCheck{
 					Type:     "hub",
 					Image:    "release-argus/argus",
@@ -668,7 +768,10 @@ func TestConvertAndCensorLatestVersionRequire(t *testing.T) {
 					Username: "user",
 					Token:    "<secret>"},
 				RegexContent: ".*",
-				RegexVersion: `([0-9.]+)`},
+				RegexVersion: `([0-9.]+)`,
+				IgnoreDrafts: true,
+				RegexBranch:  "^main$",
+				RegexAuthor:  "^octocat$"},
 		},
 	}
 
@@ -756,6 +859,53 @@ func TestConvertAndCensorLatestVersion(t *testing.T) {
 				Require: &api_type.LatestVersionRequire{
 					RegexContent: ".*"}},
 		},
+		"sources": {
+			input: &latestver.Lookup{
+				Type: "github",
+				URL:  "release-argus/argus",
+				Sources: []*latestver.Source{
+					{Type: "url",
+						URL: "https://example.com",
+						LookupBase: latestver.LookupBase{
+							AccessToken: test.StringPtr("sourceToken")},
+						URLCommands: filter.URLCommandSlice{
+							{Type: "regex", Regex: test.StringPtr("([0-9.]+)")}}}},
+				Strategy: "agree",
+				Quorum:   2},
+			want: &api_type.LatestVersion{
+				Type:        "github",
+				URL:         "release-argus/argus",
+				URLCommands: &api_type.URLCommandSlice{},
+				Sources: []api_type.LatestVersionSource{
+					{Type: "url",
+						URL:         "https://example.com",
+						AccessToken: "<secret>",
+						URLCommands: &api_type.URLCommandSlice{
+							{Type: "regex", Regex: test.StringPtr("([0-9.]+)")}}}},
+				Strategy: "agree",
+				Quorum:   2},
+		},
+		"censor auth": {
+			input: &latestver.Lookup{
+				Type: "url",
+				URL:  "https://example.com",
+				Auth: &auth.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "secret",
+					Scopes:       []string{"read"}}},
+			want: &api_type.LatestVersion{
+				Type:        "url",
+				URL:         "https://example.com",
+				URLCommands: &api_type.URLCommandSlice{},
+				Auth: &api_type.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "<secret>",
+					Scopes:       []string{"read"}}},
+		},
 	}
 
 	for name, tc := range tests {
@@ -952,3 +1102,61 @@ func TestConvertAndCensorDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertRateLimitSettings(t *testing.T) {
+	// GIVEN ratelimit Settings
+	tests := map[string]struct {
+		settings *ratelimit.Settings
+		want     *api_type.RateLimitSettings
+	}{
+		"nil": {
+			settings: nil,
+			want:     nil},
+		"empty": {
+			settings: &ratelimit.Settings{},
+			want:     &api_type.RateLimitSettings{}},
+		"with hosts": {
+			settings: &ratelimit.Settings{
+				Limit: ratelimit.Limit{
+					Requests:      test.UIntPtr(60),
+					Interval:      "1m",
+					MaxConcurrent: test.UIntPtr(4)},
+				Hosts: []*ratelimit.HostLimit{
+					{Host: "*.github.com",
+						Limit: ratelimit.Limit{
+							Requests: test.UIntPtr(30),
+							Interval: "1h"}},
+					nil,
+					{Host: "*.docker.io",
+						Limit: ratelimit.Limit{
+							MaxConcurrent: test.UIntPtr(1)}}}},
+			want: &api_type.RateLimitSettings{
+				RateLimit: api_type.RateLimit{
+					Requests:      test.UIntPtr(60),
+					Interval:      "1m",
+					MaxConcurrent: test.UIntPtr(4)},
+				Hosts: []*api_type.RateLimitHost{
+					{Host: "*.github.com",
+						RateLimit: api_type.RateLimit{
+							Requests: test.UIntPtr(30),
+							Interval: "1h"}},
+					{Host: "*.docker.io",
+						RateLimit: api_type.RateLimit{
+							MaxConcurrent: test.UIntPtr(1)}}}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN convertRateLimitSettings is called on it
+			got := convertRateLimitSettings(tc.settings)
+
+			// THEN the Settings are converted correctly
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want:\n%v\ngot:\n%v",
+					tc.want, got)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	command "github.com/release-argus/Argus/commands"
+	"github.com/release-argus/Argus/service/auth"
 	deployedver "github.com/release-argus/Argus/service/deployed_version"
 	"github.com/release-argus/Argus/service/latest_version/filter"
 	opt "github.com/release-argus/Argus/service/options"
@@ -61,6 +62,96 @@ func TestConvertAndCensorDeployedVersionLookup(t *testing.T) {
 				URL:  "https://example.com",
 				JSON: "version"},
 		},
+		"command": {
+			dvl: &deployedver.Lookup{
+				Type:    "command",
+				Command: command.Command{"nginx", "-v"},
+				Timeout: "5s",
+				Regex:   `nginx/([0-9.]+)`},
+			want: &api_type.DeployedVersionLookup{
+				Type:    "command",
+				Command: []string{"nginx", "-v"},
+				Timeout: "5s",
+				Regex:   `nginx/([0-9.]+)`},
+		},
+		"file": {
+			dvl: &deployedver.Lookup{
+				Type:   "file",
+				Path:   "/app/pyproject.toml",
+				Format: "toml",
+				Key:    "tool.poetry.version"},
+			want: &api_type.DeployedVersionLookup{
+				Type:   "file",
+				Path:   "/app/pyproject.toml",
+				Format: "toml",
+				Key:    "tool.poetry.version"},
+		},
+		"docker": {
+			dvl: &deployedver.Lookup{
+				Type: "docker",
+				Docker: &deployedver.DockerLookup{
+					Host:   "tcp://docker.example.com:2376",
+					Labels: map[string]string{"app": "argus"},
+					Source: "label",
+					Label:  "version"}},
+			want: &api_type.DeployedVersionLookup{
+				Type: "docker",
+				Docker: &api_type.DeployedVersionDocker{
+					Host:   "tcp://docker.example.com:2376",
+					Labels: map[string]string{"app": "argus"},
+					Source: "label",
+					Label:  "version"}},
+		},
+		"kubernetes": {
+			dvl: &deployedver.Lookup{
+				Type: "kubernetes",
+				Kubernetes: &deployedver.KubernetesLookup{
+					Namespace:    "argus",
+					Name:         "argus",
+					Container:    "argus",
+					RequireReady: true}},
+			want: &api_type.DeployedVersionLookup{
+				Type: "kubernetes",
+				Kubernetes: &api_type.DeployedVersionKubernetes{
+					Namespace:    "argus",
+					Name:         "argus",
+					Container:    "argus",
+					RequireReady: true}},
+		},
+		"header": {
+			dvl: &deployedver.Lookup{
+				URL:    "https://example.com",
+				Header: "X-Version"},
+			want: &api_type.DeployedVersionLookup{
+				URL:    "https://example.com",
+				Header: "X-Version"},
+		},
+		"prometheus": {
+			dvl: &deployedver.Lookup{
+				URL: "https://example.com/metrics",
+				Prometheus: &deployedver.PrometheusMetric{
+					Metric: "app_build_info",
+					Label:  "version",
+					Labels: map[string]string{"job": "app"}}},
+			want: &api_type.DeployedVersionLookup{
+				URL: "https://example.com/metrics",
+				Prometheus: &api_type.DeployedVersionPrometheus{
+					Metric: "app_build_info",
+					Label:  "version",
+					Labels: map[string]string{"job": "app"}}},
+		},
+		"targets": {
+			dvl: &deployedver.Lookup{
+				URL:         "https://{{ target }}/version",
+				Targets:     []string{"app-1.example.com", "app-2.example.com"},
+				Policy:      "majority",
+				NotifyDrift: true},
+			want: &api_type.DeployedVersionLookup{
+				URL:         "https://{{ target }}/version",
+				Targets:     []string{"app-1.example.com", "app-2.example.com"},
+				Policy:      "majority",
+				NotifyDrift: true},
+		},
 		"censor basic_auth.password": {
 			dvl: &deployedver.Lookup{
 				URL: "https://example.com",
@@ -73,6 +164,50 @@ func TestConvertAndCensorDeployedVersionLookup(t *testing.T) {
 					Username: "alan",
 					Password: "<secret>"}},
 		},
+		"censor auth token": {
+			dvl: &deployedver.Lookup{
+				URL: "https://example.com",
+				Auth: &auth.Auth{
+					Type:  "bearer",
+					Token: "abc"}},
+			want: &api_type.DeployedVersionLookup{
+				URL: "https://example.com",
+				Auth: &api_type.Auth{
+					Type:  "bearer",
+					Token: "<secret>"}},
+		},
+		"censor auth client_secret": {
+			dvl: &deployedver.Lookup{
+				URL: "https://example.com",
+				Auth: &auth.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "secret",
+					Scopes:       []string{"read", "write"}}},
+			want: &api_type.DeployedVersionLookup{
+				URL: "https://example.com",
+				Auth: &api_type.Auth{
+					Type:         "oauth2",
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "argus",
+					ClientSecret: "<secret>",
+					Scopes:       []string{"read", "write"}}},
+		},
+		"censor auth key": {
+			dvl: &deployedver.Lookup{
+				URL: "https://example.com",
+				Auth: &auth.Auth{
+					Type:   "api_key",
+					Header: "X-Token",
+					Key:    "abc"}},
+			want: &api_type.DeployedVersionLookup{
+				URL: "https://example.com",
+				Auth: &api_type.Auth{
+					Type:   "api_key",
+					Header: "X-Token",
+					Key:    "<secret>"}},
+		},
 		"censor headers": {
 			dvl: &deployedver.Lookup{
 				URL: "https://example.com",
@@ -17,6 +17,7 @@
 package web
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -63,7 +64,7 @@ func TestMain(m *testing.M) {
 
 	// WHEN the Router is fetched for this Config
 	router = newWebUI(mainCfg)
-	go Run(mainCfg, jLog)
+	go Run(context.Background(), mainCfg, jLog)
 
 	// THEN Web UI is accessible for the tests
 	code := m.Run()
@@ -210,11 +211,13 @@ func testService(id string) (svc *service.Service) {
 	svc.LatestVersion.Init(
 		&latestver.LookupDefaults{}, &latestver.LookupDefaults{},
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	svc.DeployedVersionLookup.Init(
 		&deployedver.LookupDefaults{}, &deployedver.LookupDefaults{},
 		&svc.Status,
-		&svc.Options)
+		&svc.Options,
+		&svc.Notify)
 	svc.CommandController.Init(
 		&svc.Status,
 		&svc.Command,
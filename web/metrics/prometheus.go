@@ -21,10 +21,17 @@ import (
 
 // Prometheus metric.
 var (
-	// Latest version query successful - 0=no, 1=yes, 2=no_regex_match, 3=semantic_version_fail, 4=progressive_version_fail
+	// Latest version query successful - 0=no, 1=yes, 2=no_regex_match, 3=semantic_version_fail, 4=progressive_version_fail, 5=min_age_pending
 	LatestVersionQueryLiveness = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "latest_version_query_result_last",
-		Help: "Whether this service's last latest version query was successful (0=no, 1=yes, 2=no_regex_match, 3=semantic_version_fail, 4=progressive_version_fail)."},
+		Help: "Whether this service's last latest version query was successful (0=no, 1=yes, 2=no_regex_match, 3=semantic_version_fail, 4=progressive_version_fail, 5=min_age_pending)."},
+		[]string{
+			"id",
+		})
+	// Seconds the next latest version query is backed off for after consecutive failed queries
+	LatestVersionQueryBackoff = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "latest_version_query_backoff_seconds",
+		Help: "Number of seconds this service's next latest version query is backed off for after consecutive failed queries (0=not backing off)."},
 		[]string{
 			"id",
 		})
@@ -36,6 +43,15 @@ var (
 			"id",
 			"result",
 		})
+	// Count of the number of times each latest version source has passed/failed
+	LatestVersionSourceMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "latest_version_source_result_total",
+		Help: "Number of times a latest version source query has passed/failed."},
+		[]string{
+			"id",
+			"result",
+			"service_id",
+		})
 	// Lateest deployed version query successful - 0=no, 1=yes
 	DeployedVersionQueryLiveness = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "deployed_version_query_result_last",
@@ -51,6 +67,13 @@ var (
 			"id",
 			"result",
 		})
+	// Deployed version drift across the targets - 0=no, 1=yes
+	DeployedVersionDrift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "deployed_version_drift",
+		Help: "Whether this service's deployed version targets have different versions (0=no, 1=yes)."},
+		[]string{
+			"id",
+		})
 	// Latest version is deployed - 0=no, 1=yes, 2=approved, 3=skipped
 	LatestVersionIsDeployed = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "latest_version_is_deployed",
@@ -86,6 +109,32 @@ var (
 			"result",
 			"service_id",
 		})
+	// Count of the number of times requests to each host have waited on its rate limit
+	RateLimitWaits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_waits_total",
+		Help: "Number of times a request has waited on the rate limit of its host."},
+		[]string{
+			"host",
+		})
+	// Seconds requests to each host have waited on its rate limit
+	RateLimitWaitSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_wait_seconds_total",
+		Help: "Number of seconds requests have waited on the rate limit of their host."},
+		[]string{
+			"host",
+		})
+	// Number of jobs scheduled
+	SchedulerJobs = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "scheduler_jobs",
+		Help: "Number of jobs (latest/deployed version queries) scheduled."})
+	// Number of jobs that are due and waiting for a worker
+	SchedulerQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "scheduler_queue_depth",
+		Help: "Number of scheduled jobs that are due and waiting for a worker."})
+	// Seconds the last job started after it was due
+	SchedulerLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "scheduler_lag_seconds",
+		Help: "Number of seconds the last job started after it was due."})
 )
 
 // InitPrometheusCounter will set the `metric` counter for the given labels to 0.
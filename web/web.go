@@ -15,8 +15,11 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -27,6 +30,9 @@ import (
 
 var jLog *util.JLog
 
+// ShutdownTimeout is the time given to the requests being served when shutting down.
+const ShutdownTimeout = 10 * time.Second
+
 // NewRouter that serves the Prometheus metrics,
 // WebSocket and NodeJS frontend at the RoutePrefix.
 func NewRouter(cfg *config.Config, hub *api_v1.Hub) *mux.Router {
@@ -66,7 +72,9 @@ func newWebUI(cfg *config.Config) *mux.Router {
 	return router
 }
 
-func Run(cfg *config.Config, log *util.JLog) {
+// Run the Web UI until `ctx` is done, then shut it down gracefully
+// (waiting up to ShutdownTimeout for the requests being served).
+func Run(ctx context.Context, cfg *config.Config, log *util.JLog) {
 	// Only set if unset (avoid RACE condition in tests)
 	if log != nil && jLog == nil {
 		jLog = log
@@ -76,16 +84,34 @@ func Run(cfg *config.Config, log *util.JLog) {
 
 	listenAddress := fmt.Sprintf("%s:%s", cfg.Settings.WebListenHost(), cfg.Settings.WebListenPort())
 	jLog.Info("Listening on "+listenAddress+cfg.Settings.WebRoutePrefix(), &util.LogFrom{}, true)
-
+	server := &http.Server{
+		Addr:    listenAddress,
+		Handler: router}
+
+	// Shut down when ctx is done.
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			jLog.Warn(
+				fmt.Sprintf("Web server shutdown: %s", err),
+				&util.LogFrom{}, true)
+			server.Close()
+		}
+	}()
+
+	var err error
 	if cfg.Settings.WebCertFile() != nil && cfg.Settings.WebKeyFile() != nil {
-		jLog.Fatal(
-			http.ListenAndServeTLS(
-				listenAddress, *cfg.Settings.WebCertFile(), *cfg.Settings.WebKeyFile(), router),
-			&util.LogFrom{}, true)
+		err = server.ListenAndServeTLS(*cfg.Settings.WebCertFile(), *cfg.Settings.WebKeyFile())
 	} else {
-		jLog.Fatal(
-			http.ListenAndServe(
-				listenAddress, router),
-			&util.LogFrom{}, true)
+		err = server.ListenAndServe()
 	}
+	jLog.Fatal(err, &util.LogFrom{}, !errors.Is(err, http.ErrServerClosed))
+
+	// Wait for the requests being served.
+	<-shutdown
 }
@@ -17,6 +17,7 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -41,7 +42,7 @@ func TestMainWithRoutePrefix(t *testing.T) {
 	*cfg.Settings.Web.RoutePrefix = "/test"
 
 	// WHEN the Web UI is started with this Config
-	go Run(cfg, nil)
+	go Run(context.Background(), cfg, nil)
 	time.Sleep(500 * time.Millisecond)
 
 	// THEN Web UI is accessible
@@ -106,6 +107,41 @@ func TestWebAccessible(t *testing.T) {
 	}
 }
 
+func TestRun_Shutdown(t *testing.T) {
+	// GIVEN the Web UI is running
+	cfg := testConfig("TestRun_Shutdown.yml", nil, t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		Run(ctx, cfg, nil)
+		done <- true
+	}()
+	time.Sleep(500 * time.Millisecond)
+	url := fmt.Sprintf("http://localhost:%s/api/v1/version",
+		*cfg.Settings.Web.ListenPort)
+	if resp, err := http.Get(url); err != nil {
+		t.Fatalf("Error making request before the shutdown: %s", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	// WHEN its context is cancelled
+	cancel()
+
+	// THEN Run returns
+	select {
+	case <-done:
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("Run didn't return when its context was cancelled")
+	}
+	// AND the Web UI is no longer accessible
+	if resp, err := http.Get(url); err == nil {
+		resp.Body.Close()
+		t.Errorf("Should have failed to make a request to %s after the shutdown",
+			url)
+	}
+}
+
 func TestAccessibleHTTPS(t *testing.T) {
 	// GIVEN a bunch of URLs to test and the webserver is running with HTTPS
 	tests := map[string]struct {
@@ -133,7 +169,7 @@ func TestAccessibleHTTPS(t *testing.T) {
 	defer os.Remove(*cfg.Settings.Web.KeyFile)
 
 	router = newWebUI(cfg)
-	go Run(cfg, nil)
+	go Run(context.Background(), cfg, nil)
 	time.Sleep(250 * time.Millisecond)
 	address := fmt.Sprintf("https://localhost:%s", *cfg.Settings.Web.ListenPort)
 
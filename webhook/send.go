@@ -24,6 +24,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/release-argus/Argus/ratelimit"
 	"github.com/release-argus/Argus/util"
 	metric "github.com/release-argus/Argus/web/metrics"
 )
@@ -151,7 +152,7 @@ func (w *WebHook) try(logFrom *util.LogFrom) (err error) {
 		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
-	client := &http.Client{Transport: customTransport}
+	client := &http.Client{Transport: ratelimit.Transport(customTransport, logFrom)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return